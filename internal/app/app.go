@@ -9,6 +9,7 @@ import (
 	"fyne.io/fyne/v2/theme"
 
 	"github.com/openpdfreader/openpdfreader/internal/config"
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
 	"github.com/openpdfreader/openpdfreader/internal/ui"
 )
 
@@ -26,6 +27,7 @@ func New() *App {
 	fyneApp := app.NewWithID("com.openpdfreader.app")
 	fyneApp.SetIcon(appIconResource())
 	applyConfiguredTheme(fyneApp, cfg)
+	applyConfiguredRenderer(cfg)
 
 	return &App{
 		fyneApp: fyneApp,
@@ -63,6 +65,16 @@ func applyConfiguredTheme(fyneApp fyne.App, cfg *config.Config) {
 	}
 }
 
+func applyConfiguredRenderer(cfg *config.Config) {
+	r, err := pdf.NewRendererByName(cfg.Renderer)
+	if err != nil {
+		// Unknown or unavailable backends fall back to automatic selection.
+		cfg.Renderer = pdf.BackendAuto
+		r = pdf.NewRenderer()
+	}
+	pdf.SetDefaultRenderer(r)
+}
+
 func normalizeThemeName(name string) string {
 	switch strings.ToLower(strings.TrimSpace(name)) {
 	case "light":
@@ -1,11 +1,17 @@
 package app
 
 import (
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/openpdfreader/openpdfreader/internal/pdf"
 )
@@ -24,10 +30,174 @@ var (
 	cliExportText = func(input, output string) error {
 		return pdf.NewTextExporter().ExportToText(input, output)
 	}
+	cliSetRenderer = func(name string) error {
+		r, err := pdf.NewRendererByName(name)
+		if err != nil {
+			return err
+		}
+		pdf.SetDefaultRenderer(r)
+		return nil
+	}
+	cliListBackends = pdf.RegisteredBackends
+	cliReadFile     = os.ReadFile
+	cliWriteFile    = func(path string, data []byte) error {
+		return os.WriteFile(path, data, 0644)
+	}
+	cliIndex = func(root, indexDir string, progress func(path string)) (pdf.IndexStats, error) {
+		ix, err := pdf.OpenIndex(indexDir)
+		if err != nil {
+			return pdf.IndexStats{}, err
+		}
+		stats, err := ix.Update(root, progress)
+		if err != nil {
+			return stats, err
+		}
+		return stats, ix.Save()
+	}
+	cliSearchIndex = func(indexDir, query string, limit int) ([]pdf.IndexHit, error) {
+		ix, err := pdf.OpenIndex(indexDir)
+		if err != nil {
+			return nil, err
+		}
+		return ix.Search(query, limit)
+	}
+	cliOCR = func(input, output, lang string, progress func(done, total int)) (pdf.OCRResult, error) {
+		return pdf.NewOCRProcessor(pdf.NewTesseractEngine()).Process(input, output, pdf.OCROptions{
+			Language: lang,
+			Progress: progress,
+		})
+	}
+	cliReadOutline = func(input string) ([]pdf.OutlineItem, error) {
+		doc, err := pdf.Open(input)
+		if err != nil {
+			return nil, err
+		}
+		defer doc.Close()
+		return doc.Outline()
+	}
+	cliWriteOutline = func(input, output string, items []pdf.OutlineItem) error {
+		doc, err := pdf.Open(input)
+		if err != nil {
+			return err
+		}
+		defer doc.Close()
+		if err := doc.SetOutline(items); err != nil {
+			return err
+		}
+		return doc.SaveAs(output)
+	}
+	cliReadInfo = func(input string) (pdf.Info, error) {
+		doc, err := pdf.Open(input)
+		if err != nil {
+			return pdf.Info{}, err
+		}
+		defer doc.Close()
+		return doc.Info()
+	}
+	cliUpdateInfo = func(input, output string, update func(*pdf.Info)) error {
+		doc, err := pdf.Open(input)
+		if err != nil {
+			return err
+		}
+		defer doc.Close()
+		info, err := doc.Info()
+		if err != nil {
+			return err
+		}
+		update(&info)
+		if err := doc.SetInfo(info); err != nil {
+			return err
+		}
+		return doc.SaveAs(output)
+	}
+	cliListAttachments = func(input string) ([]pdf.Attachment, error) {
+		doc, err := pdf.Open(input)
+		if err != nil {
+			return nil, err
+		}
+		defer doc.Close()
+		return doc.Attachments()
+	}
+	cliExtractAttachments = func(input string, names []string, outputDir string) ([]string, error) {
+		doc, err := pdf.Open(input)
+		if err != nil {
+			return nil, err
+		}
+		defer doc.Close()
+		return doc.ExtractAttachments(names, outputDir)
+	}
+	cliAddAttachments = func(input, output string, attachments []pdf.Attachment, contents [][]byte) error {
+		doc, err := pdf.Open(input)
+		if err != nil {
+			return err
+		}
+		defer doc.Close()
+		for i, a := range attachments {
+			if err := doc.AddAttachment(a, contents[i]); err != nil {
+				return err
+			}
+		}
+		return doc.SaveAs(output)
+	}
+	cliRemoveAttachments = func(input, output string, names []string) error {
+		doc, err := pdf.Open(input)
+		if err != nil {
+			return err
+		}
+		defer doc.Close()
+		for _, name := range names {
+			if err := doc.RemoveAttachment(name); err != nil {
+				return err
+			}
+		}
+		return doc.SaveAs(output)
+	}
+	cliReadXMP = func(input string) (*pdf.XMP, error) {
+		doc, err := pdf.Open(input)
+		if err != nil {
+			return nil, err
+		}
+		defer doc.Close()
+		return doc.XMP()
+	}
+	cliUpdateXMP = func(input, output string, update func(*pdf.XMP) error) error {
+		doc, err := pdf.Open(input)
+		if err != nil {
+			return err
+		}
+		defer doc.Close()
+		x, err := doc.XMP()
+		if err != nil {
+			return err
+		}
+		if err := update(x); err != nil {
+			return err
+		}
+		if err := doc.SetXMP(x); err != nil {
+			return err
+		}
+		return doc.SaveAs(output)
+	}
+	cliListAnnotations = func(input string) ([]pdf.Annotation, error) {
+		doc, err := pdf.Open(input)
+		if err != nil {
+			return nil, err
+		}
+		defer doc.Close()
+		return doc.Annotations()
+	}
+	cliRemoveAnnotations = func(input, output string, ids []string) (int, error) {
+		return pdf.NewAnnotator().RemoveAnnotations(input, output, ids)
+	}
 )
 
 // RunCLI executes non-GUI PDF operations.
 func RunCLI(args []string, out io.Writer) error {
+	args, err := applyGlobalCLIFlags(args)
+	if err != nil {
+		return err
+	}
+
 	if len(args) == 0 || args[0] == "--help" || args[0] == "-h" {
 		printCLIUsage(out)
 		return nil
@@ -42,6 +212,26 @@ func RunCLI(args []string, out io.Writer) error {
 		return runExportImagesCommand(args[1:], out)
 	case "export-text":
 		return runExportTextCommand(args[1:], out)
+	case "renderers":
+		return runRenderersCommand(args[1:], out)
+	case "index":
+		return runIndexCommand(args[1:], out)
+	case "search":
+		return runSearchCommand(args[1:], out)
+	case "ocr":
+		return runOCRCommand(args[1:], out)
+	case "bookmarks":
+		return runBookmarksCommand(args[1:], out)
+	case "info":
+		return runInfoCommand(args[1:], out)
+	case "set-info":
+		return runSetInfoCommand(args[1:], out)
+	case "xmp":
+		return runXMPCommand(args[1:], out)
+	case "attachments":
+		return runAttachmentsCommand(args[1:], out)
+	case "annotations":
+		return runAnnotationsCommand(args[1:], out)
 	default:
 		return fmt.Errorf("unknown CLI command: %s", args[0])
 	}
@@ -149,6 +339,834 @@ func runExportTextCommand(args []string, out io.Writer) error {
 	return nil
 }
 
+func runIndexCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("index", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	rootFlag := fs.String("root", "", "Folder of PDF files to index")
+	indexFlag := fs.String("index", "", "Index directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	root := strings.TrimSpace(*rootFlag)
+	if root == "" {
+		return errors.New("index requires --root")
+	}
+	indexDir := strings.TrimSpace(*indexFlag)
+	if indexDir == "" {
+		indexDir = pdf.DefaultIndexDir(root)
+	}
+
+	stats, err := cliIndex(root, indexDir, func(path string) {
+		fmt.Fprintf(out, "Indexing %s\n", path)
+	})
+	if err != nil {
+		return err
+	}
+
+	failed := make([]string, 0, len(stats.Failed))
+	for path := range stats.Failed {
+		failed = append(failed, path)
+	}
+	sort.Strings(failed)
+	for _, path := range failed {
+		fmt.Fprintf(out, "Failed %s: %v\n", path, stats.Failed[path])
+	}
+	fmt.Fprintf(out, "Indexed %s into %s: %d added, %d updated, %d unchanged, %d removed, %d failed\n",
+		root, indexDir, stats.Added, stats.Updated, stats.Unchanged, stats.Removed, len(failed))
+	return nil
+}
+
+func runSearchCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	indexFlag := fs.String("index", "", "Index directory")
+	limitFlag := fs.Int("limit", 50, "Maximum number of results, 0 for all")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(*indexFlag) == "" {
+		return errors.New("search requires --index")
+	}
+	query := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if query == "" {
+		return errors.New("search requires a query")
+	}
+
+	hits, err := cliSearchIndex(strings.TrimSpace(*indexFlag), query, *limitFlag)
+	if err != nil {
+		return err
+	}
+	for _, hit := range hits {
+		fmt.Fprintf(out, "%s:%d: %s\n", hit.Path, hit.Page+1, hit.Snippet)
+	}
+	if len(hits) == 0 {
+		fmt.Fprintln(out, "No matches")
+	}
+	return nil
+}
+
+func runOCRCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("ocr", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	inputFlag := fs.String("input", "", "Input PDF file")
+	outputFlag := fs.String("output", "", "Output PDF file")
+	langFlag := fs.String("lang", pdf.DefaultOCRLanguage, "OCR languages, such as eng or deu+eng")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	input := strings.TrimSpace(*inputFlag)
+	output := strings.TrimSpace(*outputFlag)
+	if input == "" {
+		return errors.New("ocr requires --input")
+	}
+	if output == "" {
+		return errors.New("ocr requires --output")
+	}
+
+	result, err := cliOCR(input, output, strings.TrimSpace(*langFlag), func(done, total int) {
+		fmt.Fprintf(out, "Processed page %d of %d\n", done, total)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Recognized %d word(s) on %d page(s) of %s into %s, %d page(s) already had text\n",
+		result.Words, result.Recognized, input, output, result.Skipped)
+	failed := make([]int, 0, len(result.Failed))
+	for page := range result.Failed {
+		failed = append(failed, page)
+	}
+	sort.Ints(failed)
+	for _, page := range failed {
+		fmt.Fprintf(out, "Page %d could not be rendered: %v\n", page+1, result.Failed[page])
+	}
+	return nil
+}
+
+func runBookmarksCommand(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("bookmarks requires import or export")
+	}
+	switch args[0] {
+	case "export":
+		return runBookmarksExportCommand(args[1:], out)
+	case "import":
+		return runBookmarksImportCommand(args[1:], out)
+	default:
+		return fmt.Errorf("unknown bookmarks command: %s", args[0])
+	}
+}
+
+func runBookmarksExportCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("bookmarks export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	inputFlag := fs.String("input", "", "Input PDF file")
+	outputFlag := fs.String("output", "", "Output JSON file, standard output if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	input := strings.TrimSpace(*inputFlag)
+	output := strings.TrimSpace(*outputFlag)
+	if input == "" {
+		return errors.New("bookmarks export requires --input")
+	}
+
+	items, err := cliReadOutline(input)
+	if err != nil {
+		return err
+	}
+	data, err := pdf.MarshalOutlineJSON(items)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if output == "" {
+		_, err := out.Write(data)
+		return err
+	}
+	if err := cliWriteFile(output, data); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Exported %d bookmark(s) from %s into %s\n", countBookmarks(items), input, output)
+	return nil
+}
+
+func runBookmarksImportCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("bookmarks import", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	inputFlag := fs.String("input", "", "Input PDF file")
+	jsonFlag := fs.String("json", "", "Bookmarks JSON file")
+	outputFlag := fs.String("output", "", "Output PDF file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	input := strings.TrimSpace(*inputFlag)
+	jsonPath := strings.TrimSpace(*jsonFlag)
+	output := strings.TrimSpace(*outputFlag)
+	if input == "" {
+		return errors.New("bookmarks import requires --input")
+	}
+	if jsonPath == "" {
+		return errors.New("bookmarks import requires --json")
+	}
+	if output == "" {
+		return errors.New("bookmarks import requires --output")
+	}
+
+	data, err := cliReadFile(jsonPath)
+	if err != nil {
+		return err
+	}
+	items, err := pdf.UnmarshalOutlineJSON(data)
+	if err != nil {
+		return err
+	}
+	if err := cliWriteOutline(input, output, items); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Imported %d bookmark(s) into %s\n", countBookmarks(items), output)
+	return nil
+}
+
+// countBookmarks returns the number of items in an outline at all levels.
+func countBookmarks(items []pdf.OutlineItem) int {
+	n := len(items)
+	for _, item := range items {
+		n += countBookmarks(item.Children)
+	}
+	return n
+}
+
+func runInfoCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("info", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	inputFlag := fs.String("input", "", "Input PDF file")
+	jsonFlag := fs.Bool("json", false, "Print the properties as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	input := strings.TrimSpace(*inputFlag)
+	if input == "" {
+		return errors.New("info requires --input")
+	}
+
+	info, err := cliReadInfo(input)
+	if err != nil {
+		return err
+	}
+	if *jsonFlag {
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", data)
+		return err
+	}
+
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+	date := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("2006-01-02 15:04:05 -07:00")
+	}
+	rows := [][2]string{
+		{"Title", info.Title},
+		{"Author", info.Author},
+		{"Subject", info.Subject},
+		{"Keywords", info.Keywords},
+		{"Creator", info.Creator},
+		{"Producer", info.Producer},
+		{"Created", date(info.Created)},
+		{"Modified", date(info.Modified)},
+		{"PDF version", info.Version},
+		{"Pages", strconv.Itoa(info.PageCount)},
+		{"File size", fmt.Sprintf("%d bytes", info.FileSize)},
+		{"Encrypted", yesNo(info.Encrypted)},
+		{"Tagged", yesNo(info.Tagged)},
+		{"Linearized", yesNo(info.Linearized)},
+	}
+	for _, row := range rows {
+		fmt.Fprintf(out, "%-12s %s\n", row[0]+":", row[1])
+	}
+	return nil
+}
+
+func runSetInfoCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("set-info", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	inputFlag := fs.String("input", "", "Input PDF file")
+	outputFlag := fs.String("output", "", "Output PDF file")
+	fields := map[string]*string{
+		"title":    fs.String("title", "", "Document title"),
+		"author":   fs.String("author", "", "Document author"),
+		"subject":  fs.String("subject", "", "Document subject"),
+		"keywords": fs.String("keywords", "", "Document keywords"),
+		"creator":  fs.String("creator", "", "Application that created the content"),
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	input := strings.TrimSpace(*inputFlag)
+	output := strings.TrimSpace(*outputFlag)
+	if input == "" {
+		return errors.New("set-info requires --input")
+	}
+	if output == "" {
+		return errors.New("set-info requires --output")
+	}
+
+	// Only the fields given change; an empty value clears a field.
+	set := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		if value, ok := fields[f.Name]; ok {
+			set[f.Name] = *value
+		}
+	})
+	if len(set) == 0 {
+		return errors.New("set-info requires at least one of --title, --author, --subject, --keywords or --creator")
+	}
+
+	err := cliUpdateInfo(input, output, func(info *pdf.Info) {
+		for name, value := range set {
+			switch name {
+			case "title":
+				info.Title = value
+			case "author":
+				info.Author = value
+			case "subject":
+				info.Subject = value
+			case "keywords":
+				info.Keywords = value
+			case "creator":
+				info.Creator = value
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Updated %d field(s) of %s into %s\n", len(set), input, output)
+	return nil
+}
+
+// applyGlobalCLIFlags consumes options that precede the command name.
+func runXMPCommand(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("xmp requires get or set")
+	}
+	switch args[0] {
+	case "get":
+		return runXMPGetCommand(args[1:], out)
+	case "set":
+		return runXMPSetCommand(args[1:], out)
+	default:
+		return fmt.Errorf("unknown xmp command: %s", args[0])
+	}
+}
+
+func runXMPGetCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("xmp get", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	inputFlag := fs.String("input", "", "Input PDF file")
+	propertyFlag := fs.String("property", "", "Print only this property, as prefix:name")
+	rawFlag := fs.Bool("raw", false, "Print the XMP packet")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	input := strings.TrimSpace(*inputFlag)
+	if input == "" {
+		return errors.New("xmp get requires --input")
+	}
+
+	x, err := cliReadXMP(input)
+	if err != nil {
+		return err
+	}
+	if *rawFlag {
+		_, err := fmt.Fprintf(out, "%s\n", x.Marshal())
+		return err
+	}
+
+	if name := strings.TrimSpace(*propertyFlag); name != "" {
+		namespace, local, err := parseXMPName(x, name)
+		if err != nil {
+			return err
+		}
+		p, ok := x.Property(namespace, local)
+		if !ok {
+			return fmt.Errorf("xmp property %s not found", name)
+		}
+		fmt.Fprintln(out, formatXMPValue(p))
+		return nil
+	}
+
+	for _, p := range x.Properties() {
+		prefix, _ := x.Prefix(p.Namespace)
+		if prefix == "" {
+			prefix = "{" + p.Namespace + "}"
+		}
+		fmt.Fprintf(out, "%s:%s = %s\n", prefix, p.Name, formatXMPValue(p))
+	}
+	return nil
+}
+
+func runXMPSetCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("xmp set", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var namespaces, sets, removes stringListFlag
+	inputFlag := fs.String("input", "", "Input PDF file")
+	outputFlag := fs.String("output", "", "Output PDF file")
+	fs.Var(&namespaces, "ns", "Declare a namespace as prefix=URI (repeatable)")
+	fs.Var(&sets, "set", "Set a property as prefix:name=value (repeatable)")
+	fs.Var(&removes, "remove", "Remove a property given as prefix:name (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	input := strings.TrimSpace(*inputFlag)
+	output := strings.TrimSpace(*outputFlag)
+	if input == "" {
+		return errors.New("xmp set requires --input")
+	}
+	if output == "" {
+		return errors.New("xmp set requires --output")
+	}
+	if len(sets) == 0 && len(removes) == 0 {
+		return errors.New("xmp set requires at least one --set or --remove")
+	}
+
+	err := cliUpdateXMP(input, output, func(x *pdf.XMP) error {
+		for _, decl := range namespaces {
+			prefix, uri, ok := strings.Cut(decl, "=")
+			if !ok {
+				return fmt.Errorf("invalid --ns %q, want prefix=URI", decl)
+			}
+			if err := x.RegisterNamespace(strings.TrimSpace(prefix), strings.TrimSpace(uri)); err != nil {
+				return err
+			}
+		}
+		for _, assignment := range sets {
+			name, value, ok := strings.Cut(assignment, "=")
+			if !ok {
+				return fmt.Errorf("invalid --set %q, want prefix:name=value", assignment)
+			}
+			namespace, local, err := parseXMPName(x, strings.TrimSpace(name))
+			if err != nil {
+				return err
+			}
+			setXMPValue(x, namespace, local, value)
+		}
+		for _, name := range removes {
+			namespace, local, err := parseXMPName(x, strings.TrimSpace(name))
+			if err != nil {
+				return err
+			}
+			if !x.Remove(namespace, local) {
+				return fmt.Errorf("xmp property %s not found", name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Updated %d XMP value(s) of %s into %s\n", len(sets)+len(removes), input, output)
+	return nil
+}
+
+// parseXMPName resolves a prefix:name property name against the namespaces
+// of x and the well-known prefixes.
+func parseXMPName(x *pdf.XMP, name string) (namespace, local string, err error) {
+	prefix, local, ok := strings.Cut(name, ":")
+	if !ok || prefix == "" || local == "" {
+		return "", "", fmt.Errorf("invalid xmp property %q, want prefix:name", name)
+	}
+	namespace, ok = x.Namespace(prefix)
+	if !ok {
+		return "", "", fmt.Errorf("unknown xmp namespace prefix %q; declare it with --ns %s=URI", prefix, prefix)
+	}
+	return namespace, local, nil
+}
+
+// formatXMPValue formats a property value; array items are joined by "; ".
+func formatXMPValue(p pdf.XMPProperty) string {
+	switch {
+	case p.Structured():
+		return "(structured value)"
+	case p.Kind == pdf.XMPBag || p.Kind == pdf.XMPSeq:
+		return strings.Join(p.Values, "; ")
+	}
+	return p.Value()
+}
+
+// setXMPValue sets a property, keeping the kind of an existing property:
+// arrays take items separated by "; " and language alternatives get a new
+// x-default entry. New properties are text.
+func setXMPValue(x *pdf.XMP, namespace, name, value string) {
+	p, _ := x.Property(namespace, name)
+	switch {
+	case p.Structured():
+		x.SetText(namespace, name, value)
+	case p.Kind == pdf.XMPAlt:
+		x.SetAlt(namespace, name, value)
+	case p.Kind == pdf.XMPBag || p.Kind == pdf.XMPSeq:
+		var items []string
+		for _, item := range strings.Split(value, ";") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		x.Set(pdf.XMPProperty{Namespace: namespace, Name: name, Kind: p.Kind, Values: items})
+	default:
+		x.SetText(namespace, name, value)
+	}
+}
+
+func runAttachmentsCommand(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("attachments requires list, extract, add or remove")
+	}
+	switch args[0] {
+	case "list":
+		return runAttachmentsListCommand(args[1:], out)
+	case "extract":
+		return runAttachmentsExtractCommand(args[1:], out)
+	case "add":
+		return runAttachmentsAddCommand(args[1:], out)
+	case "remove":
+		return runAttachmentsRemoveCommand(args[1:], out)
+	default:
+		return fmt.Errorf("unknown attachments command: %s", args[0])
+	}
+}
+
+func runAttachmentsListCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("attachments list", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	inputFlag := fs.String("input", "", "Input PDF file")
+	jsonFlag := fs.Bool("json", false, "Print the attachments as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	input := strings.TrimSpace(*inputFlag)
+	if input == "" {
+		return errors.New("attachments list requires --input")
+	}
+
+	attachments, err := cliListAttachments(input)
+	if err != nil {
+		return err
+	}
+	if *jsonFlag {
+		if attachments == nil {
+			attachments = []pdf.Attachment{}
+		}
+		data, err := json.MarshalIndent(attachments, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", data)
+		return err
+	}
+
+	for _, a := range attachments {
+		size := "?"
+		if a.Size >= 0 {
+			size = fmt.Sprintf("%d bytes", a.Size)
+		}
+		modified := ""
+		if !a.Modified.IsZero() {
+			modified = a.Modified.Format("2006-01-02 15:04")
+		}
+		line := fmt.Sprintf("%-30s %14s  %-16s  %s", a.Name, size, modified, a.Description)
+		fmt.Fprintln(out, strings.TrimRight(line, " "))
+	}
+	fmt.Fprintf(out, "%d attachment(s)\n", len(attachments))
+	return nil
+}
+
+func runAttachmentsExtractCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("attachments extract", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var names stringListFlag
+	inputFlag := fs.String("input", "", "Input PDF file")
+	outputDirFlag := fs.String("output-dir", "", "Output directory")
+	fs.Var(&names, "name", "Attachment to extract (repeatable); all when omitted")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	input := strings.TrimSpace(*inputFlag)
+	outputDir := strings.TrimSpace(*outputDirFlag)
+	if input == "" {
+		return errors.New("attachments extract requires --input")
+	}
+	if outputDir == "" {
+		return errors.New("attachments extract requires --output-dir")
+	}
+
+	paths, err := cliExtractAttachments(input, names, outputDir)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		fmt.Fprintf(out, "Extracted %s\n", path)
+	}
+	fmt.Fprintf(out, "Extracted %d attachment(s) from %s into %s\n", len(paths), input, outputDir)
+	return nil
+}
+
+func runAttachmentsAddCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("attachments add", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var files stringListFlag
+	inputFlag := fs.String("input", "", "Input PDF file")
+	outputFlag := fs.String("output", "", "Output PDF file")
+	fs.Var(&files, "file", "File to attach (repeatable)")
+	descriptionFlag := fs.String("description", "", "Description of the attached files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	input := strings.TrimSpace(*inputFlag)
+	output := strings.TrimSpace(*outputFlag)
+	if input == "" {
+		return errors.New("attachments add requires --input")
+	}
+	if output == "" {
+		return errors.New("attachments add requires --output")
+	}
+	if len(files) == 0 {
+		return errors.New("attachments add requires --file")
+	}
+
+	attachments := make([]pdf.Attachment, len(files))
+	contents := make([][]byte, len(files))
+	for i, file := range files {
+		data, err := cliReadFile(file)
+		if err != nil {
+			return err
+		}
+		attachments[i] = pdf.Attachment{Name: filepath.Base(file), Description: *descriptionFlag}
+		contents[i] = data
+	}
+	if err := cliAddAttachments(input, output, attachments, contents); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Added %d attachment(s) to %s into %s\n", len(files), input, output)
+	return nil
+}
+
+func runAttachmentsRemoveCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("attachments remove", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var names stringListFlag
+	inputFlag := fs.String("input", "", "Input PDF file")
+	outputFlag := fs.String("output", "", "Output PDF file")
+	fs.Var(&names, "name", "Attachment to remove (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	input := strings.TrimSpace(*inputFlag)
+	output := strings.TrimSpace(*outputFlag)
+	if input == "" {
+		return errors.New("attachments remove requires --input")
+	}
+	if output == "" {
+		return errors.New("attachments remove requires --output")
+	}
+	if len(names) == 0 {
+		return errors.New("attachments remove requires --name")
+	}
+
+	if err := cliRemoveAttachments(input, output, names); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Removed %d attachment(s) from %s into %s\n", len(names), input, output)
+	return nil
+}
+
+func runAnnotationsCommand(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("annotations requires list or remove")
+	}
+	switch args[0] {
+	case "list":
+		return runAnnotationsListCommand(args[1:], out)
+	case "remove":
+		return runAnnotationsRemoveCommand(args[1:], out)
+	default:
+		return fmt.Errorf("unknown annotations command: %s", args[0])
+	}
+}
+
+func runAnnotationsListCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("annotations list", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	inputFlag := fs.String("input", "", "Input PDF file")
+	pageFlag := fs.Int("page", 0, "Only list the annotations of this page (1-based)")
+	jsonFlag := fs.Bool("json", false, "Print the annotations as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	input := strings.TrimSpace(*inputFlag)
+	if input == "" {
+		return errors.New("annotations list requires --input")
+	}
+	if *pageFlag < 0 {
+		return errors.New("annotations list --page must be positive")
+	}
+
+	all, err := cliListAnnotations(input)
+	if err != nil {
+		return err
+	}
+	annotations := []pdf.Annotation{}
+	for _, a := range all {
+		if *pageFlag == 0 || a.Page == *pageFlag-1 {
+			annotations = append(annotations, a)
+		}
+	}
+	if *jsonFlag {
+		data, err := json.MarshalIndent(annotations, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", data)
+		return err
+	}
+
+	for _, a := range annotations {
+		color := ""
+		if a.Color != nil {
+			color = fmt.Sprintf("#%02x%02x%02x", a.Color.R, a.Color.G, a.Color.B)
+		}
+		modified := ""
+		if !a.Modified.IsZero() {
+			modified = a.Modified.Format("2006-01-02 15:04")
+		}
+		contents := strings.Join(strings.Fields(a.Contents), " ")
+		line := fmt.Sprintf("%-24s %4d  %-10s  %-7s  %-16s  %-16s  %s", a.ID, a.Page+1, a.Type, color, modified, a.Author, contents)
+		fmt.Fprintln(out, strings.TrimRight(line, " "))
+	}
+	fmt.Fprintf(out, "%d annotation(s)\n", len(annotations))
+	return nil
+}
+
+func runAnnotationsRemoveCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("annotations remove", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var ids stringListFlag
+	inputFlag := fs.String("input", "", "Input PDF file")
+	outputFlag := fs.String("output", "", "Output PDF file")
+	fs.Var(&ids, "id", "ID of the annotation to remove (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	input := strings.TrimSpace(*inputFlag)
+	output := strings.TrimSpace(*outputFlag)
+	if input == "" {
+		return errors.New("annotations remove requires --input")
+	}
+	if output == "" {
+		return errors.New("annotations remove requires --output")
+	}
+	if len(ids) == 0 {
+		return errors.New("annotations remove requires --id")
+	}
+
+	removed, err := cliRemoveAnnotations(input, output, ids)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Removed %d annotation(s) from %s into %s\n", removed, input, output)
+	return nil
+}
+
+// stringListFlag collects the values of a repeatable flag.
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func applyGlobalCLIFlags(args []string) ([]string, error) {
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(args[0], "=")
+		if name != "--renderer" {
+			return args, nil
+		}
+		if !hasValue {
+			if len(args) < 2 {
+				return nil, errors.New("--renderer requires a backend name")
+			}
+			value = args[1]
+			args = args[1:]
+		}
+		args = args[1:]
+
+		if err := cliSetRenderer(strings.TrimSpace(value)); err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
+func runRenderersCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("renderers", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	for _, b := range cliListBackends() {
+		status := "available"
+		if !b.Available() {
+			status = "unavailable"
+		}
+		fmt.Fprintf(out, "%-10s %-12s %s\n", b.Name(), status, b.Capabilities())
+	}
+	return nil
+}
+
 func parseCSV(raw string) []string {
 	parts := strings.Split(raw, ",")
 	values := make([]string, 0, len(parts))
@@ -165,11 +1183,27 @@ func printCLIUsage(out io.Writer) {
 	fmt.Fprintln(out, "OpenPDF Reader CLI mode")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Usage:")
-	fmt.Fprintln(out, "  openpdfreader --cli <command> [options]")
+	fmt.Fprintln(out, "  openpdfreader --cli [--renderer auto|poppler|mutool|native] <command> [options]")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Commands:")
 	fmt.Fprintln(out, "  merge          --inputs a.pdf,b.pdf --output out.pdf")
 	fmt.Fprintln(out, "  split          --input in.pdf --output-dir ./out")
 	fmt.Fprintln(out, "  export-images  --input in.pdf --output-dir ./out --format png --scale 2.0")
 	fmt.Fprintln(out, "  export-text    --input in.pdf --output out.txt")
+	fmt.Fprintln(out, "  renderers      list rendering backends and their capabilities")
+	fmt.Fprintln(out, "  index          --root ./contracts [--index ./index]")
+	fmt.Fprintln(out, "  search         --index ./index [--limit 50] \"query\"")
+	fmt.Fprintln(out, "  ocr            --input scan.pdf --output searchable.pdf [--lang eng]")
+	fmt.Fprintln(out, "  bookmarks      export --input in.pdf [--output toc.json]")
+	fmt.Fprintln(out, "  bookmarks      import --input in.pdf --json toc.json --output out.pdf")
+	fmt.Fprintln(out, "  info           --input in.pdf [--json]")
+	fmt.Fprintln(out, "  set-info       --input in.pdf --output out.pdf [--title T] [--author A] [--subject S] [--keywords K] [--creator C]")
+	fmt.Fprintln(out, "  xmp            get --input in.pdf [--property dc:title] [--raw]")
+	fmt.Fprintln(out, "  xmp            set --input in.pdf --output out.pdf [--ns arc=URI] --set arc:Box=B-17 [--remove prefix:name]")
+	fmt.Fprintln(out, "  attachments    list --input in.pdf [--json]")
+	fmt.Fprintln(out, "  attachments    extract --input in.pdf --output-dir ./out [--name factur-x.xml]")
+	fmt.Fprintln(out, "  attachments    add --input in.pdf --output out.pdf --file data.xml [--description D]")
+	fmt.Fprintln(out, "  attachments    remove --input in.pdf --output out.pdf --name data.xml")
+	fmt.Fprintln(out, "  annotations    list --input in.pdf [--page 3] [--json]")
+	fmt.Fprintln(out, "  annotations    remove --input in.pdf --output out.pdf --id ID")
 }
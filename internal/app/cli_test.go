@@ -3,8 +3,14 @@ package app
 import (
 	"bytes"
 	"errors"
+	"image/color"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
 )
 
 func TestRunCLIHelp(t *testing.T) {
@@ -91,3 +97,545 @@ func TestRunCLIPropagatesOperationError(t *testing.T) {
 		t.Fatalf("expected propagated split error, got: %v", err)
 	}
 }
+
+func TestRunCLIRendererFlag(t *testing.T) {
+	origSet := cliSetRenderer
+	origExport := cliExportText
+	defer func() {
+		cliSetRenderer = origSet
+		cliExportText = origExport
+	}()
+
+	var selected []string
+	cliSetRenderer = func(name string) error {
+		selected = append(selected, name)
+		return nil
+	}
+	cliExportText = func(input, output string) error { return nil }
+
+	var out bytes.Buffer
+	if err := RunCLI([]string{"--renderer", "native", "export-text", "--input", "a.pdf", "--output", "a.txt"}, &out); err != nil {
+		t.Fatalf("RunCLI() returned error: %v", err)
+	}
+	if err := RunCLI([]string{"--renderer=mutool", "--help"}, &out); err != nil {
+		t.Fatalf("RunCLI() returned error: %v", err)
+	}
+	if len(selected) != 2 || selected[0] != "native" || selected[1] != "mutool" {
+		t.Fatalf("selected renderers = %#v", selected)
+	}
+}
+
+func TestRunCLIRendererFlagErrors(t *testing.T) {
+	origSet := cliSetRenderer
+	defer func() { cliSetRenderer = origSet }()
+	cliSetRenderer = func(name string) error { return errors.New("unknown rendering backend: " + name) }
+
+	var out bytes.Buffer
+	if err := RunCLI([]string{"--renderer"}, &out); err == nil {
+		t.Fatal("expected error for missing renderer name")
+	}
+	if err := RunCLI([]string{"--renderer", "pdfium", "--help"}, &out); err == nil {
+		t.Fatal("expected error for unknown renderer")
+	}
+}
+
+func TestRunCLIRenderers(t *testing.T) {
+	var out bytes.Buffer
+	if err := RunCLI([]string{"renderers"}, &out); err != nil {
+		t.Fatalf("RunCLI(renderers) returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), "native") {
+		t.Fatalf("renderers output missing native backend: %q", out.String())
+	}
+}
+
+func TestRunCLIIndexDefaultsIndexDir(t *testing.T) {
+	orig := cliIndex
+	defer func() { cliIndex = orig }()
+
+	cliIndex = func(root, indexDir string, progress func(path string)) (pdf.IndexStats, error) {
+		if root != "docs" || indexDir != pdf.DefaultIndexDir("docs") {
+			t.Fatalf("got root=%q indexDir=%q", root, indexDir)
+		}
+		progress("a.pdf")
+		return pdf.IndexStats{Added: 1, Failed: map[string]error{"b.pdf": errors.New("encrypted")}}, nil
+	}
+
+	var out bytes.Buffer
+	if err := RunCLI([]string{"index", "--root", "docs"}, &out); err != nil {
+		t.Fatalf("RunCLI(index) returned error: %v", err)
+	}
+	for _, want := range []string{"Indexing a.pdf", "Failed b.pdf: encrypted", "1 added", "1 failed"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("index output missing %q: %q", want, out.String())
+		}
+	}
+}
+
+func TestRunCLISearch(t *testing.T) {
+	orig := cliSearchIndex
+	defer func() { cliSearchIndex = orig }()
+
+	cliSearchIndex = func(indexDir, query string, limit int) ([]pdf.IndexHit, error) {
+		if indexDir != "idx" || query != "termination fee" || limit != 5 {
+			t.Fatalf("got indexDir=%q query=%q limit=%d", indexDir, query, limit)
+		}
+		return []pdf.IndexHit{{Path: "/docs/a.pdf", Page: 2, Snippet: "the termination fee"}}, nil
+	}
+
+	var out bytes.Buffer
+	if err := RunCLI([]string{"search", "--index", "idx", "--limit", "5", "termination", "fee"}, &out); err != nil {
+		t.Fatalf("RunCLI(search) returned error: %v", err)
+	}
+	if got, want := out.String(), "/docs/a.pdf:3: the termination fee\n"; got != want {
+		t.Fatalf("search output = %q, want %q", got, want)
+	}
+
+	if err := RunCLI([]string{"search", "termination"}, &out); err == nil {
+		t.Fatal("expected error without --index")
+	}
+}
+
+func TestRunCLIOCR(t *testing.T) {
+	orig := cliOCR
+	defer func() { cliOCR = orig }()
+
+	cliOCR = func(input, output, lang string, progress func(done, total int)) (pdf.OCRResult, error) {
+		if input != "scan.pdf" || output != "out.pdf" || lang != "deu+eng" {
+			t.Fatalf("got input=%q output=%q lang=%q", input, output, lang)
+		}
+		progress(1, 2)
+		progress(2, 2)
+		return pdf.OCRResult{Recognized: 1, Skipped: 1, Words: 12, Failed: map[int]error{2: errors.New("bad image")}}, nil
+	}
+
+	var out bytes.Buffer
+	if err := RunCLI([]string{"ocr", "--input", "scan.pdf", "--output", "out.pdf", "--lang", "deu+eng"}, &out); err != nil {
+		t.Fatalf("RunCLI(ocr) returned error: %v", err)
+	}
+	want := "Processed page 1 of 2\nProcessed page 2 of 2\n" +
+		"Recognized 12 word(s) on 1 page(s) of scan.pdf into out.pdf, 1 page(s) already had text\n" +
+		"Page 3 could not be rendered: bad image\n"
+	if got := out.String(); got != want {
+		t.Fatalf("ocr output = %q, want %q", got, want)
+	}
+
+	if err := RunCLI([]string{"ocr", "--input", "scan.pdf"}, &out); err == nil {
+		t.Fatal("expected error without --output")
+	}
+}
+
+func TestRunCLIBookmarks(t *testing.T) {
+	origRead, origWrite := cliReadOutline, cliWriteOutline
+	origReadFile, origWriteFile := cliReadFile, cliWriteFile
+	defer func() {
+		cliReadOutline, cliWriteOutline = origRead, origWrite
+		cliReadFile, cliWriteFile = origReadFile, origWriteFile
+	}()
+
+	items := []pdf.OutlineItem{{
+		Title:    "Chapter 1",
+		Dest:     pdf.Destination{Page: 0, Fit: "Fit"},
+		Open:     true,
+		Children: []pdf.OutlineItem{{Title: "Section 1.1", Dest: pdf.Destination{Page: 1, Fit: "Fit"}}},
+	}}
+	cliReadOutline = func(input string) ([]pdf.OutlineItem, error) {
+		if input != "in.pdf" {
+			t.Fatalf("got input=%q", input)
+		}
+		return items, nil
+	}
+	files := map[string][]byte{}
+	cliWriteFile = func(path string, data []byte) error {
+		files[path] = data
+		return nil
+	}
+	cliReadFile = func(path string) ([]byte, error) {
+		return files[path], nil
+	}
+
+	var out bytes.Buffer
+	if err := RunCLI([]string{"bookmarks", "export", "--input", "in.pdf", "--output", "toc.json"}, &out); err != nil {
+		t.Fatalf("RunCLI(bookmarks export) returned error: %v", err)
+	}
+	if got, want := out.String(), "Exported 2 bookmark(s) from in.pdf into toc.json\n"; got != want {
+		t.Fatalf("export output = %q, want %q", got, want)
+	}
+
+	var imported []pdf.OutlineItem
+	cliWriteOutline = func(input, output string, got []pdf.OutlineItem) error {
+		if input != "in.pdf" || output != "out.pdf" {
+			t.Fatalf("got input=%q output=%q", input, output)
+		}
+		imported = got
+		return nil
+	}
+	out.Reset()
+	if err := RunCLI([]string{"bookmarks", "import", "--input", "in.pdf", "--json", "toc.json", "--output", "out.pdf"}, &out); err != nil {
+		t.Fatalf("RunCLI(bookmarks import) returned error: %v", err)
+	}
+	if got, want := out.String(), "Imported 2 bookmark(s) into out.pdf\n"; got != want {
+		t.Fatalf("import output = %q, want %q", got, want)
+	}
+	if !reflect.DeepEqual(imported, items) {
+		t.Fatalf("imported = %+v, want %+v", imported, items)
+	}
+
+	out.Reset()
+	if err := RunCLI([]string{"bookmarks", "export", "--input", "in.pdf"}, &out); err != nil {
+		t.Fatalf("RunCLI(bookmarks export) to stdout returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"title": "Section 1.1"`) {
+		t.Fatalf("export to stdout = %q, want the bookmarks JSON", out.String())
+	}
+
+	if err := RunCLI([]string{"bookmarks", "import", "--input", "in.pdf", "--json", "toc.json"}, &out); err == nil {
+		t.Fatal("expected error without --output")
+	}
+	if err := RunCLI([]string{"bookmarks"}, &out); err == nil {
+		t.Fatal("expected error without a bookmarks command")
+	}
+}
+
+func TestRunCLIInfo(t *testing.T) {
+	orig := cliReadInfo
+	defer func() { cliReadInfo = orig }()
+
+	cliReadInfo = func(input string) (pdf.Info, error) {
+		if input != "in.pdf" {
+			t.Fatalf("got input=%q", input)
+		}
+		return pdf.Info{
+			Title:     "Report",
+			Created:   time.Date(2024, 3, 1, 10, 20, 30, 0, time.UTC),
+			Version:   "1.7",
+			PageCount: 3,
+			FileSize:  1234,
+			Tagged:    true,
+		}, nil
+	}
+
+	var out bytes.Buffer
+	if err := RunCLI([]string{"info", "--input", "in.pdf"}, &out); err != nil {
+		t.Fatalf("RunCLI(info) returned error: %v", err)
+	}
+	for _, line := range []string{
+		"Title:       Report\n",
+		"Author:      \n",
+		"Created:     2024-03-01 10:20:30 +00:00\n",
+		"PDF version: 1.7\n",
+		"Pages:       3\n",
+		"File size:   1234 bytes\n",
+		"Tagged:      yes\n",
+		"Linearized:  no\n",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("info output = %q, want line %q", out.String(), line)
+		}
+	}
+
+	out.Reset()
+	if err := RunCLI([]string{"info", "--input", "in.pdf", "--json"}, &out); err != nil {
+		t.Fatalf("RunCLI(info --json) returned error: %v", err)
+	}
+	for _, field := range []string{`"title": "Report"`, `"created": "2024-03-01T10:20:30Z"`, `"page_count": 3`} {
+		if !strings.Contains(out.String(), field) {
+			t.Errorf("info --json output = %q, want %s", out.String(), field)
+		}
+	}
+	if strings.Contains(out.String(), `"modified"`) {
+		t.Errorf("info --json output = %q, want no modified date", out.String())
+	}
+}
+
+func TestRunCLISetInfo(t *testing.T) {
+	orig := cliUpdateInfo
+	defer func() { cliUpdateInfo = orig }()
+
+	var got pdf.Info
+	cliUpdateInfo = func(input, output string, update func(*pdf.Info)) error {
+		if input != "in.pdf" || output != "out.pdf" {
+			t.Fatalf("got input=%q output=%q", input, output)
+		}
+		got = pdf.Info{Title: "Old", Author: "Someone", Subject: "Kept"}
+		update(&got)
+		return nil
+	}
+
+	var out bytes.Buffer
+	args := []string{"set-info", "--input", "in.pdf", "--output", "out.pdf", "--title", "New", "--author", ""}
+	if err := RunCLI(args, &out); err != nil {
+		t.Fatalf("RunCLI(set-info) returned error: %v", err)
+	}
+	if want := (pdf.Info{Title: "New", Subject: "Kept"}); got != want {
+		t.Errorf("info = %+v, want %+v", got, want)
+	}
+	if want := "Updated 2 field(s) of in.pdf into out.pdf\n"; out.String() != want {
+		t.Errorf("set-info output = %q, want %q", out.String(), want)
+	}
+
+	if err := RunCLI([]string{"set-info", "--input", "in.pdf", "--output", "out.pdf"}, &out); err == nil {
+		t.Error("expected error without any field")
+	}
+}
+
+const testCLIXMPPacket = `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:arc="http://example.com/archive/" arc:Box="B-17">
+   <dc:title><rdf:Alt><rdf:li xml:lang="x-default">Minutes</rdf:li></rdf:Alt></dc:title>
+   <dc:subject><rdf:Bag><rdf:li>board</rdf:li><rdf:li>2024</rdf:li></rdf:Bag></dc:subject>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`
+
+func TestRunCLIXMPGet(t *testing.T) {
+	orig := cliReadXMP
+	defer func() { cliReadXMP = orig }()
+
+	cliReadXMP = func(input string) (*pdf.XMP, error) {
+		if input != "in.pdf" {
+			t.Fatalf("got input=%q", input)
+		}
+		return pdf.ParseXMP([]byte(testCLIXMPPacket))
+	}
+
+	var out bytes.Buffer
+	if err := RunCLI([]string{"xmp", "get", "--input", "in.pdf"}, &out); err != nil {
+		t.Fatalf("RunCLI(xmp get) returned error: %v", err)
+	}
+	if want := "arc:Box = B-17\ndc:title = Minutes\ndc:subject = board; 2024\n"; out.String() != want {
+		t.Errorf("xmp get output = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := RunCLI([]string{"xmp", "get", "--input", "in.pdf", "--property", "arc:Box"}, &out); err != nil {
+		t.Fatalf("RunCLI(xmp get --property) returned error: %v", err)
+	}
+	if out.String() != "B-17\n" {
+		t.Errorf("xmp get --property output = %q, want B-17", out.String())
+	}
+
+	if err := RunCLI([]string{"xmp", "get", "--input", "in.pdf", "--property", "dc:rights"}, &out); err == nil {
+		t.Error("expected error for a missing property")
+	}
+	if err := RunCLI([]string{"xmp", "get", "--input", "in.pdf", "--property", "foo:Bar"}, &out); err == nil {
+		t.Error("expected error for an unknown prefix")
+	}
+}
+
+func TestRunCLIXMPSet(t *testing.T) {
+	orig := cliUpdateXMP
+	defer func() { cliUpdateXMP = orig }()
+
+	var got *pdf.XMP
+	cliUpdateXMP = func(input, output string, update func(*pdf.XMP) error) error {
+		if input != "in.pdf" || output != "out.pdf" {
+			t.Fatalf("got input=%q output=%q", input, output)
+		}
+		x, err := pdf.ParseXMP([]byte(testCLIXMPPacket))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = x
+		return update(x)
+	}
+
+	var out bytes.Buffer
+	args := []string{"xmp", "set", "--input", "in.pdf", "--output", "out.pdf",
+		"--ns", "case=http://example.com/case/",
+		"--set", "case:Number=2024-118",
+		"--set", "dc:subject=minutes; board",
+		"--set", "dc:title=Approved",
+		"--remove", "arc:Box",
+	}
+	if err := RunCLI(args, &out); err != nil {
+		t.Fatalf("RunCLI(xmp set) returned error: %v", err)
+	}
+	if p, _ := got.Property("http://example.com/case/", "Number"); p.Kind != pdf.XMPText || p.Value() != "2024-118" {
+		t.Errorf("case:Number = %+v", p)
+	}
+	if p, _ := got.Property("http://purl.org/dc/elements/1.1/", "subject"); p.Kind != pdf.XMPBag || strings.Join(p.Values, "|") != "minutes|board" {
+		t.Errorf("dc:subject = %+v", p)
+	}
+	if p, _ := got.Property("http://purl.org/dc/elements/1.1/", "title"); p.Kind != pdf.XMPAlt || p.Value() != "Approved" {
+		t.Errorf("dc:title = %+v", p)
+	}
+	if _, ok := got.Property("http://example.com/archive/", "Box"); ok {
+		t.Error("arc:Box was not removed")
+	}
+	if want := "Updated 4 XMP value(s) of in.pdf into out.pdf\n"; out.String() != want {
+		t.Errorf("xmp set output = %q, want %q", out.String(), want)
+	}
+
+	if err := RunCLI([]string{"xmp", "set", "--input", "in.pdf", "--output", "out.pdf"}, &out); err == nil {
+		t.Error("expected error without --set or --remove")
+	}
+	if err := RunCLI([]string{"xmp", "set", "--input", "in.pdf", "--output", "out.pdf", "--set", "new:Value=1"}, &out); err == nil {
+		t.Error("expected error for an undeclared prefix")
+	}
+	if err := RunCLI([]string{"xmp"}, &out); err == nil {
+		t.Error("expected error without an xmp command")
+	}
+}
+
+func TestRunCLIAttachments(t *testing.T) {
+	origList, origExtract := cliListAttachments, cliExtractAttachments
+	origAdd, origRemove := cliAddAttachments, cliRemoveAttachments
+	origReadFile := cliReadFile
+	defer func() {
+		cliListAttachments, cliExtractAttachments = origList, origExtract
+		cliAddAttachments, cliRemoveAttachments = origAdd, origRemove
+		cliReadFile = origReadFile
+	}()
+
+	attachments := []pdf.Attachment{
+		{Name: "factur-x.xml", FileName: "factur-x.xml", Description: "Invoice", Size: 21, Modified: time.Date(2024, 5, 6, 7, 8, 0, 0, time.UTC)},
+		{Name: "notes", FileName: "../notes.txt", Size: -1},
+	}
+	cliListAttachments = func(input string) ([]pdf.Attachment, error) {
+		if input != "in.pdf" {
+			t.Fatalf("got input=%q", input)
+		}
+		return attachments, nil
+	}
+
+	var out bytes.Buffer
+	if err := RunCLI([]string{"attachments", "list", "--input", "in.pdf"}, &out); err != nil {
+		t.Fatalf("RunCLI(attachments list) returned error: %v", err)
+	}
+	want := "factur-x.xml                         21 bytes  2024-05-06 07:08  Invoice\n" +
+		"notes                                       ?\n" +
+		"2 attachment(s)\n"
+	if out.String() != want {
+		t.Errorf("list output = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := RunCLI([]string{"attachments", "list", "--input", "in.pdf", "--json"}, &out); err != nil {
+		t.Fatalf("RunCLI(attachments list --json) returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"file_name": "factur-x.xml"`) {
+		t.Errorf("list --json output = %q", out.String())
+	}
+
+	cliExtractAttachments = func(input string, names []string, outputDir string) ([]string, error) {
+		if input != "in.pdf" || outputDir != "out" || !reflect.DeepEqual(names, []string{"notes"}) {
+			t.Fatalf("got input=%q names=%v outputDir=%q", input, names, outputDir)
+		}
+		return []string{filepath.Join("out", "notes.txt")}, nil
+	}
+	out.Reset()
+	if err := RunCLI([]string{"attachments", "extract", "--input", "in.pdf", "--output-dir", "out", "--name", "notes"}, &out); err != nil {
+		t.Fatalf("RunCLI(attachments extract) returned error: %v", err)
+	}
+	if want := "Extracted " + filepath.Join("out", "notes.txt") + "\nExtracted 1 attachment(s) from in.pdf into out\n"; out.String() != want {
+		t.Errorf("extract output = %q, want %q", out.String(), want)
+	}
+
+	cliReadFile = func(path string) ([]byte, error) {
+		return []byte("data of " + path), nil
+	}
+	var added []pdf.Attachment
+	cliAddAttachments = func(input, output string, got []pdf.Attachment, contents [][]byte) error {
+		if input != "in.pdf" || output != "out.pdf" || string(contents[0]) != "data of dir/a.xml" {
+			t.Fatalf("got input=%q output=%q contents=%q", input, output, contents)
+		}
+		added = got
+		return nil
+	}
+	out.Reset()
+	if err := RunCLI([]string{"attachments", "add", "--input", "in.pdf", "--output", "out.pdf", "--file", "dir/a.xml", "--file", "b.txt", "--description", "Data"}, &out); err != nil {
+		t.Fatalf("RunCLI(attachments add) returned error: %v", err)
+	}
+	if want := []pdf.Attachment{{Name: "a.xml", Description: "Data"}, {Name: "b.txt", Description: "Data"}}; !reflect.DeepEqual(added, want) {
+		t.Errorf("added = %+v, want %+v", added, want)
+	}
+	if want := "Added 2 attachment(s) to in.pdf into out.pdf\n"; out.String() != want {
+		t.Errorf("add output = %q, want %q", out.String(), want)
+	}
+
+	var removed []string
+	cliRemoveAttachments = func(input, output string, names []string) error {
+		removed = names
+		return nil
+	}
+	if err := RunCLI([]string{"attachments", "remove", "--input", "in.pdf", "--output", "out.pdf", "--name", "notes"}, &out); err != nil {
+		t.Fatalf("RunCLI(attachments remove) returned error: %v", err)
+	}
+	if !reflect.DeepEqual(removed, []string{"notes"}) {
+		t.Errorf("removed = %v", removed)
+	}
+
+	if err := RunCLI([]string{"attachments", "remove", "--input", "in.pdf", "--output", "out.pdf"}, &out); err == nil {
+		t.Error("expected error without --name")
+	}
+	if err := RunCLI([]string{"attachments", "add", "--input", "in.pdf", "--output", "out.pdf"}, &out); err == nil {
+		t.Error("expected error without --file")
+	}
+	if err := RunCLI([]string{"attachments"}, &out); err == nil {
+		t.Error("expected error without an attachments command")
+	}
+}
+
+func TestRunCLIAnnotations(t *testing.T) {
+	origList, origRemove := cliListAnnotations, cliRemoveAnnotations
+	defer func() {
+		cliListAnnotations, cliRemoveAnnotations = origList, origRemove
+	}()
+
+	cliListAnnotations = func(input string) ([]pdf.Annotation, error) {
+		if input != "in.pdf" {
+			t.Fatalf("got input=%q", input)
+		}
+		return []pdf.Annotation{
+			{ID: "hl-1", Page: 0, Type: "Highlight", Author: "Ann", Contents: "check\nthis", Color: &color.NRGBA{R: 255, G: 230, A: 255}, Modified: time.Date(2024, 5, 6, 7, 8, 0, 0, time.UTC)},
+			{ID: "#12", Page: 2, Type: "Square"},
+		}, nil
+	}
+
+	var out bytes.Buffer
+	if err := RunCLI([]string{"annotations", "list", "--input", "in.pdf"}, &out); err != nil {
+		t.Fatalf("RunCLI(annotations list) returned error: %v", err)
+	}
+	want := "hl-1                        1  Highlight   #ffe600  2024-05-06 07:08  Ann               check this\n" +
+		"#12                         3  Square\n" +
+		"2 annotation(s)\n"
+	if out.String() != want {
+		t.Errorf("list output = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := RunCLI([]string{"annotations", "list", "--input", "in.pdf", "--page", "3", "--json"}, &out); err != nil {
+		t.Fatalf("RunCLI(annotations list --json) returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"id": "#12"`) || strings.Contains(out.String(), "hl-1") {
+		t.Errorf("list --page 3 --json output = %q", out.String())
+	}
+
+	var removed []string
+	cliRemoveAnnotations = func(input, output string, ids []string) (int, error) {
+		if input != "in.pdf" || output != "out.pdf" {
+			t.Fatalf("got input=%q output=%q", input, output)
+		}
+		removed = ids
+		return len(ids), nil
+	}
+	out.Reset()
+	if err := RunCLI([]string{"annotations", "remove", "--input", "in.pdf", "--output", "out.pdf", "--id", "hl-1", "--id", "#12"}, &out); err != nil {
+		t.Fatalf("RunCLI(annotations remove) returned error: %v", err)
+	}
+	if !reflect.DeepEqual(removed, []string{"hl-1", "#12"}) {
+		t.Errorf("removed = %v", removed)
+	}
+	if want := "Removed 2 annotation(s) from in.pdf into out.pdf\n"; out.String() != want {
+		t.Errorf("remove output = %q, want %q", out.String(), want)
+	}
+
+	if err := RunCLI([]string{"annotations", "remove", "--input", "in.pdf", "--output", "out.pdf"}, &out); err == nil {
+		t.Error("expected error without --id")
+	}
+	if err := RunCLI([]string{"annotations"}, &out); err == nil {
+		t.Error("expected error without an annotations command")
+	}
+}
@@ -16,6 +16,10 @@ type Config struct {
 	Theme          string   `json:"theme"` // "light", "dark", "system"
 	DefaultZoom    float64  `json:"default_zoom"`
 	ShowThumbnails bool     `json:"show_thumbnails"`
+	Renderer       string   `json:"renderer"` // "auto", "poppler", "mutool", "native"
+	RenderCacheMB  int      `json:"render_cache_mb"`
+	PrefetchPages  int      `json:"prefetch_pages"` // pages rendered ahead/behind the current one
+	ViewMode       string   `json:"view_mode"`      // "single", "continuous", "two-page", "book"
 }
 
 // Default returns the default configuration.
@@ -28,6 +32,10 @@ func Default() *Config {
 		Theme:          "system",
 		DefaultZoom:    1.0,
 		ShowThumbnails: true,
+		Renderer:       "auto",
+		RenderCacheMB:  256,
+		PrefetchPages:  2,
+		ViewMode:       "single",
 	}
 }
 
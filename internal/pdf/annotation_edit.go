@@ -0,0 +1,353 @@
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Annotation describes an annotation on a page. Pop-up windows are not
+// listed on their own; they belong to the annotation that opens them.
+type Annotation struct {
+	// ID is the annotation's /NM name or, for unnamed annotations, "#"
+	// and its object number.
+	ID       string       `json:"id"`
+	Page     int          `json:"page"` // 0-indexed
+	Type     string       `json:"type"` // the subtype, such as Highlight or Text
+	Rect     Rect         `json:"rect"`
+	Author   string       `json:"author,omitempty"`
+	Contents string       `json:"contents,omitempty"`
+	Color    *color.NRGBA `json:"color,omitempty"`
+	Modified time.Time    `json:"modified,omitzero"`
+}
+
+// AnnotationEdit is a change to an annotation. Nil fields are left as
+// they are.
+type AnnotationEdit struct {
+	Contents *string
+	Color    color.Color
+	// Rect moves or resizes the annotation; the points of lines, polygons,
+	// ink strokes and text markup are mapped along.
+	Rect *Rect
+}
+
+// Annotations returns the annotations of every page, page by page in the
+// order of each page's /Annots.
+func (d *Document) Annotations() ([]Annotation, error) {
+	if d.context() == nil {
+		return nil, errors.New("no document loaded")
+	}
+
+	d.renderMu.Lock()
+	defer d.renderMu.Unlock()
+	var list []Annotation
+	for page := range d.pageCount {
+		entries, err := pageAnnotations(d.ctx, page)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", page+1, err)
+		}
+		for _, e := range entries {
+			if e.subtype() != "Popup" {
+				list = append(list, readAnnotation(d.ctx, e))
+			}
+		}
+	}
+	return list, nil
+}
+
+// UpdateAnnotation applies edit to the annotation id and writes the result
+// to outputPath, or back to inputPath if outputPath is empty.
+func (a *Annotator) UpdateAnnotation(inputPath, outputPath, id string, edit AnnotationEdit) error {
+	if inputPath == "" {
+		return errors.New("input path is required")
+	}
+	if edit.Rect != nil && edit.Rect.Empty() {
+		return errors.New("annotation rectangle is empty")
+	}
+
+	ctx, err := readContextFile(inputPath, a.userPassword, a.ownerPassword)
+	if err != nil {
+		return err
+	}
+	found := false
+	for page := range ctx.PageCount {
+		entries, err := pageAnnotations(ctx, page)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.subtype() == "Popup" || annotationID(e) != id {
+				continue
+			}
+			if err := editAnnotation(ctx, e.dict, edit); err != nil {
+				return err
+			}
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("annotation %q not found", id)
+	}
+	return writeAnnotated(ctx, inputPath, outputPath)
+}
+
+// RemoveAnnotations deletes the annotations with the given IDs, with their
+// pop-up windows, and writes the result to outputPath, or back to
+// inputPath if outputPath is empty. It returns how many were removed.
+func (a *Annotator) RemoveAnnotations(inputPath, outputPath string, ids []string) (int, error) {
+	if inputPath == "" {
+		return 0, errors.New("input path is required")
+	}
+	if len(ids) == 0 {
+		return 0, errors.New("no annotation IDs given")
+	}
+
+	ctx, err := readContextFile(inputPath, a.userPassword, a.ownerPassword)
+	if err != nil {
+		return 0, err
+	}
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = false
+	}
+
+	removed := 0
+	for page := range ctx.PageCount {
+		entries, err := pageAnnotations(ctx, page)
+		if err != nil {
+			return 0, err
+		}
+		drop := make(map[int]bool)
+		popups := make(map[types.IndirectRef]bool)
+		for _, e := range entries {
+			id := annotationID(e)
+			if _, ok := wanted[id]; !ok || e.subtype() == "Popup" {
+				continue
+			}
+			wanted[id] = true
+			drop[e.index] = true
+			if ref, ok := e.dict["Popup"].(types.IndirectRef); ok {
+				popups[ref] = true
+			}
+			removed++
+		}
+		if len(drop) == 0 {
+			continue
+		}
+
+		kept := types.Array{}
+		for _, e := range entries {
+			if ref, ok := e.obj.(types.IndirectRef); ok && popups[ref] {
+				continue
+			}
+			if !drop[e.index] {
+				kept = append(kept, e.obj)
+			}
+		}
+		pageDict, _, _, err := ctx.PageDict(page+1, false)
+		if err != nil {
+			return 0, err
+		}
+		if len(kept) == 0 {
+			pageDict.Delete("Annots")
+		} else {
+			pageDict.Update("Annots", kept)
+		}
+	}
+	for _, id := range ids {
+		if !wanted[id] {
+			return 0, fmt.Errorf("annotation %q not found", id)
+		}
+	}
+	return removed, writeAnnotated(ctx, inputPath, outputPath)
+}
+
+// annotationEntry is an annotation dictionary and where it sits.
+type annotationEntry struct {
+	page  int
+	index int          // position in the page's /Annots
+	obj   types.Object // the /Annots element, usually an indirect reference
+	dict  types.Dict
+}
+
+func (e annotationEntry) subtype() string {
+	if name := e.dict.NameEntry("Subtype"); name != nil {
+		return *name
+	}
+	return ""
+}
+
+// pageAnnotations returns the annotation dictionaries of a page (0-indexed).
+func pageAnnotations(ctx *model.Context, page int) ([]annotationEntry, error) {
+	pageDict, _, _, err := ctx.PageDict(page+1, false)
+	if err != nil {
+		return nil, err
+	}
+	if pageDict == nil {
+		return nil, errors.New("page not found")
+	}
+	annots, err := ctx.DereferenceArray(pageDict["Annots"])
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]annotationEntry, 0, len(annots))
+	for i, obj := range annots {
+		dict, err := ctx.DereferenceDict(obj)
+		if err != nil || dict == nil {
+			continue
+		}
+		entries = append(entries, annotationEntry{page: page, index: i, obj: obj, dict: dict})
+	}
+	return entries, nil
+}
+
+// annotationID identifies an annotation by its /NM name, falling back to
+// its object number and, for annotations stored inline in /Annots, to its
+// page and position.
+func annotationID(e annotationEntry) string {
+	if nm, ok := e.dict["NM"]; ok {
+		if s, err := types.StringOrHexLiteral(nm); err == nil && s != nil && *s != "" {
+			return *s
+		}
+	}
+	if ref, ok := e.obj.(types.IndirectRef); ok {
+		return fmt.Sprintf("#%d", ref.ObjectNumber)
+	}
+	return fmt.Sprintf("#p%d.%d", e.page+1, e.index+1)
+}
+
+func readAnnotation(ctx *model.Context, e annotationEntry) Annotation {
+	a := Annotation{ID: annotationID(e), Page: e.page, Type: e.subtype()}
+	if rect := pageBox(ctx.XRefTable, e.dict, "Rect"); rect != nil {
+		a.Rect = *rect
+	}
+	if s, err := ctx.DereferenceText(e.dict["T"]); err == nil {
+		a.Author = s
+	}
+	if s, err := ctx.DereferenceText(e.dict["Contents"]); err == nil {
+		a.Contents = s
+	}
+	a.Color = annotationColor(ctx.XRefTable, e.dict["C"])
+	a.Modified = infoDate(ctx.XRefTable, e.dict["M"])
+	if a.Modified.IsZero() {
+		// pdfcpu writes the date under ModDate.
+		a.Modified = infoDate(ctx.XRefTable, e.dict["ModDate"])
+	}
+	return a
+}
+
+// annotationColor reads a /C colour array of 1 (gray), 3 (RGB) or 4 (CMYK)
+// components; an empty array means transparent and gives nil.
+func annotationColor(xRefTable *model.XRefTable, obj types.Object) *color.NRGBA {
+	arr, err := xRefTable.DereferenceArray(obj)
+	if err != nil {
+		return nil
+	}
+	v := make([]float64, len(arr))
+	for i, o := range arr {
+		n, err := xRefTable.DereferenceNumber(o)
+		if err != nil {
+			return nil
+		}
+		v[i] = min(max(n, 0), 1)
+	}
+	var r, g, b float64
+	switch len(v) {
+	case 1:
+		r, g, b = v[0], v[0], v[0]
+	case 3:
+		r, g, b = v[0], v[1], v[2]
+	case 4:
+		r, g, b = (1-v[0])*(1-v[3]), (1-v[1])*(1-v[3]), (1-v[2])*(1-v[3])
+	default:
+		return nil
+	}
+	return &color.NRGBA{R: uint8(r*255 + 0.5), G: uint8(g*255 + 0.5), B: uint8(b*255 + 0.5), A: 255}
+}
+
+func editAnnotation(ctx *model.Context, dict types.Dict, edit AnnotationEdit) error {
+	if edit.Contents != nil {
+		if *edit.Contents == "" {
+			dict.Delete("Contents")
+		} else {
+			s, err := pdfTextString(*edit.Contents)
+			if err != nil {
+				return err
+			}
+			dict.Update("Contents", s)
+		}
+	}
+	if edit.Rect != nil {
+		old := pageBox(ctx.XRefTable, dict, "Rect")
+		to := orderedRect(edit.Rect.LLX, edit.Rect.LLY, edit.Rect.URX, edit.Rect.URY)
+		if old != nil && !old.Empty() {
+			mapAnnotationPoints(ctx.XRefTable, dict, *old, to)
+		}
+		dict.Update("Rect", to.rectangle().Array())
+	}
+	if edit.Color != nil {
+		dict.Update("C", simpleColor(edit.Color).Array())
+	}
+	// The appearance stream of a shape still shows its old colour and
+	// geometry. Other annotations keep theirs, such as the image of a
+	// stamp or the value of a form field.
+	if edit.Color != nil || edit.Rect != nil {
+		if err := redrawAppearance(ctx.XRefTable, dict); err != nil {
+			return err
+		}
+	}
+	dict.Update("M", types.StringLiteral(types.DateString(time.Now())))
+	return nil
+}
+
+// mapAnnotationPoints moves the coordinates of the point arrays of an
+// annotation from the rectangle from to the rectangle to.
+func mapAnnotationPoints(xRefTable *model.XRefTable, dict types.Dict, from, to Rect) {
+	sx, sy := to.Width()/from.Width(), to.Height()/from.Height()
+	mapArray := func(arr types.Array) types.Array {
+		out := make(types.Array, len(arr))
+		for i, o := range arr {
+			n, err := xRefTable.DereferenceNumber(o)
+			if err != nil {
+				return arr
+			}
+			if i%2 == 0 {
+				n = to.LLX + (n-from.LLX)*sx
+			} else {
+				n = to.LLY + (n-from.LLY)*sy
+			}
+			out[i] = types.Float(n)
+		}
+		return out
+	}
+	for _, key := range []string{"QuadPoints", "Vertices", "L", "CL"} {
+		if arr, err := xRefTable.DereferenceArray(dict[key]); err == nil && arr != nil {
+			dict.Update(key, mapArray(arr))
+		}
+	}
+	if ink, err := xRefTable.DereferenceArray(dict["InkList"]); err == nil && ink != nil {
+		strokes := make(types.Array, len(ink))
+		for i, stroke := range ink {
+			arr, err := xRefTable.DereferenceArray(stroke)
+			if err != nil {
+				return
+			}
+			strokes[i] = mapArray(arr)
+		}
+		dict.Update("InkList", strokes)
+	}
+}
+
+// writeAnnotated writes an edited document to outputPath, or back to
+// inputPath if outputPath is empty.
+func writeAnnotated(ctx *model.Context, inputPath, outputPath string) error {
+	if outputPath == "" {
+		outputPath = inputPath
+	}
+	return api.WriteContextFile(ctx, outputPath)
+}
@@ -0,0 +1,324 @@
+package pdf
+
+import (
+	"fmt"
+	"image/color"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+func writeAnnotatedPDF(t *testing.T) string {
+	t.Helper()
+	return writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 7 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [4 0 R 5 0 R 6 0 R] >>",
+		"<< /Type /Annot /Subtype /Text /NM (note-1) /T (Ann) /Contents (Check this) /C [1 0 0] /M (D:20240506070809Z) /Rect [50 700 70 720] >>",
+		"<< /Type /Annot /Subtype /Highlight /C [0 0 0 0] /Popup 6 0 R /Rect [100 100 200 120] /QuadPoints [100 120 200 120 100 100 200 100] >>",
+		"<< /Type /Annot /Subtype /Popup /Parent 5 0 R /Rect [200 100 300 200] >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [8 0 R] >>",
+		"<< /Type /Annot /Subtype /Square /NM (box) /Rect [10 10 40 40] /C [0.5] >>",
+	)
+}
+
+func TestDocumentAnnotations(t *testing.T) {
+	doc, err := Open(writeAnnotatedPDF(t))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+
+	got, err := doc.Annotations()
+	if err != nil {
+		t.Fatalf("Annotations() returned error: %v", err)
+	}
+	want := []Annotation{
+		{
+			ID: "note-1", Page: 0, Type: "Text", Rect: Rect{LLX: 50, LLY: 700, URX: 70, URY: 720},
+			Author: "Ann", Contents: "Check this", Color: &color.NRGBA{R: 255, A: 255},
+			Modified: time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		},
+		{ID: "#5", Page: 0, Type: "Highlight", Rect: Rect{LLX: 100, LLY: 100, URX: 200, URY: 120}, Color: &color.NRGBA{R: 255, G: 255, B: 255, A: 255}},
+		{ID: "box", Page: 1, Type: "Square", Rect: Rect{LLX: 10, LLY: 10, URX: 40, URY: 40}, Color: &color.NRGBA{R: 128, G: 128, B: 128, A: 255}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Annotations() = %+v, want %d annotations", got, len(want))
+	}
+	for i := range want {
+		if !got[i].Modified.Equal(want[i].Modified) {
+			t.Errorf("Annotations()[%d].Modified = %v, want %v", i, got[i].Modified, want[i].Modified)
+		}
+		got[i].Modified, want[i].Modified = time.Time{}, time.Time{}
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("Annotations()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAnnotatorUpdateAndRemoveAnnotations(t *testing.T) {
+	in := writeAnnotatedPDF(t)
+	out := filepath.Join(t.TempDir(), "edited.pdf")
+	a := NewAnnotator()
+
+	contents := "Fixed"
+	if err := a.UpdateAnnotation(in, out, "note-1", AnnotationEdit{Contents: &contents, Color: color.NRGBA{B: 255, A: 255}}); err != nil {
+		t.Fatalf("UpdateAnnotation() returned error: %v", err)
+	}
+	if err := a.UpdateAnnotation(out, "", "#5", AnnotationEdit{Rect: &Rect{LLX: 100, LLY: 200, URX: 300, URY: 240}}); err != nil {
+		t.Fatalf("UpdateAnnotation() returned error: %v", err)
+	}
+	if err := a.UpdateAnnotation(out, "", "missing", AnnotationEdit{Contents: &contents}); err == nil {
+		t.Error("UpdateAnnotation() expected error for an unknown ID")
+	}
+
+	doc, err := Open(out)
+	if err != nil {
+		t.Fatalf("Open(edited) failed: %v", err)
+	}
+	list, err := doc.Annotations()
+	if err != nil {
+		t.Fatalf("Annotations() returned error: %v", err)
+	}
+	if note := list[0]; note.Contents != "Fixed" || *note.Color != (color.NRGBA{B: 255, A: 255}) || note.Modified.IsZero() {
+		t.Errorf("edited note = %+v", note)
+	}
+	if hl := list[1]; hl.Rect != (Rect{LLX: 100, LLY: 200, URX: 300, URY: 240}) {
+		t.Errorf("moved highlight = %+v", hl)
+	}
+	// The quads of the highlight follow its rectangle.
+	entries, _ := pageAnnotations(doc.ctx, 0)
+	quads, _ := doc.ctx.DereferenceArray(entries[1].dict["QuadPoints"])
+	var points []float64
+	for _, o := range quads {
+		n, _ := doc.ctx.DereferenceNumber(o)
+		points = append(points, n)
+	}
+	if want := []float64{100, 240, 300, 240, 100, 200, 300, 200}; !reflect.DeepEqual(points, want) {
+		t.Errorf("QuadPoints = %v, want %v", points, want)
+	}
+	doc.Close()
+
+	n, err := a.RemoveAnnotations(out, "", []string{"#5", "box"})
+	if err != nil || n != 2 {
+		t.Fatalf("RemoveAnnotations() = %d, %v, want 2", n, err)
+	}
+	if _, err := a.RemoveAnnotations(out, "", []string{"#5"}); err == nil {
+		t.Error("RemoveAnnotations() expected error for a removed ID")
+	}
+
+	doc, err = Open(out)
+	if err != nil {
+		t.Fatalf("Open(removed) failed: %v", err)
+	}
+	defer doc.Close()
+	list, err = doc.Annotations()
+	if err != nil || len(list) != 1 || list[0].ID != "note-1" {
+		t.Errorf("Annotations() after removal = %+v, %v", list, err)
+	}
+	// The pop-up of the highlight went with it.
+	if entries, _ := pageAnnotations(doc.ctx, 0); len(entries) != 1 {
+		t.Errorf("page 1 keeps %d annotations, want 1", len(entries))
+	}
+}
+
+func TestAnnotatorUpdateShapeColorRedrawsAppearance(t *testing.T) {
+	path := writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+	)
+	out := filepath.Join(t.TempDir(), "arrow.pdf")
+	a := NewAnnotator()
+	arrow := ShapeStyle{Color: color.NRGBA{B: 255, A: 255}, Width: 2, Dash: []float64{4, 2}, LineEnd: LineEndingOpenArrow}
+	if err := a.AddLine(path, out, 0, Point{X: 100, Y: 100}, Point{X: 300, Y: 100}, arrow, "arrow"); err != nil {
+		t.Fatalf("AddLine() returned error: %v", err)
+	}
+	doc, err := Open(out)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	list, err := doc.Annotations()
+	doc.Close()
+	if err != nil || len(list) != 1 {
+		t.Fatalf("Annotations() = %+v, %v", list, err)
+	}
+	if err := a.UpdateAnnotation(out, "", list[0].ID, AnnotationEdit{Color: color.NRGBA{G: 255, A: 255}}); err != nil {
+		t.Fatalf("UpdateAnnotation() returned error: %v", err)
+	}
+
+	ctx, err := api.ReadContextFile(out)
+	if err != nil {
+		t.Fatalf("ReadContextFile() failed: %v", err)
+	}
+	entries, err := pageAnnotations(ctx, 0)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("pageAnnotations() = %d entries, %v", len(entries), err)
+	}
+	line := entries[0].dict
+	if c := annotationColor(ctx.XRefTable, line["C"]); c == nil || *c != (color.NRGBA{G: 255, A: 255}) {
+		t.Errorf("C = %v, want green", c)
+	}
+	ap, err := ctx.DereferenceDict(line["AP"])
+	if err != nil || ap == nil {
+		t.Fatal("the edited arrow has no appearance dictionary")
+	}
+	sd, _, err := ctx.DereferenceStreamDict(ap["N"])
+	if err != nil || sd == nil {
+		t.Fatal("the edited arrow has no normal appearance stream")
+	}
+	if err := sd.Decode(); err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+	content := string(sd.Content)
+	for _, want := range []string{"0 1 0 RG", "[4 2] 0 d", "100 100 m\n300 100 l\nS"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("appearance %q lacks %q", content, want)
+		}
+	}
+	if strings.Contains(content, "0 0 1 RG") {
+		t.Errorf("appearance %q keeps the old colour", content)
+	}
+}
+
+func TestAnnotatorUpdateKeepsOtherAppearances(t *testing.T) {
+	form := "0 0 1 rg 0 0 40 20 re f"
+	path := writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [4 0 R 5 0 R] >>",
+		"<< /Type /Annot /Subtype /Stamp /NM (stamp) /Rect [100 100 140 120] /AP << /N 6 0 R >> >>",
+		"<< /Type /Annot /Subtype /Widget /NM (field) /FT /Tx /T (name) /Rect [200 100 240 120] /AP << /N 6 0 R >> >>",
+		fmt.Sprintf("<< /Type /XObject /Subtype /Form /BBox [0 0 40 20] /Length %d >>\nstream\n%s\nendstream", len(form), form),
+	)
+	a := NewAnnotator()
+	for _, id := range []string{"stamp", "field"} {
+		edit := AnnotationEdit{Color: color.NRGBA{G: 255, A: 255}, Rect: &Rect{LLX: 300, LLY: 300, URX: 380, URY: 340}}
+		if err := a.UpdateAnnotation(path, "", id, edit); err != nil {
+			t.Fatalf("UpdateAnnotation(%s) returned error: %v", id, err)
+		}
+	}
+
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		t.Fatalf("ReadContextFile() failed: %v", err)
+	}
+	entries, err := pageAnnotations(ctx, 0)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("pageAnnotations() = %d entries, %v", len(entries), err)
+	}
+	for _, e := range entries {
+		ap, err := ctx.DereferenceDict(e.dict["AP"])
+		if err != nil || ap == nil {
+			t.Fatalf("the recoloured %s lost its appearance dictionary", e.subtype())
+		}
+		sd, _, err := ctx.DereferenceStreamDict(ap["N"])
+		if err != nil || sd == nil {
+			t.Fatalf("the recoloured %s lost its normal appearance stream", e.subtype())
+		}
+		if err := sd.Decode(); err != nil {
+			t.Fatalf("Decode() failed: %v", err)
+		}
+		if got := strings.TrimSpace(string(sd.Content)); got != form {
+			t.Errorf("%s appearance = %q, want %q", e.subtype(), got, form)
+		}
+	}
+}
+
+func TestAnnotatorUpdateShapeRectRedrawsAppearance(t *testing.T) {
+	path := writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+	)
+	a := NewAnnotator()
+	if err := a.AddSquare(path, "", 0, Rect{LLX: 100, LLY: 100, URX: 200, URY: 150}, ShapeStyle{Width: 2}, ""); err != nil {
+		t.Fatalf("AddSquare() returned error: %v", err)
+	}
+	if err := a.AddLine(path, "", 0, Point{X: 100, Y: 300}, Point{X: 200, Y: 300}, ShapeStyle{LineEnd: LineEndingOpenArrow}, ""); err != nil {
+		t.Fatalf("AddLine() returned error: %v", err)
+	}
+	doc, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	list, err := doc.Annotations()
+	doc.Close()
+	if err != nil || len(list) != 2 {
+		t.Fatalf("Annotations() = %+v, %v", list, err)
+	}
+	square := Rect{LLX: 300, LLY: 400, URX: 500, URY: 500}
+	if err := a.UpdateAnnotation(path, "", list[0].ID, AnnotationEdit{Rect: &square}); err != nil {
+		t.Fatalf("UpdateAnnotation(square) returned error: %v", err)
+	}
+	// Doubling the length of the line leaves its arrowhead the same size.
+	line := list[1].Rect
+	line.URX += line.Width()
+	if err := a.UpdateAnnotation(path, "", list[1].ID, AnnotationEdit{Rect: &line}); err != nil {
+		t.Fatalf("UpdateAnnotation(line) returned error: %v", err)
+	}
+
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		t.Fatalf("ReadContextFile() failed: %v", err)
+	}
+	entries, err := pageAnnotations(ctx, 0)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("pageAnnotations() = %d entries, %v", len(entries), err)
+	}
+	// The margin of the line, left of its start, doubles with the rest.
+	wants := []string{"301 401 198 98 re", "101 300 m\n301 300 l\nS\n[] 0 d\n293.206 304.5 m\n301 300 l"}
+	for i, e := range entries {
+		ap, _ := ctx.DereferenceDict(e.dict["AP"])
+		sd, _, err := ctx.DereferenceStreamDict(ap["N"])
+		if err != nil || sd == nil {
+			t.Fatalf("the moved %s has no normal appearance stream", e.subtype())
+		}
+		if bbox, rect := pageBox(ctx.XRefTable, sd.Dict, "BBox"), pageBox(ctx.XRefTable, e.dict, "Rect"); bbox == nil || rect == nil || *bbox != *rect {
+			t.Errorf("%s BBox = %v, Rect = %v", e.subtype(), bbox, rect)
+		}
+		if err := sd.Decode(); err != nil {
+			t.Fatalf("Decode() failed: %v", err)
+		}
+		if content := string(sd.Content); !strings.Contains(content, wants[i]) {
+			t.Errorf("%s appearance %q lacks %q", e.subtype(), content, wants[i])
+		}
+	}
+	if rect := pageBox(ctx.XRefTable, entries[0].dict, "Rect"); rect == nil || *rect != square {
+		t.Errorf("square Rect = %v, want %v", rect, square)
+	}
+}
+
+func TestAnnotatorEditsEncryptedFile(t *testing.T) {
+	encrypted := filepath.Join(t.TempDir(), "locked.pdf")
+	if err := api.EncryptFile(writeAnnotatedPDF(t), encrypted, model.NewAESConfiguration("user", "owner", 256)); err != nil {
+		t.Fatalf("EncryptFile() failed: %v", err)
+	}
+
+	contents := "Fixed"
+	if err := NewAnnotator().UpdateAnnotation(encrypted, "", "note-1", AnnotationEdit{Contents: &contents}); err == nil {
+		t.Fatal("UpdateAnnotation() expected error without the password")
+	}
+	a := NewAnnotatorWithPasswords("user", "owner")
+	if err := a.UpdateAnnotation(encrypted, "", "note-1", AnnotationEdit{Contents: &contents}); err != nil {
+		t.Fatalf("UpdateAnnotation() returned error: %v", err)
+	}
+	if n, err := a.RemoveAnnotations(encrypted, "", []string{"box"}); err != nil || n != 1 {
+		t.Fatalf("RemoveAnnotations() = %d, %v, want 1", n, err)
+	}
+
+	doc, err := openWithPasswords(encrypted, "user", "owner")
+	if err != nil {
+		t.Fatalf("openWithPasswords() failed: %v", err)
+	}
+	defer doc.Close()
+	list, err := doc.Annotations()
+	if err != nil || len(list) != 2 || list[0].Contents != "Fixed" {
+		t.Errorf("Annotations() = %+v, %v", list, err)
+	}
+}
@@ -0,0 +1,625 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+
+	pdfcolor "github.com/pdfcpu/pdfcpu/pkg/pdfcpu/color"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// LineEnding is the mark drawn at an end of a line or polyline annotation.
+type LineEnding int
+
+const (
+	LineEndingNone LineEnding = iota
+	LineEndingOpenArrow
+	LineEndingClosedArrow
+	LineEndingCircle
+	LineEndingSquare
+	LineEndingDiamond
+)
+
+// String returns the PDF name of e.
+func (e LineEnding) String() string {
+	return model.LineEndingStyleName(e.style())
+}
+
+// lineEndingNamed returns the line ending with the PDF name name, or
+// LineEndingNone.
+func lineEndingNamed(name string) LineEnding {
+	for e := LineEndingOpenArrow; e <= LineEndingDiamond; e++ {
+		if e.String() == name {
+			return e
+		}
+	}
+	return LineEndingNone
+}
+
+func (e LineEnding) style() model.LineEndingStyle {
+	switch e {
+	case LineEndingOpenArrow:
+		return model.LEOpenArrow
+	case LineEndingClosedArrow:
+		return model.LEClosedArrow
+	case LineEndingCircle:
+		return model.LECircle
+	case LineEndingSquare:
+		return model.LESquare
+	case LineEndingDiamond:
+		return model.LEDiamond
+	default:
+		return model.LENone
+	}
+}
+
+// ShapeStyle is the appearance of a shape annotation.
+type ShapeStyle struct {
+	// Color strokes the outline; nil draws in red.
+	Color color.Color
+	// Interior fills squares, circles, polygons and closed line endings;
+	// nil leaves them empty.
+	Interior color.Color
+	// Width is the width of the outline in points; zero draws 1 point wide.
+	Width float64
+	// Dash is the lengths of the dashes and gaps of a dashed outline; nil
+	// draws it solid.
+	Dash []float64
+	// Opacity applies to the whole shape; zero is opaque.
+	Opacity float64
+	// LineStart and LineEnd are drawn at the ends of lines and polylines.
+	LineStart, LineEnd LineEnding
+}
+
+func (s ShapeStyle) width() float64 {
+	if s.Width == 0 {
+		return 1
+	}
+	return s.Width
+}
+
+func (s ShapeStyle) validate() error {
+	if s.Width < 0 {
+		return fmt.Errorf("line width %g must not be negative", s.Width)
+	}
+	if s.Opacity < 0 || s.Opacity > 1 {
+		return fmt.Errorf("opacity %g is out of range 0..1", s.Opacity)
+	}
+	total := 0.0
+	for _, d := range s.Dash {
+		if d < 0 {
+			return fmt.Errorf("dash length %g must not be negative", d)
+		}
+		total += d
+	}
+	if len(s.Dash) > 0 && total == 0 {
+		return errors.New("dash pattern must not be all zeros")
+	}
+	return nil
+}
+
+// AddSquare adds a rectangle annotation with the bounds rect, in PDF user
+// space, to the selected page. The outline is drawn inside rect.
+func (a *Annotator) AddSquare(inputPath, outputPath string, pageNum int, rect Rect, style ShapeStyle, contents string) error {
+	return a.addBoxShape(inputPath, outputPath, pageNum, model.AnnSquare, rect, style, contents)
+}
+
+// AddCircle adds an ellipse annotation inscribed in rect, in PDF user
+// space, to the selected page.
+func (a *Annotator) AddCircle(inputPath, outputPath string, pageNum int, rect Rect, style ShapeStyle, contents string) error {
+	return a.addBoxShape(inputPath, outputPath, pageNum, model.AnnCircle, rect, style, contents)
+}
+
+func (a *Annotator) addBoxShape(inputPath, outputPath string, pageNum int, kind model.AnnotationType, rect Rect, style ShapeStyle, contents string) error {
+	if err := validateAnnotationInput(inputPath, pageNum); err != nil {
+		return err
+	}
+	if err := style.validate(); err != nil {
+		return err
+	}
+
+	box, err := annotationRect(inputPath, pageNum, rect)
+	if err != nil {
+		return err
+	}
+	col, fill, opacity := shapeColors(style)
+	bs := shapeBorderStyle(style)
+	var ann model.AnnotationRenderer
+	if kind == model.AnnCircle {
+		ann = model.NewCircleAnnotation(*box, contents, nextAnnotationID("circle"), "", 0, &col,
+			"OpenPDF Reader", nil, opacity, "", "", fill, 0, 0, 0, 0, style.width(), bs, false, 0)
+	} else {
+		ann = model.NewSquareAnnotation(*box, contents, nextAnnotationID("square"), "", 0, &col,
+			"OpenPDF Reader", nil, opacity, "", "", fill, 0, 0, 0, 0, style.width(), bs, false, 0)
+	}
+
+	bbox := normalizedRect(box)
+	return addAnnotationsFile(inputPath, outputPath, pageSelection(pageNum),
+		withAppearance(ann, bbox, boxContent(kind == model.AnnCircle, bbox, style), style), nil, false)
+}
+
+// boxContent draws a rectangle, or an ellipse if ellipse is set, with its
+// outline inside bbox.
+func boxContent(ellipse bool, bbox Rect, style ShapeStyle) *shapeContent {
+	content := newShapeContent(style)
+	inner := insetRect(bbox, style.width()/2)
+	if ellipse {
+		content.ellipse(inner)
+	} else {
+		content.rect(inner)
+	}
+	content.paint(true)
+	return content
+}
+
+// AddLine adds a line annotation from one point to another, in PDF user
+// space, to the selected page. Arrowheads and other marks are drawn at its
+// ends as style's LineStart and LineEnd.
+func (a *Annotator) AddLine(inputPath, outputPath string, pageNum int, from, to Point, style ShapeStyle, contents string) error {
+	if err := validateAnnotationInput(inputPath, pageNum); err != nil {
+		return err
+	}
+	if err := style.validate(); err != nil {
+		return err
+	}
+	if from == to {
+		return errors.New("line has no length")
+	}
+
+	content, bounds := lineContent([]Point{from, to}, style, false)
+	box, err := annotationRect(inputPath, pageNum, bounds)
+	if err != nil {
+		return err
+	}
+	col, fill, opacity := shapeColors(style)
+	start, end := style.LineStart.style(), style.LineEnd.style()
+	var intent *model.LineIntent
+	if style.LineStart != LineEndingNone || style.LineEnd != LineEndingNone {
+		arrow := model.IntentLineArrow
+		intent = &arrow
+	}
+	ann := model.NewLineAnnotation(*box, contents, nextAnnotationID("line"), "", 0, &col,
+		"OpenPDF Reader", nil, opacity, "", "",
+		types.Point{X: from.X, Y: from.Y}, types.Point{X: to.X, Y: to.Y}, &start, &end,
+		0, 0, 0, intent, nil, false, false, 0, 0, fill, style.width(), shapeBorderStyle(style))
+
+	return addAnnotationsFile(inputPath, outputPath, pageSelection(pageNum),
+		withAppearance(ann, normalizedRect(box), content, style), nil, false)
+}
+
+// AddPolygon adds a closed polygon annotation with the given vertices, in
+// PDF user space, to the selected page.
+func (a *Annotator) AddPolygon(inputPath, outputPath string, pageNum int, vertices []Point, style ShapeStyle, contents string) error {
+	return a.addPolyShape(inputPath, outputPath, pageNum, model.AnnPolygon, vertices, style, contents)
+}
+
+// AddPolyLine adds an open polyline annotation through the given vertices,
+// in PDF user space, to the selected page, with style's LineStart and
+// LineEnd drawn at its ends.
+func (a *Annotator) AddPolyLine(inputPath, outputPath string, pageNum int, vertices []Point, style ShapeStyle, contents string) error {
+	return a.addPolyShape(inputPath, outputPath, pageNum, model.AnnPolyLine, vertices, style, contents)
+}
+
+func (a *Annotator) addPolyShape(inputPath, outputPath string, pageNum int, kind model.AnnotationType, vertices []Point, style ShapeStyle, contents string) error {
+	if err := validateAnnotationInput(inputPath, pageNum); err != nil {
+		return err
+	}
+	if err := style.validate(); err != nil {
+		return err
+	}
+	closed := kind == model.AnnPolygon
+	if closed && len(vertices) < 3 {
+		return errors.New("polygon needs at least 3 vertices")
+	}
+	if len(vertices) < 2 {
+		return errors.New("polyline needs at least 2 vertices")
+	}
+
+	content, bounds := lineContent(vertices, style, closed)
+	box, err := annotationRect(inputPath, pageNum, bounds)
+	if err != nil {
+		return err
+	}
+	coords := make([]float64, 0, 2*len(vertices))
+	for _, v := range vertices {
+		coords = append(coords, v.X, v.Y)
+	}
+	col, fill, opacity := shapeColors(style)
+	var ann model.AnnotationRenderer
+	if closed {
+		ann = model.NewPolygonAnnotation(*box, contents, nextAnnotationID("polygon"), "", 0, &col,
+			"OpenPDF Reader", nil, opacity, "", "",
+			types.NewNumberArray(coords...), nil, nil, nil, fill, style.width(), shapeBorderStyle(style), false, 0)
+	} else {
+		start, end := style.LineStart.style(), style.LineEnd.style()
+		ann = model.NewPolyLineAnnotation(*box, contents, nextAnnotationID("polyline"), "", 0, &col,
+			"OpenPDF Reader", nil, opacity, "", "",
+			types.NewNumberArray(coords...), nil, nil, nil, fill, style.width(), shapeBorderStyle(style), &start, &end)
+	}
+
+	return addAnnotationsFile(inputPath, outputPath, pageSelection(pageNum),
+		withAppearance(ann, normalizedRect(box), content, style), nil, false)
+}
+
+// shapeColors returns the outline colour, interior colour and opacity of
+// style in the form pdfcpu takes them.
+func shapeColors(style ShapeStyle) (pdfcolor.SimpleColor, *pdfcolor.SimpleColor, *float64) {
+	col := pdfcolor.Red
+	if style.Color != nil {
+		col = simpleColor(style.Color)
+	}
+	var fill *pdfcolor.SimpleColor
+	if style.Interior != nil {
+		c := simpleColor(style.Interior)
+		fill = &c
+	}
+	var opacity *float64
+	if style.Opacity > 0 && style.Opacity < 1 {
+		opacity = &style.Opacity
+	}
+	return col, fill, opacity
+}
+
+func shapeBorderStyle(style ShapeStyle) model.BorderStyle {
+	if len(style.Dash) > 0 {
+		return model.BSDashed
+	}
+	return model.BSSolid
+}
+
+// redrawAppearance replaces the appearance stream of a square, circle,
+// line, polygon or polyline annotation with one drawn from its entries, as
+// the Add functions draw them. Other annotations are left alone.
+func redrawAppearance(xRefTable *model.XRefTable, dict types.Dict) error {
+	rect := pageBox(xRefTable, dict, "Rect")
+	style := readShapeStyle(xRefTable, dict)
+	var content *shapeContent
+	switch subtype := dict.NameEntry("Subtype"); {
+	case rect == nil || subtype == nil:
+	case *subtype == "Square" || *subtype == "Circle":
+		content = boxContent(*subtype == "Circle", *rect, style)
+	case *subtype == "Line":
+		if points := annotationPoints(xRefTable, dict["L"]); len(points) == 2 {
+			content, *rect = lineContent(points, style, false)
+		}
+	case *subtype == "Polygon" || *subtype == "PolyLine":
+		closed := *subtype == "Polygon"
+		if points := annotationPoints(xRefTable, dict["Vertices"]); len(points) >= 2 && (!closed || len(points) >= 3) {
+			content, *rect = lineContent(points, style, closed)
+		}
+	}
+	if content == nil {
+		return nil
+	}
+	// Lines keep their arrowheads inside the rectangle, whose margins do
+	// not scale with the line.
+	dict.Update("Rect", rect.rectangle().Array())
+
+	ref, err := appearanceStream(xRefTable, *rect, content.buf.Bytes(), style.Opacity)
+	if err != nil {
+		return err
+	}
+	dict.Update("AP", types.Dict{"N": *ref})
+	return nil
+}
+
+// readShapeStyle reads the style of a shape annotation from its colours,
+// border style, opacity and line endings.
+func readShapeStyle(xRefTable *model.XRefTable, dict types.Dict) ShapeStyle {
+	var style ShapeStyle
+	if c := annotationColor(xRefTable, dict["C"]); c != nil {
+		style.Color = *c
+	}
+	if c := annotationColor(xRefTable, dict["IC"]); c != nil {
+		style.Interior = *c
+	}
+	if bs, err := xRefTable.DereferenceDict(dict["BS"]); err == nil && bs != nil {
+		if w, err := xRefTable.DereferenceNumber(bs["W"]); err == nil && w > 0 {
+			style.Width = w
+		}
+		if dash := annotationNumbers(xRefTable, bs["D"]); len(dash) > 0 {
+			style.Dash = dash
+		}
+	}
+	if ca, err := xRefTable.DereferenceNumber(dict["CA"]); err == nil && ca >= 0 && ca < 1 {
+		style.Opacity = ca
+	}
+	if le, err := xRefTable.DereferenceArray(dict["LE"]); err == nil && len(le) == 2 {
+		if name, ok := le[0].(types.Name); ok {
+			style.LineStart = lineEndingNamed(string(name))
+		}
+		if name, ok := le[1].(types.Name); ok {
+			style.LineEnd = lineEndingNamed(string(name))
+		}
+	}
+	return style
+}
+
+// annotationPoints reads an array of x, y coordinates such as /L or
+// /Vertices.
+func annotationPoints(xRefTable *model.XRefTable, obj types.Object) []Point {
+	v := annotationNumbers(xRefTable, obj)
+	points := make([]Point, 0, len(v)/2)
+	for i := 0; i+1 < len(v); i += 2 {
+		points = append(points, Point{X: v[i], Y: v[i+1]})
+	}
+	return points
+}
+
+// annotationNumbers reads an array of numbers, or returns nil.
+func annotationNumbers(xRefTable *model.XRefTable, obj types.Object) []float64 {
+	arr, err := xRefTable.DereferenceArray(obj)
+	if err != nil {
+		return nil
+	}
+	v := make([]float64, len(arr))
+	for i, o := range arr {
+		n, err := xRefTable.DereferenceNumber(o)
+		if err != nil {
+			return nil
+		}
+		v[i] = n
+	}
+	return v
+}
+
+// lineContent draws a polyline, closed into a polygon if closed, with the
+// line endings of style, and returns the drawing and the area it covers.
+func lineContent(points []Point, style ShapeStyle, closed bool) (*shapeContent, Rect) {
+	w := style.width()
+	c := newShapeContent(style)
+	c.polyline(points, closed)
+	c.paint(closed)
+
+	var bounds Rect
+	add := func(p Point, r float64) {
+		bounds = bounds.Union(Rect{LLX: p.X - r, LLY: p.Y - r, URX: p.X + r, URY: p.Y + r})
+	}
+	for _, p := range points {
+		// Miters at sharp corners reach further than half the width.
+		add(p, w)
+	}
+	if !closed {
+		n := len(points)
+		c.resetDash()
+		for _, end := range []struct {
+			ending   LineEnding
+			tip, out Point
+		}{
+			{style.LineStart, points[0], points[1]},
+			{style.LineEnd, points[n-1], points[n-2]},
+		} {
+			if end.ending == LineEndingNone {
+				continue
+			}
+			size := lineEndingSize(w)
+			c.lineEnding(end.ending, end.tip, end.out, size)
+			add(end.tip, size+w)
+		}
+	}
+	return c, bounds
+}
+
+// lineEndingSize is the length of an arrowhead, and the diameter of the
+// other line endings, for lines of width w.
+func lineEndingSize(w float64) float64 {
+	return 6 + 3*w
+}
+
+// insetRect shrinks r by d on every side, keeping at least its centre.
+func insetRect(r Rect, d float64) Rect {
+	dx, dy := min(d, r.Width()/2), min(d, r.Height()/2)
+	return Rect{LLX: r.LLX + dx, LLY: r.LLY + dy, URX: r.URX - dx, URY: r.URY - dy}
+}
+
+// kappa places the control points of the Bézier curves approximating a
+// quarter ellipse.
+const kappa = 0.5522847498
+
+// shapeContent builds the content stream of a shape's appearance.
+type shapeContent struct {
+	buf      bytes.Buffer
+	interior bool
+}
+
+func newShapeContent(style ShapeStyle) *shapeContent {
+	c := &shapeContent{interior: style.Interior != nil}
+	if style.Opacity > 0 && style.Opacity < 1 {
+		c.buf.WriteString("/GS0 gs\n")
+	}
+	col := pdfcolor.Red
+	if style.Color != nil {
+		col = simpleColor(style.Color)
+	}
+	fmt.Fprintf(&c.buf, "%s %s %s RG\n", pdfNumber(float64(col.R)), pdfNumber(float64(col.G)), pdfNumber(float64(col.B)))
+	if style.Interior != nil {
+		fill := simpleColor(style.Interior)
+		fmt.Fprintf(&c.buf, "%s %s %s rg\n", pdfNumber(float64(fill.R)), pdfNumber(float64(fill.G)), pdfNumber(float64(fill.B)))
+	}
+	fmt.Fprintf(&c.buf, "%s w\n1 j\n", pdfNumber(style.width()))
+	if len(style.Dash) > 0 {
+		c.buf.WriteString("[")
+		for i, d := range style.Dash {
+			if i > 0 {
+				c.buf.WriteString(" ")
+			}
+			c.buf.WriteString(pdfNumber(d))
+		}
+		c.buf.WriteString("] 0 d\n")
+	}
+	return c
+}
+
+func (c *shapeContent) moveTo(p Point) {
+	fmt.Fprintf(&c.buf, "%s %s m\n", pdfNumber(p.X), pdfNumber(p.Y))
+}
+
+func (c *shapeContent) lineTo(p Point) {
+	fmt.Fprintf(&c.buf, "%s %s l\n", pdfNumber(p.X), pdfNumber(p.Y))
+}
+
+func (c *shapeContent) curveTo(c1, c2, p Point) {
+	fmt.Fprintf(&c.buf, "%s %s %s %s %s %s c\n",
+		pdfNumber(c1.X), pdfNumber(c1.Y), pdfNumber(c2.X), pdfNumber(c2.Y), pdfNumber(p.X), pdfNumber(p.Y))
+}
+
+func (c *shapeContent) rect(r Rect) {
+	fmt.Fprintf(&c.buf, "%s %s %s %s re\n", pdfNumber(r.LLX), pdfNumber(r.LLY), pdfNumber(r.Width()), pdfNumber(r.Height()))
+}
+
+// ellipse adds the ellipse inscribed in r as four curves.
+func (c *shapeContent) ellipse(r Rect) {
+	cx, cy := (r.LLX+r.URX)/2, (r.LLY+r.URY)/2
+	rx, ry := r.Width()/2, r.Height()/2
+	kx, ky := kappa*rx, kappa*ry
+	c.moveTo(Point{cx + rx, cy})
+	c.curveTo(Point{cx + rx, cy + ky}, Point{cx + kx, cy + ry}, Point{cx, cy + ry})
+	c.curveTo(Point{cx - kx, cy + ry}, Point{cx - rx, cy + ky}, Point{cx - rx, cy})
+	c.curveTo(Point{cx - rx, cy - ky}, Point{cx - kx, cy - ry}, Point{cx, cy - ry})
+	c.curveTo(Point{cx + kx, cy - ry}, Point{cx + rx, cy - ky}, Point{cx + rx, cy})
+	c.buf.WriteString("h\n")
+}
+
+func (c *shapeContent) polyline(points []Point, closed bool) {
+	c.moveTo(points[0])
+	for _, p := range points[1:] {
+		c.lineTo(p)
+	}
+	if closed {
+		c.buf.WriteString("h\n")
+	}
+}
+
+// paint strokes the current path, filling it first if it is a closed
+// shape and the style has an interior colour.
+func (c *shapeContent) paint(closed bool) {
+	if closed && c.interior {
+		c.buf.WriteString("B\n")
+	} else {
+		c.buf.WriteString("S\n")
+	}
+}
+
+// resetDash makes the following lines solid, as line endings are drawn.
+func (c *shapeContent) resetDash() {
+	c.buf.WriteString("[] 0 d\n")
+}
+
+// lineEnding draws ending at tip, the end of a line segment coming from
+// out, size long.
+func (c *shapeContent) lineEnding(ending LineEnding, tip, out Point, size float64) {
+	dx, dy := tip.X-out.X, tip.Y-out.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return
+	}
+	// d points along the line out of the tip, n across it.
+	d := Point{dx / length, dy / length}
+	n := Point{-d.Y, d.X}
+	at := func(along, across float64) Point {
+		return Point{tip.X + along*d.X + across*n.X, tip.Y + along*d.Y + across*n.Y}
+	}
+
+	half := size / 2
+	switch ending {
+	case LineEndingOpenArrow, LineEndingClosedArrow:
+		// Wings at 30 degrees to the line.
+		back, side := -size*math.Cos(math.Pi/6), size*math.Sin(math.Pi/6)
+		c.moveTo(at(back, side))
+		c.lineTo(tip)
+		c.lineTo(at(back, -side))
+		if ending == LineEndingOpenArrow {
+			c.buf.WriteString("S\n")
+			return
+		}
+		c.buf.WriteString("h\n")
+	case LineEndingCircle:
+		c.ellipse(Rect{LLX: tip.X - half, LLY: tip.Y - half, URX: tip.X + half, URY: tip.Y + half})
+	case LineEndingSquare:
+		c.moveTo(at(half, half))
+		c.lineTo(at(-half, half))
+		c.lineTo(at(-half, -half))
+		c.lineTo(at(half, -half))
+		c.buf.WriteString("h\n")
+	case LineEndingDiamond:
+		c.moveTo(at(half, 0))
+		c.lineTo(at(0, half))
+		c.lineTo(at(-half, 0))
+		c.lineTo(at(0, -half))
+		c.buf.WriteString("h\n")
+	default:
+		return
+	}
+	c.paint(true)
+}
+
+// appearanceAnnotation gives an annotation pdfcpu renders a normal
+// appearance stream, so that readers show it as drawn here rather than
+// each working out its own, and adds the dash pattern pdfcpu leaves out of
+// the border style.
+type appearanceAnnotation struct {
+	model.AnnotationRenderer
+	bbox    Rect
+	content []byte
+	opacity float64
+	dash    []float64
+}
+
+func withAppearance(ann model.AnnotationRenderer, bbox Rect, content *shapeContent, style ShapeStyle) appearanceAnnotation {
+	return appearanceAnnotation{
+		AnnotationRenderer: ann,
+		bbox:               bbox,
+		content:            content.buf.Bytes(),
+		opacity:            style.Opacity,
+		dash:               style.Dash,
+	}
+}
+
+// RenderDict renders the annotation with its appearance stream.
+func (a appearanceAnnotation) RenderDict(xRefTable *model.XRefTable, pageIndRef *types.IndirectRef) (types.Dict, error) {
+	d, err := a.AnnotationRenderer.RenderDict(xRefTable, pageIndRef)
+	if err != nil {
+		return nil, err
+	}
+	if bs, ok := d["BS"].(types.Dict); ok && len(a.dash) > 0 {
+		bs["D"] = types.NewNumberArray(a.dash...)
+	}
+
+	ref, err := appearanceStream(xRefTable, a.bbox, a.content, a.opacity)
+	if err != nil {
+		return nil, err
+	}
+	d["AP"] = types.Dict{"N": *ref}
+	return d, nil
+}
+
+// appearanceStream adds a form XObject drawing content over bbox at the
+// given opacity, where zero is opaque, and returns a reference to it.
+func appearanceStream(xRefTable *model.XRefTable, bbox Rect, content []byte, opacity float64) (*types.IndirectRef, error) {
+	sd, err := xRefTable.NewStreamDictForBuf(content)
+	if err != nil {
+		return nil, err
+	}
+	sd.InsertName("Type", "XObject")
+	sd.InsertName("Subtype", "Form")
+	sd.Insert("BBox", bbox.rectangle().Array())
+	if opacity > 0 && opacity < 1 {
+		sd.Insert("Resources", types.Dict{
+			"ExtGState": types.Dict{
+				"GS0": types.Dict{
+					"Type": types.Name("ExtGState"),
+					"CA":   types.Float(opacity),
+					"ca":   types.Float(opacity),
+				},
+			},
+		})
+	}
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+	return xRefTable.IndRefForNewObject(*sd)
+}
@@ -0,0 +1,138 @@
+package pdf
+
+import (
+	"image/color"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+func TestAnnotatorAddShapes(t *testing.T) {
+	path := writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+	)
+	out := filepath.Join(t.TempDir(), "shapes.pdf")
+	a := NewAnnotator()
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+
+	arrow := ShapeStyle{Color: blue, Width: 2, Dash: []float64{4, 2}, Opacity: 0.5, LineEnd: LineEndingOpenArrow}
+	if err := a.AddLine(path, out, 0, Point{X: 100, Y: 100}, Point{X: 300, Y: 100}, arrow, "arrow"); err != nil {
+		t.Fatalf("AddLine() returned error: %v", err)
+	}
+	filled := ShapeStyle{Color: red, Interior: blue}
+	if err := a.AddCircle(out, "", 0, Rect{LLX: 50, LLY: 500, URX: 150, URY: 560}, filled, ""); err != nil {
+		t.Fatalf("AddCircle() returned error: %v", err)
+	}
+	if err := a.AddSquare(out, "", 0, Rect{LLX: 200, LLY: 500, URX: 300, URY: 560}, ShapeStyle{}, ""); err != nil {
+		t.Fatalf("AddSquare() returned error: %v", err)
+	}
+	triangle := []Point{{X: 400, Y: 400}, {X: 500, Y: 400}, {X: 450, Y: 480}}
+	if err := a.AddPolygon(out, "", 0, triangle, filled, ""); err != nil {
+		t.Fatalf("AddPolygon() returned error: %v", err)
+	}
+	if err := a.AddPolyLine(out, "", 0, triangle, ShapeStyle{LineStart: LineEndingCircle, LineEnd: LineEndingClosedArrow}, ""); err != nil {
+		t.Fatalf("AddPolyLine() returned error: %v", err)
+	}
+
+	ctx, err := api.ReadContextFile(out)
+	if err != nil {
+		t.Fatalf("ReadContextFile() failed: %v", err)
+	}
+	entries, err := pageAnnotations(ctx, 0)
+	if err != nil {
+		t.Fatalf("pageAnnotations() failed: %v", err)
+	}
+	var subtypes []string
+	for _, e := range entries {
+		subtypes = append(subtypes, e.subtype())
+	}
+	if want := []string{"Line", "Circle", "Square", "Polygon", "PolyLine"}; !reflect.DeepEqual(subtypes, want) {
+		t.Fatalf("annotation types = %v, want %v", subtypes, want)
+	}
+
+	// Every shape carries an appearance stream covering its rectangle.
+	for _, e := range entries {
+		ap, err := ctx.DereferenceDict(e.dict["AP"])
+		if err != nil || ap == nil {
+			t.Fatalf("%s has no appearance dictionary", e.subtype())
+		}
+		sd, _, err := ctx.DereferenceStreamDict(ap["N"])
+		if err != nil || sd == nil {
+			t.Fatalf("%s has no normal appearance stream", e.subtype())
+		}
+		if bbox, rect := pageBox(ctx.XRefTable, sd.Dict, "BBox"), pageBox(ctx.XRefTable, e.dict, "Rect"); bbox == nil || rect == nil || *bbox != *rect {
+			t.Errorf("%s BBox = %v, Rect = %v", e.subtype(), bbox, rect)
+		}
+	}
+
+	line := entries[0].dict
+	if le := line.ArrayEntry("LE"); len(le) != 2 || le[0] != types.Name("None") || le[1] != types.Name("OpenArrow") {
+		t.Errorf("LE = %v", le)
+	}
+	bs := line.DictEntry("BS")
+	if bs == nil || bs.NameEntry("S") == nil || *bs.NameEntry("S") != "D" || len(bs.ArrayEntry("D")) != 2 {
+		t.Errorf("BS = %v, want a dashed border", bs)
+	}
+	ap, _ := ctx.DereferenceDict(line["AP"])
+	sd, _, _ := ctx.DereferenceStreamDict(ap["N"])
+	if err := sd.Decode(); err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+	content := string(sd.Content)
+	for _, want := range []string{"/GS0 gs", "0 0 1 RG", "[4 2] 0 d", "100 100 m\n300 100 l\nS", "[] 0 d"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("line appearance %q lacks %q", content, want)
+		}
+	}
+	if res := sd.Dict.DictEntry("Resources"); res == nil || res.DictEntry("ExtGState") == nil {
+		t.Errorf("line appearance resources = %v, want the opacity state", res)
+	}
+
+	if ic := entries[1].dict.ArrayEntry("IC"); len(ic) != 3 {
+		t.Errorf("circle IC = %v, want the interior colour", ic)
+	}
+}
+
+func TestAnnotatorShapeErrors(t *testing.T) {
+	a := NewAnnotator()
+	p := Point{X: 10, Y: 10}
+	tests := map[string]error{
+		"zero-length line":   a.AddLine("in.pdf", "", 0, p, p, ShapeStyle{}, ""),
+		"negative width":     a.AddLine("in.pdf", "", 0, p, Point{X: 20, Y: 20}, ShapeStyle{Width: -1}, ""),
+		"opacity":            a.AddSquare("in.pdf", "", 0, Rect{URX: 10, URY: 10}, ShapeStyle{Opacity: 1.5}, ""),
+		"empty dash":         a.AddCircle("in.pdf", "", 0, Rect{URX: 10, URY: 10}, ShapeStyle{Dash: []float64{0, 0}}, ""),
+		"two-point polygon":  a.AddPolygon("in.pdf", "", 0, []Point{p, {X: 20, Y: 20}}, ShapeStyle{}, ""),
+		"one-point polyline": a.AddPolyLine("in.pdf", "", 0, []Point{p}, ShapeStyle{}, ""),
+	}
+	for name, err := range tests {
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLineContentArrowhead(t *testing.T) {
+	style := ShapeStyle{Width: 2, LineEnd: LineEndingOpenArrow}
+	content, bounds := lineContent([]Point{{X: 0, Y: 0}, {X: 100, Y: 0}}, style, false)
+
+	// A 12 point arrowhead at the end, its wings at 30 degrees.
+	size := lineEndingSize(2)
+	if size != 12 {
+		t.Fatalf("lineEndingSize(2) = %v, want 12", size)
+	}
+	want := "89.608 6 m\n100 0 l\n89.608 -6 l\nS\n"
+	if !strings.HasSuffix(content.buf.String(), want) {
+		t.Errorf("content = %q, want it to end with %q", content.buf.String(), want)
+	}
+	// The bounds take in the width of the line and the arrowhead.
+	if want := (Rect{LLX: -2, LLY: -14, URX: 114, URY: 14}); bounds != want {
+		t.Errorf("bounds = %v, want %v", bounds, want)
+	}
+}
@@ -3,6 +3,7 @@ package pdf
 import (
 	"errors"
 	"fmt"
+	"image/color"
 	"strconv"
 	"time"
 
@@ -15,23 +16,36 @@ import (
 var addAnnotationsFile = api.AddAnnotationsFile
 
 // Annotator provides basic PDF annotation operations.
-type Annotator struct{}
+type Annotator struct {
+	userPassword  string
+	ownerPassword string
+}
 
 // NewAnnotator creates a new annotator.
 func NewAnnotator() *Annotator {
 	return &Annotator{}
 }
 
-// AddHighlight adds a highlight annotation to the selected page.
-func (a *Annotator) AddHighlight(inputPath, outputPath string, pageNum int, contents string) error {
+// NewAnnotatorWithPasswords creates an annotator that edits and removes the
+// annotations of password-protected files with the given passwords.
+func NewAnnotatorWithPasswords(userPassword, ownerPassword string) *Annotator {
+	return &Annotator{userPassword: userPassword, ownerPassword: ownerPassword}
+}
+
+// AddHighlight adds a highlight annotation covering rect, in PDF user
+// space, to the selected page.
+func (a *Annotator) AddHighlight(inputPath, outputPath string, pageNum int, rect Rect, contents string) error {
 	if err := validateAnnotationInput(inputPath, pageNum); err != nil {
 		return err
 	}
 
-	rect := types.NewRectangle(100, 600, 500, 640)
-	quad := types.NewQuadLiteralForRect(rect)
+	box, err := annotationRect(inputPath, pageNum, rect)
+	if err != nil {
+		return err
+	}
+	quad := types.NewQuadLiteralForRect(box)
 	ann := model.NewHighlightAnnotation(
-		*rect,
+		*box,
 		contents,
 		nextAnnotationID("hl"),
 		"",
@@ -51,14 +65,19 @@ func (a *Annotator) AddHighlight(inputPath, outputPath string, pageNum int, cont
 	return addAnnotationsFile(inputPath, outputPath, pageSelection(pageNum), ann, nil, false)
 }
 
-// AddText adds a text annotation to the selected page.
-func (a *Annotator) AddText(inputPath, outputPath string, pageNum int, contents string) error {
+// AddText adds a text annotation at rect, in PDF user space, to the
+// selected page.
+func (a *Annotator) AddText(inputPath, outputPath string, pageNum int, rect Rect, contents string) error {
 	if err := validateAnnotationInput(inputPath, pageNum); err != nil {
 		return err
 	}
 
+	box, err := annotationRect(inputPath, pageNum, rect)
+	if err != nil {
+		return err
+	}
 	ann := model.NewTextAnnotation(
-		*types.NewRectangle(80, 620, 320, 760),
+		*box,
 		contents,
 		nextAnnotationID("txt"),
 		"",
@@ -79,38 +98,209 @@ func (a *Annotator) AddText(inputPath, outputPath string, pageNum int, contents
 	return addAnnotationsFile(inputPath, outputPath, pageSelection(pageNum), ann, nil, false)
 }
 
-// AddShape adds a square annotation to the selected page.
-func (a *Annotator) AddShape(inputPath, outputPath string, pageNum int, contents string) error {
+// AddShape adds a square annotation with the bounds rect, in PDF user
+// space, to the selected page, outlined in red and filled light gray.
+func (a *Annotator) AddShape(inputPath, outputPath string, pageNum int, rect Rect, contents string) error {
+	style := ShapeStyle{Interior: color.NRGBA{R: 204, G: 204, B: 204, A: 255}}
+	return a.AddSquare(inputPath, outputPath, pageNum, rect, style, contents)
+}
+
+// MarkupKind is the kind of a text markup annotation.
+type MarkupKind int
+
+const (
+	MarkupHighlight MarkupKind = iota
+	MarkupUnderline
+	MarkupStrikeOut
+	MarkupSquiggly
+)
+
+// String returns the annotation subtype of k.
+func (k MarkupKind) String() string {
+	switch k {
+	case MarkupUnderline:
+		return "Underline"
+	case MarkupStrikeOut:
+		return "StrikeOut"
+	case MarkupSquiggly:
+		return "Squiggly"
+	default:
+		return "Highlight"
+	}
+}
+
+// DefaultColor returns the colour markups of kind k get unless another is
+// chosen.
+func (k MarkupKind) DefaultColor() color.NRGBA {
+	switch k {
+	case MarkupUnderline:
+		return color.NRGBA{R: 0, G: 102, B: 255, A: 255}
+	case MarkupStrikeOut:
+		return color.NRGBA{R: 230, G: 0, B: 0, A: 255}
+	case MarkupSquiggly:
+		return color.NRGBA{R: 0, G: 170, B: 0, A: 255}
+	default:
+		return color.NRGBA{R: 255, G: 230, B: 0, A: 255}
+	}
+}
+
+// MarkupStyle is the appearance of a text markup annotation. A nil Color
+// uses the default colour of the kind and a zero Opacity is opaque.
+type MarkupStyle struct {
+	Color   color.Color
+	Opacity float64
+}
+
+// AddTextMarkup adds a highlight, underline, strikeout or squiggly
+// annotation over text on the selected page. lines are the boxes of the
+// marked text in PDF user space, one per line; each becomes an entry of
+// QuadPoints, oriented the way the text is displayed.
+func (a *Annotator) AddTextMarkup(inputPath, outputPath string, pageNum int, kind MarkupKind, lines []Rect, style MarkupStyle, contents string) error {
 	if err := validateAnnotationInput(inputPath, pageNum); err != nil {
 		return err
 	}
+	if len(lines) == 0 {
+		return errors.New("no text to mark up")
+	}
+	if style.Opacity < 0 || style.Opacity > 1 {
+		return fmt.Errorf("opacity %g is out of range 0..1", style.Opacity)
+	}
 
-	ann := model.NewSquareAnnotation(
-		*types.NewRectangle(180, 440, 430, 650),
-		contents,
-		nextAnnotationID("shape"),
-		"",
-		0,
-		&pdfcolor.Red,
-		"OpenPDF Reader",
-		nil,
-		nil,
-		"",
-		"",
-		&pdfcolor.LightGray,
-		0,
-		0,
-		0,
-		0,
-		1,
-		model.BSSolid,
-		false,
-		0,
-	)
+	geom, err := readPageGeometryFile(inputPath, pageNum)
+	if err != nil {
+		return err
+	}
+	var bounds Rect
+	quads := make(types.QuadPoints, 0, len(lines))
+	for _, line := range lines {
+		line = orderedRect(line.LLX, line.LLY, line.URX, line.URY)
+		bounds = bounds.Union(line)
+		quads = append(quads, markupQuad(geom, line))
+	}
+	box, err := clipAnnotationRect(geom, bounds)
+	if err != nil {
+		return err
+	}
+
+	c := style.Color
+	if c == nil {
+		c = kind.DefaultColor()
+	}
+	col := simpleColor(c)
+	var opacity *float64
+	if style.Opacity > 0 && style.Opacity < 1 {
+		opacity = &style.Opacity
+	}
+
+	id := nextAnnotationID("markup")
+	var ann model.AnnotationRenderer
+	switch kind {
+	case MarkupUnderline:
+		ann = model.NewUnderlineAnnotation(*box, contents, id, "", 0, &col, 0, 0, 1, "OpenPDF Reader", nil, opacity, "", "", quads)
+	case MarkupStrikeOut:
+		ann = model.NewStrikeOutAnnotation(*box, contents, id, "", 0, &col, 0, 0, 1, "OpenPDF Reader", nil, opacity, "", "", quads)
+	case MarkupSquiggly:
+		ann = model.NewSquigglyAnnotation(*box, contents, id, "", 0, &col, 0, 0, 1, "OpenPDF Reader", nil, opacity, "", "", quads)
+	default:
+		ann = model.NewHighlightAnnotation(*box, contents, id, "", 0, &col, 0, 0, 1, "OpenPDF Reader", nil, opacity, "", "", quads)
+	}
+
+	return addAnnotationsFile(inputPath, outputPath, pageSelection(pageNum), ann, nil, false)
+}
+
+// markupQuad returns the quadrilateral of a line of text in user space.
+// The corners are ordered top-left, top-right, bottom-left, bottom-right as
+// the line is displayed, so that underlines and strikeouts follow the text
+// on rotated pages.
+func markupQuad(geom PageGeometry, line Rect) types.QuadLiteral {
+	d := geom.UserToDisplay(line)
+	corner := func(x, y float64) types.Point {
+		ux, uy := geom.displayPointToUser(x, y)
+		return types.Point{X: ux, Y: uy}
+	}
+	return types.QuadLiteral{
+		P1: corner(d.LLX, d.LLY),
+		P2: corner(d.URX, d.LLY),
+		P3: corner(d.LLX, d.URY),
+		P4: corner(d.URX, d.URY),
+	}
+}
+
+// InkStyle is the appearance of an ink annotation. A nil Color draws in
+// black, a zero Width strokes 2 points wide and a zero Opacity is opaque.
+type InkStyle struct {
+	Color   color.Color
+	Width   float64
+	Opacity float64
+}
+
+// defaultInkWidth is the stroke width of ink annotations without one.
+const defaultInkWidth = 2
+
+// AddInk adds a freehand ink annotation to the selected page. Each stroke
+// is a path of points in PDF user space; a stroke of a single point draws
+// a dot.
+func (a *Annotator) AddInk(inputPath, outputPath string, pageNum int, strokes [][]Point, style InkStyle, contents string) error {
+	if err := validateAnnotationInput(inputPath, pageNum); err != nil {
+		return err
+	}
+	if style.Width < 0 {
+		return fmt.Errorf("stroke width %g must not be negative", style.Width)
+	}
+	if style.Opacity < 0 || style.Opacity > 1 {
+		return fmt.Errorf("opacity %g is out of range 0..1", style.Opacity)
+	}
+
+	width := style.Width
+	if width == 0 {
+		width = defaultInkWidth
+	}
+	var bounds Rect
+	ink := make([]model.InkPath, 0, len(strokes))
+	for _, stroke := range strokes {
+		if len(stroke) == 0 {
+			continue
+		}
+		if len(stroke) == 1 {
+			stroke = []Point{stroke[0], stroke[0]}
+		}
+		path := make(model.InkPath, 0, 2*len(stroke))
+		for _, p := range stroke {
+			path = append(path, p.X, p.Y)
+			// Leave room for the line width around the points.
+			bounds = bounds.Union(Rect{LLX: p.X - width/2, LLY: p.Y - width/2, URX: p.X + width/2, URY: p.Y + width/2})
+		}
+		ink = append(ink, path)
+	}
+	if len(ink) == 0 {
+		return errors.New("no strokes to draw")
+	}
+	box, err := annotationRect(inputPath, pageNum, bounds)
+	if err != nil {
+		return err
+	}
+
+	c := style.Color
+	if c == nil {
+		c = color.Black
+	}
+	col := simpleColor(c)
+	var opacity *float64
+	if style.Opacity > 0 && style.Opacity < 1 {
+		opacity = &style.Opacity
+	}
+	ann := model.NewInkAnnotation(*box, contents, nextAnnotationID("ink"), "", 0, &col,
+		"OpenPDF Reader", nil, opacity, "", "", ink, width, model.BSSolid)
 
 	return addAnnotationsFile(inputPath, outputPath, pageSelection(pageNum), ann, nil, false)
 }
 
+// simpleColor converts c to the colour type of pdfcpu, ignoring alpha.
+func simpleColor(c color.Color) pdfcolor.SimpleColor {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return pdfcolor.SimpleColor{R: float32(n.R) / 255, G: float32(n.G) / 255, B: float32(n.B) / 255}
+}
+
 func validateAnnotationInput(inputPath string, pageNum int) error {
 	if inputPath == "" {
 		return errors.New("input path is required")
@@ -121,6 +311,25 @@ func validateAnnotationInput(inputPath string, pageNum int) error {
 	return nil
 }
 
+// annotationRect normalizes rect, which may have its corners in any order,
+// and clips it to the MediaBox of the page read from inputPath.
+func annotationRect(inputPath string, pageNum int, rect Rect) (*types.Rectangle, error) {
+	geom, err := readPageGeometryFile(inputPath, pageNum)
+	if err != nil {
+		return nil, err
+	}
+	return clipAnnotationRect(geom, rect)
+}
+
+// clipAnnotationRect normalizes rect and clips it to the MediaBox of geom.
+func clipAnnotationRect(geom PageGeometry, rect Rect) (*types.Rectangle, error) {
+	r := orderedRect(rect.LLX, rect.LLY, rect.URX, rect.URY).Intersect(geom.MediaBox)
+	if r.Empty() {
+		return nil, errors.New("annotation rectangle is empty or outside the page")
+	}
+	return r.rectangle(), nil
+}
+
 func pageSelection(pageNum int) []string {
 	return []string{strconv.Itoa(pageNum + 1)}
 }
@@ -2,10 +2,13 @@ package pdf
 
 import (
 	"errors"
+	"fmt"
+	"image/color"
 	"strings"
 	"testing"
 
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
 )
 
 func TestPageSelection(t *testing.T) {
@@ -24,7 +27,21 @@ func TestValidateAnnotationInput(t *testing.T) {
 	}
 }
 
+// stubLetterGeometry makes annotations read a Letter page without opening
+// the input file.
+func stubLetterGeometry(t *testing.T) {
+	t.Helper()
+	original := readPageGeometryFile
+	t.Cleanup(func() {
+		readPageGeometryFile = original
+	})
+	readPageGeometryFile = func(path string, pageNum int) (PageGeometry, error) {
+		return defaultPageGeometry(), nil
+	}
+}
+
 func TestAnnotatorMethodsCallAPI(t *testing.T) {
+	stubLetterGeometry(t)
 	original := addAnnotationsFile
 	defer func() {
 		addAnnotationsFile = original
@@ -55,13 +72,14 @@ func TestAnnotatorMethodsCallAPI(t *testing.T) {
 	}
 
 	a := NewAnnotator()
-	if err := a.AddHighlight("in.pdf", "out.pdf", 0, "hl"); err != nil {
+	rect := Rect{LLX: 100, LLY: 600, URX: 500, URY: 640}
+	if err := a.AddHighlight("in.pdf", "out.pdf", 0, rect, "hl"); err != nil {
 		t.Fatalf("AddHighlight() returned error: %v", err)
 	}
-	if err := a.AddText("in.pdf", "out.pdf", 0, "note"); err != nil {
+	if err := a.AddText("in.pdf", "out.pdf", 0, rect, "note"); err != nil {
 		t.Fatalf("AddText() returned error: %v", err)
 	}
-	if err := a.AddShape("in.pdf", "out.pdf", 0, "shape"); err != nil {
+	if err := a.AddShape("in.pdf", "out.pdf", 0, rect, "shape"); err != nil {
 		t.Fatalf("AddShape() returned error: %v", err)
 	}
 
@@ -71,6 +89,7 @@ func TestAnnotatorMethodsCallAPI(t *testing.T) {
 }
 
 func TestAnnotatorPropagatesAPIError(t *testing.T) {
+	stubLetterGeometry(t)
 	original := addAnnotationsFile
 	defer func() {
 		addAnnotationsFile = original
@@ -81,7 +100,7 @@ func TestAnnotatorPropagatesAPIError(t *testing.T) {
 	}
 
 	a := NewAnnotator()
-	err := a.AddText("in.pdf", "out.pdf", 0, "note")
+	err := a.AddText("in.pdf", "out.pdf", 0, Rect{LLX: 80, LLY: 620, URX: 320, URY: 760}, "note")
 	if err == nil {
 		t.Fatal("AddText() expected error")
 	}
@@ -89,3 +108,221 @@ func TestAnnotatorPropagatesAPIError(t *testing.T) {
 		t.Fatalf("error = %q, want api failed", err.Error())
 	}
 }
+
+func TestAnnotatorPropagatesGeometryError(t *testing.T) {
+	originalAdd := addAnnotationsFile
+	originalGeometry := readPageGeometryFile
+	defer func() {
+		addAnnotationsFile = originalAdd
+		readPageGeometryFile = originalGeometry
+	}()
+
+	reads := 0
+	readPageGeometryFile = func(path string, pageNum int) (PageGeometry, error) {
+		reads++
+		return PageGeometry{}, errors.New("page 9 not found")
+	}
+	addAnnotationsFile = func(inFile, outFile string, selectedPages []string, ar model.AnnotationRenderer, conf *model.Configuration, incr bool) error {
+		t.Fatal("annotation added to an unreadable page")
+		return nil
+	}
+
+	a := NewAnnotator()
+	lines := []Rect{{LLX: 72, LLY: 700, URX: 300, URY: 712}, {LLX: 72, LLY: 686, URX: 180, URY: 698}}
+	errs := []error{
+		a.AddText("in.pdf", "out.pdf", 8, Rect{LLX: 80, LLY: 620, URX: 320, URY: 760}, "note"),
+		a.AddTextMarkup("in.pdf", "out.pdf", 8, MarkupHighlight, lines, MarkupStyle{}, ""),
+	}
+	for _, err := range errs {
+		if err == nil || !strings.Contains(err.Error(), "page 9 not found") {
+			t.Errorf("error = %v, want the geometry error", err)
+		}
+	}
+	// The page is read once per annotation, however many lines it marks.
+	if reads != 2 {
+		t.Errorf("geometry reads = %d, want 2", reads)
+	}
+}
+
+func TestAnnotatorPlacesGivenRect(t *testing.T) {
+	originalAdd := addAnnotationsFile
+	originalGeometry := readPageGeometryFile
+	defer func() {
+		addAnnotationsFile = originalAdd
+		readPageGeometryFile = originalGeometry
+	}()
+
+	readPageGeometryFile = func(path string, pageNum int) (PageGeometry, error) {
+		g := defaultPageGeometry()
+		g.MediaBox = Rect{URX: 1224, URY: 1584}
+		g.CropBox = Rect{LLX: 612, LLY: 792, URX: 1224, URY: 1584}
+		return g, nil
+	}
+
+	var rect types.Rectangle
+	addAnnotationsFile = func(inFile, outFile string, selectedPages []string, ar model.AnnotationRenderer, conf *model.Configuration, incr bool) error {
+		if app, ok := ar.(appearanceAnnotation); ok {
+			ar = app.AnnotationRenderer
+		}
+		sq, ok := ar.(model.SquareAnnotation)
+		if !ok {
+			t.Fatalf("annotation type = %T, want model.SquareAnnotation", ar)
+		}
+		rect = sq.Rect
+		return nil
+	}
+
+	tests := []struct {
+		in   Rect
+		want Rect
+	}{
+		{Rect{LLX: 700, LLY: 900, URX: 800, URY: 1000}, Rect{LLX: 700, LLY: 900, URX: 800, URY: 1000}},
+		// Corners in any order are normalized.
+		{Rect{LLX: 800, LLY: 1000, URX: 700, URY: 900}, Rect{LLX: 700, LLY: 900, URX: 800, URY: 1000}},
+		// Boxes reaching off the page are clipped to the MediaBox.
+		{Rect{LLX: 1200, LLY: -10, URX: 1300, URY: 20}, Rect{LLX: 1200, LLY: 0, URX: 1224, URY: 20}},
+	}
+	for _, tt := range tests {
+		if err := NewAnnotator().AddShape("in.pdf", "out.pdf", 0, tt.in, "shape"); err != nil {
+			t.Fatalf("AddShape(%v) returned error: %v", tt.in, err)
+		}
+		got := Rect{LLX: rect.LL.X, LLY: rect.LL.Y, URX: rect.UR.X, URY: rect.UR.Y}
+		if got != tt.want {
+			t.Errorf("AddShape(%v) placed %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, r := range []Rect{{}, {LLX: 2000, LLY: 2000, URX: 2100, URY: 2100}} {
+		if err := NewAnnotator().AddShape("in.pdf", "out.pdf", 0, r, "shape"); err == nil {
+			t.Errorf("AddShape(%v) expected error", r)
+		}
+	}
+}
+
+func TestAnnotatorAddTextMarkup(t *testing.T) {
+	stubLetterGeometry(t)
+	originalAdd := addAnnotationsFile
+	defer func() {
+		addAnnotationsFile = originalAdd
+	}()
+
+	var got model.AnnotationRenderer
+	addAnnotationsFile = func(inFile, outFile string, selectedPages []string, ar model.AnnotationRenderer, conf *model.Configuration, incr bool) error {
+		got = ar
+		return nil
+	}
+
+	lines := []Rect{
+		{LLX: 72, LLY: 700, URX: 300, URY: 712},
+		{LLX: 72, LLY: 686, URX: 180, URY: 698},
+	}
+	style := MarkupStyle{Color: color.NRGBA{R: 255, A: 255}, Opacity: 0.5}
+	if err := NewAnnotator().AddTextMarkup("in.pdf", "out.pdf", 0, MarkupUnderline, lines, style, "typo"); err != nil {
+		t.Fatalf("AddTextMarkup() returned error: %v", err)
+	}
+	ul, ok := got.(model.UnderlineAnnotation)
+	if !ok {
+		t.Fatalf("annotation type = %T, want model.UnderlineAnnotation", got)
+	}
+	if len(ul.Quad) != 2 {
+		t.Fatalf("QuadPoints has %d quads, want one per line", len(ul.Quad))
+	}
+	want := types.QuadLiteral{
+		P1: types.Point{X: 72, Y: 712}, P2: types.Point{X: 300, Y: 712},
+		P3: types.Point{X: 72, Y: 700}, P4: types.Point{X: 300, Y: 700},
+	}
+	if ul.Quad[0] != want {
+		t.Errorf("Quad[0] = %v, want %v", ul.Quad[0], want)
+	}
+	if r := ul.Rect; r.LL.X != 72 || r.LL.Y != 686 || r.UR.X != 300 || r.UR.Y != 712 {
+		t.Errorf("Rect = %v, want the union of the lines", r)
+	}
+	if ul.C == nil || ul.C.R != 1 || ul.C.G != 0 || ul.CA == nil || *ul.CA != 0.5 {
+		t.Errorf("colour = %v, opacity = %v", ul.C, ul.CA)
+	}
+
+	// Without a colour, the kind's default is used and the markup is opaque.
+	if err := NewAnnotator().AddTextMarkup("in.pdf", "out.pdf", 0, MarkupHighlight, lines[:1], MarkupStyle{}, ""); err != nil {
+		t.Fatalf("AddTextMarkup() returned error: %v", err)
+	}
+	hl := got.(model.HighlightAnnotation)
+	if hl.C == nil || *hl.C != simpleColor(MarkupHighlight.DefaultColor()) || hl.CA != nil {
+		t.Errorf("default colour = %v, opacity = %v", hl.C, hl.CA)
+	}
+
+	if err := NewAnnotator().AddTextMarkup("in.pdf", "out.pdf", 0, MarkupSquiggly, nil, MarkupStyle{}, ""); err == nil {
+		t.Error("AddTextMarkup() expected error without lines")
+	}
+	if err := NewAnnotator().AddTextMarkup("in.pdf", "out.pdf", 0, MarkupStrikeOut, lines, MarkupStyle{Opacity: 2}, ""); err == nil {
+		t.Error("AddTextMarkup() expected error for opacity 2")
+	}
+}
+
+func TestMarkupQuadFollowsRotation(t *testing.T) {
+	g := defaultPageGeometry()
+	g.Rotate = 90
+	// On a page turned clockwise, displayed text runs up user space y and
+	// its top faces lower x.
+	got := markupQuad(g, Rect{LLX: 100, LLY: 200, URX: 112, URY: 400})
+	want := types.QuadLiteral{
+		P1: types.Point{X: 100, Y: 200}, P2: types.Point{X: 100, Y: 400},
+		P3: types.Point{X: 112, Y: 200}, P4: types.Point{X: 112, Y: 400},
+	}
+	if got != want {
+		t.Errorf("markupQuad() = %v, want %v", got, want)
+	}
+}
+
+func TestAnnotatorAddInk(t *testing.T) {
+	stubLetterGeometry(t)
+	originalAdd := addAnnotationsFile
+	defer func() {
+		addAnnotationsFile = originalAdd
+	}()
+
+	var got model.AnnotationRenderer
+	addAnnotationsFile = func(inFile, outFile string, selectedPages []string, ar model.AnnotationRenderer, conf *model.Configuration, incr bool) error {
+		got = ar
+		return nil
+	}
+
+	strokes := [][]Point{
+		{{X: 100, Y: 500}, {X: 120, Y: 520}, {X: 140, Y: 505}},
+		{},
+		{{X: 200, Y: 450}},
+	}
+	style := InkStyle{Color: color.NRGBA{B: 255, A: 255}, Width: 4, Opacity: 0.8}
+	if err := NewAnnotator().AddInk("in.pdf", "out.pdf", 0, strokes, style, "scribble"); err != nil {
+		t.Fatalf("AddInk() returned error: %v", err)
+	}
+	ink, ok := got.(model.InkAnnotation)
+	if !ok {
+		t.Fatalf("annotation type = %T, want model.InkAnnotation", got)
+	}
+	want := []model.InkPath{{100, 500, 120, 520, 140, 505}, {200, 450, 200, 450}}
+	if fmt.Sprint(ink.InkList) != fmt.Sprint(want) {
+		t.Errorf("InkList = %v, want %v", ink.InkList, want)
+	}
+	// The rectangle takes in the width of the strokes.
+	if r := ink.Rect; r.LL.X != 98 || r.LL.Y != 448 || r.UR.X != 202 || r.UR.Y != 522 {
+		t.Errorf("Rect = %v", r)
+	}
+	if ink.BorderWidth != 4 || ink.C == nil || ink.C.B != 1 || ink.CA == nil || *ink.CA != 0.8 {
+		t.Errorf("width = %v, colour = %v, opacity = %v", ink.BorderWidth, ink.C, ink.CA)
+	}
+
+	if err := NewAnnotator().AddInk("in.pdf", "out.pdf", 0, strokes[:1], InkStyle{}, ""); err != nil {
+		t.Fatalf("AddInk() returned error: %v", err)
+	}
+	ink = got.(model.InkAnnotation)
+	if ink.BorderWidth != defaultInkWidth || ink.C == nil || *ink.C != simpleColor(color.Black) || ink.CA != nil {
+		t.Errorf("default width = %v, colour = %v, opacity = %v", ink.BorderWidth, ink.C, ink.CA)
+	}
+
+	if err := NewAnnotator().AddInk("in.pdf", "out.pdf", 0, [][]Point{{}}, InkStyle{}, ""); err == nil {
+		t.Error("AddInk() expected error without points")
+	}
+	if err := NewAnnotator().AddInk("in.pdf", "out.pdf", 0, strokes, InkStyle{Width: -1}, ""); err == nil {
+		t.Error("AddInk() expected error for a negative width")
+	}
+}
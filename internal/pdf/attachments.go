@@ -0,0 +1,258 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// Attachment is a file embedded in the document, such as the invoice XML of
+// a Factur-X document or a member of a portfolio.
+type Attachment struct {
+	// Name is the key of the attachment in the EmbeddedFiles name tree.
+	Name        string    `json:"name"`
+	FileName    string    `json:"file_name"`
+	Description string    `json:"description,omitempty"`
+	Size        int64     `json:"size"`
+	Modified    time.Time `json:"modified,omitzero"`
+}
+
+// BaseName returns a file name to store the attachment under: the base of
+// its recorded file name, which may contain a path, or of its name.
+func (a Attachment) BaseName() string {
+	name := a.FileName
+	if name == "" {
+		name = a.Name
+	}
+	name = filepath.Base(filepath.FromSlash(strings.ReplaceAll(name, `\`, "/")))
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		return "attachment"
+	}
+	return name
+}
+
+// Attachments returns the files embedded in the document. Size is -1 when
+// the document does not record it.
+func (d *Document) Attachments() ([]Attachment, error) {
+	if d.context() == nil {
+		return nil, errors.New("no document loaded")
+	}
+
+	d.renderMu.Lock()
+	defer d.renderMu.Unlock()
+	list, err := d.ctx.ListAttachments()
+	if err != nil {
+		return nil, err
+	}
+	attachments := make([]Attachment, 0, len(list))
+	for _, a := range list {
+		attachment := Attachment{
+			Name:        a.ID,
+			FileName:    a.FileName,
+			Description: a.Desc,
+			Size:        attachmentSize(d.ctx, a.ID),
+		}
+		if a.ModTime != nil {
+			attachment.Modified = *a.ModTime
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+// AttachmentData returns the content of the attachment name.
+func (d *Document) AttachmentData(name string) ([]byte, error) {
+	if d.context() == nil {
+		return nil, errors.New("no document loaded")
+	}
+
+	d.renderMu.Lock()
+	defer d.renderMu.Unlock()
+	if !hasAttachment(d.ctx, name) {
+		return nil, fmt.Errorf("attachment %q not found", name)
+	}
+	list, err := d.ctx.ExtractAttachments([]string{name})
+	if err != nil {
+		return nil, err
+	}
+	if len(list) != 1 {
+		return nil, fmt.Errorf("attachment %q not found", name)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(list[0].Reader); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ExtractAttachments writes the attachments named, or all of them when
+// names is empty, into outputDir and returns the paths written, in order.
+// Each file is stored under its BaseName; when two attachments share one,
+// as a/invoice.xml and b/invoice.xml do, the later ones get a numbered
+// suffix such as invoice-2.xml rather than overwrite the first.
+func (d *Document) ExtractAttachments(names []string, outputDir string) ([]string, error) {
+	if outputDir == "" {
+		return nil, errors.New("output directory is required")
+	}
+	all, err := d.Attachments()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]Attachment, len(all))
+	for _, a := range all {
+		byName[a.Name] = a
+	}
+	if len(names) == 0 {
+		for _, a := range all {
+			names = append(names, a.Name)
+		}
+	}
+	for _, name := range names {
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("attachment %q not found", name)
+		}
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, err
+	}
+
+	taken := make(map[string]bool, len(names))
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		data, err := d.AttachmentData(name)
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(outputDir, uniqueFileName(byName[name].BaseName(), taken))
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// uniqueFileName returns name, or name with a numbered suffix before its
+// extension if it is taken, and marks the result taken. Names that differ
+// only in case collide, as they do on case-insensitive file systems.
+func uniqueFileName(name string, taken map[string]bool) string {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if stem == "" {
+		stem, ext = name, ""
+	}
+	for n := 2; taken[strings.ToLower(name)]; n++ {
+		name = fmt.Sprintf("%s-%d%s", stem, n, ext)
+	}
+	taken[strings.ToLower(name)] = true
+	return name
+}
+
+// AddAttachment embeds data under a.Name, which is also used as the file
+// name. A zero a.Modified is replaced by the current time. The change is
+// kept in memory until the document is saved.
+func (d *Document) AddAttachment(a Attachment, data []byte) error {
+	if d.context() == nil {
+		return errors.New("no document loaded")
+	}
+	if a.Name == "" {
+		return errors.New("attachment name must not be empty")
+	}
+
+	d.renderMu.Lock()
+	defer d.renderMu.Unlock()
+	if hasAttachment(d.ctx, a.Name) {
+		return fmt.Errorf("attachment %q already exists", a.Name)
+	}
+	modified := a.Modified
+	if modified.IsZero() {
+		modified = time.Now()
+	}
+	err := d.ctx.AddAttachment(model.Attachment{
+		Reader:  bytes.NewReader(data),
+		ID:      a.Name,
+		Desc:    a.Description,
+		ModTime: &modified,
+	}, false)
+	if err != nil {
+		return err
+	}
+	d.setModified(true)
+	return nil
+}
+
+// RemoveAttachment deletes the attachment name. The change is kept in
+// memory until the document is saved.
+func (d *Document) RemoveAttachment(name string) error {
+	if d.context() == nil {
+		return errors.New("no document loaded")
+	}
+
+	d.renderMu.Lock()
+	defer d.renderMu.Unlock()
+	if !hasAttachment(d.ctx, name) {
+		return fmt.Errorf("attachment %q not found", name)
+	}
+	if _, err := d.ctx.RemoveAttachments([]string{name}); err != nil {
+		return err
+	}
+	d.setModified(true)
+	return nil
+}
+
+// hasAttachment reports whether the EmbeddedFiles name tree has key name.
+// pdfcpu also matches file names and descriptions, which would make the
+// API act on the wrong attachment.
+func hasAttachment(ctx *model.Context, name string) bool {
+	if ctx.Names["EmbeddedFiles"] == nil {
+		if err := ctx.LocateNameTree("EmbeddedFiles", false); err != nil {
+			return false
+		}
+	}
+	tree := ctx.Names["EmbeddedFiles"]
+	if tree == nil {
+		return false
+	}
+	_, ok := tree.Value(name)
+	return ok
+}
+
+// attachmentSize reads the size recorded in the parameters of an embedded
+// file stream, or -1.
+func attachmentSize(ctx *model.Context, name string) int64 {
+	tree := ctx.Names["EmbeddedFiles"]
+	if tree == nil {
+		return -1
+	}
+	obj, ok := tree.Value(name)
+	if !ok {
+		return -1
+	}
+	fileSpec, err := ctx.DereferenceDict(obj)
+	if err != nil || fileSpec == nil {
+		return -1
+	}
+	ef, err := ctx.DereferenceDict(fileSpec["EF"])
+	if err != nil || ef == nil {
+		return -1
+	}
+	sd, _, err := ctx.DereferenceStreamDict(ef["F"])
+	if err != nil || sd == nil {
+		return -1
+	}
+	params, err := ctx.DereferenceDict(sd.Dict["Params"])
+	if err != nil || params == nil {
+		return -1
+	}
+	size, err := ctx.DereferenceInteger(params["Size"])
+	if err != nil || size == nil {
+		return -1
+	}
+	return int64(*size)
+}
@@ -0,0 +1,152 @@
+package pdf
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDocumentAttachments(t *testing.T) {
+	path := writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+	)
+	doc, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+
+	if list, err := doc.Attachments(); err != nil || len(list) != 0 {
+		t.Fatalf("Attachments() = %v, %v, want none", list, err)
+	}
+
+	modified := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	invoice := []byte("<Invoice>42</Invoice>")
+	if err := doc.AddAttachment(Attachment{Name: "factur-x.xml", Description: "Invoice data", Modified: modified}, invoice); err != nil {
+		t.Fatalf("AddAttachment() returned error: %v", err)
+	}
+	if err := doc.AddAttachment(Attachment{Name: "notes.txt"}, []byte("notes")); err != nil {
+		t.Fatalf("AddAttachment() returned error: %v", err)
+	}
+	if err := doc.AddAttachment(Attachment{Name: "notes.txt"}, nil); err == nil {
+		t.Error("AddAttachment() accepted a duplicate name")
+	}
+	if !doc.IsModified() {
+		t.Error("AddAttachment() did not mark the document modified")
+	}
+	if err := doc.RemoveAttachment("notes.txt"); err != nil {
+		t.Fatalf("RemoveAttachment() returned error: %v", err)
+	}
+	// Descriptions are not names.
+	if err := doc.RemoveAttachment("Invoice data"); err == nil {
+		t.Error("RemoveAttachment() removed an attachment by description")
+	}
+
+	out := filepath.Join(t.TempDir(), "attachments.pdf")
+	if err := doc.SaveAs(out); err != nil {
+		t.Fatalf("SaveAs() failed: %v", err)
+	}
+	saved, err := Open(out)
+	if err != nil {
+		t.Fatalf("Open(saved) failed: %v", err)
+	}
+	defer saved.Close()
+
+	list, err := saved.Attachments()
+	if err != nil {
+		t.Fatalf("Attachments() of saved returned error: %v", err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("Attachments() of saved = %+v, want 1", list)
+	}
+	got := list[0]
+	if got.Name != "factur-x.xml" || got.FileName != "factur-x.xml" || got.Description != "Invoice data" ||
+		got.Size != int64(len(invoice)) || !got.Modified.Equal(modified) {
+		t.Errorf("Attachments()[0] = %+v", got)
+	}
+	data, err := saved.AttachmentData("factur-x.xml")
+	if err != nil {
+		t.Fatalf("AttachmentData() returned error: %v", err)
+	}
+	if string(data) != string(invoice) {
+		t.Errorf("AttachmentData() = %q, want %q", data, invoice)
+	}
+	if _, err := saved.AttachmentData("notes.txt"); err == nil {
+		t.Error("AttachmentData() found a removed attachment")
+	}
+}
+
+func TestDocumentExtractAttachments(t *testing.T) {
+	doc, err := Open(writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+	))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+	for _, a := range []struct{ name, data string }{
+		{"a/invoice.xml", "first"},
+		{"b/invoice.xml", "second"},
+		{"INVOICE.xml", "third"},
+		{"notes", "notes"},
+	} {
+		if err := doc.AddAttachment(Attachment{Name: a.name}, []byte(a.data)); err != nil {
+			t.Fatalf("AddAttachment(%s) returned error: %v", a.name, err)
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "out")
+	if _, err := doc.ExtractAttachments([]string{"notes", "missing"}, dir); err == nil {
+		t.Error("ExtractAttachments() expected error for an unknown name")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("ExtractAttachments() wrote files before failing: %v", err)
+	}
+
+	paths, err := doc.ExtractAttachments([]string{"a/invoice.xml", "b/invoice.xml", "INVOICE.xml"}, dir)
+	if err != nil {
+		t.Fatalf("ExtractAttachments() returned error: %v", err)
+	}
+	// Attachments sharing a file name are not overwritten.
+	want := map[string]string{"invoice.xml": "first", "invoice-2.xml": "second", "INVOICE-3.xml": "third"}
+	var got []string
+	for _, path := range paths {
+		got = append(got, filepath.Base(path))
+		if data, err := os.ReadFile(path); err != nil || string(data) != want[filepath.Base(path)] {
+			t.Errorf("%s = %q, %v, want %q", path, data, err, want[filepath.Base(path)])
+		}
+	}
+	if wantNames := []string{"invoice.xml", "invoice-2.xml", "INVOICE-3.xml"}; !reflect.DeepEqual(got, wantNames) {
+		t.Errorf("ExtractAttachments() paths = %v, want %v", got, wantNames)
+	}
+
+	// Without names, every attachment is extracted.
+	if paths, err := doc.ExtractAttachments(nil, dir); err != nil || len(paths) != 4 {
+		t.Errorf("ExtractAttachments(all) = %v, %v, want 4 paths", paths, err)
+	}
+}
+
+func TestAttachmentBaseName(t *testing.T) {
+	tests := []struct {
+		a    Attachment
+		want string
+	}{
+		{Attachment{Name: "id", FileName: "factur-x.xml"}, "factur-x.xml"},
+		{Attachment{Name: "report.csv"}, "report.csv"},
+		{Attachment{FileName: "../../etc/passwd"}, "passwd"},
+		{Attachment{FileName: `C:\Users\me\notes.txt`}, "notes.txt"},
+		{Attachment{FileName: ".."}, "attachment"},
+		{Attachment{}, "attachment"},
+	}
+	for _, tt := range tests {
+		if got := tt.a.BaseName(); got != tt.want {
+			t.Errorf("BaseName(%+v) = %q, want %q", tt.a, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// BackendAuto selects the first available registered backend.
+const BackendAuto = "auto"
+
+// ErrTextUnsupported is returned by backends that cannot extract text.
+var ErrTextUnsupported = errors.New("text extraction is not supported by this backend")
+
+// RenderSource identifies the document a backend operates on.
+type RenderSource struct {
+	Path          string
+	UserPassword  string
+	OwnerPassword string
+
+	// Context is the already loaded document, if any. Backends that work
+	// in-process use it instead of re-reading Path.
+	Context *model.Context
+}
+
+// Capabilities describes what a rendering backend supports.
+type Capabilities struct {
+	Render    bool // rasterize pages
+	PageCount bool // report the number of pages
+	Text      bool // extract page text
+	Passwords bool // open encrypted documents
+	External  bool // depends on an external executable
+}
+
+// String returns a compact, human readable list of capabilities.
+func (c Capabilities) String() string {
+	var parts []string
+	if c.Render {
+		parts = append(parts, "render")
+	}
+	if c.PageCount {
+		parts = append(parts, "page-count")
+	}
+	if c.Text {
+		parts = append(parts, "text")
+	}
+	if c.Passwords {
+		parts = append(parts, "passwords")
+	}
+	if c.External {
+		parts = append(parts, "external")
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// RenderBackend rasterizes pages and reads basic document information.
+// pageNum is 0-indexed in all methods.
+type RenderBackend interface {
+	// Name returns the registry name of the backend.
+	Name() string
+	// Available reports whether the backend can be used on this system.
+	Available() bool
+	// Capabilities reports the features the backend implements.
+	Capabilities() Capabilities
+	// RenderPage renders a page at the given resolution.
+	RenderPage(src RenderSource, pageNum int, dpi int) (image.Image, error)
+	// PageCount returns the number of pages in the document.
+	PageCount(src RenderSource) (int, error)
+	// ExtractText returns the text of a page.
+	ExtractText(src RenderSource, pageNum int) (string, error)
+}
+
+// PageImageFunc receives rendered pages in page order. Returning an error
+// stops the batch.
+type PageImageFunc func(pageNum int, img image.Image) error
+
+// BatchRenderBackend is implemented by backends that can render a range of
+// pages in one pass, avoiding a process launch and file parse per page.
+type BatchRenderBackend interface {
+	RenderBackend
+	// RenderPages renders pages first..last (inclusive, 0-indexed) and calls
+	// fn for each page as soon as it is available.
+	RenderPages(src RenderSource, first, last int, dpi int, fn PageImageFunc) error
+}
+
+var (
+	backendsMu sync.RWMutex
+	backends   = []RenderBackend{
+		newPopplerBackend(exec.LookPath),
+		newMutoolBackend(exec.LookPath),
+		newNativeBackend(),
+	}
+)
+
+// RegisterBackend adds a backend to the registry, replacing any backend
+// registered under the same name. Newly registered backends take part in
+// automatic selection after the built-in ones.
+func RegisterBackend(b RenderBackend) {
+	if b == nil {
+		return
+	}
+
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	for i, existing := range backends {
+		if existing.Name() == b.Name() {
+			backends[i] = b
+			return
+		}
+	}
+	backends = append(backends, b)
+}
+
+// RegisteredBackends returns all registered backends in selection order.
+func RegisteredBackends() []RenderBackend {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	out := make([]RenderBackend, len(backends))
+	copy(out, backends)
+	return out
+}
+
+// BackendNames returns the sorted names of all registered backends.
+func BackendNames() []string {
+	registered := RegisteredBackends()
+	names := make([]string, 0, len(registered))
+	for _, b := range registered {
+		names = append(names, b.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupBackend selects a backend by name. An empty name or "auto" picks
+// the first available backend in registration order.
+func LookupBackend(name string) (RenderBackend, error) {
+	return selectBackend(RegisteredBackends(), name)
+}
+
+func selectBackend(candidates []RenderBackend, name string) (RenderBackend, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == BackendAuto {
+		for _, b := range candidates {
+			if b.Available() {
+				return b, nil
+			}
+		}
+		return nil, errors.New("no rendering backend available")
+	}
+
+	for _, b := range candidates {
+		if b.Name() != name {
+			continue
+		}
+		if !b.Available() {
+			return nil, fmt.Errorf("rendering backend %q is not available on this system", name)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("unknown rendering backend: %s", name)
+}
+
+// nativeBackend renders pages in-process from the pdfcpu document model.
+type nativeBackend struct{}
+
+func newNativeBackend() *nativeBackend {
+	return &nativeBackend{}
+}
+
+func (b *nativeBackend) Name() string {
+	return BackendNative
+}
+
+func (b *nativeBackend) Available() bool {
+	return true
+}
+
+func (b *nativeBackend) Capabilities() Capabilities {
+	return Capabilities{
+		Render:    true,
+		Text:      true,
+		PageCount: true,
+		Passwords: true,
+	}
+}
+
+func (b *nativeBackend) RenderPage(src RenderSource, pageNum int, dpi int) (image.Image, error) {
+	ctx, err := src.context()
+	if err != nil {
+		return nil, err
+	}
+	return renderPageNative(ctx, pageNum, dpi)
+}
+
+// RenderPages loads the document once and renders the range in-process.
+func (b *nativeBackend) RenderPages(src RenderSource, first, last int, dpi int, fn PageImageFunc) error {
+	ctx, err := src.context()
+	if err != nil {
+		return err
+	}
+	src.Context = ctx
+	return renderPagesOneByOne(b, src, first, last, dpi, fn)
+}
+
+func (b *nativeBackend) PageCount(src RenderSource) (int, error) {
+	ctx, err := src.context()
+	if err != nil {
+		return 0, err
+	}
+	return ctx.PageCount, nil
+}
+
+// ExtractText returns the page text found by the built-in content stream
+// interpreter, in reading order.
+func (b *nativeBackend) ExtractText(src RenderSource, pageNum int) (string, error) {
+	ctx, err := src.context()
+	if err != nil {
+		return "", err
+	}
+	text, err := extractPageTextNative(ctx, pageNum)
+	if err != nil {
+		return "", err
+	}
+	return text.String(), nil
+}
+
+// context returns the loaded document or reads it from Path.
+func (s RenderSource) context() (*model.Context, error) {
+	if s.Context != nil {
+		return s.Context, nil
+	}
+	if s.Path == "" {
+		return nil, errors.New("no document loaded")
+	}
+	if s.UserPassword == "" && s.OwnerPassword == "" {
+		return api.ReadContextFile(s.Path)
+	}
+
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	conf := model.NewDefaultConfiguration()
+	conf.UserPW = s.UserPassword
+	conf.OwnerPW = s.OwnerPassword
+	return api.ReadContext(f, conf)
+}
@@ -0,0 +1,150 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// mutoolBackend shells out to the MuPDF command line tool.
+type mutoolBackend struct {
+	lookPath func(string) (string, error)
+}
+
+func newMutoolBackend(lookPath func(string) (string, error)) *mutoolBackend {
+	return &mutoolBackend{lookPath: lookPath}
+}
+
+func (b *mutoolBackend) Name() string {
+	return BackendMutool
+}
+
+func (b *mutoolBackend) Available() bool {
+	_, err := b.lookPath("mutool")
+	return err == nil
+}
+
+func (b *mutoolBackend) Capabilities() Capabilities {
+	available := b.Available()
+	return Capabilities{
+		Render:    available,
+		PageCount: available,
+		Text:      available,
+		Passwords: true,
+		External:  true,
+	}
+}
+
+func (b *mutoolBackend) RenderPage(src RenderSource, pageNum int, dpi int) (image.Image, error) {
+	tmpDir, err := os.MkdirTemp("", "openpdfreader-mutool-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	outPath := filepath.Join(tmpDir, "page.png")
+	args := mutoolDrawArgs(src, pageNum, outPath, "png", dpi)
+	if _, err := runMutool(args); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(outPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode PNG: %v", err)
+	}
+	return img, nil
+}
+
+func (b *mutoolBackend) PageCount(src RenderSource) (int, error) {
+	args := []string{"info"}
+	if pw := mutoolPassword(src); pw != "" {
+		args = append(args, "-p", pw)
+	}
+	args = append(args, src.Path)
+
+	output, err := runMutool(args)
+	if err != nil {
+		return 0, err
+	}
+	return parsePagesLine(output)
+}
+
+func (b *mutoolBackend) ExtractText(src RenderSource, pageNum int) (string, error) {
+	output, err := runMutool(mutoolDrawArgs(src, pageNum, "-", "txt", 0))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// mutoolDrawArgs builds "mutool draw" arguments for a single page.
+// dpi is ignored when it is zero (text output).
+func mutoolDrawArgs(src RenderSource, pageNum int, output, format string, dpi int) []string {
+	args := []string{"draw", "-q", "-o", output, "-F", format}
+	if dpi > 0 {
+		args = append(args, "-r", strconv.Itoa(dpi))
+	}
+	if pw := mutoolPassword(src); pw != "" {
+		args = append(args, "-p", pw)
+	}
+	// mutool uses 1-indexed pages
+	return append(args, src.Path, strconv.Itoa(pageNum+1))
+}
+
+// mutoolPassword returns the single password mutool accepts, preferring the owner password.
+func mutoolPassword(src RenderSource) string {
+	if src.OwnerPassword != "" {
+		return src.OwnerPassword
+	}
+	return src.UserPassword
+}
+
+func runMutool(args []string) ([]byte, error) {
+	cmd := exec.Command("mutool", args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		details := strings.TrimSpace(stderr.String())
+		if details != "" {
+			return nil, fmt.Errorf("mutool failed: %v: %s", err, details)
+		}
+		return nil, fmt.Errorf("mutool failed: %w", err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// RenderPages renders a page range with a single "mutool draw" invocation.
+func (b *mutoolBackend) RenderPages(src RenderSource, first, last int, dpi int, fn PageImageFunc) error {
+	tmpDir, err := os.MkdirTemp("", "openpdfreader-mutool-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	args := mutoolBatchArgs(src, first, last, dpi, filepath.Join(tmpDir, "page-%d.png"))
+	return streamBatchPages(exec.Command("mutool", args...), tmpDir, first, last, fn)
+}
+
+// mutoolBatchArgs builds "mutool draw" arguments for a page range.
+func mutoolBatchArgs(src RenderSource, first, last int, dpi int, pattern string) []string {
+	args := []string{"draw", "-q", "-o", pattern, "-F", "png", "-r", strconv.Itoa(dpi)}
+	if pw := mutoolPassword(src); pw != "" {
+		args = append(args, "-p", pw)
+	}
+	return append(args, src.Path, fmt.Sprintf("%d-%d", first+1, last+1))
+}
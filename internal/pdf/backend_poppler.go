@@ -0,0 +1,149 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// popplerBackend shells out to the poppler-utils command line tools.
+type popplerBackend struct {
+	lookPath func(string) (string, error)
+}
+
+func newPopplerBackend(lookPath func(string) (string, error)) *popplerBackend {
+	return &popplerBackend{lookPath: lookPath}
+}
+
+func (b *popplerBackend) Name() string {
+	return BackendPoppler
+}
+
+func (b *popplerBackend) Available() bool {
+	return b.has("pdftoppm")
+}
+
+func (b *popplerBackend) Capabilities() Capabilities {
+	return Capabilities{
+		Render:    b.has("pdftoppm"),
+		PageCount: b.has("pdfinfo"),
+		Text:      b.has("pdftotext"),
+		Passwords: true,
+		External:  true,
+	}
+}
+
+func (b *popplerBackend) has(command string) bool {
+	_, err := b.lookPath(command)
+	return err == nil
+}
+
+func (b *popplerBackend) RenderPage(src RenderSource, pageNum int, dpi int) (image.Image, error) {
+	cmd := exec.Command("pdftoppm", popplerRenderArgs(src, pageNum, dpi)...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("pdftoppm failed: %v: %s", err, stderr.String())
+	}
+
+	// Decode PNG from stdout
+	img, err := png.Decode(&stdout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode PNG: %v", err)
+	}
+
+	return img, nil
+}
+
+// popplerRenderArgs builds the pdftoppm arguments for a single page written
+// to stdout as PNG.
+func popplerRenderArgs(src RenderSource, pageNum int, dpi int) []string {
+	// pdftoppm uses 1-indexed pages
+	pageStr := strconv.Itoa(pageNum + 1)
+	args := []string{
+		"-png",
+		"-f", pageStr, // first page
+		"-l", pageStr, // last page (same = single page)
+		"-r", strconv.Itoa(dpi), // resolution
+		"-singlefile", // don't add page suffix
+		"-cropbox",    // render the effective (crop) box, not the media box
+	}
+	args = append(args, popplerPasswordArgs(src)...)
+	return append(args, src.Path)
+}
+
+func popplerPasswordArgs(src RenderSource) []string {
+	var args []string
+	if src.UserPassword != "" {
+		args = append(args, "-upw", src.UserPassword)
+	}
+	if src.OwnerPassword != "" {
+		args = append(args, "-opw", src.OwnerPassword)
+	}
+	return args
+}
+
+// PageCount returns the number of pages using pdfinfo.
+func (b *popplerBackend) PageCount(src RenderSource) (int, error) {
+	args := append(popplerPasswordArgs(src), src.Path)
+	output, err := exec.Command("pdfinfo", args...).Output()
+	if err != nil {
+		return 0, err
+	}
+	return parsePagesLine(output)
+}
+
+func (b *popplerBackend) ExtractText(src RenderSource, pageNum int) (string, error) {
+	return extractPageText(src.Path, pageNum, src.UserPassword, src.OwnerPassword)
+}
+
+// parsePagesLine parses the "Pages: N" line printed by pdfinfo and mutool info.
+func parsePagesLine(output []byte) (int, error) {
+	lines := bytes.Split(output, []byte("\n"))
+	for _, line := range lines {
+		line = bytes.TrimSpace(line)
+		if bytes.HasPrefix(line, []byte("Pages:")) {
+			parts := strings.Fields(string(line))
+			if len(parts) >= 2 {
+				return strconv.Atoi(parts[1])
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("could not determine page count")
+}
+
+// RenderPages renders a page range with a single pdftoppm invocation.
+func (b *popplerBackend) RenderPages(src RenderSource, first, last int, dpi int, fn PageImageFunc) error {
+	tmpDir, err := os.MkdirTemp("", "openpdfreader-pdftoppm-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cmd := exec.Command("pdftoppm", popplerBatchArgs(src, first, last, dpi, filepath.Join(tmpDir, "page"))...)
+	return streamBatchPages(cmd, tmpDir, first, last, fn)
+}
+
+// popplerBatchArgs builds the pdftoppm arguments for a page range written as
+// "<prefix>-<page>.png" files.
+func popplerBatchArgs(src RenderSource, first, last int, dpi int, prefix string) []string {
+	args := []string{
+		"-png",
+		"-f", strconv.Itoa(first + 1),
+		"-l", strconv.Itoa(last + 1),
+		"-r", strconv.Itoa(dpi),
+		"-cropbox",
+	}
+	args = append(args, popplerPasswordArgs(src)...)
+	return append(args, src.Path, prefix)
+}
@@ -0,0 +1,185 @@
+package pdf
+
+import (
+	"errors"
+	"image"
+	"path/filepath"
+	"testing"
+)
+
+// fakeBackend is a RenderBackend with canned results.
+type fakeBackend struct {
+	name      string
+	available bool
+	caps      Capabilities
+	text      string
+	rendered  []int
+	renderErr error
+}
+
+func (b *fakeBackend) Name() string               { return b.name }
+func (b *fakeBackend) Available() bool            { return b.available }
+func (b *fakeBackend) Capabilities() Capabilities { return b.caps }
+
+func (b *fakeBackend) RenderPage(src RenderSource, pageNum int, dpi int) (image.Image, error) {
+	b.rendered = append(b.rendered, pageNum)
+	if b.renderErr != nil {
+		return nil, b.renderErr
+	}
+	return image.NewRGBA(image.Rect(0, 0, dpi, dpi)), nil
+}
+
+func (b *fakeBackend) PageCount(src RenderSource) (int, error) {
+	return 7, nil
+}
+
+func (b *fakeBackend) ExtractText(src RenderSource, pageNum int) (string, error) {
+	return b.text, nil
+}
+
+func TestSelectBackend(t *testing.T) {
+	found := func(string) (string, error) { return "/usr/bin/tool", nil }
+	missing := func(string) (string, error) { return "", errors.New("not found") }
+
+	tests := []struct {
+		name       string
+		candidates []RenderBackend
+		request    string
+		want       string
+		wantErr    bool
+	}{
+		{"auto prefers poppler", []RenderBackend{newPopplerBackend(found), newMutoolBackend(found), newNativeBackend()}, "", BackendPoppler, false},
+		{"auto falls back to mutool", []RenderBackend{newPopplerBackend(missing), newMutoolBackend(found), newNativeBackend()}, "auto", BackendMutool, false},
+		{"auto falls back to native", []RenderBackend{newPopplerBackend(missing), newMutoolBackend(missing), newNativeBackend()}, "", BackendNative, false},
+		{"explicit name", []RenderBackend{newPopplerBackend(found), newNativeBackend()}, " Native ", BackendNative, false},
+		{"unavailable name", []RenderBackend{newPopplerBackend(missing), newNativeBackend()}, "poppler", "", true},
+		{"unknown name", []RenderBackend{newNativeBackend()}, "pdfium", "", true},
+		{"nothing available", []RenderBackend{newPopplerBackend(missing)}, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := selectBackend(tt.candidates, tt.request)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("selectBackend(%q) expected error, got %s", tt.request, b.Name())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectBackend(%q) returned error: %v", tt.request, err)
+			}
+			if b.Name() != tt.want {
+				t.Errorf("selectBackend(%q) = %s, want %s", tt.request, b.Name(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterBackend(t *testing.T) {
+	fake := &fakeBackend{name: "fake-register", available: true, caps: Capabilities{Render: true}}
+	RegisterBackend(fake)
+	t.Cleanup(func() {
+		backendsMu.Lock()
+		defer backendsMu.Unlock()
+		for i, b := range backends {
+			if b.Name() == fake.name {
+				backends = append(backends[:i], backends[i+1:]...)
+				break
+			}
+		}
+	})
+
+	r, err := NewRendererByName("fake-register")
+	if err != nil {
+		t.Fatalf("NewRendererByName() returned error: %v", err)
+	}
+	if r.Backend() != fake.name {
+		t.Errorf("Backend() = %q, want %q", r.Backend(), fake.name)
+	}
+
+	found := false
+	for _, name := range BackendNames() {
+		if name == fake.name {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("BackendNames() missing %q", fake.name)
+	}
+}
+
+func TestDocumentUsesInjectedRenderer(t *testing.T) {
+	testPDF := filepath.Join(t.TempDir(), "test.pdf")
+	if !createTestPDF(testPDF) {
+		t.Skip("Cannot create test PDF")
+	}
+
+	doc, err := Open(testPDF)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+
+	fake := &fakeBackend{name: "fake", available: true, caps: Capabilities{Render: true, Text: true}, text: "hello"}
+	doc.SetRenderer(NewRendererWithBackend(fake))
+
+	img, err := doc.RenderPage(0, 1.0)
+	if err != nil {
+		t.Fatalf("RenderPage() failed: %v", err)
+	}
+	if img.Bounds().Dx() != 72 || len(fake.rendered) != 1 {
+		t.Errorf("RenderPage() did not use injected backend")
+	}
+
+	text, err := doc.ExtractText(0)
+	if err != nil || text != "hello" {
+		t.Errorf("ExtractText() = %q, %v; want hello", text, err)
+	}
+}
+
+func TestPopplerRenderArgs(t *testing.T) {
+	src := RenderSource{Path: "/tmp/test.pdf", UserPassword: "u", OwnerPassword: "o"}
+	args := popplerRenderArgs(src, 2, 150)
+	expected := []string{"-png", "-f", "3", "-l", "3", "-r", "150", "-singlefile", "-cropbox", "-upw", "u", "-opw", "o", "/tmp/test.pdf"}
+	assertArgs(t, args, expected)
+}
+
+func TestMutoolDrawArgs(t *testing.T) {
+	src := RenderSource{Path: "/tmp/test.pdf", UserPassword: "u"}
+	assertArgs(t, mutoolDrawArgs(src, 0, "/tmp/out.png", "png", 96),
+		[]string{"draw", "-q", "-o", "/tmp/out.png", "-F", "png", "-r", "96", "-p", "u", "/tmp/test.pdf", "1"})
+	assertArgs(t, mutoolDrawArgs(RenderSource{Path: "/tmp/test.pdf"}, 4, "-", "txt", 0),
+		[]string{"draw", "-q", "-o", "-", "-F", "txt", "/tmp/test.pdf", "5"})
+}
+
+func TestParsePagesLine(t *testing.T) {
+	n, err := parsePagesLine([]byte("Title: x\nPages:          12\nEncrypted: no\n"))
+	if err != nil || n != 12 {
+		t.Errorf("parsePagesLine() = %d, %v; want 12", n, err)
+	}
+	if _, err := parsePagesLine([]byte("nothing here")); err == nil {
+		t.Error("parsePagesLine() expected error without a Pages line")
+	}
+}
+
+func TestCapabilitiesString(t *testing.T) {
+	if got := (Capabilities{}).String(); got != "none" {
+		t.Errorf("String() = %q, want none", got)
+	}
+	if got := (Capabilities{Render: true, Text: true}).String(); got != "render, text" {
+		t.Errorf("String() = %q, want \"render, text\"", got)
+	}
+}
+
+func assertArgs(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("args = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("args[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package pdf
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// batchPollInterval is how often a running batch checks for finished pages.
+const batchPollInterval = 20 * time.Millisecond
+
+// streamBatchPages runs cmd, which writes one "<prefix>-<n>.png" file per page
+// into dir, and hands each page to fn as soon as the tool has moved on to the
+// next one. Page numbers in file names are 1-indexed.
+func streamBatchPages(cmd *exec.Cmd, dir string, first, last int, fn PageImageFunc) error {
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	next := first
+	deliver := func(finished bool) error {
+		pages, err := batchPageFiles(dir)
+		if err != nil {
+			return err
+		}
+		for next <= last {
+			path, ok := pages[next+1]
+			if !ok {
+				return nil
+			}
+			// The newest file may still be written until the tool
+			// starts on the following page or exits.
+			if _, following := pages[next+2]; !following && !finished {
+				return nil
+			}
+			img, err := decodePNGFile(path)
+			if err != nil {
+				return fmt.Errorf("page %d: %w", next+1, err)
+			}
+			os.Remove(path)
+			if err := fn(next, img); err != nil {
+				return err
+			}
+			next++
+		}
+		return nil
+	}
+
+	ticker := time.NewTicker(batchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				details := strings.TrimSpace(stderr.String())
+				if details != "" {
+					return fmt.Errorf("%s failed: %v: %s", filepath.Base(cmd.Path), err, details)
+				}
+				return fmt.Errorf("%s failed: %w", filepath.Base(cmd.Path), err)
+			}
+			if err := deliver(true); err != nil {
+				return err
+			}
+			if next <= last {
+				return fmt.Errorf("page %d was not rendered", next+1)
+			}
+			return nil
+		case <-ticker.C:
+			if err := deliver(false); err != nil {
+				cmd.Process.Kill()
+				<-done
+				return err
+			}
+		}
+	}
+}
+
+// batchPageFiles maps 1-indexed page numbers to the PNG files in dir.
+func batchPageFiles(dir string) (map[int]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make(map[int]string, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasSuffix(name, ".png") {
+			continue
+		}
+		stem := strings.TrimSuffix(name, ".png")
+		idx := strings.LastIndex(stem, "-")
+		if idx < 0 {
+			continue
+		}
+		n, err := strconv.Atoi(stem[idx+1:])
+		if err != nil {
+			continue
+		}
+		pages[n] = filepath.Join(dir, name)
+	}
+	return pages, nil
+}
+
+func decodePNGFile(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode PNG: %v", err)
+	}
+	return img, nil
+}
+
+// renderPagesOneByOne is the batch fallback for backends without native support.
+func renderPagesOneByOne(b RenderBackend, src RenderSource, first, last int, dpi int, fn PageImageFunc) error {
+	for pageNum := first; pageNum <= last; pageNum++ {
+		img, err := b.RenderPage(src, pageNum, dpi)
+		if err != nil {
+			return fmt.Errorf("render page %d: %w", pageNum+1, err)
+		}
+		if err := fn(pageNum, img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
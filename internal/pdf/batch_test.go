@@ -0,0 +1,146 @@
+package pdf
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestPopplerBatchArgs(t *testing.T) {
+	src := RenderSource{Path: "/tmp/test.pdf", OwnerPassword: "o"}
+	assertArgs(t, popplerBatchArgs(src, 4, 9, 72, "/tmp/out/page"),
+		[]string{"-png", "-f", "5", "-l", "10", "-r", "72", "-cropbox", "-opw", "o", "/tmp/test.pdf", "/tmp/out/page"})
+}
+
+func TestMutoolBatchArgs(t *testing.T) {
+	src := RenderSource{Path: "/tmp/test.pdf"}
+	assertArgs(t, mutoolBatchArgs(src, 0, 2, 96, "/tmp/out/page-%d.png"),
+		[]string{"draw", "-q", "-o", "/tmp/out/page-%d.png", "-F", "png", "-r", "96", "/tmp/test.pdf", "1-3"})
+}
+
+func TestBatchPageFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"page-001.png", "page-12.png", "notes.txt", "page-x.png"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pages, err := batchPageFiles(dir)
+	if err != nil {
+		t.Fatalf("batchPageFiles() returned error: %v", err)
+	}
+	if len(pages) != 2 || filepath.Base(pages[1]) != "page-001.png" || filepath.Base(pages[12]) != "page-12.png" {
+		t.Errorf("batchPageFiles() = %v", pages)
+	}
+}
+
+func TestStreamBatchPages(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+
+	// A stand-in for pdftoppm that copies a prepared PNG per page.
+	srcDir := t.TempDir()
+	outDir := t.TempDir()
+	pngPath := filepath.Join(srcDir, "src.png")
+	f, err := os.Create(pngPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	png.Encode(f, image.NewGray(image.Rect(0, 0, 4, 4)))
+	f.Close()
+
+	script := "for p in 3 4 5; do cp " + pngPath + " " + filepath.Join(outDir, "page-0") + "$p.png; done"
+	cmd := exec.Command(sh, "-c", script)
+
+	var got []int
+	err = streamBatchPages(cmd, outDir, 2, 4, func(pageNum int, img image.Image) error {
+		if img.Bounds().Dx() != 4 {
+			t.Errorf("page %d has unexpected size %v", pageNum, img.Bounds())
+		}
+		got = append(got, pageNum)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("streamBatchPages() returned error: %v", err)
+	}
+	if len(got) != 3 || got[0] != 2 || got[2] != 4 {
+		t.Errorf("delivered pages = %v, want [2 3 4]", got)
+	}
+}
+
+func TestStreamBatchPagesCommandFailure(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+
+	cmd := exec.Command(sh, "-c", "echo broken >&2; exit 3")
+	err = streamBatchPages(cmd, t.TempDir(), 0, 0, func(int, image.Image) error { return nil })
+	if err == nil {
+		t.Fatal("streamBatchPages() expected error for failing command")
+	}
+}
+
+func TestDocumentRenderPages(t *testing.T) {
+	path := writeContentPDF(t, "[0 0 200 100]", "0 g 10 10 20 20 re f")
+	doc, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+	doc.SetRenderer(NewRendererWithBackend(newNativeBackend()))
+
+	var pages []int
+	err = doc.RenderPages(0, 0, 1.0, func(pageNum int, img image.Image) error {
+		pages = append(pages, pageNum)
+		if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 100 {
+			t.Errorf("image size = %dx%d, want 200x100", b.Dx(), b.Dy())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RenderPages() returned error: %v", err)
+	}
+	if len(pages) != 1 || pages[0] != 0 {
+		t.Errorf("rendered pages = %v, want [0]", pages)
+	}
+
+	if err := doc.RenderPages(0, 1, 1.0, func(int, image.Image) error { return nil }); err == nil {
+		t.Error("RenderPages() expected error for out-of-range page")
+	}
+
+	stop := errors.New("stop")
+	if err := doc.RenderPages(0, 0, 1.0, func(int, image.Image) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("RenderPages() error = %v, want callback error", err)
+	}
+}
+
+func TestDocumentRenderPagesFallsBackPerPage(t *testing.T) {
+	testPDF := filepath.Join(t.TempDir(), "test.pdf")
+	if !createTestPDF(testPDF) {
+		t.Skip("Cannot create test PDF")
+	}
+	doc, err := Open(testPDF)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+
+	fake := &fakeBackend{name: "fake", available: true, caps: Capabilities{Render: true}}
+	doc.SetRenderer(NewRendererWithBackend(fake))
+
+	count := 0
+	if err := doc.RenderPages(0, 0, 1.0, func(int, image.Image) error { count++; return nil }); err != nil {
+		t.Fatalf("RenderPages() returned error: %v", err)
+	}
+	if count != 1 || len(fake.rendered) != 1 {
+		t.Errorf("expected one page rendered through RenderPage, got count=%d rendered=%v", count, fake.rendered)
+	}
+}
@@ -0,0 +1,178 @@
+package pdf
+
+import (
+	"container/list"
+	"image"
+	"sync"
+)
+
+// DefaultRenderCacheBytes is the render cache budget used when none is configured.
+const DefaultRenderCacheBytes = 256 << 20
+
+// RenderCacheKey identifies one rendered page image.
+type RenderCacheKey struct {
+	Document uint64 // Document.ID
+	Page     int    // 0-indexed
+	DPI      int
+	Stamp    uint64 // Document.Stamp at render time
+}
+
+// RenderCacheStats reports cache usage.
+type RenderCacheStats struct {
+	Entries   int
+	Bytes     int64
+	Budget    int64
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// RenderCache is a least-recently-used cache of rendered pages bounded by an
+// approximate memory budget. It is safe for concurrent use and meant to be
+// shared by all open documents.
+type RenderCache struct {
+	mu      sync.Mutex
+	budget  int64
+	bytes   int64
+	order   *list.List // front = most recently used
+	entries map[RenderCacheKey]*list.Element
+	stats   RenderCacheStats
+}
+
+type renderCacheEntry struct {
+	key   RenderCacheKey
+	img   image.Image
+	bytes int64
+}
+
+// NewRenderCache creates a cache holding at most budget bytes of pixel data.
+// A budget <= 0 uses DefaultRenderCacheBytes.
+func NewRenderCache(budget int64) *RenderCache {
+	if budget <= 0 {
+		budget = DefaultRenderCacheBytes
+	}
+	return &RenderCache{
+		budget:  budget,
+		order:   list.New(),
+		entries: make(map[RenderCacheKey]*list.Element),
+	}
+}
+
+// Get returns a cached image and marks it as recently used.
+func (c *RenderCache) Get(key RenderCacheKey) (image.Image, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	c.order.MoveToFront(el)
+	return el.Value.(*renderCacheEntry).img, true
+}
+
+// Contains reports whether key is cached without affecting recency or stats.
+func (c *RenderCache) Contains(key RenderCacheKey) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.entries[key]
+	return ok
+}
+
+// Put stores an image, evicting least recently used entries to stay within
+// the budget. Images larger than the whole budget are not cached.
+func (c *RenderCache) Put(key RenderCacheKey, img image.Image) {
+	if img == nil {
+		return
+	}
+	size := imageBytes(img)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.removeElement(el)
+	}
+	if size > c.budget {
+		return
+	}
+
+	entry := &renderCacheEntry{key: key, img: img, bytes: size}
+	c.entries[key] = c.order.PushFront(entry)
+	c.bytes += size
+
+	for c.bytes > c.budget {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		c.removeElement(oldest)
+		c.stats.Evictions++
+	}
+}
+
+// InvalidateDocument drops all entries of a document.
+func (c *RenderCache) InvalidateDocument(docID uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, el := range c.entries {
+		if key.Document == docID {
+			c.removeElement(el)
+		}
+	}
+}
+
+// SetBudget changes the memory budget, evicting entries if necessary.
+func (c *RenderCache) SetBudget(budget int64) {
+	if budget <= 0 {
+		budget = DefaultRenderCacheBytes
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.budget = budget
+	for c.bytes > c.budget {
+		c.removeElement(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *RenderCache) Stats() RenderCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	stats.Bytes = c.bytes
+	stats.Budget = c.budget
+	return stats
+}
+
+func (c *RenderCache) removeElement(el *list.Element) {
+	entry := el.Value.(*renderCacheEntry)
+	c.order.Remove(el)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.bytes
+}
+
+// imageBytes estimates the memory held by an image's pixel buffer.
+func imageBytes(img image.Image) int64 {
+	switch m := img.(type) {
+	case *image.RGBA:
+		return int64(len(m.Pix))
+	case *image.NRGBA:
+		return int64(len(m.Pix))
+	case *image.Gray:
+		return int64(len(m.Pix))
+	case *image.Paletted:
+		return int64(len(m.Pix))
+	}
+	b := img.Bounds()
+	return int64(b.Dx()) * int64(b.Dy()) * 4
+}
@@ -0,0 +1,225 @@
+package pdf
+
+import (
+	"errors"
+	"image"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func testImage(w, h int) image.Image {
+	return image.NewRGBA(image.Rect(0, 0, w, h))
+}
+
+func TestRenderCacheGetPut(t *testing.T) {
+	c := NewRenderCache(1 << 20)
+	key := RenderCacheKey{Document: 1, Page: 0, DPI: 72}
+
+	if _, ok := c.Get(key); ok {
+		t.Fatal("Get() hit on empty cache")
+	}
+	img := testImage(10, 10)
+	c.Put(key, img)
+	got, ok := c.Get(key)
+	if !ok || got != img {
+		t.Fatal("Get() did not return stored image")
+	}
+
+	// A different stamp is a different entry.
+	if _, ok := c.Get(RenderCacheKey{Document: 1, Page: 0, DPI: 72, Stamp: 1}); ok {
+		t.Fatal("Get() hit for a different stamp")
+	}
+
+	stats := c.Stats()
+	if stats.Entries != 1 || stats.Bytes != 400 || stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestRenderCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	// Each 10x10 RGBA image is 400 bytes; the budget fits two.
+	c := NewRenderCache(800)
+	a := RenderCacheKey{Document: 1, Page: 0}
+	b := RenderCacheKey{Document: 1, Page: 1}
+	d := RenderCacheKey{Document: 1, Page: 2}
+
+	c.Put(a, testImage(10, 10))
+	c.Put(b, testImage(10, 10))
+	c.Get(a) // a becomes most recently used
+	c.Put(d, testImage(10, 10))
+
+	if !c.Contains(a) || c.Contains(b) || !c.Contains(d) {
+		t.Errorf("unexpected entries after eviction: a=%v b=%v d=%v", c.Contains(a), c.Contains(b), c.Contains(d))
+	}
+	if stats := c.Stats(); stats.Evictions != 1 || stats.Bytes != 800 {
+		t.Errorf("Stats() = %+v, want 1 eviction and 800 bytes", stats)
+	}
+
+	// Images larger than the budget are never cached.
+	c.Put(RenderCacheKey{Page: 9}, testImage(100, 100))
+	if c.Contains(RenderCacheKey{Page: 9}) {
+		t.Error("oversized image was cached")
+	}
+
+	c.SetBudget(400)
+	if stats := c.Stats(); stats.Entries != 1 || stats.Budget != 400 {
+		t.Errorf("Stats() after SetBudget = %+v", stats)
+	}
+}
+
+func TestRenderCacheInvalidateDocument(t *testing.T) {
+	c := NewRenderCache(1 << 20)
+	c.Put(RenderCacheKey{Document: 1, Page: 0}, testImage(2, 2))
+	c.Put(RenderCacheKey{Document: 1, Page: 1}, testImage(2, 2))
+	c.Put(RenderCacheKey{Document: 2, Page: 0}, testImage(2, 2))
+
+	c.InvalidateDocument(1)
+	if stats := c.Stats(); stats.Entries != 1 || stats.Bytes != 16 {
+		t.Errorf("Stats() after invalidation = %+v", stats)
+	}
+	if !c.Contains(RenderCacheKey{Document: 2, Page: 0}) {
+		t.Error("InvalidateDocument() removed another document's entry")
+	}
+}
+
+func TestDocumentRenderCache(t *testing.T) {
+	testPDF := filepath.Join(t.TempDir(), "test.pdf")
+	if !createTestPDF(testPDF) {
+		t.Skip("Cannot create test PDF")
+	}
+	doc, err := Open(testPDF)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+
+	other, err := Open(testPDF)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer other.Close()
+	if doc.ID() == other.ID() {
+		t.Fatal("documents share an ID")
+	}
+
+	fake := &fakeBackend{name: "fake", available: true, caps: Capabilities{Render: true}}
+	doc.SetRenderer(NewRendererWithBackend(fake))
+	cache := NewRenderCache(1 << 20)
+	doc.SetRenderCache(cache)
+
+	if _, err := doc.RenderPage(0, 1.0); err != nil {
+		t.Fatalf("RenderPage() failed: %v", err)
+	}
+	if _, err := doc.RenderPage(0, 1.0); err != nil {
+		t.Fatalf("RenderPage() failed: %v", err)
+	}
+	if len(fake.rendered) != 1 {
+		t.Fatalf("backend rendered %d times, want 1", len(fake.rendered))
+	}
+	if _, ok := doc.CachedPage(0, 1.0); !ok {
+		t.Fatal("CachedPage() missed after render")
+	}
+	if _, ok := doc.CachedPage(0, 2.0); ok {
+		t.Fatal("CachedPage() hit for a different DPI")
+	}
+
+	stamp := doc.Stamp()
+	if err := doc.Reload(); err != nil {
+		t.Fatalf("Reload() failed: %v", err)
+	}
+	if doc.Stamp() == stamp {
+		t.Error("Reload() did not change the stamp")
+	}
+	if cache.Stats().Entries != 0 {
+		t.Error("Reload() did not invalidate cached pages")
+	}
+
+	count := 0
+	if err := doc.RenderPages(0, 0, 1.0, func(int, image.Image) error { count++; return nil }); err != nil {
+		t.Fatalf("RenderPages() failed: %v", err)
+	}
+	if err := doc.RenderPages(0, 0, 1.0, func(int, image.Image) error { count++; return nil }); err != nil {
+		t.Fatalf("RenderPages() failed: %v", err)
+	}
+	if count != 2 || len(fake.rendered) != 2 {
+		t.Errorf("RenderPages() delivered %d pages with %d renders, want 2 and 2", count, len(fake.rendered))
+	}
+}
+
+func TestDocumentReloadDuringRender(t *testing.T) {
+	doc, err := Open(writeContentPDF(t, "[0 0 200 300]", "0 0 1 rg 20 20 100 100 re f"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+	doc.SetRenderer(NewRendererWithBackend(newNativeBackend()))
+	doc.SetRenderCache(NewRenderCache(1 << 20))
+
+	// Background workers render while the document is reloaded; run with
+	// -race to check that they never see a half-replaced document.
+	var wg sync.WaitGroup
+	for worker := range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 20 {
+				scale := 0.25 * float64(1+(worker+i)%3)
+				if _, err := doc.RenderPage(0, scale); err != nil {
+					t.Errorf("RenderPage() failed: %v", err)
+					return
+				}
+				if err := doc.RenderPages(0, 0, scale, func(int, image.Image) error { return nil }); err != nil {
+					t.Errorf("RenderPages() failed: %v", err)
+					return
+				}
+				if _, err := doc.PageGeometry(0); err != nil {
+					t.Errorf("PageGeometry() failed: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	for range 10 {
+		if err := doc.Reload(); err != nil {
+			t.Fatalf("Reload() failed: %v", err)
+		}
+	}
+	wg.Wait()
+
+	if doc.PageCount() != 1 || doc.IsModified() {
+		t.Errorf("after reload: PageCount() = %d, IsModified() = %v", doc.PageCount(), doc.IsModified())
+	}
+}
+
+func TestDocumentDoesNotCachePlaceholders(t *testing.T) {
+	doc, err := Open(writeContentPDF(t, "[0 0 200 300]", ""))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+	fake := &fakeBackend{name: "fake", available: true, caps: Capabilities{Render: true}, renderErr: errors.New("render failed")}
+	doc.SetRenderer(NewRendererWithBackend(fake))
+	cache := NewRenderCache(1 << 20)
+	doc.SetRenderCache(cache)
+
+	if _, err := doc.RenderPage(0, 1.0); err != nil {
+		t.Fatalf("RenderPage() failed: %v", err)
+	}
+	if err := doc.RenderPages(0, 0, 1.0, func(int, image.Image) error { return nil }); err != nil {
+		t.Fatalf("RenderPages() failed: %v", err)
+	}
+	if cache.Stats().Entries != 0 {
+		t.Errorf("cache holds %d entries, want no placeholders", cache.Stats().Entries)
+	}
+
+	// Once the backend recovers the page is rendered rather than served
+	// from the cache.
+	fake.renderErr = nil
+	if _, err := doc.RenderPage(0, 1.0); err != nil {
+		t.Fatalf("RenderPage() failed: %v", err)
+	}
+	if _, ok := doc.CachedPage(0, 1.0); !ok {
+		t.Error("CachedPage() missed after a successful render")
+	}
+}
@@ -0,0 +1,396 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// contentOp is one operator of a page content stream together with its operands.
+type contentOp struct {
+	operator string
+	operands []types.Object
+	// inline holds the raw sample data of an inline image (BI ... ID ... EI).
+	inline []byte
+}
+
+// contentLexer tokenizes PDF content streams.
+type contentLexer struct {
+	data []byte
+	pos  int
+}
+
+var errContentEOF = errors.New("end of content stream")
+
+// parseContentStream splits a decoded content stream into operators.
+// Malformed trailing data is ignored so partially broken pages still render.
+func parseContentStream(data []byte) ([]contentOp, error) {
+	lex := &contentLexer{data: data}
+	var (
+		ops      []contentOp
+		operands []types.Object
+	)
+
+	for {
+		obj, operator, err := lex.next()
+		if err == errContentEOF {
+			return ops, nil
+		}
+		if err != nil {
+			return ops, err
+		}
+
+		if operator == "" {
+			operands = append(operands, obj)
+			continue
+		}
+
+		op := contentOp{operator: operator, operands: operands}
+		if operator == "BI" {
+			dict, inline, err := lex.inlineImage()
+			if err != nil {
+				return ops, err
+			}
+			op.operands = []types.Object{dict}
+			op.inline = inline
+		}
+		ops = append(ops, op)
+		operands = nil
+	}
+}
+
+// next returns either an operand object or an operator keyword.
+func (l *contentLexer) next() (types.Object, string, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, "", errContentEOF
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		name, err := l.name()
+		return name, "", err
+	case c == '(':
+		s, err := l.literalString()
+		return s, "", err
+	case c == '<':
+		if l.peek(1) == '<' {
+			d, err := l.dict()
+			return d, "", err
+		}
+		h, err := l.hexString()
+		return h, "", err
+	case c == '[':
+		a, err := l.array()
+		return a, "", err
+	case c == ']' || c == '>' || c == ')' || c == '}' || c == '{':
+		// Stray delimiters are skipped rather than aborting the page.
+		l.pos++
+		return l.next()
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		n, err := l.number()
+		return n, "", err
+	}
+
+	word := l.regular()
+	switch word {
+	case "true":
+		return types.Boolean(true), "", nil
+	case "false":
+		return types.Boolean(false), "", nil
+	case "null":
+		return nil, "", nil
+	}
+	return nil, word, nil
+}
+
+func (l *contentLexer) peek(offset int) byte {
+	if l.pos+offset >= len(l.data) {
+		return 0
+	}
+	return l.data[l.pos+offset]
+}
+
+func isContentSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isContentDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (l *contentLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isContentSpace(c) {
+			l.pos++
+			continue
+		}
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+func (l *contentLexer) regular() string {
+	start := l.pos
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isContentSpace(c) || isContentDelimiter(c) {
+			break
+		}
+		l.pos++
+	}
+	if l.pos == start {
+		// Unknown single byte; consume it to guarantee progress.
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+func (l *contentLexer) name() (types.Name, error) {
+	l.pos++ // skip '/'
+	start := l.pos
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isContentSpace(c) || isContentDelimiter(c) {
+			break
+		}
+		l.pos++
+	}
+	raw := string(l.data[start:l.pos])
+	if strings.Contains(raw, "#") {
+		decoded, err := types.DecodeName(raw)
+		if err == nil {
+			raw = decoded
+		}
+	}
+	return types.Name(raw), nil
+}
+
+func (l *contentLexer) number() (types.Object, error) {
+	start := l.pos
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if !(c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9')) {
+			break
+		}
+		l.pos++
+	}
+	s := string(l.data[start:l.pos])
+	if !strings.Contains(s, ".") {
+		if i, err := strconv.Atoi(s); err == nil {
+			return types.Integer(i), nil
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		// Tolerate malformed numbers like "--5" or "1.2.3".
+		return types.Float(0), nil
+	}
+	return types.Float(f), nil
+}
+
+func (l *contentLexer) literalString() (types.StringLiteral, error) {
+	l.pos++ // skip '('
+	start := l.pos
+	depth := 1
+	for l.pos < len(l.data) {
+		switch l.data[l.pos] {
+		case '\\':
+			l.pos++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				s := types.StringLiteral(l.data[start:l.pos])
+				l.pos++
+				return s, nil
+			}
+		}
+		l.pos++
+	}
+	return "", errors.New("unterminated string literal in content stream")
+}
+
+func (l *contentLexer) hexString() (types.HexLiteral, error) {
+	l.pos++ // skip '<'
+	var b strings.Builder
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		if c == '>' {
+			s := b.String()
+			if len(s)%2 == 1 {
+				s += "0"
+			}
+			return types.HexLiteral(s), nil
+		}
+		if isContentSpace(c) {
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return "", errors.New("unterminated hex string in content stream")
+}
+
+func (l *contentLexer) array() (types.Array, error) {
+	l.pos++ // skip '['
+	arr := types.Array{}
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return arr, errors.New("unterminated array in content stream")
+		}
+		if l.data[l.pos] == ']' {
+			l.pos++
+			return arr, nil
+		}
+		obj, operator, err := l.next()
+		if err != nil {
+			return arr, err
+		}
+		if operator != "" {
+			// Operators are not valid inside arrays; keep them as names so TJ arrays survive.
+			continue
+		}
+		arr = append(arr, obj)
+	}
+}
+
+func (l *contentLexer) dict() (types.Dict, error) {
+	l.pos += 2 // skip '<<'
+	d := types.NewDict()
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return d, errors.New("unterminated dictionary in content stream")
+		}
+		if l.data[l.pos] == '>' && l.peek(1) == '>' {
+			l.pos += 2
+			return d, nil
+		}
+		key, operator, err := l.next()
+		if err != nil {
+			return d, err
+		}
+		name, ok := key.(types.Name)
+		if !ok || operator != "" {
+			continue
+		}
+		val, _, err := l.next()
+		if err != nil {
+			return d, err
+		}
+		d.Insert(string(name), val)
+	}
+}
+
+// inlineImage parses the dictionary and sample data following a BI operator.
+func (l *contentLexer) inlineImage() (types.Dict, []byte, error) {
+	d := types.NewDict()
+	for {
+		obj, operator, err := l.next()
+		if err != nil {
+			return d, nil, fmt.Errorf("inline image: %w", err)
+		}
+		if operator == "ID" {
+			break
+		}
+		name, ok := obj.(types.Name)
+		if !ok {
+			continue
+		}
+		val, _, err := l.next()
+		if err != nil {
+			return d, nil, fmt.Errorf("inline image: %w", err)
+		}
+		d.Insert(string(name), val)
+	}
+
+	// Exactly one whitespace byte separates ID from the sample data.
+	if l.pos < len(l.data) && isContentSpace(l.data[l.pos]) {
+		l.pos++
+	}
+	start := l.pos
+	for i := start; i+1 < len(l.data); i++ {
+		if l.data[i] != 'E' || l.data[i+1] != 'I' {
+			continue
+		}
+		if i > start && !isContentSpace(l.data[i-1]) {
+			continue
+		}
+		if i+2 < len(l.data) && !isContentSpace(l.data[i+2]) {
+			continue
+		}
+		data := bytes.TrimRight(l.data[start:i], "\r\n \t")
+		l.pos = i + 2
+		return d, data, nil
+	}
+	return d, nil, errors.New("inline image: missing EI")
+}
+
+// operandNumber returns the numeric value of a content operand.
+func operandNumber(o types.Object) (float64, bool) {
+	switch v := o.(type) {
+	case types.Integer:
+		return float64(v), true
+	case types.Float:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// operandNumbers converts all operands to numbers, failing if any is not numeric.
+func operandNumbers(ops []types.Object, n int) ([]float64, bool) {
+	if len(ops) < n {
+		return nil, false
+	}
+	ops = ops[len(ops)-n:]
+	vals := make([]float64, n)
+	for i, o := range ops {
+		v, ok := operandNumber(o)
+		if !ok {
+			return nil, false
+		}
+		vals[i] = v
+	}
+	return vals, true
+}
+
+// operandBytes returns the decoded bytes of a string operand.
+func operandBytes(o types.Object) ([]byte, bool) {
+	switch v := o.(type) {
+	case types.StringLiteral:
+		b, err := types.Unescape(string(v))
+		if err != nil {
+			return []byte(v), true
+		}
+		return b, true
+	case types.HexLiteral:
+		b, err := v.Bytes()
+		if err != nil {
+			return nil, false
+		}
+		return b, true
+	}
+	return nil, false
+}
@@ -0,0 +1,63 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+func TestParseContentStream(t *testing.T) {
+	ops, err := parseContentStream([]byte("q 1 0 0 1 10.5 -2 cm /F1 12 Tf [(A) -120 <0042>] TJ % comment\nQ"))
+	if err != nil {
+		t.Fatalf("parseContentStream() failed: %v", err)
+	}
+
+	want := []string{"q", "cm", "Tf", "TJ", "Q"}
+	if len(ops) != len(want) {
+		t.Fatalf("got %d operators, want %d", len(ops), len(want))
+	}
+	for i, op := range ops {
+		if op.operator != want[i] {
+			t.Errorf("operator %d = %q, want %q", i, op.operator, want[i])
+		}
+	}
+
+	vals, ok := operandNumbers(ops[1].operands, 6)
+	if !ok || vals[4] != 10.5 || vals[5] != -2 {
+		t.Errorf("cm operands = %v, want translation 10.5 -2", vals)
+	}
+	if name, ok := ops[2].operands[0].(types.Name); !ok || name != "F1" {
+		t.Errorf("Tf font operand = %v, want F1", ops[2].operands[0])
+	}
+
+	arr, ok := ops[3].operands[0].(types.Array)
+	if !ok || len(arr) != 3 {
+		t.Fatalf("TJ operand = %v, want 3-element array", ops[3].operands[0])
+	}
+	if b, ok := operandBytes(arr[2]); !ok || string(b) != "\x00B" {
+		t.Errorf("hex string = %q, want \\x00B", b)
+	}
+}
+
+func TestParseContentStreamInlineImage(t *testing.T) {
+	ops, err := parseContentStream([]byte("BI /W 2 /H 1 /CS /G /BPC 8 ID \x00\xff EI Q"))
+	if err != nil {
+		t.Fatalf("parseContentStream() failed: %v", err)
+	}
+	if len(ops) != 2 || ops[0].operator != "BI" {
+		t.Fatalf("unexpected operators: %+v", ops)
+	}
+	if string(ops[0].inline) != "\x00\xff" {
+		t.Errorf("inline data = %q, want \\x00\\xff", ops[0].inline)
+	}
+	dict, ok := ops[0].operands[0].(types.Dict)
+	if !ok || dict.IntEntry("W") == nil || *dict.IntEntry("W") != 2 {
+		t.Errorf("inline dict = %v, want /W 2", ops[0].operands[0])
+	}
+}
+
+func TestParseContentStreamUnterminatedString(t *testing.T) {
+	if _, err := parseContentStream([]byte("BT (abc Tj")); err == nil {
+		t.Error("parseContentStream() should fail for an unterminated string")
+	}
+}
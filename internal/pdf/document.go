@@ -7,13 +7,15 @@ import (
 	"image/color"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 )
 
-// Global renderer instance
-var renderer = NewRenderer()
+// nextDocumentID hands out document identities for render caching.
+var nextDocumentID atomic.Uint64
 
 // IsPasswordError checks if an error indicates a password-protected PDF.
 func IsPasswordError(err error) bool {
@@ -34,6 +36,26 @@ type Document struct {
 	modified      bool
 	userPassword  string
 	ownerPassword string
+
+	renderer *Renderer
+
+	// id identifies the document in a RenderCache; stamp changes whenever
+	// the content is reloaded so stale renders are never served.
+	id    uint64
+	stamp uint64
+	cache *RenderCache
+
+	// renderMu serializes rendering because decoding streams mutates ctx.
+	renderMu sync.Mutex
+
+	// stateMu guards path, ctx, pageCount, modified and stamp, which Reload
+	// replaces while background workers may be rendering. Writers of ctx
+	// also hold renderMu, so code holding renderMu may read ctx directly.
+	stateMu sync.RWMutex
+
+	// texts caches the positioned text of each page until the next reload.
+	textsMu sync.Mutex
+	texts   map[int]*PageText
 }
 
 // Open opens a PDF file.
@@ -50,25 +72,19 @@ func Open(path string) (*Document, error) {
 		modified:      false,
 		userPassword:  "",
 		ownerPassword: "",
+		id:            nextDocumentID.Add(1),
 	}, nil
 }
 
 // OpenWithPassword opens a password-protected PDF file.
 func OpenWithPassword(path, password string) (*Document, error) {
-	// Open the file
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	// Create configuration with password
-	conf := model.NewDefaultConfiguration()
-	conf.UserPW = password
-	conf.OwnerPW = password
+	return openWithPasswords(path, password, password)
+}
 
-	// Read with password configuration
-	ctx, err := api.ReadContext(f, conf)
+// openWithPasswords opens a PDF file, decrypting it with the given
+// passwords.
+func openWithPasswords(path, userPassword, ownerPassword string) (*Document, error) {
+	ctx, err := readContextFile(path, userPassword, ownerPassword)
 	if err != nil {
 		return nil, err
 	}
@@ -78,89 +94,208 @@ func OpenWithPassword(path, password string) (*Document, error) {
 		ctx:           ctx,
 		pageCount:     ctx.PageCount,
 		modified:      false,
-		userPassword:  password,
-		ownerPassword: password,
+		userPassword:  userPassword,
+		ownerPassword: ownerPassword,
+		id:            nextDocumentID.Add(1),
 	}, nil
 }
 
-// Reload re-reads the document context from disk.
-func (d *Document) Reload() error {
-	if d.path == "" {
-		return errors.New("no file path set")
+// readContextFile reads a PDF file, decrypting it with the given passwords
+// when either is set.
+func readContextFile(path, userPassword, ownerPassword string) (*model.Context, error) {
+	if userPassword == "" && ownerPassword == "" {
+		return api.ReadContextFile(path)
 	}
 
-	var (
-		ctx *model.Context
-		err error
-	)
-
-	if d.userPassword != "" || d.ownerPassword != "" {
-		f, openErr := os.Open(d.path)
-		if openErr != nil {
-			return openErr
-		}
-		defer f.Close()
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
 
-		conf := model.NewDefaultConfiguration()
-		conf.UserPW = d.userPassword
-		conf.OwnerPW = d.ownerPassword
+	conf := model.NewDefaultConfiguration()
+	conf.UserPW = userPassword
+	conf.OwnerPW = ownerPassword
+	ctx, err := api.ReadContext(f, conf)
+	if err != nil {
+		return nil, err
+	}
+	// Validation counts the pages, as ReadContextFile does.
+	if err := api.ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
 
-		ctx, err = api.ReadContext(f, conf)
-	} else {
-		ctx, err = api.ReadContextFile(d.path)
+// Reload re-reads the document context from disk.
+func (d *Document) Reload() error {
+	path := d.Path()
+	if path == "" {
+		return errors.New("no file path set")
 	}
+
+	ctx, err := readContextFile(path, d.userPassword, d.ownerPassword)
 	if err != nil {
 		return err
 	}
 
+	d.renderMu.Lock()
+	d.stateMu.Lock()
 	d.ctx = ctx
 	d.pageCount = ctx.PageCount
 	d.modified = false
+	d.stamp++
+	d.stateMu.Unlock()
+	d.renderMu.Unlock()
+	if d.cache != nil {
+		d.cache.InvalidateDocument(d.id)
+	}
+	d.textsMu.Lock()
+	d.texts = nil
+	d.textsMu.Unlock()
 	return nil
 }
 
+// ID returns an identifier that is unique among open documents.
+func (d *Document) ID() uint64 {
+	return d.id
+}
+
+// Stamp returns a counter that changes every time the document is reloaded.
+func (d *Document) Stamp() uint64 {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+	return d.stamp
+}
+
+// SetRenderCache makes RenderPage and RenderPages store and reuse rendered
+// pages in c. Passing nil disables caching.
+func (d *Document) SetRenderCache(c *RenderCache) {
+	d.cache = c
+}
+
+// CachedPage returns a previously rendered page at the given zoom factor
+// without rendering.
+func (d *Document) CachedPage(pageNum int, scale float64) (image.Image, bool) {
+	if d.cache == nil {
+		return nil, false
+	}
+	return d.cache.Get(d.cacheKey(pageNum, scaleToDPI(scale)))
+}
+
+func (d *Document) isCached(pageNum int, scale float64) bool {
+	return d.cache != nil && d.cache.Contains(d.cacheKey(pageNum, scaleToDPI(scale)))
+}
+
+func (d *Document) cacheKey(pageNum, dpi int) RenderCacheKey {
+	return RenderCacheKey{Document: d.id, Page: pageNum, DPI: dpi, Stamp: d.Stamp()}
+}
+
+// Renderer returns the renderer used for this document.
+func (d *Document) Renderer() *Renderer {
+	if d.renderer != nil {
+		return d.renderer
+	}
+	return DefaultRenderer()
+}
+
+// SetRenderer overrides the renderer for this document.
+// Passing nil reverts to the default renderer.
+func (d *Document) SetRenderer(r *Renderer) {
+	d.renderer = r
+}
+
+// source describes the open document for a rendering backend.
+func (d *Document) source() RenderSource {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+	return RenderSource{
+		Path:          d.path,
+		UserPassword:  d.userPassword,
+		OwnerPassword: d.ownerPassword,
+		Context:       d.ctx,
+	}
+}
+
 // Close closes the document and releases resources.
 func (d *Document) Close() error {
+	d.renderMu.Lock()
+	d.stateMu.Lock()
 	d.ctx = nil
+	d.stateMu.Unlock()
+	d.renderMu.Unlock()
 	return nil
 }
 
+// context returns the parsed document, or nil once it is closed.
+func (d *Document) context() *model.Context {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+	return d.ctx
+}
+
+// Passwords returns the passwords the document was opened with.
+func (d *Document) Passwords() (user, owner string) {
+	return d.userPassword, d.ownerPassword
+}
+
 // Path returns the file path.
 func (d *Document) Path() string {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
 	return d.path
 }
 
 // PageCount returns the number of pages.
 func (d *Document) PageCount() int {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
 	return d.pageCount
 }
 
 // IsModified returns true if the document has unsaved changes.
 func (d *Document) IsModified() bool {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
 	return d.modified
 }
 
+// setModified records whether the document has unsaved changes.
+func (d *Document) setModified(modified bool) {
+	d.stateMu.Lock()
+	d.modified = modified
+	d.stateMu.Unlock()
+}
+
 // Save saves the document to its original path.
 func (d *Document) Save() error {
-	if d.path == "" {
+	path := d.Path()
+	if path == "" {
 		return errors.New("no file path set")
 	}
-	return d.SaveAs(d.path)
+	return d.SaveAs(path)
 }
 
 // SaveAs saves the document to the specified path.
 func (d *Document) SaveAs(path string) error {
-	if d.ctx == nil {
+	if d.context() == nil {
 		return errors.New("no document loaded")
 	}
 
-	err := api.WriteContextFile(d.ctx, path)
+	d.renderMu.Lock()
+	err := syncXMP(d.ctx)
+	if err == nil {
+		err = api.WriteContextFile(d.ctx, path)
+	}
+	d.renderMu.Unlock()
 	if err != nil {
 		return err
 	}
 
+	d.stateMu.Lock()
 	d.path = path
 	d.modified = false
+	d.stateMu.Unlock()
 	return nil
 }
 
@@ -168,35 +303,157 @@ func (d *Document) SaveAs(path string) error {
 // pageNum is 0-indexed.
 // scale is the zoom factor (1.0 = 100%).
 func (d *Document) RenderPage(pageNum int, scale float64) (image.Image, error) {
-	if d.ctx == nil {
+	if d.context() == nil {
 		return nil, errors.New("no document loaded")
 	}
 
-	if pageNum < 0 || pageNum >= d.pageCount {
+	if pageNum < 0 || pageNum >= d.PageCount() {
 		return nil, errors.New("page number out of range")
 	}
 
-	// Try to use poppler renderer if available
-	if renderer.CanRender() {
-		// Convert scale to DPI (1.0 = 72 DPI, 2.0 = 144 DPI)
-		dpi := int(72 * scale)
-		if dpi < 36 {
-			dpi = 36
+	if img, ok := d.CachedPage(pageNum, scale); ok {
+		return img, nil
+	}
+
+	img, rendered := d.renderUncached(pageNum, scale)
+	if rendered {
+		d.storeCached(pageNum, scale, img)
+	}
+	return img, nil
+}
+
+// renderUncached renders a page with the active renderer, falling back to a
+// placeholder when rendering fails. rendered reports whether img is the page
+// itself; placeholders are never cached so the page is retried later.
+func (d *Document) renderUncached(pageNum int, scale float64) (img image.Image, rendered bool) {
+	if img, err := d.renderBackendPage(pageNum, scale); err == nil {
+		return img, true
+	}
+	// Fall through to placeholder on error
+
+	width, height, err := d.GetPageSize(pageNum)
+	if err != nil {
+		width, height = letterRect.Width(), letterRect.Height()
+	}
+	return placeholderPage(width, height, scale), false
+}
+
+// renderBackendPage renders a page with the active renderer, bypassing the
+// render cache and reporting failures instead of drawing a placeholder.
+func (d *Document) renderBackendPage(pageNum int, scale float64) (image.Image, error) {
+	renderer := d.Renderer()
+	if !renderer.CanRender() {
+		return nil, errors.New("no rendering backend available")
+	}
+	unlock := d.lockRender(renderer)
+	defer unlock()
+	return renderer.renderSourcePage(d.source(), pageNum, scaleToDPI(scale))
+}
+
+func (d *Document) storeCached(pageNum int, scale float64, img image.Image) {
+	if d.cache != nil {
+		d.cache.Put(d.cacheKey(pageNum, scaleToDPI(scale)), img)
+	}
+}
+
+// RenderPages renders pages first..last (inclusive, 0-indexed) at one zoom
+// factor and calls fn for each page in order. The range is rendered in a
+// single backend pass where possible; pages the backend fails to produce are
+// rendered individually, falling back to placeholders like RenderPage.
+func (d *Document) RenderPages(first, last int, scale float64, fn PageImageFunc) error {
+	if d.context() == nil {
+		return errors.New("no document loaded")
+	}
+
+	if first < 0 || last >= d.PageCount() || first > last {
+		return errors.New("page range out of range")
+	}
+
+	// Serve cached pages directly and batch-render each uncached run.
+	for pageNum := first; pageNum <= last; {
+		if img, ok := d.CachedPage(pageNum, scale); ok {
+			if err := fn(pageNum, img); err != nil {
+				return err
+			}
+			pageNum++
+			continue
+		}
+
+		runEnd := pageNum
+		for runEnd < last && !d.isCached(runEnd+1, scale) {
+			runEnd++
 		}
-		if dpi > 300 {
-			dpi = 300
+		if err := d.renderRun(pageNum, runEnd, scale, fn); err != nil {
+			return err
 		}
+		pageNum = runEnd + 1
+	}
+	return nil
+}
 
-		img, err := renderer.RenderPage(d.path, pageNum, dpi)
-		if err == nil {
-			return img, nil
+// renderRun renders first..last in one backend pass, rendering pages the
+// backend did not deliver individually.
+func (d *Document) renderRun(first, last int, scale float64, fn PageImageFunc) error {
+	next := first
+	renderer := d.Renderer()
+	if renderer.CanRender() {
+		var fnErr error
+		unlock := d.lockRender(renderer)
+		renderer.renderSourcePages(d.source(), first, last, scaleToDPI(scale), func(pageNum int, img image.Image) error {
+			d.storeCached(pageNum, scale, img)
+			if fnErr = fn(pageNum, img); fnErr != nil {
+				return fnErr
+			}
+			next = pageNum + 1
+			return nil
+		})
+		unlock()
+		if fnErr != nil {
+			return fnErr
 		}
-		// Fall through to placeholder on error
+		// Backend failures fall through to per-page rendering below.
 	}
 
-	// Fallback: return a placeholder image
-	width := int(612 * scale)  // Letter size width in points
-	height := int(792 * scale) // Letter size height in points
+	for ; next <= last; next++ {
+		img, rendered := d.renderUncached(next, scale)
+		if rendered {
+			d.storeCached(next, scale, img)
+		}
+		if err := fn(next, img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockRender serializes in-process rendering, which decodes streams in d.ctx.
+// External backends work on the file and need no lock.
+func (d *Document) lockRender(r *Renderer) func() {
+	if r.Capabilities().External {
+		return func() {}
+	}
+	d.renderMu.Lock()
+	return d.renderMu.Unlock
+}
+
+// scaleToDPI converts a zoom factor to a render resolution
+// (1.0 = 72 DPI, 2.0 = 144 DPI), clamped to a sane range.
+func scaleToDPI(scale float64) int {
+	dpi := int(72 * scale)
+	if dpi < 36 {
+		dpi = 36
+	}
+	if dpi > 300 {
+		dpi = 300
+	}
+	return dpi
+}
+
+// placeholderPage returns a blank page of the given size in points with a
+// light border.
+func placeholderPage(widthPt, heightPt, scale float64) image.Image {
+	width := max(int(widthPt*scale), 1)
+	height := max(int(heightPt*scale), 1)
 
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
 
@@ -219,32 +476,106 @@ func (d *Document) RenderPage(pageNum int, scale float64) (image.Image, error) {
 		img.Set(width-1, y, gray)
 	}
 
-	return img, nil
+	return img
 }
 
-// GetPageSize returns the size of a page in points.
+// GetPageSize returns the displayed size of a page in points.
+// It accounts for the effective box, /UserUnit and /Rotate.
 func (d *Document) GetPageSize(pageNum int) (width, height float64, err error) {
-	if d.ctx == nil {
-		return 0, 0, errors.New("no document loaded")
+	g, err := d.PageGeometry(pageNum)
+	if err != nil {
+		return 0, 0, err
 	}
+	width, height = g.Size()
+	return width, height, nil
+}
 
-	if pageNum < 0 || pageNum >= d.pageCount {
-		return 0, 0, errors.New("page number out of range")
+// PageGeometry returns the page boxes, rotation and user unit of a page.
+// pageNum is 0-indexed.
+func (d *Document) PageGeometry(pageNum int) (PageGeometry, error) {
+	if d.context() == nil {
+		return PageGeometry{}, errors.New("no document loaded")
 	}
 
-	// Default to Letter size
-	return 612, 792, nil
+	if pageNum < 0 || pageNum >= d.PageCount() {
+		return PageGeometry{}, errors.New("page number out of range")
+	}
+
+	// Looking up the page dereferences objects, which pdfcpu caches in ctx.
+	d.renderMu.Lock()
+	defer d.renderMu.Unlock()
+	return readPageGeometry(d.ctx, pageNum)
 }
 
-// ExtractText extracts text from a page.
+// ExtractText extracts text from a page with the active renderer, falling
+// back to PageText when the renderer and pdftotext cannot.
 func (d *Document) ExtractText(pageNum int) (string, error) {
-	if d.ctx == nil {
+	if d.context() == nil {
 		return "", errors.New("no document loaded")
 	}
 
-	if pageNum < 0 || pageNum >= d.pageCount {
+	if pageNum < 0 || pageNum >= d.PageCount() {
 		return "", errors.New("page number out of range")
 	}
 
-	return extractPageText(d.path, pageNum, d.userPassword, d.ownerPassword)
+	renderer := d.Renderer()
+	unlock := d.lockRender(renderer)
+	text, err := renderer.extractText(d.source(), pageNum)
+	unlock()
+	if err == nil {
+		return text, nil
+	}
+	// Without a text tool, read the page with the built-in interpreter.
+	pageText, textErr := d.PageText(pageNum)
+	if textErr != nil {
+		return "", err
+	}
+	return pageText.String(), nil
+}
+
+// PageText returns the positioned text of a page (0-indexed). It uses
+// pdftotext when installed and the built-in content stream interpreter
+// otherwise; only the latter reports character boxes and fonts exactly.
+func (d *Document) PageText(pageNum int) (*PageText, error) {
+	geom, err := d.PageGeometry(pageNum)
+	if err != nil {
+		return nil, err
+	}
+
+	d.textsMu.Lock()
+	cached, ok := d.texts[pageNum]
+	d.textsMu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	var text *PageText
+	if _, lookErr := textLookPath("pdftotext"); lookErr == nil {
+		text, err = extractPageTextLayout(d.Path(), pageNum, geom, d.userPassword, d.ownerPassword)
+	} else {
+		d.renderMu.Lock()
+		text, err = extractPageTextNative(d.ctx, pageNum)
+		d.renderMu.Unlock()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d.textsMu.Lock()
+	if d.texts == nil {
+		d.texts = make(map[int]*PageText)
+	}
+	d.texts[pageNum] = text
+	d.textsMu.Unlock()
+	return text, nil
+}
+
+// Words returns the words of a page with their bounding boxes in reading
+// order. pageNum is 0-indexed.
+func (d *Document) Words(pageNum int) ([]TextWord, error) {
+	text, err := d.PageText(pageNum)
+	if err != nil {
+		return nil, err
+	}
+	return text.Words(), nil
 }
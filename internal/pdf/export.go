@@ -3,6 +3,7 @@ package pdf
 import (
 	"errors"
 	"fmt"
+	"image"
 	"image/jpeg"
 	"image/png"
 	"os"
@@ -47,37 +48,43 @@ func (e *ImageExporter) ExportToImages(inputPath, outputDir, format string, scal
 	defer doc.Close()
 
 	outFiles := make([]string, 0, doc.PageCount())
-	for i := 0; i < doc.PageCount(); i++ {
-		img, err := doc.RenderPage(i, scale)
-		if err != nil {
-			return nil, fmt.Errorf("render page %d: %w", i+1, err)
-		}
-
-		outPath := filepath.Join(outputDir, fmt.Sprintf("page-%03d.%s", i+1, format))
-		f, err := os.Create(outPath)
-		if err != nil {
-			return nil, err
-		}
+	if doc.PageCount() == 0 {
+		return outFiles, nil
+	}
 
-		if format == "png" {
-			err = png.Encode(f, img)
-		} else {
-			err = jpeg.Encode(f, img, &jpeg.Options{Quality: 92})
-		}
-		closeErr := f.Close()
-		if err != nil {
-			return nil, err
+	err = doc.RenderPages(0, doc.PageCount()-1, scale, func(pageNum int, img image.Image) error {
+		outPath := filepath.Join(outputDir, fmt.Sprintf("page-%03d.%s", pageNum+1, format))
+		if err := writeImageFile(outPath, img, format); err != nil {
+			return fmt.Errorf("write page %d: %w", pageNum+1, err)
 		}
-		if closeErr != nil {
-			return nil, closeErr
-		}
-
 		outFiles = append(outFiles, outPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return outFiles, nil
 }
 
+func writeImageFile(path string, img image.Image, format string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if format == "png" {
+		err = png.Encode(f, img)
+	} else {
+		err = jpeg.Encode(f, img, &jpeg.Options{Quality: 92})
+	}
+	closeErr := f.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
 func normalizeImageFormat(format string) string {
 	format = strings.ToLower(strings.TrimSpace(format))
 	if format == "jpeg" {
@@ -0,0 +1,484 @@
+package pdf
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"unicode/utf16"
+
+	pdffont "github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Font descriptor flags (PDF 32000-1, table 123).
+const (
+	fontFlagFixedPitch = 1 << 0
+	fontFlagItalic     = 1 << 6
+	fontFlagForceBold  = 1 << 18
+)
+
+// pdfFont holds what the native interpreter needs to decode, measure and draw text.
+type pdfFont struct {
+	baseFont     string
+	composite    bool
+	firstChar    int
+	widths       []float64
+	cidWidths    map[int]float64
+	defaultWidth float64
+	encoding     [256]rune
+	toUnicode    map[int]string
+	ascent       float64 // glyph space units above the baseline
+	descent      float64 // glyph space units below the baseline, negative
+	face         *sfnt.Font
+	buf          sfnt.Buffer
+}
+
+// fontGlyph is one decoded character code of a shown string.
+type fontGlyph struct {
+	code  int
+	text  string
+	width float64 // glyph space units (1/1000 text space)
+	space bool    // single-byte code 32, subject to word spacing
+}
+
+var (
+	goFaceOnce sync.Once
+	goFaces    map[string]*sfnt.Font
+)
+
+func loadGoFaces() {
+	goFaceOnce.Do(func() {
+		goFaces = map[string]*sfnt.Font{}
+		sources := map[string][]byte{
+			"regular":    goregular.TTF,
+			"bold":       gobold.TTF,
+			"italic":     goitalic.TTF,
+			"bolditalic": gobolditalic.TTF,
+			"mono":       gomono.TTF,
+			"monobold":   gomonobold.TTF,
+		}
+		for name, ttf := range sources {
+			if f, err := sfnt.Parse(ttf); err == nil {
+				goFaces[name] = f
+			}
+		}
+	})
+}
+
+// substituteFace picks a built-in Go font that approximates a PDF font.
+func substituteFace(baseFont string, flags int) *sfnt.Font {
+	loadGoFaces()
+
+	lower := strings.ToLower(baseFont)
+	bold := strings.Contains(lower, "bold") || strings.Contains(lower, "black") || strings.Contains(lower, "heavy") || flags&fontFlagForceBold != 0
+	italic := strings.Contains(lower, "italic") || strings.Contains(lower, "oblique") || flags&fontFlagItalic != 0
+	mono := strings.Contains(lower, "courier") || strings.Contains(lower, "mono") || flags&fontFlagFixedPitch != 0
+
+	key := "regular"
+	switch {
+	case mono && bold:
+		key = "monobold"
+	case mono:
+		key = "mono"
+	case bold && italic:
+		key = "bolditalic"
+	case bold:
+		key = "bold"
+	case italic:
+		key = "italic"
+	}
+	return goFaces[key]
+}
+
+// stripSubsetPrefix removes the "ABCDEF+" prefix of subsetted font names.
+func stripSubsetPrefix(name string) string {
+	if len(name) > 7 && name[6] == '+' {
+		return name[7:]
+	}
+	return name
+}
+
+// defaultPDFFont is used when a content stream selects a font that is not in the resources.
+func defaultPDFFont() *pdfFont {
+	f := &pdfFont{baseFont: "Helvetica", defaultWidth: 500, ascent: 718, descent: -207}
+	f.encoding = baseEncoding("WinAnsiEncoding")
+	f.face = substituteFace(f.baseFont, 0)
+	return f
+}
+
+// loadPDFFont builds a pdfFont from a font dictionary.
+func loadPDFFont(xRefTable *model.XRefTable, fontDict types.Dict) *pdfFont {
+	f := &pdfFont{defaultWidth: 1000, ascent: 800, descent: -200}
+	if name := fontDict.NameEntry("BaseFont"); name != nil {
+		f.baseFont = stripSubsetPrefix(*name)
+	}
+
+	flags := 0
+	subtype := ""
+	if st := fontDict.Subtype(); st != nil {
+		subtype = *st
+	}
+
+	descriptorSource := fontDict
+	if subtype == "Type0" {
+		f.composite = true
+		if arr, err := xRefTable.DereferenceArray(fontDict["DescendantFonts"]); err == nil && len(arr) > 0 {
+			if desc, err := xRefTable.DereferenceDict(arr[0]); err == nil && desc != nil {
+				descriptorSource = desc
+				f.loadCIDWidths(xRefTable, desc)
+			}
+		}
+	} else {
+		f.loadSimpleWidths(xRefTable, fontDict)
+		f.loadEncoding(xRefTable, fontDict)
+	}
+
+	if fd, err := xRefTable.DereferenceDict(descriptorSource["FontDescriptor"]); err == nil && fd != nil {
+		if v, err := xRefTable.DereferenceNumber(fd["Flags"]); err == nil {
+			flags = int(v)
+		}
+		if v, err := xRefTable.DereferenceNumber(fd["MissingWidth"]); err == nil && v > 0 && !f.composite {
+			f.defaultWidth = v
+		}
+		ascent, errA := xRefTable.DereferenceNumber(fd["Ascent"])
+		descent, errD := xRefTable.DereferenceNumber(fd["Descent"])
+		if errA == nil && errD == nil && ascent > descent {
+			f.ascent, f.descent = ascent, math.Min(descent, 0)
+		}
+	}
+
+	if sd, _, err := xRefTable.DereferenceStreamDict(fontDict["ToUnicode"]); err == nil && sd != nil {
+		if err := sd.Decode(); err == nil {
+			f.toUnicode = parseToUnicodeCMap(sd.Content)
+		}
+	}
+
+	f.face = substituteFace(f.baseFont, flags)
+	return f
+}
+
+func (f *pdfFont) loadSimpleWidths(xRefTable *model.XRefTable, fontDict types.Dict) {
+	if v, err := xRefTable.DereferenceNumber(fontDict["FirstChar"]); err == nil {
+		f.firstChar = int(v)
+	}
+	arr, err := xRefTable.DereferenceArray(fontDict["Widths"])
+	if err != nil || arr == nil {
+		return
+	}
+	f.widths = make([]float64, len(arr))
+	for i, o := range arr {
+		if v, err := xRefTable.DereferenceNumber(o); err == nil {
+			f.widths[i] = v
+		}
+	}
+}
+
+func (f *pdfFont) loadCIDWidths(xRefTable *model.XRefTable, desc types.Dict) {
+	if v, err := xRefTable.DereferenceNumber(desc["DW"]); err == nil {
+		f.defaultWidth = v
+	}
+	arr, err := xRefTable.DereferenceArray(desc["W"])
+	if err != nil || arr == nil {
+		return
+	}
+
+	f.cidWidths = map[int]float64{}
+	for i := 0; i < len(arr); {
+		first, err := xRefTable.DereferenceNumber(arr[i])
+		if err != nil || i+1 >= len(arr) {
+			return
+		}
+		next, _ := xRefTable.Dereference(arr[i+1])
+		if list, ok := next.(types.Array); ok {
+			for j, o := range list {
+				if w, err := xRefTable.DereferenceNumber(o); err == nil {
+					f.cidWidths[int(first)+j] = w
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(arr) {
+			return
+		}
+		last, err1 := xRefTable.DereferenceNumber(arr[i+1])
+		w, err2 := xRefTable.DereferenceNumber(arr[i+2])
+		if err1 != nil || err2 != nil {
+			return
+		}
+		for c := int(first); c <= int(last) && c-int(first) < 65536; c++ {
+			f.cidWidths[c] = w
+		}
+		i += 3
+	}
+}
+
+func (f *pdfFont) loadEncoding(xRefTable *model.XRefTable, fontDict types.Dict) {
+	base := "StandardEncoding"
+	lowerName := strings.ToLower(f.baseFont)
+	if strings.Contains(lowerName, "symbol") || strings.Contains(lowerName, "dingbats") {
+		base = ""
+	}
+
+	enc, _ := xRefTable.Dereference(fontDict["Encoding"])
+	var differences types.Array
+	switch v := enc.(type) {
+	case types.Name:
+		base = string(v)
+	case types.Dict:
+		if n := v.NameEntry("BaseEncoding"); n != nil {
+			base = *n
+		}
+		differences, _ = xRefTable.DereferenceArray(v["Differences"])
+	}
+
+	f.encoding = baseEncoding(base)
+
+	code := 0
+	for _, o := range differences {
+		switch v := o.(type) {
+		case types.Integer:
+			code = int(v)
+		case types.Float:
+			code = int(v)
+		case types.Name:
+			if code >= 0 && code < 256 {
+				if r, ok := glyphNameToRune(string(v)); ok {
+					f.encoding[code] = r
+				}
+			}
+			code++
+		}
+	}
+}
+
+// baseEncoding returns the code-to-rune table of a predefined simple font encoding.
+func baseEncoding(name string) [256]rune {
+	var table [256]rune
+	switch name {
+	case "WinAnsiEncoding":
+		for i := 0; i < 256; i++ {
+			table[i] = charmap.Windows1252.DecodeByte(byte(i))
+		}
+	case "MacRomanEncoding":
+		for i := 0; i < 256; i++ {
+			table[i] = charmap.Macintosh.DecodeByte(byte(i))
+		}
+	case "StandardEncoding":
+		for i := 0; i < 256; i++ {
+			table[i] = charmap.Windows1252.DecodeByte(byte(i))
+		}
+		table['\''] = '’'
+		table['`'] = '‘'
+	default:
+		for i := 0; i < 256; i++ {
+			table[i] = rune(i)
+		}
+	}
+	return table
+}
+
+// decode splits a shown string into glyphs with their widths and Unicode text.
+func (f *pdfFont) decode(b []byte) []fontGlyph {
+	if f.composite {
+		glyphs := make([]fontGlyph, 0, len(b)/2)
+		for i := 0; i+1 < len(b); i += 2 {
+			code := int(b[i])<<8 | int(b[i+1])
+			w, ok := f.cidWidths[code]
+			if !ok {
+				w = f.defaultWidth
+			}
+			glyphs = append(glyphs, fontGlyph{code: code, text: f.toUnicode[code], width: w})
+		}
+		return glyphs
+	}
+
+	glyphs := make([]fontGlyph, 0, len(b))
+	for _, c := range b {
+		code := int(c)
+		text, ok := f.toUnicode[code]
+		if !ok {
+			if r := f.encoding[code]; r != 0 {
+				text = string(r)
+			}
+		}
+		glyphs = append(glyphs, fontGlyph{
+			code:  code,
+			text:  text,
+			width: f.simpleWidth(code, text),
+			space: code == 32,
+		})
+	}
+	return glyphs
+}
+
+func (f *pdfFont) simpleWidth(code int, text string) float64 {
+	if idx := code - f.firstChar; f.widths != nil && idx >= 0 && idx < len(f.widths) {
+		return f.widths[idx]
+	}
+	if pdffont.IsCoreFont(f.baseFont) {
+		return float64(pdffont.CharWidth(f.baseFont, rune(code)))
+	}
+	if f.face != nil && text != "" {
+		r := []rune(text)[0]
+		if idx, err := f.face.GlyphIndex(&f.buf, r); err == nil && idx != 0 {
+			upem := float64(f.face.UnitsPerEm())
+			if adv, err := f.face.GlyphAdvance(&f.buf, idx, fixed.I(int(upem)), 0); err == nil {
+				return float64(adv) / 64 * 1000 / upem
+			}
+		}
+	}
+	return f.defaultWidth
+}
+
+// parseToUnicodeCMap extracts bfchar and bfrange mappings from a ToUnicode CMap.
+func parseToUnicodeCMap(data []byte) map[int]string {
+	ops, _ := parseContentStream(data)
+	m := map[int]string{}
+
+	for _, op := range ops {
+		switch op.operator {
+		case "endbfchar":
+			for i := 0; i+1 < len(op.operands); i += 2 {
+				src, ok1 := operandBytes(op.operands[i])
+				dst, ok2 := operandBytes(op.operands[i+1])
+				if ok1 && ok2 {
+					m[bytesToCode(src)] = utf16BytesToString(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(op.operands); i += 3 {
+				lo, ok1 := operandBytes(op.operands[i])
+				hi, ok2 := operandBytes(op.operands[i+1])
+				if !ok1 || !ok2 {
+					continue
+				}
+				start, end := bytesToCode(lo), bytesToCode(hi)
+				if end < start || end-start > 65535 {
+					continue
+				}
+				if arr, ok := op.operands[i+2].(types.Array); ok {
+					for j, o := range arr {
+						if dst, ok := operandBytes(o); ok && start+j <= end {
+							m[start+j] = utf16BytesToString(dst)
+						}
+					}
+					continue
+				}
+				dst, ok := operandBytes(op.operands[i+2])
+				if !ok || len(dst) == 0 {
+					continue
+				}
+				for c := start; c <= end; c++ {
+					m[c] = utf16BytesToString(dst)
+					dst = incrementUTF16(dst)
+				}
+			}
+		}
+	}
+	return m
+}
+
+func bytesToCode(b []byte) int {
+	code := 0
+	for _, c := range b {
+		code = code<<8 | int(c)
+	}
+	return code
+}
+
+func utf16BytesToString(b []byte) string {
+	if len(b)%2 == 1 {
+		return string(rune(b[0]))
+	}
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+func incrementUTF16(b []byte) []byte {
+	out := append([]byte(nil), b...)
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i]++
+		if out[i] != 0 {
+			break
+		}
+	}
+	return out
+}
+
+// glyphNameToRune maps Adobe glyph names used in Differences arrays to Unicode.
+func glyphNameToRune(name string) (rune, bool) {
+	if r, ok := glyphNames[name]; ok {
+		return r, true
+	}
+	if len(name) == 1 {
+		return rune(name[0]), true
+	}
+	if strings.HasPrefix(name, "uni") && len(name) == 7 {
+		if v, ok := parseHexRune(name[3:]); ok {
+			return v, true
+		}
+	}
+	if strings.HasPrefix(name, "u") && (len(name) == 5 || len(name) == 6 || len(name) == 7) {
+		if v, ok := parseHexRune(name[1:]); ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+func parseHexRune(s string) (rune, bool) {
+	var v rune
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			v = v<<4 | (c - '0')
+		case c >= 'A' && c <= 'F':
+			v = v<<4 | (c - 'A' + 10)
+		case c >= 'a' && c <= 'f':
+			v = v<<4 | (c - 'a' + 10)
+		default:
+			return 0, false
+		}
+	}
+	return v, true
+}
+
+var glyphNames = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$',
+	"percent": '%', "ampersand": '&', "quotesingle": '\'', "parenleft": '(', "parenright": ')',
+	"asterisk": '*', "plus": '+', "comma": ',', "hyphen": '-', "period": '.', "slash": '/',
+	"zero": '0', "one": '1', "two": '2', "three": '3', "four": '4', "five": '5', "six": '6',
+	"seven": '7', "eight": '8', "nine": '9', "colon": ':', "semicolon": ';', "less": '<',
+	"equal": '=', "greater": '>', "question": '?', "at": '@', "bracketleft": '[',
+	"backslash": '\\', "bracketright": ']', "asciicircum": '^', "underscore": '_',
+	"grave": '`', "braceleft": '{', "bar": '|', "braceright": '}', "asciitilde": '~',
+	"quoteleft": '‘', "quoteright": '’', "quotedblleft": '“', "quotedblright": '”',
+	"quotesinglbase": '‚', "quotedblbase": '„', "endash": '–', "emdash": '—',
+	"bullet": '•', "ellipsis": '…', "dagger": '†', "daggerdbl": '‡',
+	"trademark": '™', "copyright": '©', "registered": '®', "degree": '°',
+	"section": '§', "paragraph": '¶', "fi": 'ﬁ', "fl": 'ﬂ', "ff": 'ﬀ',
+	"ffi": 'ﬃ', "ffl": 'ﬄ', "Euro": '€', "minus": '−', "multiply": '×',
+	"divide": '÷', "periodcentered": '·', "nbspace": ' ', "sterling": '£',
+	"yen": '¥', "cent": '¢', "florin": 'ƒ', "guillemotleft": '«',
+	"guillemotright": '»', "guilsinglleft": '‹', "guilsinglright": '›',
+	"exclamdown": '¡', "questiondown": '¿', "germandbls": 'ß',
+	"adieresis": 'ä', "odieresis": 'ö', "udieresis": 'ü',
+	"Adieresis": 'Ä', "Odieresis": 'Ö', "Udieresis": 'Ü',
+	"eacute": 'é', "egrave": 'è', "ecircumflex": 'ê', "aacute": 'á',
+	"agrave": 'à', "acircumflex": 'â', "ccedilla": 'ç', "ntilde": 'ñ',
+	"oacute": 'ó', "uacute": 'ú', "iacute": 'í', "dotlessi": 'ı',
+}
@@ -0,0 +1,311 @@
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Rect is a rectangle in PDF user space (origin bottom-left, y up).
+type Rect struct {
+	LLX, LLY, URX, URY float64
+}
+
+// Point is a point in PDF user space.
+type Point struct {
+	X, Y float64
+}
+
+// Width returns the horizontal extent of r.
+func (r Rect) Width() float64 {
+	return r.URX - r.LLX
+}
+
+// Height returns the vertical extent of r.
+func (r Rect) Height() float64 {
+	return r.URY - r.LLY
+}
+
+// Empty reports whether r has no area.
+func (r Rect) Empty() bool {
+	return r.Width() <= 0 || r.Height() <= 0
+}
+
+// Intersect returns the overlap of r and o, or an empty Rect.
+func (r Rect) Intersect(o Rect) Rect {
+	out := Rect{
+		LLX: math.Max(r.LLX, o.LLX),
+		LLY: math.Max(r.LLY, o.LLY),
+		URX: math.Min(r.URX, o.URX),
+		URY: math.Min(r.URY, o.URY),
+	}
+	if out.Empty() {
+		return Rect{}
+	}
+	return out
+}
+
+// Union returns the smallest rectangle containing r and o. An empty
+// rectangle does not contribute.
+func (r Rect) Union(o Rect) Rect {
+	if r.Empty() {
+		return o
+	}
+	if o.Empty() {
+		return r
+	}
+	return Rect{
+		LLX: math.Min(r.LLX, o.LLX),
+		LLY: math.Min(r.LLY, o.LLY),
+		URX: math.Max(r.URX, o.URX),
+		URY: math.Max(r.URY, o.URY),
+	}
+}
+
+func (r Rect) rectangle() *types.Rectangle {
+	return types.NewRectangle(r.LLX, r.LLY, r.URX, r.URY)
+}
+
+// letterRect is the page box assumed when a document does not declare one.
+var letterRect = Rect{URX: 612, URY: 792}
+
+// PageGeometry describes the page boxes of one page (PDF 32000-1, 14.11.2).
+// Missing boxes are filled with their defaults and all boxes are clipped to
+// the MediaBox.
+type PageGeometry struct {
+	MediaBox Rect
+	CropBox  Rect
+	BleedBox Rect
+	TrimBox  Rect
+	ArtBox   Rect
+	Rotate   int     // clockwise display rotation: 0, 90, 180 or 270
+	UserUnit float64 // size of a user space unit in points, usually 1
+}
+
+// EffectiveBox returns the visible region of the page (the CropBox).
+func (g PageGeometry) EffectiveBox() Rect {
+	return g.CropBox
+}
+
+// Size returns the displayed page size in points, taking the effective box,
+// /UserUnit and /Rotate into account.
+func (g PageGeometry) Size() (width, height float64) {
+	unit := g.UserUnit
+	if unit <= 0 {
+		unit = 1
+	}
+	box := g.EffectiveBox()
+	width, height = box.Width()*unit, box.Height()*unit
+	if g.Rotate == 90 || g.Rotate == 270 {
+		width, height = height, width
+	}
+	return width, height
+}
+
+// UserToDisplay maps a rectangle in PDF user space to display space: points
+// on the page as shown, with the origin at the top-left corner and
+// /Rotate and /UserUnit applied. In the result, LLX/LLY is the top-left
+// corner.
+func (g PageGeometry) UserToDisplay(r Rect) Rect {
+	x1, y1 := g.userPointToDisplay(r.LLX, r.LLY)
+	x2, y2 := g.userPointToDisplay(r.URX, r.URY)
+	return orderedRect(x1, y1, x2, y2)
+}
+
+// DisplayToUser is the inverse of UserToDisplay.
+func (g PageGeometry) DisplayToUser(r Rect) Rect {
+	x1, y1 := g.displayPointToUser(r.LLX, r.LLY)
+	x2, y2 := g.displayPointToUser(r.URX, r.URY)
+	return orderedRect(x1, y1, x2, y2)
+}
+
+// DisplayPointToUser maps a point in display space to PDF user space.
+func (g PageGeometry) DisplayPointToUser(p Point) Point {
+	x, y := g.displayPointToUser(p.X, p.Y)
+	return Point{X: x, Y: y}
+}
+
+// DestinationAt returns an XYZ destination that shows page (0-indexed) from
+// top display points below its top edge, keeping the zoom.
+func (g PageGeometry) DestinationAt(page int, top float64) Destination {
+	dest := Destination{Page: page, Fit: "XYZ"}
+	x, y := g.displayPointToUser(0, top)
+	if g.Rotate == 90 || g.Rotate == 270 {
+		// The display's vertical axis runs along user space x.
+		dest.Left = &x
+	} else {
+		dest.Top = &y
+	}
+	return dest
+}
+
+// DisplayTop returns how far below the top edge of the displayed page a
+// destination's top is, in points, and false if it does not set one.
+func (g PageGeometry) DisplayTop(dest Destination) (float64, bool) {
+	box := g.EffectiveBox()
+	var y float64
+	switch {
+	case (g.Rotate == 90 || g.Rotate == 270) && dest.Left != nil:
+		_, y = g.userPointToDisplay(*dest.Left, box.LLY)
+	case g.Rotate != 90 && g.Rotate != 270 && dest.Top != nil:
+		_, y = g.userPointToDisplay(box.LLX, *dest.Top)
+	default:
+		return 0, false
+	}
+	_, height := g.Size()
+	return max(0, min(y, height)), true
+}
+
+func (g PageGeometry) unit() float64 {
+	if g.UserUnit <= 0 {
+		return 1
+	}
+	return g.UserUnit
+}
+
+func (g PageGeometry) userPointToDisplay(x, y float64) (float64, float64) {
+	box := g.EffectiveBox()
+	w, h := box.Width(), box.Height()
+	// Unrotated, top-left origin.
+	x, y = x-box.LLX, box.URY-y
+	switch g.Rotate {
+	case 90:
+		x, y = h-y, x
+	case 180:
+		x, y = w-x, h-y
+	case 270:
+		x, y = y, w-x
+	}
+	return x * g.unit(), y * g.unit()
+}
+
+func (g PageGeometry) displayPointToUser(x, y float64) (float64, float64) {
+	box := g.EffectiveBox()
+	w, h := box.Width(), box.Height()
+	x, y = x/g.unit(), y/g.unit()
+	switch g.Rotate {
+	case 90:
+		x, y = y, h-x
+	case 180:
+		x, y = w-x, h-y
+	case 270:
+		x, y = w-y, x
+	}
+	return box.LLX + x, box.URY - y
+}
+
+// defaultPageGeometry is a Letter page without rotation.
+func defaultPageGeometry() PageGeometry {
+	return PageGeometry{
+		MediaBox: letterRect,
+		CropBox:  letterRect,
+		BleedBox: letterRect,
+		TrimBox:  letterRect,
+		ArtBox:   letterRect,
+		UserUnit: 1,
+	}
+}
+
+// readPageGeometry returns the geometry of a page (0-indexed).
+func readPageGeometry(ctx *model.Context, pageNum int) (PageGeometry, error) {
+	if ctx == nil {
+		return PageGeometry{}, errors.New("no document loaded")
+	}
+	pageDict, _, inh, err := ctx.PageDict(pageNum+1, false)
+	if err != nil {
+		return PageGeometry{}, err
+	}
+	if pageDict == nil || inh == nil {
+		return PageGeometry{}, fmt.Errorf("page %d not found", pageNum+1)
+	}
+	return pageGeometryFromDict(ctx.XRefTable, pageDict, inh), nil
+}
+
+// readPageGeometryFile reads the geometry of a page from a PDF file.
+// It is a variable so tests can avoid touching the file system.
+var readPageGeometryFile = func(path string, pageNum int) (PageGeometry, error) {
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		return PageGeometry{}, err
+	}
+	return readPageGeometry(ctx, pageNum)
+}
+
+func pageGeometryFromDict(xRefTable *model.XRefTable, pageDict types.Dict, inh *model.InheritedPageAttrs) PageGeometry {
+	g := defaultPageGeometry()
+
+	if inh.MediaBox != nil {
+		g.MediaBox = normalizedRect(inh.MediaBox)
+	}
+	if g.MediaBox.Empty() {
+		g.MediaBox = letterRect
+	}
+
+	g.CropBox = clipBox(rectFromRectangle(inh.CropBox), g.MediaBox, g.MediaBox)
+	g.BleedBox = clipBox(pageBox(xRefTable, pageDict, "BleedBox"), g.MediaBox, g.CropBox)
+	g.TrimBox = clipBox(pageBox(xRefTable, pageDict, "TrimBox"), g.MediaBox, g.CropBox)
+	g.ArtBox = clipBox(pageBox(xRefTable, pageDict, "ArtBox"), g.MediaBox, g.CropBox)
+
+	g.Rotate = ((inh.Rotate % 360) + 360) % 360
+	g.Rotate -= g.Rotate % 90
+
+	if v, err := xRefTable.DereferenceNumber(pageDict["UserUnit"]); err == nil && v > 0 {
+		g.UserUnit = v
+	}
+	return g
+}
+
+// clipBox clips box to media, substituting def for missing or degenerate boxes.
+func clipBox(box *Rect, media, def Rect) Rect {
+	if box == nil {
+		return def
+	}
+	clipped := box.Intersect(media)
+	if clipped.Empty() {
+		return def
+	}
+	return clipped
+}
+
+func pageBox(xRefTable *model.XRefTable, pageDict types.Dict, key string) *Rect {
+	arr, err := xRefTable.DereferenceArray(pageDict[key])
+	if err != nil || len(arr) != 4 {
+		return nil
+	}
+	var v [4]float64
+	for i, o := range arr {
+		n, err := xRefTable.DereferenceNumber(o)
+		if err != nil {
+			return nil
+		}
+		v[i] = n
+	}
+	r := normalizedRect(types.NewRectangle(v[0], v[1], v[2], v[3]))
+	return &r
+}
+
+func rectFromRectangle(r *types.Rectangle) *Rect {
+	if r == nil {
+		return nil
+	}
+	out := normalizedRect(r)
+	return &out
+}
+
+func orderedRect(x1, y1, x2, y2 float64) Rect {
+	return Rect{
+		LLX: math.Min(x1, x2),
+		LLY: math.Min(y1, y2),
+		URX: math.Max(x1, x2),
+		URY: math.Max(y1, y2),
+	}
+}
+
+// normalizedRect orders the corners so that LL is below and left of UR.
+func normalizedRect(r *types.Rectangle) Rect {
+	return orderedRect(r.LL.X, r.LL.Y, r.UR.X, r.UR.Y)
+}
@@ -0,0 +1,160 @@
+package pdf
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func openContentPDF(t *testing.T, pageAttrs string) *Document {
+	t.Helper()
+	doc, err := Open(writeContentPDF(t, pageAttrs, ""))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	t.Cleanup(func() { doc.Close() })
+	return doc
+}
+
+func TestDocumentPageGeometryA4(t *testing.T) {
+	doc := openContentPDF(t, "[0 0 595 842]")
+
+	g, err := doc.PageGeometry(0)
+	if err != nil {
+		t.Fatalf("PageGeometry() returned error: %v", err)
+	}
+	want := Rect{URX: 595, URY: 842}
+	if g.MediaBox != want || g.CropBox != want || g.TrimBox != want || g.BleedBox != want || g.ArtBox != want {
+		t.Errorf("boxes = %+v, want all %+v", g, want)
+	}
+	if g.Rotate != 0 || g.UserUnit != 1 {
+		t.Errorf("Rotate = %d, UserUnit = %v; want 0, 1", g.Rotate, g.UserUnit)
+	}
+
+	w, h, err := doc.GetPageSize(0)
+	if err != nil || w != 595 || h != 842 {
+		t.Errorf("GetPageSize() = %v x %v, %v; want 595 x 842", w, h, err)
+	}
+}
+
+func TestDocumentPageGeometryBoxesRotateUserUnit(t *testing.T) {
+	attrs := "[0 0 600 800] /CropBox [10 20 510 720] /TrimBox [0 0 1000 400] /ArtBox [900 900 950 950] /Rotate -90 /UserUnit 2"
+	doc := openContentPDF(t, attrs)
+
+	g, err := doc.PageGeometry(0)
+	if err != nil {
+		t.Fatalf("PageGeometry() returned error: %v", err)
+	}
+	if g.CropBox != (Rect{LLX: 10, LLY: 20, URX: 510, URY: 720}) {
+		t.Errorf("CropBox = %+v", g.CropBox)
+	}
+	// BleedBox defaults to the CropBox.
+	if g.BleedBox != g.CropBox {
+		t.Errorf("BleedBox = %+v, want CropBox", g.BleedBox)
+	}
+	// TrimBox is clipped to the MediaBox.
+	if g.TrimBox != (Rect{URX: 600, URY: 400}) {
+		t.Errorf("TrimBox = %+v, want clipped to MediaBox", g.TrimBox)
+	}
+	// An ArtBox outside the MediaBox falls back to the CropBox.
+	if g.ArtBox != g.CropBox {
+		t.Errorf("ArtBox = %+v, want CropBox", g.ArtBox)
+	}
+	if g.Rotate != 270 || g.UserUnit != 2 {
+		t.Errorf("Rotate = %d, UserUnit = %v; want 270, 2", g.Rotate, g.UserUnit)
+	}
+
+	// 500x700 crop box, doubled by UserUnit and turned sideways.
+	w, h, err := doc.GetPageSize(0)
+	if err != nil || w != 1400 || h != 1000 {
+		t.Errorf("GetPageSize() = %v x %v, %v; want 1400 x 1000", w, h, err)
+	}
+}
+
+func TestDocumentPageGeometryErrors(t *testing.T) {
+	doc := &Document{}
+	if _, err := doc.PageGeometry(0); err == nil {
+		t.Error("PageGeometry() expected error without a document")
+	}
+
+	testPDF := filepath.Join(t.TempDir(), "test.pdf")
+	if !createTestPDF(testPDF) {
+		t.Skip("Cannot create test PDF")
+	}
+	doc, err := Open(testPDF)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+	if _, err := doc.PageGeometry(1); err == nil {
+		t.Error("PageGeometry() expected error for out-of-range page")
+	}
+}
+
+func TestRenderPagePlaceholderUsesPageSize(t *testing.T) {
+	doc := openContentPDF(t, "[0 0 842 595]")
+	doc.SetRenderer(NewRendererWithBackend(nil))
+
+	img, err := doc.RenderPage(0, 1.0)
+	if err != nil {
+		t.Fatalf("RenderPage() returned error: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 842 || b.Dy() != 595 {
+		t.Errorf("placeholder size = %dx%d, want 842x595", b.Dx(), b.Dy())
+	}
+}
+
+func TestPageGeometryDisplayTransform(t *testing.T) {
+	base := PageGeometry{CropBox: Rect{LLX: 10, LLY: 20, URX: 210, URY: 320}, UserUnit: 1}
+	user := Rect{LLX: 20, LLY: 300, URX: 60, URY: 310} // near the top-left corner
+
+	tests := []struct {
+		rotate int
+		want   Rect
+	}{
+		{0, Rect{LLX: 10, LLY: 10, URX: 50, URY: 20}},
+		{90, Rect{LLX: 280, LLY: 10, URX: 290, URY: 50}},
+		{180, Rect{LLX: 150, LLY: 280, URX: 190, URY: 290}},
+		{270, Rect{LLX: 10, LLY: 150, URX: 20, URY: 190}},
+	}
+	for _, tt := range tests {
+		g := base
+		g.Rotate = tt.rotate
+		got := g.UserToDisplay(user)
+		if got != tt.want {
+			t.Errorf("Rotate %d: UserToDisplay = %+v, want %+v", tt.rotate, got, tt.want)
+		}
+		if back := g.DisplayToUser(got); back != user {
+			t.Errorf("Rotate %d: DisplayToUser = %+v, want %+v", tt.rotate, back, user)
+		}
+	}
+
+	g := base
+	g.UserUnit = 2
+	if got := g.UserToDisplay(user); got != (Rect{LLX: 20, LLY: 20, URX: 100, URY: 40}) {
+		t.Errorf("UserUnit 2: UserToDisplay = %+v", got)
+	}
+}
+
+func TestPageGeometryDestinationAt(t *testing.T) {
+	base := PageGeometry{CropBox: Rect{LLX: 10, LLY: 20, URX: 210, URY: 320}, UserUnit: 1}
+	for _, rotate := range []int{0, 90, 180, 270} {
+		g := base
+		g.Rotate = rotate
+		dest := g.DestinationAt(3, 50)
+		if dest.Page != 3 || dest.Fit != "XYZ" || (dest.Left == nil) == (dest.Top == nil) {
+			t.Errorf("Rotate %d: DestinationAt = %+v, want an XYZ destination with one coordinate", rotate, dest)
+			continue
+		}
+		if top, ok := g.DisplayTop(dest); !ok || top != 50 {
+			t.Errorf("Rotate %d: DisplayTop = %v, %v; want 50", rotate, top, ok)
+		}
+	}
+
+	top := 300.0
+	if got, ok := base.DisplayTop(Destination{Fit: "XYZ", Top: &top}); !ok || got != 20 {
+		t.Errorf("DisplayTop(top 300) = %v, %v; want 20", got, ok)
+	}
+	if _, ok := base.DisplayTop(Destination{Fit: "Fit"}); ok {
+		t.Error("DisplayTop(Fit) reported a position")
+	}
+}
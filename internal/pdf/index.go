@@ -0,0 +1,605 @@
+package pdf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// indexFileName is the name of the index inside its directory.
+const indexFileName = "index.json"
+
+// indexTextDirName is the directory inside the index directory holding the
+// page texts of each indexed file, named after its contents hash.
+const indexTextDirName = "text"
+
+// defaultIndexDirName is the directory an index is kept in inside the
+// indexed folder by default.
+const defaultIndexDirName = ".openpdfreader-index"
+
+// indexVersion changes whenever the on-disk format does.
+const indexVersion = 2
+
+// snippetContext is how many characters of text surround a match in a
+// search result snippet.
+const snippetContext = 60
+
+// Index is a persistent full-text index of the PDFs under a folder. It
+// keeps an inverted index from lower-cased terms to the pages containing
+// them in one file, and the page texts of each PDF in a file of their own,
+// read only for the snippets of search results. An update extracts and
+// writes the texts of new and changed PDFs alone.
+type Index struct {
+	dir string
+
+	Version int                       `json:"version"`
+	Root    string                    `json:"root"`
+	Files   []*IndexedFile            `json:"files"`
+	Terms   map[string][]IndexPosting `json:"terms"`
+}
+
+// IndexedFile is one PDF in an index. Path is relative to the index root
+// with forward slashes.
+type IndexedFile struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Hash    string    `json:"hash"`  // SHA-256 of the file contents
+	Pages   int       `json:"pages"` // number of pages
+
+	// text holds the page texts from their extraction until the index is
+	// saved, or once they are read for a snippet.
+	text    []string
+	unsaved bool
+}
+
+// IndexPosting is a page containing a term: an index into Index.Files and
+// a 0-indexed page number.
+type IndexPosting struct {
+	File int `json:"f"`
+	Page int `json:"p"`
+}
+
+// IndexStats summarizes an index update.
+type IndexStats struct {
+	Added     int
+	Updated   int
+	Unchanged int
+	Removed   int
+	Failed    map[string]error // relative path to extraction error
+}
+
+// IndexHit is a page matching an index search.
+type IndexHit struct {
+	Path    string // absolute path of the PDF
+	Page    int    // 0-indexed
+	Snippet string
+}
+
+// indexExtractText returns the text of every page of a PDF. It is a
+// variable so tests can index without a text extraction tool.
+var indexExtractText = func(path string) ([]string, error) {
+	doc, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+
+	pages := make([]string, doc.PageCount())
+	for i := range pages {
+		text, err := doc.ExtractText(i)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", i+1, err)
+		}
+		pages[i] = text
+	}
+	return pages, nil
+}
+
+// DefaultIndexDir returns where the index of root is kept by default.
+func DefaultIndexDir(root string) string {
+	return filepath.Join(root, defaultIndexDirName)
+}
+
+// OpenIndex loads the index stored in dir, or returns an empty index if
+// there is none yet.
+func OpenIndex(dir string) (*Index, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	ix := &Index{dir: dir, Version: indexVersion, Terms: map[string][]IndexPosting{}}
+
+	data, err := os.ReadFile(filepath.Join(dir, indexFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ix, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	switch {
+	case header.Version < indexVersion:
+		// An index in an older format is built again on the next update.
+		return ix, nil
+	case header.Version > indexVersion:
+		return nil, fmt.Errorf("unsupported index version %d", header.Version)
+	}
+	if err := json.Unmarshal(data, ix); err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	if ix.Terms == nil {
+		ix.Terms = map[string][]IndexPosting{}
+	}
+	return ix, nil
+}
+
+// Save writes the page texts extracted since the last save and then the
+// index to its directory, replacing each file atomically, and removes the
+// texts no file refers to any more.
+func (ix *Index) Save() error {
+	textDir := filepath.Join(ix.dir, indexTextDirName)
+	if err := os.MkdirAll(textDir, 0755); err != nil {
+		return err
+	}
+	for _, f := range ix.Files {
+		if !f.unsaved {
+			continue
+		}
+		data, err := json.Marshal(f.text)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(textDir, f.Hash+".json", data); err != nil {
+			return err
+		}
+		// Read back for snippets when needed.
+		f.text, f.unsaved = nil, false
+	}
+
+	data, err := json.Marshal(ix)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(ix.dir, indexFileName, data); err != nil {
+		return err
+	}
+	return ix.removeUnusedTexts(textDir)
+}
+
+// removeUnusedTexts deletes the files in textDir that hold the texts of no
+// indexed file, such as those of removed or changed PDFs.
+func (ix *Index) removeUnusedTexts(textDir string) error {
+	used := make(map[string]bool, len(ix.Files))
+	for _, f := range ix.Files {
+		used[f.Hash+".json"] = true
+	}
+	entries, err := os.ReadDir(textDir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !used[e.Name()] {
+			if err := os.Remove(filepath.Join(textDir, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pageTexts returns the page texts of f, reading them from the index
+// directory unless they are at hand.
+func (ix *Index) pageTexts(f *IndexedFile) ([]string, error) {
+	if f.text != nil {
+		return f.text, nil
+	}
+	data, err := os.ReadFile(filepath.Join(ix.dir, indexTextDirName, f.Hash+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var text []string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return nil, fmt.Errorf("failed to read index text of %s: %w", f.Path, err)
+	}
+	f.text = text
+	return text, nil
+}
+
+// writeFileAtomic writes data to the file name in dir through a temporary
+// file, so that readers see either the old or the new contents.
+func writeFileAtomic(dir, name string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, name+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
+
+// Update brings the index in line with the PDFs under root. Files whose
+// size and modification time are unchanged are kept; changed files are
+// re-extracted only if their contents hash differs. Files that cannot be
+// read are recorded in Failed and keep their previous entries. progress,
+// if not nil, is called with the relative path of each file before it is
+// extracted.
+func (ix *Index) Update(root string, progress func(path string)) (IndexStats, error) {
+	stats := IndexStats{Failed: map[string]error{}}
+
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return stats, err
+	}
+	if ix.Root != root {
+		// Relative paths mean nothing under a different root.
+		ix.Files = nil
+		ix.Terms = map[string][]IndexPosting{}
+		ix.Root = root
+	}
+
+	existing := make(map[string]*IndexedFile, len(ix.Files))
+	for _, f := range ix.Files {
+		existing[f.Path] = f
+	}
+
+	var (
+		files   []*IndexedFile
+		pending []*IndexedFile
+		updated = map[string]bool{}
+	)
+	// keep carries over the entry of rel, and of the files under rel if it
+	// is a directory, when they cannot be read this time.
+	keep := func(rel string, dir bool) {
+		if f := existing[rel]; f != nil {
+			files = append(files, f)
+			delete(existing, rel)
+		}
+		if !dir {
+			return
+		}
+		for path, f := range existing {
+			if strings.HasPrefix(path, rel+"/") {
+				files = append(files, f)
+				delete(existing, path)
+			}
+		}
+	}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			// Record unreadable directories and files and index the rest.
+			if rel, relErr := filepath.Rel(root, path); relErr == nil {
+				stats.Failed[filepath.ToSlash(rel)] = err
+				keep(filepath.ToSlash(rel), d != nil && d.IsDir())
+			}
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path == ix.dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.EqualFold(filepath.Ext(path), ".pdf") {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		info, err := d.Info()
+		if err != nil {
+			stats.Failed[rel] = err
+			keep(rel, false)
+			return nil
+		}
+
+		old := existing[rel]
+		if old != nil && old.Size == info.Size() && old.ModTime.Equal(info.ModTime()) {
+			stats.Unchanged++
+			keep(rel, false)
+			return nil
+		}
+
+		hash, err := hashFile(path)
+		if err != nil {
+			stats.Failed[rel] = err
+			keep(rel, false)
+			return nil
+		}
+		delete(existing, rel)
+		if old != nil && old.Hash == hash {
+			stats.Unchanged++
+			old.Size, old.ModTime = info.Size(), info.ModTime()
+			files = append(files, old)
+			return nil
+		}
+		f := &IndexedFile{Path: rel, Size: info.Size(), ModTime: info.ModTime(), Hash: hash}
+		updated[rel] = old != nil
+		files = append(files, f)
+		pending = append(pending, f)
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+	stats.Removed = len(existing)
+
+	failed := ix.extract(pending, progress)
+	var extracted []*IndexedFile
+	for _, f := range pending {
+		switch {
+		case failed[f.Path] != nil:
+			stats.Failed[f.Path] = failed[f.Path]
+			continue
+		case updated[f.Path]:
+			stats.Updated++
+		default:
+			stats.Added++
+		}
+		extracted = append(extracted, f)
+	}
+
+	previous := ix.Files
+	ix.Files = nil
+	for _, f := range files {
+		if _, bad := failed[f.Path]; !bad {
+			ix.Files = append(ix.Files, f)
+		}
+	}
+	sort.Slice(ix.Files, func(i, j int) bool { return ix.Files[i].Path < ix.Files[j].Path })
+	ix.updateTerms(previous, extracted)
+	return stats, nil
+}
+
+// extract fills in the page texts of files in parallel and returns the
+// errors by relative path.
+func (ix *Index) extract(files []*IndexedFile, progress func(path string)) map[string]error {
+	failed := map[string]error{}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	work := make(chan *IndexedFile)
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range work {
+				pages, err := indexExtractText(filepath.Join(ix.Root, filepath.FromSlash(f.Path)))
+				mu.Lock()
+				if err != nil {
+					failed[f.Path] = err
+				} else {
+					f.Pages, f.text, f.unsaved = len(pages), pages, true
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, f := range files {
+		if progress != nil {
+			progress(f.Path)
+		}
+		work <- f
+	}
+	close(work)
+	wg.Wait()
+	return failed
+}
+
+// updateTerms moves the postings of the files kept from previous, the
+// file list before the update, to their new positions, drops those of the
+// files removed or extracted again, and adds those of the files extracted.
+func (ix *Index) updateTerms(previous, extracted []*IndexedFile) {
+	position := make(map[*IndexedFile]int, len(ix.Files))
+	for i, f := range ix.Files {
+		position[f] = i
+	}
+	moved := make([]int, len(previous))
+	for i, f := range previous {
+		if j, ok := position[f]; ok {
+			moved[i] = j
+		} else {
+			moved[i] = -1
+		}
+	}
+	// Both file lists are sorted by path, so moved postings stay sorted.
+	for term, postings := range ix.Terms {
+		kept := postings[:0]
+		for _, p := range postings {
+			if p.File < len(moved) && moved[p.File] >= 0 {
+				kept = append(kept, IndexPosting{File: moved[p.File], Page: p.Page})
+			}
+		}
+		if len(kept) == 0 {
+			delete(ix.Terms, term)
+		} else {
+			ix.Terms[term] = kept
+		}
+	}
+
+	added := map[string]bool{}
+	for _, f := range extracted {
+		fi := position[f]
+		for page, text := range f.text {
+			seen := map[string]bool{}
+			for _, term := range indexTerms(text) {
+				if seen[term] {
+					continue
+				}
+				seen[term] = true
+				added[term] = true
+				ix.Terms[term] = append(ix.Terms[term], IndexPosting{File: fi, Page: page})
+			}
+		}
+	}
+	for term := range added {
+		slices.SortFunc(ix.Terms[term], func(a, b IndexPosting) int {
+			if a.File != b.File {
+				return a.File - b.File
+			}
+			return a.Page - b.Page
+		})
+	}
+}
+
+// Search returns the pages containing every term of query, in file and
+// page order, with a snippet around the first match. A limit of zero or
+// less returns all hits.
+func (ix *Index) Search(query string, limit int) ([]IndexHit, error) {
+	terms := indexTerms(query)
+	if len(terms) == 0 {
+		return nil, errors.New("empty search query")
+	}
+
+	var pages []IndexPosting
+	for i, term := range terms {
+		postings := ix.Terms[term]
+		if i == 0 {
+			pages = append([]IndexPosting(nil), postings...)
+			continue
+		}
+		pages = intersectPostings(pages, postings)
+	}
+
+	var hits []IndexHit
+	for _, p := range pages {
+		if limit > 0 && len(hits) >= limit {
+			break
+		}
+		if p.File >= len(ix.Files) || p.Page >= ix.Files[p.File].Pages {
+			continue
+		}
+		f := ix.Files[p.File]
+		text, err := ix.pageTexts(f)
+		if err != nil {
+			return nil, err
+		}
+		if p.Page >= len(text) {
+			continue
+		}
+		hits = append(hits, IndexHit{
+			Path:    filepath.Join(ix.Root, filepath.FromSlash(f.Path)),
+			Page:    p.Page,
+			Snippet: indexSnippet(text[p.Page], terms),
+		})
+	}
+	return hits, nil
+}
+
+// intersectPostings returns the postings in both a and b, which are sorted
+// by file and page.
+func intersectPostings(a, b []IndexPosting) []IndexPosting {
+	less := func(x, y IndexPosting) bool {
+		return x.File < y.File || (x.File == y.File && x.Page < y.Page)
+	}
+	var out []IndexPosting
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, a[i])
+			i++
+			j++
+		case less(a[i], b[j]):
+			i++
+		default:
+			j++
+		}
+	}
+	return out
+}
+
+// indexTerms splits text into lower-cased runs of letters and digits.
+func indexTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// indexSnippet returns the text around the first occurrence of any of the
+// terms on a single line, with "..." marking cut ends.
+func indexSnippet(text string, terms []string) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	// Lower-case rune by rune, so that offsets in lower are offsets in
+	// runes whatever the case mapping does to the encoded length.
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	center := -1
+	for _, term := range terms {
+		if i := runeIndex(lower, []rune(term)); i >= 0 && (center < 0 || i < center) {
+			center = i
+		}
+	}
+	center = max(center, 0)
+	start := max(0, center-snippetContext)
+	end := min(len(runes), center+snippetContext)
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(runes) {
+		snippet += "..."
+	}
+	return snippet
+}
+
+// runeIndex returns the offset of the first occurrence of sub in s, or -1.
+func runeIndex(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if slices.Equal(s[i:i+len(sub)], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
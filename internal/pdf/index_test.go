@@ -0,0 +1,348 @@
+package pdf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeIndexText makes indexing read page texts from the PDF files
+// themselves, one page per line, and counts the extractions.
+func fakeIndexText(t *testing.T) *atomic.Int32 {
+	t.Helper()
+	orig := indexExtractText
+	t.Cleanup(func() { indexExtractText = orig })
+
+	calls := new(atomic.Int32)
+	indexExtractText = func(path string) ([]string, error) {
+		calls.Add(1)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(string(data), "broken") {
+			return nil, errors.New("broken file")
+		}
+		return strings.Split(string(data), "\n"), nil
+	}
+	return calls
+}
+
+func writeIndexFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndexUpdateAndSearch(t *testing.T) {
+	calls := fakeIndexText(t)
+	root := t.TempDir()
+	writeIndexFile(t, filepath.Join(root, "a.pdf"), "Master services agreement\nTermination for convenience")
+	writeIndexFile(t, filepath.Join(root, "sub", "b.PDF"), "Termination fee of $5,000")
+	writeIndexFile(t, filepath.Join(root, "notes.txt"), "termination")
+	writeIndexFile(t, filepath.Join(root, "bad.pdf"), "broken")
+
+	dir := filepath.Join(root, ".index")
+	ix, err := OpenIndex(dir)
+	if err != nil {
+		t.Fatalf("OpenIndex() returned error: %v", err)
+	}
+	stats, err := ix.Update(root, nil)
+	if err != nil {
+		t.Fatalf("Update() returned error: %v", err)
+	}
+	if stats.Added != 2 || len(stats.Failed) != 1 || stats.Failed["bad.pdf"] == nil {
+		t.Fatalf("stats = %+v, want 2 added and bad.pdf failed", stats)
+	}
+	if err := ix.Save(); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	ix, err = OpenIndex(dir)
+	if err != nil {
+		t.Fatalf("OpenIndex() after save returned error: %v", err)
+	}
+	hits, err := ix.Search("TERMINATION", 0)
+	if err != nil {
+		t.Fatalf("Search() returned error: %v", err)
+	}
+	want := []IndexHit{
+		{Path: filepath.Join(root, "a.pdf"), Page: 1, Snippet: "Termination for convenience"},
+		{Path: filepath.Join(root, "sub", "b.PDF"), Page: 0, Snippet: "Termination fee of $5,000"},
+	}
+	if !reflect.DeepEqual(hits, want) {
+		t.Fatalf("Search() = %+v, want %+v", hits, want)
+	}
+
+	if hits, _ := ix.Search("termination fee", 0); len(hits) != 1 || hits[0].Page != 0 {
+		t.Errorf("Search(all terms) = %+v, want only b.PDF", hits)
+	}
+	if hits, _ := ix.Search("termination", 1); len(hits) != 1 {
+		t.Errorf("Search(limit 1) returned %d hits", len(hits))
+	}
+	if _, err := ix.Search(" ,. ", 0); err == nil {
+		t.Error("Search() expected error for a query without terms")
+	}
+
+	// Unchanged files are not extracted again; touched files are hashed
+	// and kept when their contents are the same.
+	calls.Store(0)
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(root, "a.pdf"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	writeIndexFile(t, filepath.Join(root, "sub", "b.PDF"), "Renewal terms")
+	if err := os.Remove(filepath.Join(root, "bad.pdf")); err != nil {
+		t.Fatal(err)
+	}
+	stats, err = ix.Update(root, nil)
+	if err != nil {
+		t.Fatalf("second Update() returned error: %v", err)
+	}
+	if stats.Unchanged != 1 || stats.Updated != 1 || stats.Added != 0 || calls.Load() != 1 {
+		t.Errorf("stats = %+v after %d extractions, want 1 unchanged and 1 updated from 1", stats, calls.Load())
+	}
+	if hits, _ := ix.Search("fee", 0); len(hits) != 0 {
+		t.Errorf("Search(fee) = %+v, want no hits after the update", hits)
+	}
+}
+
+func TestIndexRemovesDeletedFiles(t *testing.T) {
+	fakeIndexText(t)
+	root := t.TempDir()
+	writeIndexFile(t, filepath.Join(root, "a.pdf"), "alpha")
+
+	ix, err := OpenIndex(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ix.Update(root, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root, "a.pdf")); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := ix.Update(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Removed != 1 || len(ix.Files) != 0 || len(ix.Terms) != 0 {
+		t.Errorf("stats = %+v, files = %d, terms = %d; want the file removed", stats, len(ix.Files), len(ix.Terms))
+	}
+}
+
+func TestIndexSnippet(t *testing.T) {
+	text := strings.Repeat("lorem ", 20) + "the Needle\n\tis here " + strings.Repeat("ipsum ", 20)
+	got := indexSnippet(text, []string{"needle"})
+	if !strings.HasPrefix(got, "...") || !strings.HasSuffix(got, "...") {
+		t.Errorf("snippet %q should be cut at both ends", got)
+	}
+	if !strings.Contains(got, "the Needle is here") {
+		t.Errorf("snippet %q should contain the match with whitespace collapsed", got)
+	}
+}
+
+func TestIndexSnippetCaseMapping(t *testing.T) {
+	// Dotted capital I lower-cases to a shorter encoding; the snippet must
+	// still be centred on the match.
+	text := strings.Repeat("İSTANBUL ", 30) + "the Needle " + strings.Repeat("ıpsum ", 30)
+	got := indexSnippet(text, []string{"needle"})
+	if !strings.Contains(got, "the Needle") {
+		t.Errorf("snippet %q should contain the match", got)
+	}
+	if got := indexSnippet("İstanbul and Needle", []string{"istanbul"}); got != "İstanbul and Needle" {
+		t.Errorf("snippet = %q, want the whole text", got)
+	}
+}
+
+func TestIndexSkipsUnreadableDirectories(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("directory permissions do not apply to root")
+	}
+	fakeIndexText(t)
+	root := t.TempDir()
+	writeIndexFile(t, filepath.Join(root, "a.pdf"), "alpha")
+	writeIndexFile(t, filepath.Join(root, "locked", "b.pdf"), "beta")
+	locked := filepath.Join(root, "locked")
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(locked, 0755) })
+
+	ix, err := OpenIndex(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := ix.Update(root, nil)
+	if err != nil {
+		t.Fatalf("Update() returned error: %v", err)
+	}
+	if stats.Added != 1 || stats.Failed["locked"] == nil {
+		t.Errorf("stats = %+v, want a.pdf added and the locked directory recorded", stats)
+	}
+}
+
+func TestIndexIncrementalUpdate(t *testing.T) {
+	calls := fakeIndexText(t)
+	root := t.TempDir()
+	writeIndexFile(t, filepath.Join(root, "a.pdf"), "alpha clause")
+	writeIndexFile(t, filepath.Join(root, "b.pdf"), "beta clause")
+	writeIndexFile(t, filepath.Join(root, "c.pdf"), "gamma clause")
+
+	dir := filepath.Join(t.TempDir(), "index")
+	ix, err := OpenIndex(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ix.Update(root, nil); err != nil {
+		t.Fatalf("Update() returned error: %v", err)
+	}
+	if err := ix.Save(); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+	// The page texts are kept apart from the term dictionary.
+	data, err := os.ReadFile(filepath.Join(dir, indexFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "alpha clause") {
+		t.Errorf("%s holds the page texts: %s", indexFileName, data)
+	}
+	textDir := filepath.Join(dir, indexTextDirName)
+	texts := func() map[string]time.Time {
+		entries, err := os.ReadDir(textDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := map[string]time.Time{}
+		for _, e := range entries {
+			info, err := e.Info()
+			if err != nil {
+				t.Fatal(err)
+			}
+			m[e.Name()] = info.ModTime()
+		}
+		return m
+	}
+	before := texts()
+	if len(before) != 3 {
+		t.Fatalf("text files = %v, want 3", before)
+	}
+
+	// Change one file and add one that sorts first, moving the others.
+	calls.Store(0)
+	writeIndexFile(t, filepath.Join(root, "b.pdf"), "delta clause")
+	writeIndexFile(t, filepath.Join(root, "0.pdf"), "epsilon")
+	ix, err = OpenIndex(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := ix.Update(root, nil)
+	if err != nil {
+		t.Fatalf("second Update() returned error: %v", err)
+	}
+	if stats.Added != 1 || stats.Updated != 1 || stats.Unchanged != 2 || calls.Load() != 2 {
+		t.Errorf("stats = %+v after %d extractions, want 1 added, 1 updated and 2 unchanged from 2", stats, calls.Load())
+	}
+	if err := ix.Save(); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+	after := texts()
+	if len(after) != 4 {
+		t.Errorf("text files = %v, want 4 with the old text of b.pdf removed", after)
+	}
+	for name, modTime := range before {
+		if got, ok := after[name]; ok && !got.Equal(modTime) {
+			t.Errorf("unchanged text %s was rewritten", name)
+		}
+	}
+
+	ix, err = OpenIndex(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for query, want := range map[string][]string{
+		"clause":  {"a.pdf", "b.pdf", "c.pdf"},
+		"gamma":   {"c.pdf"},
+		"delta":   {"b.pdf"},
+		"beta":    nil,
+		"epsilon": {"0.pdf"},
+	} {
+		hits, err := ix.Search(query, 0)
+		if err != nil {
+			t.Fatalf("Search(%s) returned error: %v", query, err)
+		}
+		var got []string
+		for _, h := range hits {
+			got = append(got, filepath.Base(h.Path))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Search(%s) = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestIndexKeepsFilesItCannotRead(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("file permissions do not apply to root")
+	}
+	fakeIndexText(t)
+	root := t.TempDir()
+	writeIndexFile(t, filepath.Join(root, "a.pdf"), "alpha")
+	writeIndexFile(t, filepath.Join(root, "locked", "b.pdf"), "beta")
+
+	ix, err := OpenIndex(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ix.Update(root, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// a.pdf looks changed but cannot be hashed, and the directory of
+	// b.pdf cannot be listed.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(root, "a.pdf"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{filepath.Join(root, "a.pdf"), filepath.Join(root, "locked")} {
+		if err := os.Chmod(path, 0); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.Chmod(path, 0755) })
+	}
+	stats, err := ix.Update(root, nil)
+	if err != nil {
+		t.Fatalf("Update() returned error: %v", err)
+	}
+	if stats.Removed != 0 || stats.Failed["a.pdf"] == nil || stats.Failed["locked"] == nil {
+		t.Errorf("stats = %+v, want a.pdf and locked failed and nothing removed", stats)
+	}
+	for _, query := range []string{"alpha", "beta"} {
+		if hits, err := ix.Search(query, 0); err != nil || len(hits) != 1 {
+			t.Errorf("Search(%s) = %+v, %v, want the previous entry", query, hits, err)
+		}
+	}
+}
+
+func TestOpenIndexOlderVersion(t *testing.T) {
+	dir := t.TempDir()
+	old := `{"version":1,"root":"/docs","files":[{"path":"a.pdf","pages":["alpha"]}],"terms":{"alpha":[{"f":0,"p":0}]}}`
+	writeIndexFile(t, filepath.Join(dir, indexFileName), old)
+	ix, err := OpenIndex(dir)
+	if err != nil {
+		t.Fatalf("OpenIndex() returned error: %v", err)
+	}
+	if ix.Version != indexVersion || len(ix.Files) != 0 || len(ix.Terms) != 0 {
+		t.Errorf("OpenIndex() = %+v, want an empty index to build again", ix)
+	}
+}
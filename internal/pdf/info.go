@@ -0,0 +1,216 @@
+package pdf
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Info is the document metadata and file properties. The text fields and
+// dates come from the Info dictionary, falling back to the XMP metadata
+// for fields the dictionary lacks.
+type Info struct {
+	Title    string    `json:"title,omitempty"`
+	Author   string    `json:"author,omitempty"`
+	Subject  string    `json:"subject,omitempty"`
+	Keywords string    `json:"keywords,omitempty"`
+	Creator  string    `json:"creator,omitempty"`
+	Producer string    `json:"producer,omitempty"`
+	Created  time.Time `json:"created,omitzero"`
+	Modified time.Time `json:"modified,omitzero"`
+
+	// The properties below are read-only.
+	Version    string `json:"version"`
+	PageCount  int    `json:"page_count"`
+	FileSize   int64  `json:"file_size"`
+	Encrypted  bool   `json:"encrypted"`
+	Tagged     bool   `json:"tagged"`
+	Linearized bool   `json:"linearized"`
+}
+
+// infoTextKeys are the text entries of the Info dictionary; all but
+// Producer are editable.
+var (
+	infoTextKeys     = []string{"Title", "Author", "Subject", "Keywords", "Creator", "Producer"}
+	infoEditableKeys = infoTextKeys[:5]
+)
+
+// text returns a pointer to the field of an Info dictionary key.
+func (info *Info) text(key string) *string {
+	switch key {
+	case "Title":
+		return &info.Title
+	case "Author":
+		return &info.Author
+	case "Subject":
+		return &info.Subject
+	case "Keywords":
+		return &info.Keywords
+	case "Creator":
+		return &info.Creator
+	case "Producer":
+		return &info.Producer
+	}
+	return nil
+}
+
+// Info returns the document metadata and file properties.
+func (d *Document) Info() (Info, error) {
+	if d.context() == nil {
+		return Info{}, errors.New("no document loaded")
+	}
+
+	d.renderMu.Lock()
+	defer d.renderMu.Unlock()
+	info, err := readInfo(d.ctx)
+	if err != nil {
+		return Info{}, err
+	}
+	if fi, err := os.Stat(d.Path()); err == nil {
+		info.FileSize = fi.Size()
+	}
+	return info, nil
+}
+
+// SetInfo replaces Title, Author, Subject, Keywords and Creator in the Info
+// dictionary; empty values remove the entry and the XMP property mirroring
+// it. Producer and the dates are set by the PDF writer when the document is
+// saved. The change is kept in memory until the document is saved, when
+// the XMP metadata is updated to match.
+func (d *Document) SetInfo(info Info) error {
+	if d.context() == nil {
+		return errors.New("no document loaded")
+	}
+
+	d.renderMu.Lock()
+	defer d.renderMu.Unlock()
+	if err := writeInfo(d.ctx, info); err != nil {
+		return err
+	}
+	if err := clearXMPInfo(d.ctx, info); err != nil {
+		return err
+	}
+	d.setModified(true)
+	return nil
+}
+
+func readInfo(ctx *model.Context) (Info, error) {
+	info := Info{
+		Version:   ctx.VersionString(),
+		PageCount: ctx.PageCount,
+		Encrypted: ctx.Encrypt != nil,
+	}
+	if ctx.Read != nil {
+		info.FileSize = ctx.Read.FileSize
+		info.Linearized = ctx.Read.Linearized
+	}
+
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return Info{}, err
+	}
+	if markInfo, err := ctx.DereferenceDict(catalog["MarkInfo"]); err == nil && markInfo != nil {
+		if marked := markInfo.BooleanEntry("Marked"); marked != nil {
+			info.Tagged = *marked
+		}
+	}
+
+	dict := readInfoDict(ctx)
+	for _, key := range infoTextKeys {
+		*info.text(key) = *dict.text(key)
+	}
+	info.Created, info.Modified = dict.Created, dict.Modified
+
+	if xmp, err := readXMPInfo(ctx.XRefTable, catalog); err == nil {
+		info.fillFrom(xmp)
+	}
+	return info, nil
+}
+
+// readInfoDict reads the text entries and dates of the Info dictionary.
+func readInfoDict(ctx *model.Context) Info {
+	var info Info
+	if ctx.Info == nil {
+		return info
+	}
+	dict, err := ctx.DereferenceDict(*ctx.Info)
+	if err != nil || dict == nil {
+		return info
+	}
+	for _, key := range infoTextKeys {
+		if s, err := ctx.DereferenceText(dict[key]); err == nil {
+			*info.text(key) = strings.TrimSpace(s)
+		}
+	}
+	info.Created = infoDate(ctx.XRefTable, dict["CreationDate"])
+	info.Modified = infoDate(ctx.XRefTable, dict["ModDate"])
+	return info
+}
+
+// fillFrom copies the metadata fields of other that are empty in info.
+func (info *Info) fillFrom(other Info) {
+	for _, key := range infoTextKeys {
+		if field := info.text(key); *field == "" {
+			*field = *other.text(key)
+		}
+	}
+	if info.Created.IsZero() {
+		info.Created = other.Created
+	}
+	if info.Modified.IsZero() {
+		info.Modified = other.Modified
+	}
+}
+
+// infoDate parses a PDF date string, returning the zero time if it is
+// missing or malformed.
+func infoDate(xRefTable *model.XRefTable, obj types.Object) time.Time {
+	s, err := xRefTable.DereferenceText(obj)
+	if err != nil || s == "" {
+		return time.Time{}
+	}
+	t, ok := types.DateTime(s, true)
+	if !ok {
+		return time.Time{}
+	}
+	return t
+}
+
+// writeInfo updates the editable entries of the Info dictionary, creating
+// it if needed.
+func writeInfo(ctx *model.Context, info Info) error {
+	var dict types.Dict
+	if ctx.Info != nil {
+		d, err := ctx.DereferenceDict(*ctx.Info)
+		if err != nil {
+			return err
+		}
+		dict = d
+	}
+	if dict == nil {
+		dict = types.NewDict()
+		ref, err := ctx.IndRefForNewObject(dict)
+		if err != nil {
+			return err
+		}
+		ctx.Info = ref
+	}
+
+	for _, key := range infoEditableKeys {
+		value := strings.TrimSpace(*info.text(key))
+		if value == "" {
+			dict.Delete(key)
+			continue
+		}
+		s, err := pdfTextString(value)
+		if err != nil {
+			return err
+		}
+		dict.Update(key, s)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testXMPPacket = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmlns:pdf="http://ns.adobe.com/pdf/1.3/"
+    xmp:CreatorTool="Writer" pdf:Producer="XMP Producer">
+   <dc:title><rdf:Alt>
+    <rdf:li xml:lang="de">Bericht</rdf:li>
+    <rdf:li xml:lang="x-default">Report</rdf:li>
+   </rdf:Alt></dc:title>
+   <dc:creator><rdf:Seq><rdf:li>Ann</rdf:li><rdf:li>Bob</rdf:li></rdf:Seq></dc:creator>
+   <pdf:Keywords>xmp, keywords</pdf:Keywords>
+   <xmp:CreateDate>2024-03-01T10:20:30+01:00</xmp:CreateDate>
+   <xmp:ModifyDate>2024-03-02</xmp:ModifyDate>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+func TestParseXMPInfo(t *testing.T) {
+	info, err := parseXMPInfo([]byte(testXMPPacket))
+	if err != nil {
+		t.Fatalf("parseXMPInfo() returned error: %v", err)
+	}
+	want := Info{
+		Title:    "Report",
+		Author:   "Ann, Bob",
+		Keywords: "xmp, keywords",
+		Creator:  "Writer",
+		Producer: "XMP Producer",
+		Created:  time.Date(2024, 3, 1, 10, 20, 30, 0, time.FixedZone("", 3600)),
+		Modified: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+	}
+	if info.Title != want.Title || info.Author != want.Author || info.Keywords != want.Keywords ||
+		info.Creator != want.Creator || info.Producer != want.Producer ||
+		!info.Created.Equal(want.Created) || !info.Modified.Equal(want.Modified) {
+		t.Errorf("parseXMPInfo() = %+v, want %+v", info, want)
+	}
+}
+
+func TestDocumentInfo(t *testing.T) {
+	path := writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R /Metadata 4 0 R /MarkInfo << /Marked true >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		fmt.Sprintf("<< /Type /Metadata /Subtype /XML /Length %d >>\nstream\n%s\nendstream", len(testXMPPacket)+1, testXMPPacket),
+		"<< /Title <FEFF00C9007400E9> /Author (Info Author) /CreationDate (D:20230102030405Z) >>",
+	)
+	// The trailer written by writeObjectsPDF has no /Info entry.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = bytes.Replace(data, []byte("/Root 1 0 R"), []byte("/Root 1 0 R /Info 5 0 R"), 1)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+
+	info, err := doc.Info()
+	if err != nil {
+		t.Fatalf("Info() returned error: %v", err)
+	}
+	// The Info dictionary wins; XMP fills the gaps.
+	if info.Title != "Été" || info.Author != "Info Author" || info.Keywords != "xmp, keywords" || info.Creator != "Writer" {
+		t.Errorf("Info() text = %+v", info)
+	}
+	if !info.Created.Equal(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Created = %v, want 2023-01-02 03:04:05 UTC", info.Created)
+	}
+	if info.Version != "1.4" || info.PageCount != 1 || info.FileSize == 0 || !info.Tagged || info.Encrypted || info.Linearized {
+		t.Errorf("Info() properties = %+v", info)
+	}
+
+	info.Title = "Nouveau titre ✓"
+	info.Author = ""
+	info.Subject = "Quarterly"
+	if err := doc.SetInfo(info); err != nil {
+		t.Fatalf("SetInfo() returned error: %v", err)
+	}
+	if !doc.IsModified() {
+		t.Error("SetInfo() did not mark the document modified")
+	}
+	out := filepath.Join(t.TempDir(), "info.pdf")
+	if err := doc.SaveAs(out); err != nil {
+		t.Fatalf("SaveAs() failed: %v", err)
+	}
+
+	saved, err := Open(out)
+	if err != nil {
+		t.Fatalf("Open(saved) failed: %v", err)
+	}
+	defer saved.Close()
+	got, err := saved.Info()
+	if err != nil {
+		t.Fatalf("Info() of saved returned error: %v", err)
+	}
+	// The removed author is removed from the XMP metadata too.
+	if got.Title != "Nouveau titre ✓" || got.Subject != "Quarterly" || got.Author != "" {
+		t.Errorf("saved Info() = %+v", got)
+	}
+}
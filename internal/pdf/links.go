@@ -0,0 +1,187 @@
+package pdf
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// LinkKind is what following a link does.
+type LinkKind int
+
+const (
+	// LinkGoTo jumps to Dest in the same document.
+	LinkGoTo LinkKind = iota
+	// LinkGoToR opens File at Dest.
+	LinkGoToR
+	// LinkURI opens URI.
+	LinkURI
+	// LinkNamed runs the viewer action Name, such as NextPage.
+	LinkNamed
+)
+
+// String returns the PDF action type of k.
+func (k LinkKind) String() string {
+	switch k {
+	case LinkGoTo:
+		return "GoTo"
+	case LinkGoToR:
+		return "GoToR"
+	case LinkURI:
+		return "URI"
+	case LinkNamed:
+		return "Named"
+	default:
+		return "Unknown"
+	}
+}
+
+// Link is a link annotation on a page.
+type Link struct {
+	Rect Rect // user space
+	Kind LinkKind
+	// Dest is the target of GoTo and GoToR links. For GoToR, Page is a
+	// page of File, or -1 when the target is named or missing.
+	Dest Destination
+	File string // GoToR target, resolved against the document's directory
+	URI  string
+	Name string // named action: NextPage, PrevPage, FirstPage or LastPage
+}
+
+// Links returns the link annotations of a page (0-indexed) that the viewer
+// can follow, in the order of the page's /Annots.
+func (d *Document) Links(pageNum int) ([]Link, error) {
+	if d.context() == nil {
+		return nil, errors.New("no document loaded")
+	}
+	if pageNum < 0 || pageNum >= d.PageCount() {
+		return nil, errors.New("page number out of range")
+	}
+
+	d.renderMu.Lock()
+	defer d.renderMu.Unlock()
+	links, err := readLinks(d.ctx, pageNum)
+	if err != nil {
+		return nil, err
+	}
+	for i := range links {
+		if links[i].File != "" && !filepath.IsAbs(links[i].File) && d.Path() != "" {
+			links[i].File = filepath.Join(filepath.Dir(d.Path()), links[i].File)
+		}
+	}
+	return links, nil
+}
+
+func readLinks(ctx *model.Context, pageNum int) ([]Link, error) {
+	pageDict, _, _, err := ctx.PageDict(pageNum+1, false)
+	if err != nil {
+		return nil, err
+	}
+	if pageDict == nil {
+		return nil, errors.New("page not found")
+	}
+	annots, err := ctx.DereferenceArray(pageDict["Annots"])
+	if err != nil || len(annots) == 0 {
+		return nil, nil
+	}
+
+	r, err := newOutlineReader(ctx.XRefTable)
+	if err != nil {
+		return nil, err
+	}
+	var links []Link
+	for _, obj := range annots {
+		annot, err := ctx.DereferenceDict(obj)
+		if err != nil || annot == nil || annot.NameEntry("Subtype") == nil || *annot.NameEntry("Subtype") != "Link" {
+			continue
+		}
+		rect := pageBox(ctx.XRefTable, annot, "Rect")
+		if rect == nil || rect.Empty() {
+			continue
+		}
+		if link, ok := r.link(annot); ok {
+			link.Rect = *rect
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+// link reads the target of a link annotation from its /Dest or /A entry.
+// Actions the viewer cannot follow are skipped.
+func (r *outlineReader) link(annot types.Dict) (Link, bool) {
+	if dest, found := annot.Find("Dest"); found {
+		return Link{Kind: LinkGoTo, Dest: r.resolveDestination(dest)}, true
+	}
+	action, err := r.xRefTable.DereferenceDict(annot["A"])
+	if err != nil || action == nil || action.NameEntry("S") == nil {
+		return Link{}, false
+	}
+
+	switch *action.NameEntry("S") {
+	case "GoTo":
+		return Link{Kind: LinkGoTo, Dest: r.resolveDestination(action["D"])}, true
+	case "GoToR":
+		file := r.fileSpec(action["F"])
+		if file == "" {
+			return Link{}, false
+		}
+		return Link{Kind: LinkGoToR, File: file, Dest: r.remoteDestination(action["D"])}, true
+	case "URI":
+		uri, err := r.xRefTable.DereferenceStringOrHexLiteral(action["URI"], model.V10, nil)
+		if err != nil || uri == "" {
+			return Link{}, false
+		}
+		return Link{Kind: LinkURI, URI: uri}, true
+	case "Named":
+		name := action.NameEntry("N")
+		if name == nil {
+			return Link{}, false
+		}
+		return Link{Kind: LinkNamed, Name: *name}, true
+	}
+	return Link{}, false
+}
+
+// remoteDestination reads the destination of a GoToR action, whose page is
+// a page number rather than a reference. Named destinations of the other
+// file are not resolved.
+func (r *outlineReader) remoteDestination(obj types.Object) Destination {
+	obj, _ = r.xRefTable.Dereference(obj)
+	arr, ok := obj.(types.Array)
+	if !ok || len(arr) == 0 {
+		return Destination{Page: -1}
+	}
+	page, err := r.xRefTable.DereferenceInteger(arr[0])
+	if err != nil || page == nil || page.Value() < 0 {
+		return Destination{Page: -1}
+	}
+	local := append(types.Array{nil}, arr[1:]...)
+	dest := r.explicitDestination(local)
+	dest.Page = page.Value()
+	return dest
+}
+
+// fileSpec returns the file name of a file specification string or
+// dictionary.
+func (r *outlineReader) fileSpec(obj types.Object) string {
+	obj, err := r.xRefTable.Dereference(obj)
+	if err != nil {
+		return ""
+	}
+	if d, ok := obj.(types.Dict); ok {
+		for _, key := range []string{"UF", "F", "Unix", "DOS", "Mac"} {
+			if name := r.fileSpec(d[key]); name != "" {
+				return name
+			}
+		}
+		return ""
+	}
+	name, err := model.Text(obj)
+	if err != nil {
+		return ""
+	}
+	return filepath.FromSlash(name)
+}
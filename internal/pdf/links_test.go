@@ -0,0 +1,53 @@
+package pdf
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDocumentLinks(t *testing.T) {
+	path := writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R /Dests << /intro [4 0 R /Fit] >> >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [5 0 R 6 0 R 7 0 R 8 0 R 9 0 R 10 0 R 11 0 R] >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Type /Annot /Subtype /Link /Rect [72 700 144 720] /Dest [4 0 R /XYZ 0 500 0] >>",
+		"<< /Type /Annot /Subtype /Link /Rect [144 720 72 700] /A << /S /GoTo /D /intro >> >>",
+		"<< /Type /Annot /Subtype /Link /Rect [72 600 144 620] /A << /S /URI /URI (https://example.com/a?b=c) >> >>",
+		"<< /Type /Annot /Subtype /Link /Rect [72 500 144 520] /A << /S /GoToR /F (other.pdf) /D [2 /FitH 300] >> >>",
+		"<< /Type /Annot /Subtype /Link /Rect [72 400 144 420] /A << /S /Named /N /NextPage >> >>",
+		"<< /Type /Annot /Subtype /Link /Rect [72 300 144 320] /A << /S /Launch /F (run.sh) >> >>",
+		"<< /Type /Annot /Subtype /Text /Rect [72 200 92 220] /Contents (Note) >>",
+	)
+	doc, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+
+	links, err := doc.Links(0)
+	if err != nil {
+		t.Fatalf("Links() returned error: %v", err)
+	}
+
+	zero, top, top300 := 0.0, 500.0, 300.0
+	want := []Link{
+		{Rect: Rect{LLX: 72, LLY: 700, URX: 144, URY: 720}, Kind: LinkGoTo, Dest: Destination{Page: 1, Fit: "XYZ", Left: &zero, Top: &top}},
+		{Rect: Rect{LLX: 72, LLY: 700, URX: 144, URY: 720}, Kind: LinkGoTo, Dest: Destination{Page: 1, Fit: "Fit"}},
+		{Rect: Rect{LLX: 72, LLY: 600, URX: 144, URY: 620}, Kind: LinkURI, URI: "https://example.com/a?b=c"},
+		{Rect: Rect{LLX: 72, LLY: 500, URX: 144, URY: 520}, Kind: LinkGoToR, File: filepath.Join(filepath.Dir(path), "other.pdf"),
+			Dest: Destination{Page: 2, Fit: "FitH", Top: &top300}},
+		{Rect: Rect{LLX: 72, LLY: 400, URX: 144, URY: 420}, Kind: LinkNamed, Name: "NextPage"},
+	}
+	if !reflect.DeepEqual(links, want) {
+		t.Fatalf("Links() = %+v, want %+v", links, want)
+	}
+
+	if links, err := doc.Links(1); err != nil || links != nil {
+		t.Errorf("Links(1) = %+v, %v; want nil, nil", links, err)
+	}
+	if _, err := doc.Links(2); err == nil {
+		t.Error("Links(2) expected an error for a missing page")
+	}
+}
@@ -0,0 +1,1760 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // decoders for pdfcpu image extraction
+	_ "image/png"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	_ "golang.org/x/image/tiff" // CMYK images are extracted as TIFF
+	"golang.org/x/image/vector"
+)
+
+// maxFormDepth bounds Form XObject recursion on malicious or cyclic files.
+const maxFormDepth = 12
+
+// matrix is a PDF transformation matrix [a b c d e f] applied to row vectors.
+type matrix [6]float64
+
+func identityMatrix() matrix {
+	return matrix{1, 0, 0, 1, 0, 0}
+}
+
+func translateMatrix(tx, ty float64) matrix {
+	return matrix{1, 0, 0, 1, tx, ty}
+}
+
+// mul returns m followed by n.
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func (m matrix) apply(x, y float64) (float64, float64) {
+	return x*m[0] + y*m[2] + m[4], x*m[1] + y*m[3] + m[5]
+}
+
+func (m matrix) invert() (matrix, bool) {
+	det := m[0]*m[3] - m[1]*m[2]
+	if math.Abs(det) < 1e-12 {
+		return matrix{}, false
+	}
+	return matrix{
+		m[3] / det,
+		-m[1] / det,
+		-m[2] / det,
+		m[0] / det,
+		(m[2]*m[5] - m[3]*m[4]) / det,
+		(m[1]*m[4] - m[0]*m[5]) / det,
+	}, true
+}
+
+// scale returns the average linear scale factor of m.
+func (m matrix) scale() float64 {
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+type point struct {
+	x, y float64
+}
+
+// colorSpace is the subset of PDF colour space information needed to map operands to RGB.
+type colorSpace struct {
+	family string // DeviceGray, DeviceRGB, DeviceCMYK, Indexed, Separation, Pattern
+	comps  int
+	base   *colorSpace
+	hival  int
+	lookup []byte
+}
+
+var (
+	deviceGray = &colorSpace{family: "DeviceGray", comps: 1}
+	deviceRGB  = &colorSpace{family: "DeviceRGB", comps: 3}
+	deviceCMYK = &colorSpace{family: "DeviceCMYK", comps: 4}
+)
+
+func (cs *colorSpace) toRGB(vals []float64) color.NRGBA {
+	clamp := func(v float64) uint8 {
+		if v <= 0 {
+			return 0
+		}
+		if v >= 1 {
+			return 255
+		}
+		return uint8(v*255 + 0.5)
+	}
+
+	switch cs.family {
+	case "DeviceGray":
+		if len(vals) < 1 {
+			return color.NRGBA{0, 0, 0, 255}
+		}
+		g := clamp(vals[0])
+		return color.NRGBA{g, g, g, 255}
+	case "DeviceRGB":
+		if len(vals) < 3 {
+			return color.NRGBA{0, 0, 0, 255}
+		}
+		return color.NRGBA{clamp(vals[0]), clamp(vals[1]), clamp(vals[2]), 255}
+	case "DeviceCMYK":
+		if len(vals) < 4 {
+			return color.NRGBA{0, 0, 0, 255}
+		}
+		k := vals[3]
+		return color.NRGBA{
+			clamp((1 - vals[0]) * (1 - k)),
+			clamp((1 - vals[1]) * (1 - k)),
+			clamp((1 - vals[2]) * (1 - k)),
+			255,
+		}
+	case "Indexed":
+		if len(vals) < 1 || cs.base == nil {
+			return color.NRGBA{0, 0, 0, 255}
+		}
+		idx := int(vals[0])
+		if idx < 0 {
+			idx = 0
+		}
+		if idx > cs.hival {
+			idx = cs.hival
+		}
+		n := cs.base.comps
+		start := idx * n
+		if start+n > len(cs.lookup) {
+			return color.NRGBA{0, 0, 0, 255}
+		}
+		baseVals := make([]float64, n)
+		for i := 0; i < n; i++ {
+			baseVals[i] = float64(cs.lookup[start+i]) / 255
+		}
+		return cs.base.toRGB(baseVals)
+	case "Separation":
+		// Without evaluating the tint transform, render tints as shades of grey.
+		if len(vals) < 1 {
+			return color.NRGBA{0, 0, 0, 255}
+		}
+		g := clamp(1 - vals[0])
+		return color.NRGBA{g, g, g, 255}
+	case "Pattern":
+		return color.NRGBA{192, 192, 192, 255}
+	}
+
+	switch len(vals) {
+	case 1:
+		return deviceGray.toRGB(vals)
+	case 3:
+		return deviceRGB.toRGB(vals)
+	case 4:
+		return deviceCMYK.toRGB(vals)
+	}
+	return color.NRGBA{0, 0, 0, 255}
+}
+
+// graphicsState is the part of the PDF graphics state the native renderer honours.
+type graphicsState struct {
+	ctm         matrix
+	fillColor   color.NRGBA
+	strokeColor color.NRGBA
+	fillSpace   *colorSpace
+	strokeSpace *colorSpace
+	fillAlpha   float64
+	strokeAlpha float64
+	lineWidth   float64
+	lineCap     int
+	lineJoin    int
+	miterLimit  float64
+	dash        []float64
+	dashPhase   float64
+	clip        *image.Alpha
+
+	font        *pdfFont
+	fontSize    float64
+	charSpacing float64
+	wordSpacing float64
+	hScale      float64
+	leading     float64
+	rise        float64
+	renderMode  int
+}
+
+func newGraphicsState(ctm matrix) graphicsState {
+	return graphicsState{
+		ctm:         ctm,
+		fillColor:   color.NRGBA{0, 0, 0, 255},
+		strokeColor: color.NRGBA{0, 0, 0, 255},
+		fillSpace:   deviceGray,
+		strokeSpace: deviceGray,
+		fillAlpha:   1,
+		strokeAlpha: 1,
+		lineWidth:   1,
+		miterLimit:  10,
+		hScale:      1,
+	}
+}
+
+// pathBuilder accumulates the current path in device space.
+type pathBuilder struct {
+	subpaths [][]point
+	closed   []bool
+	start    point
+	current  point
+	open     bool
+}
+
+func (p *pathBuilder) moveTo(pt point) {
+	p.subpaths = append(p.subpaths, []point{pt})
+	p.closed = append(p.closed, false)
+	p.start = pt
+	p.current = pt
+	p.open = true
+}
+
+func (p *pathBuilder) lineTo(pt point) {
+	if !p.open {
+		p.moveTo(p.current)
+	}
+	last := len(p.subpaths) - 1
+	p.subpaths[last] = append(p.subpaths[last], pt)
+	p.current = pt
+}
+
+func (p *pathBuilder) curveTo(c1, c2, end point) {
+	start := p.current
+	length := math.Hypot(c1.x-start.x, c1.y-start.y) +
+		math.Hypot(c2.x-c1.x, c2.y-c1.y) +
+		math.Hypot(end.x-c2.x, end.y-c2.y)
+	steps := int(length/3) + 1
+	if steps > 64 {
+		steps = 64
+	}
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		mt := 1 - t
+		x := mt*mt*mt*start.x + 3*mt*mt*t*c1.x + 3*mt*t*t*c2.x + t*t*t*end.x
+		y := mt*mt*mt*start.y + 3*mt*mt*t*c1.y + 3*mt*t*t*c2.y + t*t*t*end.y
+		p.lineTo(point{x, y})
+	}
+}
+
+func (p *pathBuilder) closePath() {
+	if !p.open || len(p.subpaths) == 0 {
+		return
+	}
+	p.closed[len(p.closed)-1] = true
+	p.current = p.start
+	p.open = false
+}
+
+func (p *pathBuilder) reset() {
+	*p = pathBuilder{}
+}
+
+func (p *pathBuilder) empty() bool {
+	return len(p.subpaths) == 0
+}
+
+// nativeRenderer interprets one page's content streams into an RGBA image.
+// Without a destination image it paints nothing and only reports the text
+// it shows to onGlyph.
+type nativeRenderer struct {
+	ctx      *model.Context
+	dst      *image.RGBA
+	onGlyph  func(textGlyph)
+	state    graphicsState
+	stack    []graphicsState
+	path     pathBuilder
+	pendClip bool
+	tm       matrix
+	tlm      matrix
+	fonts    map[string]*pdfFont
+	images   map[int]image.Image
+	depth    int
+}
+
+// renderPageNative rasterizes a page (0-indexed) from a loaded pdfcpu context.
+func renderPageNative(ctx *model.Context, pageNum int, dpi int) (image.Image, error) {
+	if ctx == nil {
+		return nil, errors.New("no document loaded")
+	}
+	if dpi <= 0 {
+		return nil, errors.New("dpi must be greater than zero")
+	}
+
+	pageDict, _, inh, err := ctx.PageDict(pageNum+1, false)
+	if err != nil {
+		return nil, err
+	}
+	if pageDict == nil || inh == nil {
+		return nil, fmt.Errorf("page %d not found", pageNum+1)
+	}
+
+	geom := pageGeometryFromDict(ctx.XRefTable, pageDict, inh)
+	box := geom.EffectiveBox()
+
+	// One user space unit is UserUnit/72 inch.
+	scale := float64(dpi) / 72 * geom.UserUnit
+	width := int(math.Round(box.Width() * scale))
+	height := int(math.Round(box.Height() * scale))
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	// Map user space to an upright device space with y pointing down.
+	base := matrix{scale, 0, 0, -scale, -box.LLX * scale, box.URY * scale}
+	switch geom.Rotate {
+	case 90:
+		base = base.mul(matrix{0, 1, -1, 0, float64(height), 0})
+		width, height = height, width
+	case 180:
+		base = base.mul(matrix{-1, 0, 0, -1, float64(width), float64(height)})
+	case 270:
+		base = base.mul(matrix{0, -1, 1, 0, 0, float64(width)})
+		width, height = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range dst.Pix {
+		dst.Pix[i] = 255
+	}
+
+	content, err := ctx.PageContent(pageDict)
+	if err != nil && err != model.ErrNoContent {
+		return nil, err
+	}
+
+	r := &nativeRenderer{
+		ctx:    ctx,
+		dst:    dst,
+		state:  newGraphicsState(base),
+		fonts:  map[string]*pdfFont{},
+		images: map[int]image.Image{},
+	}
+	if err == nil {
+		ops, _ := parseContentStream(content)
+		r.run(ops, inh.Resources)
+	}
+	r.drawAnnotations(pageDict, base)
+
+	return dst, nil
+}
+
+func (r *nativeRenderer) xref() *model.XRefTable {
+	return r.ctx.XRefTable
+}
+
+// painting reports whether the interpreter produces an image.
+func (r *nativeRenderer) painting() bool {
+	return r.dst != nil
+}
+
+func (r *nativeRenderer) run(ops []contentOp, resources types.Dict) {
+	for _, op := range ops {
+		r.execute(op, resources)
+	}
+}
+
+func (r *nativeRenderer) devicePoint(x, y float64) point {
+	dx, dy := r.state.ctm.apply(x, y)
+	return point{dx, dy}
+}
+
+func (r *nativeRenderer) execute(op contentOp, resources types.Dict) {
+	args := op.operands
+	gs := &r.state
+
+	switch op.operator {
+	// Graphics state
+	case "q":
+		saved := r.state
+		saved.dash = append([]float64(nil), r.state.dash...)
+		r.stack = append(r.stack, saved)
+	case "Q":
+		if n := len(r.stack); n > 0 {
+			r.state = r.stack[n-1]
+			r.stack = r.stack[:n-1]
+		}
+	case "cm":
+		if v, ok := operandNumbers(args, 6); ok {
+			gs.ctm = matrix{v[0], v[1], v[2], v[3], v[4], v[5]}.mul(gs.ctm)
+		}
+	case "w":
+		if v, ok := operandNumbers(args, 1); ok {
+			gs.lineWidth = v[0]
+		}
+	case "J":
+		if v, ok := operandNumbers(args, 1); ok {
+			gs.lineCap = int(v[0])
+		}
+	case "j":
+		if v, ok := operandNumbers(args, 1); ok {
+			gs.lineJoin = int(v[0])
+		}
+	case "M":
+		if v, ok := operandNumbers(args, 1); ok {
+			gs.miterLimit = v[0]
+		}
+	case "d":
+		if len(args) >= 2 {
+			r.setDash(args[0], args[1])
+		}
+	case "gs":
+		if len(args) >= 1 {
+			if name, ok := args[0].(types.Name); ok {
+				r.applyExtGState(resources, string(name))
+			}
+		}
+
+	// Path construction
+	case "m":
+		if v, ok := operandNumbers(args, 2); ok {
+			r.path.moveTo(r.devicePoint(v[0], v[1]))
+		}
+	case "l":
+		if v, ok := operandNumbers(args, 2); ok {
+			r.path.lineTo(r.devicePoint(v[0], v[1]))
+		}
+	case "c":
+		if v, ok := operandNumbers(args, 6); ok {
+			r.path.curveTo(r.devicePoint(v[0], v[1]), r.devicePoint(v[2], v[3]), r.devicePoint(v[4], v[5]))
+		}
+	case "v":
+		if v, ok := operandNumbers(args, 4); ok {
+			r.path.curveTo(r.path.current, r.devicePoint(v[0], v[1]), r.devicePoint(v[2], v[3]))
+		}
+	case "y":
+		if v, ok := operandNumbers(args, 4); ok {
+			end := r.devicePoint(v[2], v[3])
+			r.path.curveTo(r.devicePoint(v[0], v[1]), end, end)
+		}
+	case "h":
+		r.path.closePath()
+	case "re":
+		if v, ok := operandNumbers(args, 4); ok {
+			x, y, w, h := v[0], v[1], v[2], v[3]
+			r.path.moveTo(r.devicePoint(x, y))
+			r.path.lineTo(r.devicePoint(x+w, y))
+			r.path.lineTo(r.devicePoint(x+w, y+h))
+			r.path.lineTo(r.devicePoint(x, y+h))
+			r.path.closePath()
+		}
+
+	// Path painting
+	case "S":
+		r.strokePath()
+		r.endPath()
+	case "s":
+		r.path.closePath()
+		r.strokePath()
+		r.endPath()
+	case "f", "F", "f*":
+		r.fillPath()
+		r.endPath()
+	case "B", "B*":
+		r.fillPath()
+		r.strokePath()
+		r.endPath()
+	case "b", "b*":
+		r.path.closePath()
+		r.fillPath()
+		r.strokePath()
+		r.endPath()
+	case "n":
+		r.endPath()
+	case "W", "W*":
+		r.pendClip = true
+
+	// Colour
+	case "g":
+		r.setColor(false, deviceGray, args)
+	case "G":
+		r.setColor(true, deviceGray, args)
+	case "rg":
+		r.setColor(false, deviceRGB, args)
+	case "RG":
+		r.setColor(true, deviceRGB, args)
+	case "k":
+		r.setColor(false, deviceCMYK, args)
+	case "K":
+		r.setColor(true, deviceCMYK, args)
+	case "cs", "CS":
+		if len(args) >= 1 {
+			if name, ok := args[0].(types.Name); ok {
+				cs := r.resolveColorSpace(resources, string(name))
+				if op.operator == "cs" {
+					gs.fillSpace = cs
+					gs.fillColor = cs.toRGB(make([]float64, cs.comps))
+				} else {
+					gs.strokeSpace = cs
+					gs.strokeColor = cs.toRGB(make([]float64, cs.comps))
+				}
+			}
+		}
+	case "sc", "scn":
+		r.setColor(false, gs.fillSpace, args)
+	case "SC", "SCN":
+		r.setColor(true, gs.strokeSpace, args)
+
+	// XObjects and inline images
+	case "Do":
+		if len(args) >= 1 {
+			if name, ok := args[0].(types.Name); ok {
+				r.drawXObject(resources, string(name))
+			}
+		}
+	case "BI":
+		if len(args) >= 1 {
+			if d, ok := args[0].(types.Dict); ok {
+				r.drawInlineImage(d, op.inline, resources)
+			}
+		}
+
+	// Text
+	case "BT":
+		r.tm = identityMatrix()
+		r.tlm = identityMatrix()
+	case "ET":
+	case "Tc":
+		if v, ok := operandNumbers(args, 1); ok {
+			gs.charSpacing = v[0]
+		}
+	case "Tw":
+		if v, ok := operandNumbers(args, 1); ok {
+			gs.wordSpacing = v[0]
+		}
+	case "Tz":
+		if v, ok := operandNumbers(args, 1); ok {
+			gs.hScale = v[0] / 100
+		}
+	case "TL":
+		if v, ok := operandNumbers(args, 1); ok {
+			gs.leading = v[0]
+		}
+	case "Ts":
+		if v, ok := operandNumbers(args, 1); ok {
+			gs.rise = v[0]
+		}
+	case "Tr":
+		if v, ok := operandNumbers(args, 1); ok {
+			gs.renderMode = int(v[0])
+		}
+	case "Tf":
+		if len(args) >= 2 {
+			name, _ := args[0].(types.Name)
+			size, _ := operandNumber(args[1])
+			gs.font = r.resolveFont(resources, string(name))
+			gs.fontSize = size
+		}
+	case "Td":
+		if v, ok := operandNumbers(args, 2); ok {
+			r.tlm = translateMatrix(v[0], v[1]).mul(r.tlm)
+			r.tm = r.tlm
+		}
+	case "TD":
+		if v, ok := operandNumbers(args, 2); ok {
+			gs.leading = -v[1]
+			r.tlm = translateMatrix(v[0], v[1]).mul(r.tlm)
+			r.tm = r.tlm
+		}
+	case "Tm":
+		if v, ok := operandNumbers(args, 6); ok {
+			r.tlm = matrix{v[0], v[1], v[2], v[3], v[4], v[5]}
+			r.tm = r.tlm
+		}
+	case "T*":
+		r.nextLine()
+	case "Tj":
+		if len(args) >= 1 {
+			if b, ok := operandBytes(args[len(args)-1]); ok {
+				r.showText(b)
+			}
+		}
+	case "'":
+		r.nextLine()
+		if len(args) >= 1 {
+			if b, ok := operandBytes(args[len(args)-1]); ok {
+				r.showText(b)
+			}
+		}
+	case "\"":
+		if len(args) >= 3 {
+			if v, ok := operandNumber(args[0]); ok {
+				gs.wordSpacing = v
+			}
+			if v, ok := operandNumber(args[1]); ok {
+				gs.charSpacing = v
+			}
+			r.nextLine()
+			if b, ok := operandBytes(args[2]); ok {
+				r.showText(b)
+			}
+		}
+	case "TJ":
+		if len(args) >= 1 {
+			if arr, ok := args[len(args)-1].(types.Array); ok {
+				r.showTextArray(arr)
+			}
+		}
+	}
+}
+
+func (r *nativeRenderer) endPath() {
+	if r.pendClip {
+		r.intersectClip(r.path.subpaths)
+		r.pendClip = false
+	}
+	r.path.reset()
+}
+
+func (r *nativeRenderer) setDash(arrObj, phaseObj types.Object) {
+	arr, _ := arrObj.(types.Array)
+	dash := make([]float64, 0, len(arr))
+	total := 0.0
+	for _, o := range arr {
+		if v, ok := operandNumber(o); ok && v >= 0 {
+			dash = append(dash, v)
+			total += v
+		}
+	}
+	if total <= 0 {
+		dash = nil
+	}
+	r.state.dash = dash
+	r.state.dashPhase, _ = operandNumber(phaseObj)
+}
+
+func (r *nativeRenderer) setColor(stroke bool, cs *colorSpace, args []types.Object) {
+	vals := make([]float64, 0, len(args))
+	for _, o := range args {
+		if v, ok := operandNumber(o); ok {
+			vals = append(vals, v)
+		}
+	}
+	c := cs.toRGB(vals)
+	if stroke {
+		r.state.strokeSpace = cs
+		r.state.strokeColor = c
+	} else {
+		r.state.fillSpace = cs
+		r.state.fillColor = c
+	}
+}
+
+func (r *nativeRenderer) resourceEntry(resources types.Dict, category, name string) types.Object {
+	if resources == nil {
+		return nil
+	}
+	sub, err := r.xref().DereferenceDict(resources[category])
+	if err != nil || sub == nil {
+		return nil
+	}
+	return sub[name]
+}
+
+func (r *nativeRenderer) resolveColorSpace(resources types.Dict, name string) *colorSpace {
+	switch name {
+	case "DeviceGray", "G", "CalGray":
+		return deviceGray
+	case "DeviceRGB", "RGB", "CalRGB":
+		return deviceRGB
+	case "DeviceCMYK", "CMYK":
+		return deviceCMYK
+	case "Pattern":
+		return &colorSpace{family: "Pattern", comps: 0}
+	}
+	return r.parseColorSpace(r.resourceEntry(resources, "ColorSpace", name))
+}
+
+func (r *nativeRenderer) parseColorSpace(obj types.Object) *colorSpace {
+	obj, err := r.xref().Dereference(obj)
+	if err != nil || obj == nil {
+		return deviceGray
+	}
+
+	switch v := obj.(type) {
+	case types.Name:
+		return r.resolveColorSpace(nil, string(v))
+	case types.Array:
+		if len(v) == 0 {
+			return deviceGray
+		}
+		family, _ := v[0].(types.Name)
+		switch family {
+		case "ICCBased":
+			if len(v) > 1 {
+				if sd, _, err := r.xref().DereferenceStreamDict(v[1]); err == nil && sd != nil {
+					switch n := sd.IntEntry("N"); {
+					case n != nil && *n == 1:
+						return deviceGray
+					case n != nil && *n == 4:
+						return deviceCMYK
+					}
+				}
+			}
+			return deviceRGB
+		case "CalGray":
+			return deviceGray
+		case "CalRGB", "Lab":
+			return deviceRGB
+		case "Indexed", "I":
+			if len(v) < 4 {
+				return deviceGray
+			}
+			cs := &colorSpace{family: "Indexed", comps: 1, base: r.parseColorSpace(v[1])}
+			if hival, err := r.xref().DereferenceNumber(v[2]); err == nil {
+				cs.hival = int(hival)
+			}
+			cs.lookup = r.lookupBytes(v[3])
+			return cs
+		case "Separation", "DeviceN":
+			return &colorSpace{family: "Separation", comps: 1}
+		case "Pattern":
+			return &colorSpace{family: "Pattern", comps: 0}
+		}
+	}
+	return deviceGray
+}
+
+func (r *nativeRenderer) lookupBytes(obj types.Object) []byte {
+	obj, err := r.xref().Dereference(obj)
+	if err != nil {
+		return nil
+	}
+	switch v := obj.(type) {
+	case types.StringLiteral, types.HexLiteral:
+		b, _ := operandBytes(v)
+		return b
+	case types.StreamDict:
+		if err := v.Decode(); err == nil {
+			return v.Content
+		}
+	}
+	return nil
+}
+
+func (r *nativeRenderer) applyExtGState(resources types.Dict, name string) {
+	d, err := r.xref().DereferenceDict(r.resourceEntry(resources, "ExtGState", name))
+	if err != nil || d == nil {
+		return
+	}
+	gs := &r.state
+	if v, err := r.xref().DereferenceNumber(d["ca"]); err == nil {
+		gs.fillAlpha = v
+	}
+	if v, err := r.xref().DereferenceNumber(d["CA"]); err == nil {
+		gs.strokeAlpha = v
+	}
+	if v, err := r.xref().DereferenceNumber(d["LW"]); err == nil {
+		gs.lineWidth = v
+	}
+	if v, err := r.xref().DereferenceNumber(d["LC"]); err == nil {
+		gs.lineCap = int(v)
+	}
+	if v, err := r.xref().DereferenceNumber(d["LJ"]); err == nil {
+		gs.lineJoin = int(v)
+	}
+	if v, err := r.xref().DereferenceNumber(d["ML"]); err == nil {
+		gs.miterLimit = v
+	}
+	if arr, err := r.xref().DereferenceArray(d["D"]); err == nil && len(arr) == 2 {
+		r.setDash(arr[0], arr[1])
+	}
+	if arr, err := r.xref().DereferenceArray(d["Font"]); err == nil && len(arr) == 2 {
+		if fontDict, err := r.xref().DereferenceDict(arr[0]); err == nil && fontDict != nil {
+			gs.font = loadPDFFont(r.xref(), fontDict)
+		}
+		if size, err := r.xref().DereferenceNumber(arr[1]); err == nil {
+			gs.fontSize = size
+		}
+	}
+}
+
+func (r *nativeRenderer) resolveFont(resources types.Dict, name string) *pdfFont {
+	entry := r.resourceEntry(resources, "Font", name)
+	key := name
+	if ref, ok := entry.(types.IndirectRef); ok {
+		key = ref.String()
+	}
+	if f, ok := r.fonts[key]; ok {
+		return f
+	}
+
+	f := defaultPDFFont()
+	if fontDict, err := r.xref().DereferenceDict(entry); err == nil && fontDict != nil {
+		f = loadPDFFont(r.xref(), fontDict)
+	}
+	r.fonts[key] = f
+	return f
+}
+
+// Rasterization
+
+// coverage rasterizes closed polygons (device space) into an alpha mask covering bounds.
+func (r *nativeRenderer) coverage(polys [][]point) (*image.Alpha, image.Rectangle) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, poly := range polys {
+		for _, p := range poly {
+			minX = math.Min(minX, p.x)
+			minY = math.Min(minY, p.y)
+			maxX = math.Max(maxX, p.x)
+			maxY = math.Max(maxY, p.y)
+		}
+	}
+	if math.IsInf(minX, 0) {
+		return nil, image.Rectangle{}
+	}
+
+	bounds := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX))+1, int(math.Ceil(maxY))+1)
+	bounds = bounds.Intersect(r.dst.Bounds())
+	if bounds.Empty() {
+		return nil, image.Rectangle{}
+	}
+
+	ras := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	ox, oy := float64(bounds.Min.X), float64(bounds.Min.Y)
+	for _, poly := range polys {
+		if len(poly) < 2 {
+			continue
+		}
+		ras.MoveTo(float32(poly[0].x-ox), float32(poly[0].y-oy))
+		for _, p := range poly[1:] {
+			ras.LineTo(float32(p.x-ox), float32(p.y-oy))
+		}
+		ras.ClosePath()
+	}
+
+	mask := image.NewAlpha(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	ras.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+	return mask, bounds
+}
+
+// paint composites a solid colour through a coverage mask, the clip and a constant alpha.
+func (r *nativeRenderer) paint(mask *image.Alpha, bounds image.Rectangle, c color.NRGBA, alpha float64) {
+	if mask == nil || alpha <= 0 {
+		return
+	}
+	clip := r.state.clip
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		mrow := (y - bounds.Min.Y) * mask.Stride
+		drow := y*r.dst.Stride + bounds.Min.X*4
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			cov := float64(mask.Pix[mrow+x-bounds.Min.X]) / 255
+			if clip != nil {
+				cov *= float64(clip.Pix[y*clip.Stride+x]) / 255
+			}
+			a := cov * alpha * float64(c.A) / 255
+			if a > 0 {
+				i := drow + (x-bounds.Min.X)*4
+				blendPixel(r.dst.Pix[i:i+4], c.R, c.G, c.B, a)
+			}
+		}
+	}
+}
+
+func blendPixel(px []byte, red, green, blue uint8, a float64) {
+	if a >= 1 {
+		px[0], px[1], px[2], px[3] = red, green, blue, 255
+		return
+	}
+	inv := 1 - a
+	px[0] = uint8(float64(red)*a + float64(px[0])*inv + 0.5)
+	px[1] = uint8(float64(green)*a + float64(px[1])*inv + 0.5)
+	px[2] = uint8(float64(blue)*a + float64(px[2])*inv + 0.5)
+	px[3] = 255
+}
+
+func (r *nativeRenderer) fillPath() {
+	if r.path.empty() || !r.painting() {
+		return
+	}
+	mask, bounds := r.coverage(r.path.subpaths)
+	r.paint(mask, bounds, r.state.fillColor, r.state.fillAlpha)
+}
+
+func (r *nativeRenderer) strokePath() {
+	if r.path.empty() || !r.painting() {
+		return
+	}
+	gs := &r.state
+	scale := gs.ctm.scale()
+	width := gs.lineWidth * scale
+	if width < 1 {
+		width = 1
+	}
+
+	subpaths, closed := r.path.subpaths, r.path.closed
+	if len(gs.dash) > 0 {
+		dash := make([]float64, len(gs.dash))
+		for i, d := range gs.dash {
+			dash[i] = d * scale
+		}
+		subpaths, closed = dashSubpaths(subpaths, closed, dash, gs.dashPhase*scale)
+	}
+
+	polys := strokePolygons(subpaths, closed, width, gs.lineCap, gs.lineJoin, gs.miterLimit)
+	mask, bounds := r.coverage(polys)
+	r.paint(mask, bounds, gs.strokeColor, gs.strokeAlpha)
+}
+
+func (r *nativeRenderer) intersectClip(subpaths [][]point) {
+	if !r.painting() {
+		return
+	}
+	full := image.NewAlpha(r.dst.Bounds())
+	mask, bounds := r.coverage(subpaths)
+	if mask != nil {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			copy(full.Pix[y*full.Stride+bounds.Min.X:y*full.Stride+bounds.Max.X],
+				mask.Pix[(y-bounds.Min.Y)*mask.Stride:(y-bounds.Min.Y)*mask.Stride+bounds.Dx()])
+		}
+	}
+	if old := r.state.clip; old != nil {
+		for i := range full.Pix {
+			full.Pix[i] = uint8(uint16(full.Pix[i]) * uint16(old.Pix[i]) / 255)
+		}
+	}
+	r.state.clip = full
+}
+
+// orient returns poly with positive signed area so overlapping stroke pieces never cancel out.
+func orient(poly []point) []point {
+	area := 0.0
+	for i := range poly {
+		j := (i + 1) % len(poly)
+		area += poly[i].x*poly[j].y - poly[j].x*poly[i].y
+	}
+	if area >= 0 {
+		return poly
+	}
+	out := make([]point, len(poly))
+	for i := range poly {
+		out[i] = poly[len(poly)-1-i]
+	}
+	return out
+}
+
+func discPolygon(c point, radius float64) []point {
+	steps := int(radius*2) + 8
+	if steps > 48 {
+		steps = 48
+	}
+	poly := make([]point, steps)
+	for i := range poly {
+		a := 2 * math.Pi * float64(i) / float64(steps)
+		poly[i] = point{c.x + radius*math.Cos(a), c.y + radius*math.Sin(a)}
+	}
+	return poly
+}
+
+// strokePolygons converts polylines into filled outline pieces: one quad per segment plus joins and caps.
+func strokePolygons(subpaths [][]point, closed []bool, width float64, lineCap, lineJoin int, miterLimit float64) [][]point {
+	half := width / 2
+	var polys [][]point
+
+	for si, sp := range subpaths {
+		pts := dedupePoints(sp)
+		isClosed := si < len(closed) && closed[si]
+		if isClosed && len(pts) > 1 && pts[0] != pts[len(pts)-1] {
+			pts = append(pts, pts[0])
+		}
+
+		if len(pts) == 1 {
+			// A zero-length subpath only paints with round or square caps.
+			switch lineCap {
+			case 1:
+				polys = append(polys, discPolygon(pts[0], half))
+			case 2:
+				p := pts[0]
+				polys = append(polys, []point{{p.x - half, p.y - half}, {p.x + half, p.y - half}, {p.x + half, p.y + half}, {p.x - half, p.y + half}})
+			}
+			continue
+		}
+
+		for i := 0; i+1 < len(pts); i++ {
+			a, b := pts[i], pts[i+1]
+			dx, dy := b.x-a.x, b.y-a.y
+			length := math.Hypot(dx, dy)
+			if length == 0 {
+				continue
+			}
+			ux, uy := dx/length, dy/length
+			nx, ny := -uy*half, ux*half
+
+			if lineCap == 2 && !isClosed {
+				if i == 0 {
+					a = point{a.x - ux*half, a.y - uy*half}
+				}
+				if i+2 == len(pts) {
+					b = point{b.x + ux*half, b.y + uy*half}
+				}
+			}
+			polys = append(polys, orient([]point{
+				{a.x + nx, a.y + ny},
+				{b.x + nx, b.y + ny},
+				{b.x - nx, b.y - ny},
+				{a.x - nx, a.y - ny},
+			}))
+		}
+
+		// Joins between consecutive segments.
+		last := len(pts) - 1
+		for i := 1; i <= last; i++ {
+			if i == last && !isClosed {
+				break
+			}
+			prev := pts[i-1]
+			v := pts[i]
+			next := pts[(i+1)%len(pts)]
+			if i == last {
+				next = pts[1]
+			}
+			polys = append(polys, joinPolygons(prev, v, next, half, lineJoin, miterLimit)...)
+		}
+
+		if lineCap == 1 && !isClosed {
+			polys = append(polys, discPolygon(pts[0], half), discPolygon(pts[last], half))
+		}
+	}
+	return polys
+}
+
+func joinPolygons(prev, v, next point, half float64, lineJoin int, miterLimit float64) [][]point {
+	d1x, d1y := v.x-prev.x, v.y-prev.y
+	d2x, d2y := next.x-v.x, next.y-v.y
+	l1, l2 := math.Hypot(d1x, d1y), math.Hypot(d2x, d2y)
+	if l1 == 0 || l2 == 0 {
+		return nil
+	}
+	n1 := point{-d1y / l1 * half, d1x / l1 * half}
+	n2 := point{-d2y / l2 * half, d2x / l2 * half}
+
+	if lineJoin == 1 {
+		return [][]point{discPolygon(v, half)}
+	}
+
+	polys := [][]point{
+		orient([]point{v, {v.x + n1.x, v.y + n1.y}, {v.x + n2.x, v.y + n2.y}}),
+		orient([]point{v, {v.x - n1.x, v.y - n1.y}, {v.x - n2.x, v.y - n2.y}}),
+	}
+	if lineJoin != 0 {
+		return polys
+	}
+
+	// Miter: extend the outer bevel to the intersection of the offset edges.
+	bx, by := n1.x+n2.x, n1.y+n2.y
+	bl := math.Hypot(bx, by)
+	if bl < 1e-9 {
+		return polys
+	}
+	cosHalf := (bx*n1.x + by*n1.y) / (bl * half)
+	if cosHalf <= 1e-9 {
+		return polys
+	}
+	miterLen := half / cosHalf
+	if miterLen/half > miterLimit {
+		return polys
+	}
+	for _, s := range []float64{1, -1} {
+		m := point{v.x + s*bx/bl*miterLen, v.y + s*by/bl*miterLen}
+		polys = append(polys, orient([]point{v, {v.x + s*n1.x, v.y + s*n1.y}, m, {v.x + s*n2.x, v.y + s*n2.y}}))
+	}
+	return polys
+}
+
+func dedupePoints(pts []point) []point {
+	out := make([]point, 0, len(pts))
+	for _, p := range pts {
+		if len(out) > 0 && math.Abs(out[len(out)-1].x-p.x) < 1e-6 && math.Abs(out[len(out)-1].y-p.y) < 1e-6 {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// dashSubpaths splits polylines into the "on" intervals of a dash pattern.
+func dashSubpaths(subpaths [][]point, closed []bool, dash []float64, phase float64) ([][]point, []bool) {
+	total := 0.0
+	for _, d := range dash {
+		total += d
+	}
+	if total <= 0 {
+		return subpaths, closed
+	}
+
+	var out [][]point
+	for si, sp := range subpaths {
+		pts := sp
+		if si < len(closed) && closed[si] && len(sp) > 1 {
+			pts = append(append([]point(nil), sp...), sp[0])
+		}
+
+		idx := 0
+		remaining := dash[0]
+		on := true
+		for p := math.Mod(phase, total); p > 0; {
+			if p >= remaining {
+				p -= remaining
+				idx = (idx + 1) % len(dash)
+				remaining = dash[idx]
+				on = !on
+				continue
+			}
+			remaining -= p
+			p = 0
+		}
+
+		var current []point
+		if on && len(pts) > 0 {
+			current = []point{pts[0]}
+		}
+		for i := 0; i+1 < len(pts); i++ {
+			a, b := pts[i], pts[i+1]
+			segLen := math.Hypot(b.x-a.x, b.y-a.y)
+			pos := 0.0
+			for segLen-pos > remaining {
+				pos += remaining
+				t := pos / segLen
+				split := point{a.x + (b.x-a.x)*t, a.y + (b.y-a.y)*t}
+				if on {
+					current = append(current, split)
+					out = append(out, current)
+					current = nil
+				} else {
+					current = []point{split}
+				}
+				on = !on
+				idx = (idx + 1) % len(dash)
+				remaining = dash[idx]
+			}
+			remaining -= segLen - pos
+			if on {
+				current = append(current, b)
+			}
+		}
+		if on && len(current) > 1 {
+			out = append(out, current)
+		}
+	}
+	return out, make([]bool, len(out))
+}
+
+// Text
+
+func (r *nativeRenderer) nextLine() {
+	r.tlm = translateMatrix(0, -r.state.leading).mul(r.tlm)
+	r.tm = r.tlm
+}
+
+func (r *nativeRenderer) showTextArray(arr types.Array) {
+	gs := &r.state
+	for _, item := range arr {
+		if v, ok := operandNumber(item); ok {
+			tx := -v / 1000 * gs.fontSize * gs.hScale
+			r.tm = translateMatrix(tx, 0).mul(r.tm)
+			continue
+		}
+		if b, ok := operandBytes(item); ok {
+			r.showText(b)
+		}
+	}
+}
+
+func (r *nativeRenderer) showText(b []byte) {
+	gs := &r.state
+	f := gs.font
+	if f == nil {
+		f = defaultPDFFont()
+		gs.font = f
+	}
+
+	for _, g := range f.decode(b) {
+		trm := matrix{gs.fontSize * gs.hScale, 0, 0, gs.fontSize, 0, gs.rise}.mul(r.tm).mul(gs.ctm)
+		mode := gs.renderMode % 4
+		if mode != 3 && r.painting() {
+			r.drawGlyph(f, g, trm, mode)
+		}
+		if r.onGlyph != nil {
+			r.onGlyph(textGlyph{
+				text:    glyphText(f, g),
+				font:    f.baseFont,
+				trm:     trm,
+				width:   g.width / 1000,
+				ascent:  f.ascent / 1000,
+				descent: f.descent / 1000,
+			})
+		}
+
+		advance := g.width/1000*gs.fontSize + gs.charSpacing
+		if g.space {
+			advance += gs.wordSpacing
+		}
+		r.tm = translateMatrix(advance*gs.hScale, 0).mul(r.tm)
+	}
+}
+
+func (r *nativeRenderer) drawGlyph(f *pdfFont, g fontGlyph, trm matrix, mode int) {
+	if f.face == nil {
+		return
+	}
+	var ch rune
+	switch {
+	case g.text != "":
+		ch = []rune(g.text)[0]
+	case !f.composite && g.code >= 33:
+		ch = rune(g.code)
+	default:
+		return
+	}
+	if ch == ' ' || ch == ' ' {
+		return
+	}
+
+	idx, err := f.face.GlyphIndex(&f.buf, ch)
+	if err != nil || idx == 0 {
+		return
+	}
+	upem := int(f.face.UnitsPerEm())
+	segments, err := f.face.LoadGlyph(&f.buf, idx, fixed.I(upem), nil)
+	if err != nil {
+		return
+	}
+
+	// sfnt outlines are y-down in font units; glyph space is y-up in ems.
+	scale := 1 / float64(upem)
+	toDevice := func(p fixed.Point26_6) point {
+		x, y := trm.apply(float64(p.X)/64*scale, -float64(p.Y)/64*scale)
+		return point{x, y}
+	}
+
+	var path pathBuilder
+	for _, seg := range segments {
+		switch seg.Op {
+		case sfnt.SegmentOpMoveTo:
+			if !path.empty() {
+				path.closePath()
+			}
+			path.moveTo(toDevice(seg.Args[0]))
+		case sfnt.SegmentOpLineTo:
+			path.lineTo(toDevice(seg.Args[0]))
+		case sfnt.SegmentOpQuadTo:
+			c := toDevice(seg.Args[0])
+			end := toDevice(seg.Args[1])
+			start := path.current
+			c1 := point{start.x + 2.0/3*(c.x-start.x), start.y + 2.0/3*(c.y-start.y)}
+			c2 := point{end.x + 2.0/3*(c.x-end.x), end.y + 2.0/3*(c.y-end.y)}
+			path.curveTo(c1, c2, end)
+		case sfnt.SegmentOpCubeTo:
+			path.curveTo(toDevice(seg.Args[0]), toDevice(seg.Args[1]), toDevice(seg.Args[2]))
+		}
+	}
+	path.closePath()
+
+	if mode == 0 || mode == 2 {
+		mask, bounds := r.coverage(path.subpaths)
+		r.paint(mask, bounds, r.state.fillColor, r.state.fillAlpha)
+	}
+	if mode == 1 || mode == 2 {
+		width := r.state.lineWidth * r.state.ctm.scale()
+		if width < 1 {
+			width = 1
+		}
+		polys := strokePolygons(path.subpaths, path.closed, width, 0, 0, r.state.miterLimit)
+		mask, bounds := r.coverage(polys)
+		r.paint(mask, bounds, r.state.strokeColor, r.state.strokeAlpha)
+	}
+}
+
+// XObjects
+
+func (r *nativeRenderer) drawXObject(resources types.Dict, name string) {
+	entry := r.resourceEntry(resources, "XObject", name)
+	if entry == nil {
+		return
+	}
+	objNr := 0
+	if ref, ok := entry.(types.IndirectRef); ok {
+		objNr = ref.ObjectNumber.Value()
+	}
+	sd, _, err := r.xref().DereferenceStreamDict(entry)
+	if err != nil || sd == nil {
+		return
+	}
+
+	switch subtype := sd.Subtype(); {
+	case subtype != nil && *subtype == "Image":
+		r.drawImageXObject(sd, name, objNr)
+	case subtype != nil && *subtype == "Form":
+		r.drawForm(sd, resources)
+	}
+}
+
+func (r *nativeRenderer) drawForm(sd *types.StreamDict, parentResources types.Dict) {
+	if r.depth >= maxFormDepth {
+		return
+	}
+	if err := sd.Decode(); err != nil {
+		return
+	}
+
+	resources := parentResources
+	if d, err := r.xref().DereferenceDict(sd.Dict["Resources"]); err == nil && d != nil {
+		resources = d
+	}
+
+	savedState := r.state
+	savedStack := len(r.stack)
+	savedTM, savedTLM := r.tm, r.tlm
+
+	if arr, err := r.xref().DereferenceArray(sd.Dict["Matrix"]); err == nil && len(arr) == 6 {
+		if v, ok := operandNumbers(arr, 6); ok {
+			r.state.ctm = matrix{v[0], v[1], v[2], v[3], v[4], v[5]}.mul(r.state.ctm)
+		}
+	}
+	if arr, err := r.xref().DereferenceArray(sd.Dict["BBox"]); err == nil && len(arr) == 4 {
+		if v, ok := operandNumbers(arr, 4); ok {
+			var clip pathBuilder
+			clip.moveTo(r.devicePoint(v[0], v[1]))
+			clip.lineTo(r.devicePoint(v[2], v[1]))
+			clip.lineTo(r.devicePoint(v[2], v[3]))
+			clip.lineTo(r.devicePoint(v[0], v[3]))
+			clip.closePath()
+			r.intersectClip(clip.subpaths)
+		}
+	}
+
+	ops, _ := parseContentStream(sd.Content)
+	r.depth++
+	r.run(ops, resources)
+	r.depth--
+
+	r.state = savedState
+	if len(r.stack) > savedStack {
+		r.stack = r.stack[:savedStack]
+	}
+	r.tm, r.tlm = savedTM, savedTLM
+}
+
+// Annotations
+
+// Annotation flags (/F) of annotations that are not displayed.
+const (
+	annotationFlagHidden = 1 << 1
+	annotationFlagNoView = 1 << 5
+)
+
+// drawAnnotations paints the normal appearance stream of each displayed
+// annotation of a page over its content. base maps user space to device
+// space.
+func (r *nativeRenderer) drawAnnotations(pageDict types.Dict, base matrix) {
+	annots, err := r.xref().DereferenceArray(pageDict["Annots"])
+	if err != nil {
+		return
+	}
+	for _, obj := range annots {
+		annot, err := r.xref().DereferenceDict(obj)
+		if err != nil || annot == nil {
+			continue
+		}
+		if f := annot.IntEntry("F"); f != nil && *f&(annotationFlagHidden|annotationFlagNoView) != 0 {
+			continue
+		}
+		sd := r.normalAppearance(annot)
+		if sd == nil {
+			continue
+		}
+		rect := pageBox(r.xref(), annot, "Rect")
+		if rect == nil {
+			continue
+		}
+		place, ok := r.appearanceMatrix(sd, *rect)
+		if !ok {
+			continue
+		}
+		r.state = newGraphicsState(place.mul(base))
+		r.stack = r.stack[:0]
+		r.drawForm(sd, nil)
+	}
+}
+
+// normalAppearance returns the form XObject that displays an annotation:
+// /AP /N, or the entry of /N selected by /AS for annotations with several
+// appearance states.
+func (r *nativeRenderer) normalAppearance(annot types.Dict) *types.StreamDict {
+	ap, err := r.xref().DereferenceDict(annot["AP"])
+	if err != nil || ap == nil {
+		return nil
+	}
+	entry, err := r.xref().Dereference(ap["N"])
+	if err != nil {
+		return nil
+	}
+	switch n := entry.(type) {
+	case types.StreamDict:
+		return &n
+	case types.Dict:
+		state := annot.NameEntry("AS")
+		if state == nil {
+			return nil
+		}
+		sd, _, err := r.xref().DereferenceStreamDict(n[*state])
+		if err != nil {
+			return nil
+		}
+		return sd
+	}
+	return nil
+}
+
+// appearanceMatrix returns the matrix that maps an appearance stream onto
+// the annotation rectangle (PDF 32000-1 12.5.5): the form's /BBox,
+// transformed by its /Matrix, is scaled and translated to fit rect. The
+// form's own /Matrix is applied by drawForm.
+func (r *nativeRenderer) appearanceMatrix(sd *types.StreamDict, rect Rect) (matrix, bool) {
+	bbox := pageBox(r.xref(), sd.Dict, "BBox")
+	if bbox == nil {
+		return matrix{}, false
+	}
+	form := identityMatrix()
+	if arr, err := r.xref().DereferenceArray(sd.Dict["Matrix"]); err == nil && len(arr) == 6 {
+		if v, ok := operandNumbers(arr, 6); ok {
+			form = matrix{v[0], v[1], v[2], v[3], v[4], v[5]}
+		}
+	}
+
+	t := Rect{LLX: math.Inf(1), LLY: math.Inf(1), URX: math.Inf(-1), URY: math.Inf(-1)}
+	for _, c := range [][2]float64{{bbox.LLX, bbox.LLY}, {bbox.URX, bbox.LLY}, {bbox.LLX, bbox.URY}, {bbox.URX, bbox.URY}} {
+		x, y := form.apply(c[0], c[1])
+		t = Rect{LLX: min(t.LLX, x), LLY: min(t.LLY, y), URX: max(t.URX, x), URY: max(t.URY, y)}
+	}
+	if t.Empty() {
+		return matrix{}, false
+	}
+	sx, sy := rect.Width()/t.Width(), rect.Height()/t.Height()
+	return matrix{sx, 0, 0, sy, rect.LLX - t.LLX*sx, rect.LLY - t.LLY*sy}, true
+}
+
+func (r *nativeRenderer) drawImageXObject(sd *types.StreamDict, name string, objNr int) {
+	if !r.painting() {
+		return
+	}
+	if mask := sd.BooleanEntry("ImageMask"); mask != nil && *mask {
+		if err := sd.Decode(); err != nil {
+			return
+		}
+		w, h := sd.IntEntry("Width"), sd.IntEntry("Height")
+		if w == nil || h == nil {
+			return
+		}
+		invert := false
+		if dec := sd.ArrayEntry("Decode"); len(dec) == 2 {
+			if v, ok := operandNumber(dec[0]); ok && v == 1 {
+				invert = true
+			}
+		}
+		r.drawStencil(stencilMask(sd.Content, *w, *h, invert))
+		return
+	}
+
+	img, ok := r.images[objNr]
+	if !ok || objNr == 0 {
+		img = r.decodeImageXObject(sd, name, objNr)
+		if objNr != 0 {
+			r.images[objNr] = img
+		}
+	}
+	if img != nil {
+		r.drawImage(img)
+	}
+}
+
+func (r *nativeRenderer) decodeImageXObject(sd *types.StreamDict, name string, objNr int) image.Image {
+	extracted, err := pdfcpu.ExtractImage(r.ctx, sd, false, name, objNr, false)
+	if err != nil || extracted == nil || extracted.Reader == nil {
+		return nil
+	}
+	img, _, err := image.Decode(extracted.Reader)
+	if err != nil {
+		return nil
+	}
+	return img
+}
+
+// stencilMask turns 1-bit image mask samples into an alpha image; set bits paint unless inverted.
+func stencilMask(data []byte, w, h int, invert bool) *image.Alpha {
+	mask := image.NewAlpha(image.Rect(0, 0, w, h))
+	rowBytes := (w + 7) / 8
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*rowBytes + x/8
+			if i >= len(data) {
+				return mask
+			}
+			bit := data[i]>>(7-uint(x%8))&1 == 1
+			if bit == invert {
+				mask.Pix[y*mask.Stride+x] = 255
+			}
+		}
+	}
+	return mask
+}
+
+// imageTransform returns the device bounds of the unit square and its inverse mapping.
+func (r *nativeRenderer) imageTransform() (image.Rectangle, matrix, bool) {
+	inv, ok := r.state.ctm.invert()
+	if !ok {
+		return image.Rectangle{}, matrix{}, false
+	}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, c := range []point{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		p := r.devicePoint(c.x, c.y)
+		minX, minY = math.Min(minX, p.x), math.Min(minY, p.y)
+		maxX, maxY = math.Max(maxX, p.x), math.Max(maxY, p.y)
+	}
+	bounds := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+	return bounds.Intersect(r.dst.Bounds()), inv, true
+}
+
+func (r *nativeRenderer) drawImage(src image.Image) {
+	bounds, inv, ok := r.imageTransform()
+	if !ok || bounds.Empty() {
+		return
+	}
+	sb := src.Bounds()
+	sw, sh := float64(sb.Dx()), float64(sb.Dy())
+	clip := r.state.clip
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			u, v := inv.apply(float64(x)+0.5, float64(y)+0.5)
+			if u < 0 || u >= 1 || v < 0 || v >= 1 {
+				continue
+			}
+			sx := sb.Min.X + int(u*sw)
+			sy := sb.Min.Y + int((1-v)*sh)
+			if sy >= sb.Max.Y {
+				sy = sb.Max.Y - 1
+			}
+			cr, cg, cb, ca := src.At(sx, sy).RGBA()
+			if ca == 0 {
+				continue
+			}
+			a := float64(ca) / 0xffff * r.state.fillAlpha
+			if clip != nil {
+				a *= float64(clip.Pix[y*clip.Stride+x]) / 255
+			}
+			if a <= 0 {
+				continue
+			}
+			// Un-premultiply before blending.
+			red := uint8(cr * 0xff / ca)
+			green := uint8(cg * 0xff / ca)
+			blue := uint8(cb * 0xff / ca)
+			i := y*r.dst.Stride + x*4
+			blendPixel(r.dst.Pix[i:i+4], red, green, blue, a)
+		}
+	}
+}
+
+func (r *nativeRenderer) drawStencil(mask *image.Alpha) {
+	bounds, inv, ok := r.imageTransform()
+	if !ok || bounds.Empty() {
+		return
+	}
+	mb := mask.Bounds()
+	mw, mh := float64(mb.Dx()), float64(mb.Dy())
+	cov := image.NewAlpha(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			u, v := inv.apply(float64(x)+0.5, float64(y)+0.5)
+			if u < 0 || u >= 1 || v < 0 || v >= 1 {
+				continue
+			}
+			sx := int(u * mw)
+			sy := int((1 - v) * mh)
+			if sy >= mb.Dy() {
+				sy = mb.Dy() - 1
+			}
+			cov.Pix[(y-bounds.Min.Y)*cov.Stride+x-bounds.Min.X] = mask.Pix[sy*mask.Stride+sx]
+		}
+	}
+	r.paint(cov, bounds, r.state.fillColor, r.state.fillAlpha)
+}
+
+// inlineImageKey expands abbreviated inline image dictionary keys.
+var inlineImageKey = map[string]string{
+	"W": "Width", "H": "Height", "BPC": "BitsPerComponent", "CS": "ColorSpace",
+	"F": "Filter", "IM": "ImageMask", "D": "Decode", "DP": "DecodeParms",
+}
+
+func (r *nativeRenderer) drawInlineImage(d types.Dict, data []byte, resources types.Dict) {
+	if !r.painting() {
+		return
+	}
+	entries := map[string]types.Object{}
+	for k, v := range d {
+		if long, ok := inlineImageKey[k]; ok {
+			k = long
+		}
+		entries[k] = v
+	}
+
+	w, _ := operandNumber(entries["Width"])
+	h, _ := operandNumber(entries["Height"])
+	if w <= 0 || h <= 0 {
+		return
+	}
+	bpc := 8.0
+	if v, ok := operandNumber(entries["BitsPerComponent"]); ok {
+		bpc = v
+	}
+
+	data, err := decodeInlineFilters(entries["Filter"], data)
+	if err != nil {
+		return
+	}
+
+	if im, ok := entries["ImageMask"].(types.Boolean); ok && bool(im) {
+		invert := false
+		if dec, ok := entries["Decode"].(types.Array); ok && len(dec) == 2 {
+			if v, ok := operandNumber(dec[0]); ok && v == 1 {
+				invert = true
+			}
+		}
+		r.drawStencil(stencilMask(data, int(w), int(h), invert))
+		return
+	}
+
+	cs := deviceGray
+	if name, ok := entries["ColorSpace"].(types.Name); ok {
+		cs = r.resolveColorSpace(resources, string(name))
+	} else if entries["ColorSpace"] != nil {
+		cs = r.parseColorSpace(entries["ColorSpace"])
+	}
+
+	if f, ok := entries["Filter"].(types.Name); ok && (f == "DCT" || f == "DCTDecode") {
+		if img, _, err := image.Decode(bytes.NewReader(data)); err == nil {
+			r.drawImage(img)
+		}
+		return
+	}
+
+	if img := rawSamplesImage(data, int(w), int(h), int(bpc), cs); img != nil {
+		r.drawImage(img)
+	}
+}
+
+func decodeInlineFilters(filter types.Object, data []byte) ([]byte, error) {
+	var names []string
+	switch v := filter.(type) {
+	case types.Name:
+		names = []string{string(v)}
+	case types.Array:
+		for _, o := range v {
+			if n, ok := o.(types.Name); ok {
+				names = append(names, string(n))
+			}
+		}
+	}
+
+	for _, name := range names {
+		switch name {
+		case "AHx", "ASCIIHexDecode":
+			s := strings.Map(func(r rune) rune {
+				if r == '>' || r == ' ' || r == '\n' || r == '\r' || r == '\t' {
+					return -1
+				}
+				return r
+			}, string(data))
+			if len(s)%2 == 1 {
+				s += "0"
+			}
+			decoded, err := hex.DecodeString(s)
+			if err != nil {
+				return nil, err
+			}
+			data = decoded
+		case "Fl", "FlateDecode":
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			decoded, err := io.ReadAll(zr)
+			zr.Close()
+			if err != nil && len(decoded) == 0 {
+				return nil, err
+			}
+			data = decoded
+		case "DCT", "DCTDecode":
+			// Left for image.Decode.
+		default:
+			return nil, fmt.Errorf("unsupported inline image filter %s", name)
+		}
+	}
+	return data, nil
+}
+
+// rawSamplesImage builds an image from uncompressed samples in the given colour space.
+func rawSamplesImage(data []byte, w, h, bpc int, cs *colorSpace) image.Image {
+	comps := cs.comps
+	if comps <= 0 {
+		comps = 1
+	}
+	if bpc != 8 && bpc != 1 && bpc != 2 && bpc != 4 {
+		return nil
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	rowBits := w * comps * bpc
+	rowBytes := (rowBits + 7) / 8
+	maxVal := float64(int(1)<<uint(bpc) - 1)
+	vals := make([]float64, comps)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			for c := 0; c < comps; c++ {
+				bitPos := (x*comps + c) * bpc
+				i := y*rowBytes + bitPos/8
+				if i >= len(data) {
+					return img
+				}
+				sample := int(data[i])
+				if bpc < 8 {
+					shift := 8 - bpc - bitPos%8
+					sample = (sample >> uint(shift)) & (int(1)<<uint(bpc) - 1)
+				}
+				if cs.family == "Indexed" {
+					vals[c] = float64(sample)
+				} else {
+					vals[c] = float64(sample) / maxVal
+				}
+			}
+			img.SetNRGBA(x, y, cs.toRGB(vals))
+		}
+	}
+	return img
+}
@@ -0,0 +1,191 @@
+package pdf
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+// writeContentPDF writes a single-page PDF with the given page content stream.
+func writeContentPDF(t *testing.T, mediaBox, content string) string {
+	t.Helper()
+	return writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox %s /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>", mediaBox),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	)
+}
+
+// writeObjectsPDF writes a PDF of the given objects, numbered from 1, with
+// object 1 as the catalog.
+func writeObjectsPDF(t *testing.T, objects ...string) string {
+	t.Helper()
+
+	out := "%PDF-1.4\n"
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = len(out)
+		out += fmt.Sprintf("%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := len(out)
+	out += fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		out += fmt.Sprintf("%010d 00000 n \n", off)
+	}
+	out += fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	path := filepath.Join(t.TempDir(), "content.pdf")
+	if err := os.WriteFile(path, []byte(out), 0644); err != nil {
+		t.Fatalf("failed to write test PDF: %v", err)
+	}
+	return path
+}
+
+func TestRenderPageNativeFilledRect(t *testing.T) {
+	path := writeContentPDF(t, "[0 0 200 100]", "1 0 0 rg 10 10 50 30 re f 0 0 1 RG 4 w 100 50 m 190 50 l S")
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		t.Fatalf("ReadContextFile() failed: %v", err)
+	}
+
+	img, err := renderPageNative(ctx, 0, 144)
+	if err != nil {
+		t.Fatalf("renderPageNative() failed: %v", err)
+	}
+
+	b := img.Bounds()
+	if b.Dx() != 400 || b.Dy() != 200 {
+		t.Fatalf("image size = %dx%d, want 400x200", b.Dx(), b.Dy())
+	}
+
+	tests := []struct {
+		name string
+		x, y int
+		want color.RGBA
+	}{
+		{"background", 5, 5, color.RGBA{255, 255, 255, 255}},
+		// Rectangle spans user y 10..40, i.e. device rows 120..180 at 2x.
+		{"fill", 60, 150, color.RGBA{255, 0, 0, 255}},
+		{"stroke", 300, 100, color.RGBA{0, 0, 255, 255}},
+	}
+	for _, tt := range tests {
+		got := color.RGBAModel.Convert(img.At(tt.x, tt.y)).(color.RGBA)
+		if got != tt.want {
+			t.Errorf("%s pixel (%d,%d) = %v, want %v", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestRenderPageNativeText(t *testing.T) {
+	path := writeContentPDF(t, "[0 0 200 100]", "BT /F1 40 Tf 10 30 Td (HH) Tj ET")
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		t.Fatalf("ReadContextFile() failed: %v", err)
+	}
+
+	img, err := renderPageNative(ctx, 0, 72)
+	if err != nil {
+		t.Fatalf("renderPageNative() failed: %v", err)
+	}
+
+	dark := 0
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, _, _, _ := img.At(x, y).RGBA()
+			if r < 0x8000 {
+				dark++
+			}
+		}
+	}
+	if dark == 0 {
+		t.Error("expected glyph pixels to be painted")
+	}
+}
+
+func TestRenderPageNativeRotation(t *testing.T) {
+	path := writeContentPDF(t, "[0 0 200 100] /Rotate 90", "")
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		t.Fatalf("ReadContextFile() failed: %v", err)
+	}
+
+	img, err := renderPageNative(ctx, 0, 72)
+	if err != nil {
+		t.Fatalf("renderPageNative() failed: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 100 || b.Dy() != 200 {
+		t.Errorf("rotated size = %dx%d, want 100x200", b.Dx(), b.Dy())
+	}
+}
+
+func TestRenderPageNativeAnnotations(t *testing.T) {
+	form := func(attrs, content string) string {
+		return fmt.Sprintf("<< /Type /XObject /Subtype /Form %s /Length %d >>\nstream\n%s\nendstream", attrs, len(content), content)
+	}
+	path := writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 200 300] /Annots [4 0 R 5 0 R 6 0 R] >>",
+		"<< /Type /Annot /Subtype /Square /Rect [20 20 60 60] /AP << /N 7 0 R >> >>",
+		"<< /Type /Annot /Subtype /Square /Rect [100 100 140 140] /F 2 /AP << /N 8 0 R >> >>",
+		"<< /Type /Annot /Subtype /Stamp /Rect [100 200 180 240] /AS /On /AP << /N << /On 9 0 R >> >> >>",
+		// A 10 point appearance scaled up to the 40 point rectangle.
+		form("/BBox [0 0 10 10]", "1 0 0 rg 0 0 10 10 re f"),
+		form("/BBox [0 0 10 10]", "0 0 1 rg 0 0 10 10 re f"),
+		// Rotated a quarter turn, the 10x20 box becomes 20x10 and is
+		// scaled by 4; the lower half of the form lands on the right half
+		// of the rectangle.
+		form("/BBox [0 0 10 20] /Matrix [0 1 -1 0 0 0]", "0 1 0 rg 0 0 10 10 re f"),
+	)
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		t.Fatalf("ReadContextFile() failed: %v", err)
+	}
+
+	img, err := renderPageNative(ctx, 0, 72)
+	if err != nil {
+		t.Fatalf("renderPageNative() failed: %v", err)
+	}
+
+	white := color.RGBA{255, 255, 255, 255}
+	tests := []struct {
+		name string
+		x, y int
+		want color.RGBA
+	}{
+		// Device y is 300 minus user y.
+		{"appearance", 40, 260, color.RGBA{255, 0, 0, 255}},
+		{"outside rect", 70, 260, white},
+		{"hidden", 120, 180, white},
+		{"rotated appearance", 160, 80, color.RGBA{0, 255, 0, 255}},
+		{"rotated empty half", 120, 80, white},
+	}
+	for _, tt := range tests {
+		got := color.RGBAModel.Convert(img.At(tt.x, tt.y)).(color.RGBA)
+		if got != tt.want {
+			t.Errorf("%s pixel (%d,%d) = %v, want %v", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestRenderPageNativeInvalidPage(t *testing.T) {
+	path := writeContentPDF(t, "[0 0 200 100]", "")
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		t.Fatalf("ReadContextFile() failed: %v", err)
+	}
+
+	if _, err := renderPageNative(ctx, 5, 72); err == nil {
+		t.Error("renderPageNative() should fail for an out-of-range page")
+	}
+	if _, err := renderPageNative(nil, 0, 72); err == nil {
+		t.Error("renderPageNative() should fail without a document")
+	}
+}
@@ -0,0 +1,215 @@
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// textGlyph is a glyph shown by the content stream interpreter.
+type textGlyph struct {
+	text    string
+	font    string
+	trm     matrix  // glyph space (ems) to user space
+	width   float64 // advance in ems
+	ascent  float64 // in ems
+	descent float64 // in ems, negative
+}
+
+// glyphText returns the Unicode text of a glyph. Simple fonts without a
+// mapping are assumed to use ASCII codes.
+func glyphText(f *pdfFont, g fontGlyph) string {
+	if g.text != "" {
+		return g.text
+	}
+	if !f.composite && g.code >= 32 && g.code < 127 {
+		return string(rune(g.code))
+	}
+	return ""
+}
+
+// rect returns the glyph's box from descent to ascent over its advance.
+func (g textGlyph) rect() Rect {
+	var out Rect
+	for i, c := range [4][2]float64{{0, g.descent}, {g.width, g.descent}, {g.width, g.ascent}, {0, g.ascent}} {
+		x, y := g.trm.apply(c[0], c[1])
+		if i == 0 {
+			out = Rect{LLX: x, LLY: y, URX: x, URY: y}
+			continue
+		}
+		out = orderedRect(math.Min(out.LLX, x), math.Min(out.LLY, y), math.Max(out.URX, x), math.Max(out.URY, y))
+	}
+	return out
+}
+
+// size returns the em size of the glyph in user space units.
+func (g textGlyph) size() float64 {
+	return math.Hypot(g.trm[2], g.trm[3])
+}
+
+// direction returns the unit vector of the glyph's writing direction.
+func (g textGlyph) direction() point {
+	l := math.Hypot(g.trm[0], g.trm[1])
+	if l == 0 {
+		return point{1, 0}
+	}
+	return point{g.trm[0] / l, g.trm[1] / l}
+}
+
+// extractPageTextNative interprets a page's content streams and returns the
+// positioned text it shows. pageNum is 0-indexed.
+func extractPageTextNative(ctx *model.Context, pageNum int) (*PageText, error) {
+	if ctx == nil {
+		return nil, errors.New("no document loaded")
+	}
+
+	pageDict, _, inh, err := ctx.PageDict(pageNum+1, false)
+	if err != nil {
+		return nil, err
+	}
+	if pageDict == nil || inh == nil {
+		return nil, fmt.Errorf("page %d not found", pageNum+1)
+	}
+
+	var b pageTextBuilder
+	content, err := ctx.PageContent(pageDict)
+	if err == model.ErrNoContent {
+		return b.pageText(pageNum), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := &nativeRenderer{
+		ctx:     ctx,
+		state:   newGraphicsState(identityMatrix()),
+		fonts:   map[string]*pdfFont{},
+		onGlyph: b.add,
+	}
+	ops, _ := parseContentStream(content)
+	r.run(ops, inh.Resources)
+
+	return b.pageText(pageNum), nil
+}
+
+// pageTextBuilder groups glyphs, in the order they are shown, into words
+// and lines, and lines into blocks.
+type pageTextBuilder struct {
+	lines []TextLine
+	line  *TextLine
+	word  *TextWord
+
+	dir     point   // writing direction of the current line
+	size    float64 // em size of the current line
+	lastEnd point   // where the previous glyph ended
+	space   bool    // a word break is pending
+}
+
+const (
+	// Distances in ems of the current line.
+	textWordGap      = 0.2 // a wider gap starts a new word
+	textLineGap      = 3   // a wider gap starts a new line
+	textBaselineSkew = 0.5 // a larger baseline shift starts a new line
+)
+
+func (b *pageTextBuilder) add(g textGlyph) {
+	size := g.size()
+	if size <= 0 {
+		return
+	}
+	origin := point{g.trm[4], g.trm[5]}
+	dir := g.direction()
+
+	if b.line != nil {
+		dx, dy := origin.x-b.lastEnd.x, origin.y-b.lastEnd.y
+		along := dx*b.dir.x + dy*b.dir.y
+		across := dy*b.dir.x - dx*b.dir.y
+		sameDir := dir.x*b.dir.x+dir.y*b.dir.y > 0.9
+		switch {
+		case !sameDir || math.Abs(across) > textBaselineSkew*b.size ||
+			along < -textBaselineSkew*b.size || along > textLineGap*b.size:
+			b.endLine()
+		case along > textWordGap*b.size:
+			b.space = true
+		}
+	}
+
+	endX, endY := g.trm.apply(g.width, 0)
+	end := point{endX, endY}
+
+	if g.text == "" || isBlank(g.text) {
+		// Unmapped glyphs only advance; blanks also break words.
+		if b.line != nil {
+			b.space = b.space || g.text != ""
+			b.lastEnd = end
+		}
+		return
+	}
+
+	if b.line == nil {
+		b.line = &TextLine{}
+		b.dir, b.size = dir, size
+	}
+	if b.word == nil || b.space {
+		b.endWord()
+		b.word = &TextWord{Font: g.font, FontSize: size}
+	}
+	b.space = false
+
+	ch := TextChar{Text: g.text, Rect: g.rect(), Font: g.font, FontSize: size}
+	b.word.Text += ch.Text
+	b.word.Rect = b.word.Rect.Union(ch.Rect)
+	b.word.Chars = append(b.word.Chars, ch)
+	b.lastEnd = end
+}
+
+func (b *pageTextBuilder) endWord() {
+	if b.word != nil && b.line != nil {
+		b.line.Words = append(b.line.Words, *b.word)
+	}
+	b.word = nil
+}
+
+func (b *pageTextBuilder) endLine() {
+	b.endWord()
+	if b.line != nil && len(b.line.Words) > 0 {
+		finishLine(b.line)
+		b.lines = append(b.lines, *b.line)
+	}
+	b.line = nil
+	b.space = false
+}
+
+// pageText finishes the current line and groups consecutive lines that
+// overlap horizontally and are at most a line apart into blocks.
+func (b *pageTextBuilder) pageText(pageNum int) *PageText {
+	b.endLine()
+
+	t := &PageText{Page: pageNum}
+	for i, l := range b.lines {
+		if i == 0 || !continuesBlock(b.lines[i-1], l) {
+			t.Blocks = append(t.Blocks, TextBlock{})
+		}
+		block := &t.Blocks[len(t.Blocks)-1]
+		block.Lines = append(block.Lines, l)
+	}
+	for i := range t.Blocks {
+		finishBlock(&t.Blocks[i])
+	}
+	return t
+}
+
+func continuesBlock(prev, next TextLine) bool {
+	h := prev.Rect.Height()
+	gap := prev.Rect.LLY - next.Rect.URY
+	overlap := math.Min(prev.Rect.URX, next.Rect.URX) - math.Max(prev.Rect.LLX, next.Rect.LLX)
+	return overlap > 0 && gap > -h/2 && gap < h
+}
+
+func isBlank(s string) bool {
+	return strings.TrimFunc(s, unicode.IsSpace) == ""
+}
@@ -0,0 +1,97 @@
+package pdf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractPageTextNative(t *testing.T) {
+	content := "BT /F1 12 Tf 72 700 Td (Hello world) Tj 0 -14 Td (second line) Tj ET\n" +
+		"BT /F1 12 Tf 72 400 Td (Far) Tj ( away) Tj ET"
+	doc, err := Open(writeContentPDF(t, "[0 0 612 792]", content))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+
+	text, err := extractPageTextNative(doc.ctx, 0)
+	if err != nil {
+		t.Fatalf("extractPageTextNative() returned error: %v", err)
+	}
+	if got, want := text.String(), "Hello world\nsecond line\n\nFar away"; got != want {
+		t.Fatalf("String() = %q, want %q", got, want)
+	}
+
+	hello := text.Blocks[0].Lines[0].Words[0]
+	if hello.Font != "Helvetica" || hello.FontSize != 12 {
+		t.Errorf("font = %q %v, want Helvetica 12", hello.Font, hello.FontSize)
+	}
+	if len(hello.Chars) != 5 || hello.Chars[0].Text != "H" {
+		t.Fatalf("chars = %+v, want the five letters of Hello", hello.Chars)
+	}
+	if r := hello.Rect; r.LLX != 72 || r.LLY >= 700 || r.URY <= 700 || r.URX <= 72 {
+		t.Errorf("word rect = %+v, want a box starting at 72 around the baseline 700", r)
+	}
+	if hello.Rect != hello.Chars[0].Rect.Union(hello.Chars[4].Rect) {
+		t.Errorf("word rect = %+v, want the union of its characters", hello.Rect)
+	}
+	world := text.Blocks[0].Lines[0].Words[1]
+	if world.Rect.LLX <= hello.Rect.URX {
+		t.Errorf("world starts at %v, want it after hello ending at %v", world.Rect.LLX, hello.Rect.URX)
+	}
+}
+
+func TestDocumentPageTextFallsBackToNative(t *testing.T) {
+	doc, err := Open(writeContentPDF(t, "[0 0 612 792]", "BT /F1 10 Tf 50 50 Td (abc) Tj ET"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+
+	origLookPath := textLookPath
+	t.Cleanup(func() { textLookPath = origLookPath })
+	textLookPath = func(string) (string, error) { return "", errors.New("not found") }
+
+	words, err := doc.Words(0)
+	if err != nil {
+		t.Fatalf("Words() returned error: %v", err)
+	}
+	if len(words) != 1 || words[0].Text != "abc" {
+		t.Fatalf("Words() = %+v, want abc", words)
+	}
+}
+
+func TestNativeBackendExtractText(t *testing.T) {
+	doc, err := Open(writeContentPDF(t, "[0 0 612 792]", "BT /F1 10 Tf 50 60 Td (hello) Tj 0 -12 Td (world) Tj ET"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+	doc.SetRenderer(NewRendererWithBackend(newNativeBackend()))
+
+	text, err := doc.ExtractText(0)
+	if err != nil {
+		t.Fatalf("ExtractText() returned error: %v", err)
+	}
+	if text != "hello\nworld" {
+		t.Errorf("ExtractText() = %q, want hello and world on separate lines", text)
+	}
+}
+
+func TestDocumentExtractTextFallsBackToNative(t *testing.T) {
+	doc, err := Open(writeContentPDF(t, "[0 0 612 792]", "BT /F1 10 Tf 50 50 Td (abc) Tj ET"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+
+	origLookPath := textLookPath
+	t.Cleanup(func() { textLookPath = origLookPath })
+	textLookPath = func(string) (string, error) { return "", errors.New("not found") }
+	doc.SetRenderer(NewRendererWithBackend(&fakeBackend{name: "fake", available: true, caps: Capabilities{Render: true}}))
+
+	text, err := doc.ExtractText(0)
+	if err != nil || text != "abc" {
+		t.Errorf("ExtractText() = %q, %v; want abc", text, err)
+	}
+}
@@ -0,0 +1,426 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// DefaultOCRLanguage is the language recognized when none is given.
+const DefaultOCRLanguage = "eng"
+
+// defaultOCRScale renders pages at 300 dpi, the resolution OCR engines
+// are tuned for.
+const defaultOCRScale = 300.0 / 72
+
+const (
+	// ocrFontName is the resource name of the text layer font.
+	ocrFontName = "OCRText"
+	// ocrGlyphWidth is the advance of every glyph of the text layer font in
+	// glyph space. Words are stretched to their boxes with Tz, so uniform
+	// widths keep the stretch the same for every reader.
+	ocrGlyphWidth = 500
+	// ocrDescent is the part of a word box below the baseline, matching the
+	// default font metrics of text extraction.
+	ocrDescent = 0.2
+)
+
+// OCRWord is a word recognized in a page image. Bounds are in pixels.
+type OCRWord struct {
+	Text       string
+	Bounds     image.Rectangle
+	Confidence float64 // 0 to 100
+}
+
+// OCREngine recognizes text in page images.
+type OCREngine interface {
+	// Name returns the name of the engine.
+	Name() string
+	// Available reports whether the engine can be used on this system.
+	Available() bool
+	// Recognize returns the words in an image rendered at dpi. lang is an
+	// engine-specific language selection such as "eng" or "deu+eng".
+	Recognize(img image.Image, lang string, dpi int) ([]OCRWord, error)
+}
+
+// tesseractEngine runs the tesseract command line tool.
+type tesseractEngine struct {
+	lookPath func(string) (string, error)
+	run      func(command string, args []string) ([]byte, error)
+}
+
+// NewTesseractEngine returns an OCR engine backed by a locally installed
+// tesseract.
+func NewTesseractEngine() OCREngine {
+	return &tesseractEngine{
+		lookPath: func(file string) (string, error) { return textLookPath(file) },
+		run:      func(command string, args []string) ([]byte, error) { return runTextCommand(command, args) },
+	}
+}
+
+func (e *tesseractEngine) Name() string {
+	return "tesseract"
+}
+
+func (e *tesseractEngine) Available() bool {
+	_, err := e.lookPath("tesseract")
+	return err == nil
+}
+
+func (e *tesseractEngine) Recognize(img image.Image, lang string, dpi int) ([]OCRWord, error) {
+	tmp, err := os.CreateTemp("", "openpdfreader-ocr-*.png")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := png.Encode(tmp, img); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	out, err := e.run("tesseract", tesseractArgs(tmp.Name(), lang, dpi))
+	if err != nil {
+		return nil, err
+	}
+	return parseTesseractTSV(out)
+}
+
+func tesseractArgs(imagePath, lang string, dpi int) []string {
+	if lang == "" {
+		lang = DefaultOCRLanguage
+	}
+	return []string{imagePath, "stdout", "-l", lang, "--dpi", strconv.Itoa(dpi), "tsv"}
+}
+
+// parseTesseractTSV reads the words from tesseract's TSV output, whose
+// columns are level, page_num, block_num, par_num, line_num, word_num,
+// left, top, width, height, conf and text. Words are level 5.
+func parseTesseractTSV(data []byte) ([]OCRWord, error) {
+	var words []OCRWord
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if i == 0 || line == "" {
+			continue // header
+		}
+		fields := strings.SplitN(line, "\t", 12)
+		if len(fields) < 12 {
+			return nil, fmt.Errorf("malformed tesseract output on line %d", i+1)
+		}
+		if fields[0] != "5" {
+			continue
+		}
+		text := strings.TrimSpace(fields[11])
+		conf, err := strconv.ParseFloat(fields[10], 64)
+		if err != nil || text == "" || conf < 0 {
+			continue
+		}
+
+		var box [4]int
+		for j := range box {
+			if box[j], err = strconv.Atoi(fields[6+j]); err != nil {
+				return nil, fmt.Errorf("malformed tesseract output on line %d", i+1)
+			}
+		}
+		words = append(words, OCRWord{
+			Text:       text,
+			Bounds:     image.Rect(box[0], box[1], box[0]+box[2], box[1]+box[3]),
+			Confidence: conf,
+		})
+	}
+	return words, nil
+}
+
+// OCROptions configures OCR processing.
+type OCROptions struct {
+	// Language is passed to the engine; DefaultOCRLanguage if empty.
+	Language string
+	// UserPassword and OwnerPassword decrypt a protected input.
+	UserPassword  string
+	OwnerPassword string
+	// Progress, if not nil, is called after each page with the number of
+	// pages done and the page count.
+	Progress func(done, total int)
+}
+
+// OCRResult summarizes OCR processing.
+type OCRResult struct {
+	Recognized int           // pages given a text layer
+	Skipped    int           // pages that already had text
+	Words      int           // words written
+	Failed     map[int]error // 0-indexed page to the error rendering it
+}
+
+// errOCRRender marks pages that could not be rendered for recognition.
+var errOCRRender = errors.New("cannot render page")
+
+// OCRProcessor adds searchable text layers to scanned PDFs.
+type OCRProcessor struct {
+	engine OCREngine
+}
+
+// NewOCRProcessor creates an OCR processor using engine.
+func NewOCRProcessor(engine OCREngine) *OCRProcessor {
+	return &OCRProcessor{engine: engine}
+}
+
+// Process renders every page of inputPath without text, recognizes it and
+// writes the document to outputPath with the recognized words as an
+// invisible text layer, so it can be searched and copied from. The input
+// and output may be the same file.
+func (p *OCRProcessor) Process(inputPath, outputPath string, opts OCROptions) (OCRResult, error) {
+	var result OCRResult
+	if inputPath == "" {
+		return result, errors.New("input path is required")
+	}
+	if outputPath == "" {
+		return result, errors.New("output path is required")
+	}
+	if !p.engine.Available() {
+		return result, fmt.Errorf("%s is not installed", p.engine.Name())
+	}
+
+	doc, err := openWithPasswords(inputPath, opts.UserPassword, opts.OwnerPassword)
+	if err != nil {
+		return result, err
+	}
+	defer doc.Close()
+	if !doc.Renderer().CanRender() {
+		return result, errors.New("no renderer is available to rasterize pages")
+	}
+
+	fontRef, err := doc.ctx.IndRefForNewObject(ocrFontDict())
+	if err != nil {
+		return result, err
+	}
+
+	total := doc.PageCount()
+	for page := range total {
+		n, err := p.processPage(doc, page, *fontRef, opts.Language)
+		switch {
+		case errors.Is(err, errOCRRender):
+			// Recognizing a blank stand-in would silently lose the page.
+			if result.Failed == nil {
+				result.Failed = map[int]error{}
+			}
+			result.Failed[page] = err
+		case err != nil:
+			return result, fmt.Errorf("page %d: %w", page+1, err)
+		case n < 0:
+			result.Skipped++
+		default:
+			result.Recognized++
+			result.Words += n
+		}
+		if opts.Progress != nil {
+			opts.Progress(page+1, total)
+		}
+	}
+
+	return result, api.WriteContextFile(doc.ctx, outputPath)
+}
+
+// processPage recognizes one page and adds its text layer. It returns the
+// number of words written, or -1 if the page already has text. Pages that
+// cannot be rendered return an error wrapping errOCRRender.
+func (p *OCRProcessor) processPage(doc *Document, page int, fontRef types.IndirectRef, lang string) (int, error) {
+	if words, err := doc.Words(page); err == nil && len(words) > 0 {
+		return -1, nil
+	}
+
+	geom, err := doc.PageGeometry(page)
+	if err != nil {
+		return 0, err
+	}
+	img, err := doc.renderBackendPage(page, defaultOCRScale)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errOCRRender, err)
+	}
+	recognized, err := p.engine.Recognize(img, lang, scaleToDPI(defaultOCRScale))
+	if err != nil {
+		return 0, err
+	}
+
+	words := placeOCRWords(recognized, img.Bounds(), geom)
+	if len(words) == 0 {
+		return 0, nil
+	}
+	if err := addTextLayer(doc.ctx, page, fontRef, textLayerContent(geom, words)); err != nil {
+		return 0, err
+	}
+	return len(words), nil
+}
+
+// textLayerWord is a word to write into a text layer, with its box in
+// display space.
+type textLayerWord struct {
+	text string
+	box  Rect
+}
+
+// placeOCRWords maps word boxes from the pixels of a rendered page onto the
+// page's display space.
+func placeOCRWords(words []OCRWord, bounds image.Rectangle, geom PageGeometry) []textLayerWord {
+	if bounds.Dx() <= 0 || bounds.Dy() <= 0 {
+		return nil
+	}
+	width, height := geom.Size()
+	sx := width / float64(bounds.Dx())
+	sy := height / float64(bounds.Dy())
+
+	placed := make([]textLayerWord, 0, len(words))
+	for _, w := range words {
+		r := w.Bounds.Sub(bounds.Min)
+		if r.Empty() {
+			continue
+		}
+		placed = append(placed, textLayerWord{
+			text: w.Text,
+			box: Rect{
+				LLX: float64(r.Min.X) * sx,
+				LLY: float64(r.Min.Y) * sy,
+				URX: float64(r.Max.X) * sx,
+				URY: float64(r.Max.Y) * sy,
+			},
+		})
+	}
+	return placed
+}
+
+// textLayerContent returns a content stream showing words invisibly (text
+// render mode 3) in the text layer font, each scaled to fill its box.
+// Characters outside WinAnsiEncoding are written as "?".
+func textLayerContent(geom PageGeometry, words []textLayerWord) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "BT\n3 Tr\n/%s 1 Tf\n", ocrFontName)
+	for _, w := range words {
+		code := winAnsiBytes(w.text)
+		size := w.box.Height()
+		if len(code) == 0 || size <= 0 || w.box.Width() <= 0 {
+			continue
+		}
+		hScale := 100 * w.box.Width() / (float64(len(code)) * ocrGlyphWidth / 1000 * size)
+
+		// Text space axes in user space, one em long, so that rotated
+		// pages get text running along the displayed word.
+		baseline := w.box.URY - ocrDescent*size
+		ox, oy := geom.displayPointToUser(w.box.LLX, baseline)
+		ax, ay := geom.displayPointToUser(w.box.LLX+size, baseline)
+		ux, uy := geom.displayPointToUser(w.box.LLX, baseline-size)
+
+		fmt.Fprintf(&b, "%s Tz\n%s %s %s %s %s %s Tm\n<%X> Tj\n",
+			pdfNumber(hScale),
+			pdfNumber(ax-ox), pdfNumber(ay-oy), pdfNumber(ux-ox), pdfNumber(uy-oy),
+			pdfNumber(ox), pdfNumber(oy), code)
+	}
+	b.WriteString("ET\n")
+	return b.Bytes()
+}
+
+func winAnsiBytes(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		c, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok || c < 32 {
+			c = '?'
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func pdfNumber(v float64) string {
+	v = math.Round(v*1000) / 1000
+	if v == 0 {
+		v = 0 // no "-0"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// ocrFontDict is the text layer font: Helvetica with uniform widths.
+func ocrFontDict() types.Dict {
+	widths := make(types.Array, 0, 224)
+	for range 224 {
+		widths = append(widths, types.Integer(ocrGlyphWidth))
+	}
+	return types.Dict{
+		"Type":      types.Name("Font"),
+		"Subtype":   types.Name("Type1"),
+		"BaseFont":  types.Name("Helvetica"),
+		"Encoding":  types.Name("WinAnsiEncoding"),
+		"FirstChar": types.Integer(32),
+		"LastChar":  types.Integer(255),
+		"Widths":    widths,
+	}
+}
+
+// addTextLayer appends content to a page (0-indexed), drawn after the
+// existing content in the initial graphics state, and adds the text layer
+// font to the page resources.
+func addTextLayer(ctx *model.Context, pageNum int, fontRef types.IndirectRef, content []byte) error {
+	pageDict, _, inh, err := ctx.PageDict(pageNum+1, false)
+	if err != nil {
+		return err
+	}
+	if pageDict == nil || inh == nil {
+		return fmt.Errorf("page %d not found", pageNum+1)
+	}
+
+	// The inherited resources are a copy, so the page gets its own.
+	res := inh.Resources
+	if res == nil {
+		res = types.NewDict()
+	}
+	fonts := types.NewDict()
+	if d, err := ctx.DereferenceDict(res["Font"]); err == nil && d != nil {
+		fonts = d.Clone().(types.Dict)
+	}
+	fonts[ocrFontName] = fontRef
+	res["Font"] = fonts
+	pageDict["Resources"] = res
+
+	var contents types.Array
+	if obj, found := pageDict.Find("Contents"); found {
+		o, err := ctx.Dereference(obj)
+		if err != nil {
+			return err
+		}
+		switch o := o.(type) {
+		case types.StreamDict:
+			contents = types.Array{obj}
+		case types.Array:
+			contents = append(contents, o...)
+		}
+	}
+	if len(contents) > 0 {
+		// Isolate the layer from state the page content leaves behind.
+		// Streams may be joined without a separator, so Q starts on a new
+		// line.
+		save, err := ctx.StreamDictIndRef([]byte("q\n"))
+		if err != nil {
+			return err
+		}
+		contents = append(types.Array{*save}, contents...)
+		content = append([]byte("\nQ\n"), content...)
+	}
+	layer, err := ctx.StreamDictIndRef(content)
+	if err != nil {
+		return err
+	}
+	pageDict["Contents"] = append(contents, *layer)
+	return nil
+}
@@ -0,0 +1,216 @@
+package pdf
+
+import (
+	"errors"
+	"image"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// fakeOCREngine recognizes fixed words, with boxes given as fractions of
+// the image size.
+type fakeOCREngine struct {
+	available bool
+	words     []fakeOCRWord
+	calls     int
+}
+
+type fakeOCRWord struct {
+	text                   string
+	left, top, right, bott float64
+}
+
+func (e *fakeOCREngine) Name() string    { return "fake" }
+func (e *fakeOCREngine) Available() bool { return e.available }
+
+func (e *fakeOCREngine) Recognize(img image.Image, lang string, dpi int) ([]OCRWord, error) {
+	e.calls++
+	b := img.Bounds()
+	at := func(f float64, size int) int { return int(math.Round(f * float64(size))) }
+	var words []OCRWord
+	for _, w := range e.words {
+		words = append(words, OCRWord{
+			Text:       w.text,
+			Bounds:     image.Rect(at(w.left, b.Dx()), at(w.top, b.Dy()), at(w.right, b.Dx()), at(w.bott, b.Dy())),
+			Confidence: 90,
+		})
+	}
+	return words, nil
+}
+
+func withoutPdftotext(t *testing.T) {
+	t.Helper()
+	orig := textLookPath
+	t.Cleanup(func() { textLookPath = orig })
+	textLookPath = func(string) (string, error) { return "", errors.New("not found") }
+}
+
+func TestParseTesseractTSV(t *testing.T) {
+	out := "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+		"1\t1\t0\t0\t0\t0\t0\t0\t850\t1100\t-1\t\n" +
+		"4\t1\t1\t1\t1\t0\t100\t200\t300\t40\t-1\t\n" +
+		"5\t1\t1\t1\t1\t1\t100\t200\t120\t40\t96.5\tInvoice\n" +
+		"5\t1\t1\t1\t1\t2\t240\t202\t160\t38\t91\tNo. 42\n" +
+		"5\t1\t1\t1\t1\t3\t400\t200\t10\t40\t95\t \n"
+
+	words, err := parseTesseractTSV([]byte(out))
+	if err != nil {
+		t.Fatalf("parseTesseractTSV() returned error: %v", err)
+	}
+	want := []OCRWord{
+		{Text: "Invoice", Bounds: image.Rect(100, 200, 220, 240), Confidence: 96.5},
+		{Text: "No. 42", Bounds: image.Rect(240, 202, 400, 240), Confidence: 91},
+	}
+	if !reflect.DeepEqual(words, want) {
+		t.Fatalf("words = %+v, want %+v", words, want)
+	}
+
+	if _, err := parseTesseractTSV([]byte("header\n5\t1\t1\n")); err == nil {
+		t.Error("parseTesseractTSV() expected error for a short line")
+	}
+}
+
+func TestTesseractEngineRecognize(t *testing.T) {
+	var gotArgs []string
+	e := &tesseractEngine{
+		lookPath: func(string) (string, error) { return "/usr/bin/tesseract", nil },
+		run: func(command string, args []string) ([]byte, error) {
+			gotArgs = args
+			return []byte("level\n5\t1\t1\t1\t1\t1\t1\t2\t3\t4\t90\tword\n"), nil
+		},
+	}
+	if !e.Available() {
+		t.Fatal("Available() = false, want true")
+	}
+
+	words, err := e.Recognize(image.NewGray(image.Rect(0, 0, 10, 10)), "", 300)
+	if err != nil {
+		t.Fatalf("Recognize() returned error: %v", err)
+	}
+	if len(words) != 1 || words[0].Text != "word" {
+		t.Fatalf("words = %+v, want one word", words)
+	}
+	want := []string{"stdout", "-l", "eng", "--dpi", "300", "tsv"}
+	if len(gotArgs) != 7 || filepath.Ext(gotArgs[0]) != ".png" || !reflect.DeepEqual(gotArgs[1:], want) {
+		t.Errorf("args = %q, want an image followed by %q", gotArgs, want)
+	}
+}
+
+func TestOCRProcessorAddsTextLayer(t *testing.T) {
+	withoutPdftotext(t)
+	input := writeContentPDF(t, "[0 0 200 100] /Rotate 90", "0 0 1 rg 10 10 50 30 re f")
+	output := filepath.Join(t.TempDir(), "ocr.pdf")
+
+	engine := &fakeOCREngine{available: true, words: []fakeOCRWord{
+		{"Scanned", 0.1, 0.1, 0.5, 0.2},
+		{"café", 0.55, 0.1, 0.9, 0.2},
+	}}
+	var progress []int
+	result, err := NewOCRProcessor(engine).Process(input, output, OCROptions{
+		Progress: func(done, total int) { progress = append(progress, done, total) },
+	})
+	if err != nil {
+		t.Fatalf("Process() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(result, OCRResult{Recognized: 1, Words: 2}) || !reflect.DeepEqual(progress, []int{1, 1}) {
+		t.Fatalf("result = %+v, progress = %v", result, progress)
+	}
+
+	doc, err := Open(output)
+	if err != nil {
+		t.Fatalf("Open(output) failed: %v", err)
+	}
+	defer doc.Close()
+	text, err := doc.PageText(0)
+	if err != nil {
+		t.Fatalf("PageText() returned error: %v", err)
+	}
+	if got := text.String(); got != "Scanned café" {
+		t.Fatalf("text = %q, want %q", got, "Scanned café")
+	}
+
+	// The rotated page displays as 100x200; the word lands on its box.
+	geom, _ := doc.PageGeometry(0)
+	got := geom.UserToDisplay(text.Words()[0].Rect)
+	want := Rect{LLX: 10, LLY: 20, URX: 50, URY: 40}
+	if math.Abs(got.LLX-want.LLX) > 0.5 || math.Abs(got.LLY-want.LLY) > 0.5 ||
+		math.Abs(got.URX-want.URX) > 0.5 || math.Abs(got.URY-want.URY) > 0.5 {
+		t.Errorf("word display box = %+v, want about %+v", got, want)
+	}
+
+	// The layer is invisible.
+	before, _ := renderPageNative(doc.ctx, 0, 72)
+	blank, _ := Open(input)
+	defer blank.Close()
+	after, _ := renderPageNative(blank.ctx, 0, 72)
+	if !reflect.DeepEqual(before, after) {
+		t.Error("text layer changed the rendered page")
+	}
+}
+
+func TestOCRProcessorSkipsPagesWithText(t *testing.T) {
+	withoutPdftotext(t)
+	input := writeContentPDF(t, "[0 0 200 100]", "BT /F1 12 Tf 10 50 Td (Digital) Tj ET")
+	engine := &fakeOCREngine{available: true, words: []fakeOCRWord{{"Other", 0.1, 0.1, 0.5, 0.2}}}
+
+	result, err := NewOCRProcessor(engine).Process(input, filepath.Join(t.TempDir(), "ocr.pdf"), OCROptions{})
+	if err != nil {
+		t.Fatalf("Process() returned error: %v", err)
+	}
+	if result.Skipped != 1 || result.Recognized != 0 || engine.calls != 0 {
+		t.Errorf("result = %+v after %d engine calls, want the page skipped", result, engine.calls)
+	}
+}
+
+func TestOCRProcessorReportsUnrenderablePages(t *testing.T) {
+	withoutPdftotext(t)
+	orig := defaultRenderer.Load()
+	t.Cleanup(func() { defaultRenderer.Store(orig) })
+	SetDefaultRenderer(NewRendererWithBackend(&fakeBackend{name: "fake", available: true, caps: Capabilities{Render: true}, renderErr: errors.New("bad image")}))
+
+	input := writeContentPDF(t, "[0 0 200 100]", "")
+	engine := &fakeOCREngine{available: true, words: []fakeOCRWord{{"Blank", 0.1, 0.1, 0.5, 0.2}}}
+	result, err := NewOCRProcessor(engine).Process(input, filepath.Join(t.TempDir(), "ocr.pdf"), OCROptions{})
+	if err != nil {
+		t.Fatalf("Process() returned error: %v", err)
+	}
+	if result.Recognized != 0 || engine.calls != 0 || result.Failed[0] == nil || !strings.Contains(result.Failed[0].Error(), "bad image") {
+		t.Errorf("result = %+v after %d engine calls, want page 1 failed", result, engine.calls)
+	}
+}
+
+func TestOCRProcessorOpensEncryptedInput(t *testing.T) {
+	withoutPdftotext(t)
+	input := writeContentPDF(t, "[0 0 200 100]", "")
+	encrypted := filepath.Join(t.TempDir(), "locked.pdf")
+	if err := api.EncryptFile(input, encrypted, model.NewAESConfiguration("user", "owner", 256)); err != nil {
+		t.Fatalf("EncryptFile() failed: %v", err)
+	}
+
+	engine := &fakeOCREngine{available: true, words: []fakeOCRWord{{"Secret", 0.1, 0.1, 0.5, 0.2}}}
+	output := filepath.Join(t.TempDir(), "ocr.pdf")
+	if _, err := NewOCRProcessor(engine).Process(encrypted, output, OCROptions{}); err == nil {
+		t.Fatal("Process() expected error without the password")
+	}
+	result, err := NewOCRProcessor(engine).Process(encrypted, output, OCROptions{UserPassword: "user", OwnerPassword: "owner"})
+	if err != nil {
+		t.Fatalf("Process() returned error: %v", err)
+	}
+	if result.Recognized != 1 {
+		t.Errorf("result = %+v, want the page recognized", result)
+	}
+}
+
+func TestOCRProcessorRequiresEngine(t *testing.T) {
+	input := writeContentPDF(t, "[0 0 200 100]", "")
+	_, err := NewOCRProcessor(&fakeOCREngine{}).Process(input, filepath.Join(t.TempDir(), "ocr.pdf"), OCROptions{})
+	if err == nil || err.Error() != "fake is not installed" {
+		t.Errorf("Process() error = %v, want fake is not installed", err)
+	}
+}
@@ -0,0 +1,549 @@
+package pdf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// maxOutlineDepth bounds the nesting followed when reading an outline, so
+// malformed files cannot recurse without end.
+const maxOutlineDepth = 64
+
+// Destination is a location in the document, as targeted by outline items
+// and links.
+type Destination struct {
+	Page int    // 0-indexed, -1 if the target page is unknown
+	Fit  string // XYZ, Fit, FitH, FitV, FitR, FitB, FitBH or FitBV
+	// Left and Top position the view in user space; nil leaves the
+	// coordinate unchanged.
+	Left *float64
+	Top  *float64
+	Zoom float64 // XYZ zoom factor, 0 to keep the current zoom
+}
+
+// OutlineItem is an entry of the document outline (bookmarks).
+type OutlineItem struct {
+	Title    string
+	Dest     Destination
+	Open     bool // the children are shown initially
+	Children []OutlineItem
+}
+
+// Outline returns the document outline, or nil if it has none.
+func (d *Document) Outline() ([]OutlineItem, error) {
+	if d.context() == nil {
+		return nil, errors.New("no document loaded")
+	}
+
+	// Dereferencing may decode object streams, which mutates ctx.
+	d.renderMu.Lock()
+	defer d.renderMu.Unlock()
+	return readOutline(d.ctx.XRefTable)
+}
+
+// SetOutline replaces the document outline. Destinations are written as
+// explicit destinations and items without a target page get none. The
+// change is kept in memory until the document is saved.
+func (d *Document) SetOutline(items []OutlineItem) error {
+	if d.context() == nil {
+		return errors.New("no document loaded")
+	}
+	if err := validateOutline(items, d.PageCount()); err != nil {
+		return err
+	}
+
+	d.renderMu.Lock()
+	defer d.renderMu.Unlock()
+	if err := writeOutline(d.ctx, items); err != nil {
+		return err
+	}
+	d.setModified(true)
+	return nil
+}
+
+func validateOutline(items []OutlineItem, pageCount int) error {
+	for _, item := range items {
+		if item.Dest.Page >= pageCount {
+			return fmt.Errorf("bookmark %q points to page %d of %d", item.Title, item.Dest.Page+1, pageCount)
+		}
+		if err := validateOutline(item.Children, pageCount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readOutline reads the outline tree from the catalog.
+func readOutline(xRefTable *model.XRefTable) ([]OutlineItem, error) {
+	catalog, err := xRefTable.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	outlines, err := xRefTable.DereferenceDict(catalog["Outlines"])
+	if err != nil || outlines == nil {
+		return nil, err
+	}
+
+	r, err := newOutlineReader(xRefTable)
+	if err != nil {
+		return nil, err
+	}
+	return r.items(outlines["First"], 0), nil
+}
+
+// newOutlineReader prepares the lookups needed to resolve destinations.
+func newOutlineReader(xRefTable *model.XRefTable) (*outlineReader, error) {
+	catalog, err := xRefTable.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	r := &outlineReader{
+		xRefTable: xRefTable,
+		catalog:   catalog,
+		pages:     map[int]int{},
+		seen:      map[int]bool{},
+	}
+	for i, ref := range pageRefs(xRefTable) {
+		r.pages[ref.ObjectNumber.Value()] = i
+	}
+	return r, nil
+}
+
+type outlineReader struct {
+	xRefTable *model.XRefTable
+	catalog   types.Dict
+	pages     map[int]int // page object number to 0-indexed page
+	seen      map[int]bool
+}
+
+// items reads a chain of sibling outline items starting at first. Items
+// that cannot be read end the chain; cycles are broken.
+func (r *outlineReader) items(first types.Object, depth int) []OutlineItem {
+	if depth >= maxOutlineDepth {
+		return nil
+	}
+
+	var items []OutlineItem
+	for obj := first; obj != nil; {
+		ref, ok := obj.(types.IndirectRef)
+		if !ok || r.seen[ref.ObjectNumber.Value()] {
+			break
+		}
+		r.seen[ref.ObjectNumber.Value()] = true
+
+		d, err := r.xRefTable.DereferenceDict(ref)
+		if err != nil || d == nil {
+			break
+		}
+
+		item := OutlineItem{
+			Title: outlineTitle(r.xRefTable, d["Title"]),
+			Dest:  r.destination(d),
+		}
+		if count := d.IntEntry("Count"); count != nil && *count > 0 {
+			item.Open = true
+		}
+		item.Children = r.items(d["First"], depth+1)
+		items = append(items, item)
+
+		obj = d["Next"]
+	}
+	return items
+}
+
+// destination returns the target of an outline item or link: its /Dest
+// entry, or the destination of a GoTo action in /A.
+func (r *outlineReader) destination(d types.Dict) Destination {
+	dest, found := d.Find("Dest")
+	if !found {
+		action, err := r.xRefTable.DereferenceDict(d["A"])
+		if err != nil || action == nil || action.NameEntry("S") == nil || *action.NameEntry("S") != "GoTo" {
+			return Destination{Page: -1}
+		}
+		dest = action["D"]
+	}
+	return r.resolveDestination(dest)
+}
+
+// resolveDestination reads an explicit destination array, looking up named
+// destinations first.
+func (r *outlineReader) resolveDestination(dest types.Object) Destination {
+	obj, err := r.xRefTable.Dereference(dest)
+	if err != nil {
+		return Destination{Page: -1}
+	}
+
+	switch v := obj.(type) {
+	case types.Name:
+		obj = r.namedDestination(v.Value())
+	case types.StringLiteral, types.HexLiteral:
+		name, err := model.Text(v)
+		if err != nil {
+			return Destination{Page: -1}
+		}
+		obj = r.namedDestination(name)
+	}
+
+	// A named destination may be a dictionary with the array in /D.
+	obj, _ = r.xRefTable.Dereference(obj)
+	if d, ok := obj.(types.Dict); ok {
+		obj, _ = r.xRefTable.Dereference(d["D"])
+	}
+	arr, ok := obj.(types.Array)
+	if !ok || len(arr) == 0 {
+		return Destination{Page: -1}
+	}
+	return r.explicitDestination(arr)
+}
+
+// explicitDestination reads [page /Fit args...].
+func (r *outlineReader) explicitDestination(arr types.Array) Destination {
+	dest := Destination{Page: -1, Fit: "XYZ"}
+	if ref, ok := arr[0].(types.IndirectRef); ok {
+		if page, ok := r.pages[ref.ObjectNumber.Value()]; ok {
+			dest.Page = page
+		}
+	}
+	if len(arr) > 1 {
+		if name, ok := arr[1].(types.Name); ok {
+			dest.Fit = name.Value()
+		}
+	}
+
+	arg := func(i int) *float64 {
+		if i >= len(arr) {
+			return nil
+		}
+		v, err := r.xRefTable.DereferenceNumber(arr[i])
+		if err != nil {
+			return nil // null
+		}
+		return &v
+	}
+	switch dest.Fit {
+	case "XYZ":
+		dest.Left, dest.Top = arg(2), arg(3)
+		if zoom := arg(4); zoom != nil {
+			dest.Zoom = *zoom
+		}
+	case "FitH", "FitBH":
+		dest.Top = arg(2)
+	case "FitV", "FitBV":
+		dest.Left = arg(2)
+	case "FitR":
+		dest.Left, dest.Top = arg(2), arg(5)
+	}
+	return dest
+}
+
+// namedDestination looks a name up in the /Dests name tree and in the
+// catalog's /Dests dictionary of PDF 1.1.
+func (r *outlineReader) namedDestination(name string) types.Object {
+	if names, err := r.xRefTable.DereferenceDict(r.catalog["Names"]); err == nil && names != nil {
+		if tree, err := r.xRefTable.DereferenceDict(names["Dests"]); err == nil && tree != nil {
+			if v := lookupNameTree(r.xRefTable, tree, name, 0); v != nil {
+				return v
+			}
+		}
+	}
+	if dests, err := r.xRefTable.DereferenceDict(r.catalog["Dests"]); err == nil && dests != nil {
+		return dests[name]
+	}
+	return nil
+}
+
+// lookupNameTree finds a key in a name tree node and its descendants.
+func lookupNameTree(xRefTable *model.XRefTable, node types.Dict, key string, depth int) types.Object {
+	if depth >= maxOutlineDepth {
+		return nil
+	}
+	if names, err := xRefTable.DereferenceArray(node["Names"]); err == nil {
+		for i := 0; i+1 < len(names); i += 2 {
+			k, err := xRefTable.DereferenceText(names[i])
+			if err == nil && k == key {
+				return names[i+1]
+			}
+		}
+	}
+	kids, err := xRefTable.DereferenceArray(node["Kids"])
+	if err != nil {
+		return nil
+	}
+	for _, kid := range kids {
+		d, err := xRefTable.DereferenceDict(kid)
+		if err != nil || d == nil {
+			continue
+		}
+		if limits, err := xRefTable.DereferenceArray(d["Limits"]); err == nil && len(limits) == 2 {
+			lo, err1 := xRefTable.DereferenceText(limits[0])
+			hi, err2 := xRefTable.DereferenceText(limits[1])
+			if err1 == nil && err2 == nil && (key < lo || key > hi) {
+				continue
+			}
+		}
+		if v := lookupNameTree(xRefTable, d, key, depth+1); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// outlineTitle decodes a text string, dropping control characters.
+func outlineTitle(xRefTable *model.XRefTable, obj types.Object) string {
+	s, err := xRefTable.DereferenceText(obj)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 32 || r == 127 {
+			return ' '
+		}
+		return r
+	}, s))
+}
+
+// writeOutline replaces the outline in the catalog with new objects. The
+// old outline objects are no longer referenced and are dropped on write.
+func writeOutline(ctx *model.Context, items []OutlineItem) error {
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		delete(catalog, "Outlines")
+		ctx.Outlines = nil
+		return nil
+	}
+
+	root := types.Dict{"Type": types.Name("Outlines")}
+	rootRef, err := ctx.IndRefForNewObject(root)
+	if err != nil {
+		return err
+	}
+	w := outlineWriter{ctx: ctx, pages: pageRefs(ctx.XRefTable)}
+	first, last, visible, err := w.items(items, *rootRef)
+	if err != nil {
+		return err
+	}
+	root["First"], root["Last"], root["Count"] = first, last, types.Integer(visible)
+
+	catalog["Outlines"] = *rootRef
+	ctx.Outlines = root
+	return nil
+}
+
+type outlineWriter struct {
+	ctx   *model.Context
+	pages []types.IndirectRef
+}
+
+// items writes a list of sibling items under parent and returns the first
+// and last of them and the number of items visible below parent.
+func (w *outlineWriter) items(items []OutlineItem, parent types.IndirectRef) (first, last types.IndirectRef, visible int, err error) {
+	dicts := make([]types.Dict, len(items))
+	refs := make([]types.IndirectRef, len(items))
+	for i, item := range items {
+		title, err := pdfTextString(item.Title)
+		if err != nil {
+			return first, last, 0, err
+		}
+		dicts[i] = types.Dict{"Title": title, "Parent": parent}
+		ref, err := w.ctx.IndRefForNewObject(dicts[i])
+		if err != nil {
+			return first, last, 0, err
+		}
+		refs[i] = *ref
+	}
+
+	for i, item := range items {
+		d := dicts[i]
+		if i > 0 {
+			d["Prev"] = refs[i-1]
+		}
+		if i+1 < len(items) {
+			d["Next"] = refs[i+1]
+		}
+		if dest := w.destination(item.Dest); dest != nil {
+			d["Dest"] = dest
+		}
+
+		visible++
+		if len(item.Children) == 0 {
+			continue
+		}
+		kidsFirst, kidsLast, n, err := w.items(item.Children, refs[i])
+		if err != nil {
+			return first, last, 0, err
+		}
+		d["First"], d["Last"] = kidsFirst, kidsLast
+		if item.Open {
+			d["Count"] = types.Integer(n)
+			visible += n
+		} else {
+			d["Count"] = types.Integer(-n)
+		}
+	}
+	return refs[0], refs[len(refs)-1], visible, nil
+}
+
+// destination returns an explicit destination array, or nil if dest has
+// no target page. FitR, whose rectangle is not kept, becomes XYZ.
+func (w *outlineWriter) destination(dest Destination) types.Array {
+	if dest.Page < 0 || dest.Page >= len(w.pages) {
+		return nil
+	}
+	num := func(v *float64) types.Object {
+		if v == nil {
+			return nil
+		}
+		return types.Float(*v)
+	}
+
+	switch dest.Fit {
+	case "Fit", "FitB":
+		return types.Array{w.pages[dest.Page], types.Name(dest.Fit)}
+	case "FitH", "FitBH":
+		return types.Array{w.pages[dest.Page], types.Name(dest.Fit), num(dest.Top)}
+	case "FitV", "FitBV":
+		return types.Array{w.pages[dest.Page], types.Name(dest.Fit), num(dest.Left)}
+	default:
+		var zoom types.Object
+		if dest.Zoom > 0 {
+			zoom = types.Float(dest.Zoom)
+		}
+		return types.Array{w.pages[dest.Page], types.Name("XYZ"), num(dest.Left), num(dest.Top), zoom}
+	}
+}
+
+// pdfTextString encodes s as a PDF text string, in UTF-16 when it is not
+// printable ASCII.
+func pdfTextString(s string) (types.StringLiteral, error) {
+	for _, r := range s {
+		if r < 32 || r > 126 {
+			esc, err := types.EscapedUTF16String(s)
+			if err != nil {
+				return "", err
+			}
+			return types.StringLiteral(*esc), nil
+		}
+	}
+	esc, err := types.Escape(s)
+	if err != nil {
+		return "", err
+	}
+	return types.StringLiteral(*esc), nil
+}
+
+// outlineJSON is the JSON form of an outline. It follows the format of
+// pdfcpu's bookmark export: 1-based page numbers and children in "kids".
+type outlineJSON struct {
+	Bookmarks []bookmarkJSON `json:"bookmarks"`
+}
+
+type bookmarkJSON struct {
+	Title string         `json:"title"`
+	Page  int            `json:"page,omitempty"` // 0 for no target
+	Fit   string         `json:"fit,omitempty"`
+	Left  *float64       `json:"left,omitempty"`
+	Top   *float64       `json:"top,omitempty"`
+	Zoom  float64        `json:"zoom,omitempty"`
+	Open  bool           `json:"open,omitempty"`
+	Kids  []bookmarkJSON `json:"kids,omitempty"`
+}
+
+// MarshalOutlineJSON encodes an outline as indented JSON.
+func MarshalOutlineJSON(items []OutlineItem) ([]byte, error) {
+	var toJSON func(items []OutlineItem) []bookmarkJSON
+	toJSON = func(items []OutlineItem) []bookmarkJSON {
+		out := make([]bookmarkJSON, 0, len(items))
+		for _, item := range items {
+			out = append(out, bookmarkJSON{
+				Title: item.Title,
+				Page:  item.Dest.Page + 1,
+				Fit:   item.Dest.Fit,
+				Left:  item.Dest.Left,
+				Top:   item.Dest.Top,
+				Zoom:  item.Dest.Zoom,
+				Open:  item.Open,
+				Kids:  toJSON(item.Children),
+			})
+		}
+		return out
+	}
+	return json.MarshalIndent(outlineJSON{Bookmarks: toJSON(items)}, "", "  ")
+}
+
+// UnmarshalOutlineJSON decodes an outline written by MarshalOutlineJSON or
+// pdfcpu. Bookmarks without a fit mode show the whole page.
+func UnmarshalOutlineJSON(data []byte) ([]OutlineItem, error) {
+	var doc outlineJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid bookmarks JSON: %w", err)
+	}
+
+	var fromJSON func(bms []bookmarkJSON) ([]OutlineItem, error)
+	fromJSON = func(bms []bookmarkJSON) ([]OutlineItem, error) {
+		var items []OutlineItem
+		for _, bm := range bms {
+			if bm.Page < 0 {
+				return nil, fmt.Errorf("bookmark %q has an invalid page %d", bm.Title, bm.Page)
+			}
+			kids, err := fromJSON(bm.Kids)
+			if err != nil {
+				return nil, err
+			}
+			dest := Destination{Page: bm.Page - 1, Fit: bm.Fit, Left: bm.Left, Top: bm.Top, Zoom: bm.Zoom}
+			if dest.Fit == "" {
+				dest.Fit = "Fit"
+			}
+			items = append(items, OutlineItem{Title: bm.Title, Dest: dest, Open: bm.Open, Children: kids})
+		}
+		return items, nil
+	}
+	return fromJSON(doc.Bookmarks)
+}
+
+// pageRefs returns the references of the page objects in page order,
+// walking the page tree once.
+func pageRefs(xRefTable *model.XRefTable) []types.IndirectRef {
+	root, err := xRefTable.Pages()
+	if err != nil || root == nil {
+		return nil
+	}
+
+	var refs []types.IndirectRef
+	seen := map[int]bool{}
+	var walk func(ref types.IndirectRef, depth int)
+	walk = func(ref types.IndirectRef, depth int) {
+		nr := ref.ObjectNumber.Value()
+		if seen[nr] || depth >= maxOutlineDepth {
+			return
+		}
+		seen[nr] = true
+
+		d, err := xRefTable.DereferenceDict(ref)
+		if err != nil || d == nil {
+			return
+		}
+		if t := d.Type(); t != nil && *t == "Page" {
+			refs = append(refs, ref)
+			return
+		}
+		kids, err := xRefTable.DereferenceArray(d["Kids"])
+		if err != nil {
+			return
+		}
+		for _, kid := range kids {
+			if kidRef, ok := kid.(types.IndirectRef); ok {
+				walk(kidRef, depth+1)
+			}
+		}
+	}
+	walk(*root, 0)
+	return refs
+}
@@ -0,0 +1,167 @@
+package pdf
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDocumentOutline(t *testing.T) {
+	path := writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R /Outlines 6 0 R /Names << /Dests 12 0 R >> /Dests << /legacy [5 0 R /Fit] >> >>",
+		"<< /Type /Pages /Kids [3 0 R 13 0 R] /Count 3 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 13 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 13 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Type /Outlines /First 7 0 R /Last 9 0 R /Count 4 >>",
+		// Chapter 1: open, with two children.
+		"<< /Title (Chapter 1) /Parent 6 0 R /Next 9 0 R /First 8 0 R /Last 10 0 R /Count 2 /Dest [3 0 R /XYZ 72 700 1.5] >>",
+		"<< /Title <FEFF00C9007400E9> /Parent 7 0 R /Next 10 0 R /A << /S /GoTo /D (named) >> >>",
+		// Chapter 2: closed, with one child.
+		"<< /Title (Chapter\n2) /Parent 6 0 R /Prev 7 0 R /First 11 0 R /Last 11 0 R /Count -1 /Dest /legacy >>",
+		"<< /Title (No target) /Parent 7 0 R /Prev 8 0 R /A << /S /URI /URI (https://example.com) >> >>",
+		"<< /Title (Section 2.1) /Parent 9 0 R /Dest [5 0 R /FitH null] >>",
+		"<< /Kids [14 0 R] >>",
+		"<< /Type /Pages /Parent 2 0 R /Kids [4 0 R 5 0 R] /Count 2 >>",
+		"<< /Limits [(a) (z)] /Names [(named) << /D [4 0 R /FitH 500] >>] >>",
+	)
+	doc, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+
+	outline, err := doc.Outline()
+	if err != nil {
+		t.Fatalf("Outline() returned error: %v", err)
+	}
+
+	left, top, top500 := 72.0, 700.0, 500.0
+	want := []OutlineItem{
+		{
+			Title: "Chapter 1",
+			Dest:  Destination{Page: 0, Fit: "XYZ", Left: &left, Top: &top, Zoom: 1.5},
+			Open:  true,
+			Children: []OutlineItem{
+				{Title: "Été", Dest: Destination{Page: 1, Fit: "FitH", Top: &top500}},
+				{Title: "No target", Dest: Destination{Page: -1}},
+			},
+		},
+		{
+			Title: "Chapter 2",
+			Dest:  Destination{Page: 2, Fit: "Fit"},
+			Children: []OutlineItem{
+				{Title: "Section 2.1", Dest: Destination{Page: 2, Fit: "FitH"}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(outline, want) {
+		t.Fatalf("Outline() = %+v, want %+v", outline, want)
+	}
+}
+
+func TestDocumentOutlineNone(t *testing.T) {
+	doc := openContentPDF(t, "[0 0 612 792]")
+	outline, err := doc.Outline()
+	if err != nil || outline != nil {
+		t.Errorf("Outline() = %+v, %v; want nil, nil", outline, err)
+	}
+}
+
+func TestDocumentSetOutlineRoundTrip(t *testing.T) {
+	path := writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+	)
+	doc, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+
+	top := 400.0
+	items := []OutlineItem{
+		{Title: "Report", Dest: Destination{Page: 0, Fit: "Fit"}, Open: true, Children: []OutlineItem{
+			{Title: "Résumé (draft)", Dest: Destination{Page: 1, Fit: "XYZ", Top: &top}},
+			{Title: "Appendix", Dest: Destination{Page: -1}, Children: []OutlineItem{
+				{Title: "Tables", Dest: Destination{Page: 1, Fit: "FitH", Top: &top}},
+			}},
+		}},
+	}
+	if err := doc.SetOutline(items); err != nil {
+		t.Fatalf("SetOutline() returned error: %v", err)
+	}
+	if !doc.IsModified() {
+		t.Error("IsModified() = false after SetOutline")
+	}
+
+	out := filepath.Join(t.TempDir(), "out.pdf")
+	if err := doc.SaveAs(out); err != nil {
+		t.Fatalf("SaveAs() returned error: %v", err)
+	}
+	saved, err := Open(out)
+	if err != nil {
+		t.Fatalf("Open(saved) failed: %v", err)
+	}
+	defer saved.Close()
+
+	got, err := saved.Outline()
+	if err != nil {
+		t.Fatalf("Outline() returned error: %v", err)
+	}
+	items[0].Children[1].Dest = Destination{Page: -1}
+	if !reflect.DeepEqual(got, items) {
+		t.Fatalf("Outline() = %+v, want %+v", got, items)
+	}
+
+	if err := saved.SetOutline([]OutlineItem{{Title: "Far", Dest: Destination{Page: 5}}}); err == nil {
+		t.Error("SetOutline() expected error for a page out of range")
+	}
+	if err := saved.SetOutline(nil); err != nil {
+		t.Fatalf("SetOutline(nil) returned error: %v", err)
+	}
+	if got, _ := saved.Outline(); got != nil {
+		t.Errorf("Outline() after removal = %+v, want nil", got)
+	}
+}
+
+func TestOutlineJSON(t *testing.T) {
+	top := 700.0
+	items := []OutlineItem{
+		{Title: "Intro", Dest: Destination{Page: 0, Fit: "XYZ", Top: &top}, Open: true, Children: []OutlineItem{
+			{Title: "Scope", Dest: Destination{Page: 2, Fit: "Fit"}},
+		}},
+		{Title: "Untargeted", Dest: Destination{Page: -1}},
+	}
+	data, err := MarshalOutlineJSON(items)
+	if err != nil {
+		t.Fatalf("MarshalOutlineJSON() returned error: %v", err)
+	}
+	got, err := UnmarshalOutlineJSON(data)
+	if err != nil {
+		t.Fatalf("UnmarshalOutlineJSON() returned error: %v", err)
+	}
+	items[1].Dest.Fit = "Fit"
+	if !reflect.DeepEqual(got, items) {
+		t.Fatalf("round trip = %+v, want %+v", got, items)
+	}
+
+	// pdfcpu's export format, with a header and without fit modes.
+	pdfcpuJSON := `{"header": {"source": "a.pdf"}, "bookmarks": [{"title": "Part", "page": 2, "kids": [{"title": "Ch", "page": 3}]}]}`
+	got, err = UnmarshalOutlineJSON([]byte(pdfcpuJSON))
+	if err != nil {
+		t.Fatalf("UnmarshalOutlineJSON(pdfcpu) returned error: %v", err)
+	}
+	want := []OutlineItem{{Title: "Part", Dest: Destination{Page: 1, Fit: "Fit"}, Children: []OutlineItem{
+		{Title: "Ch", Dest: Destination{Page: 2, Fit: "Fit"}},
+	}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalOutlineJSON(pdfcpu) = %+v, want %+v", got, want)
+	}
+
+	if _, err := UnmarshalOutlineJSON([]byte(`{"bookmarks": [{"title": "x", "page": -1}]}`)); err == nil {
+		t.Error("UnmarshalOutlineJSON() expected error for a negative page")
+	}
+}
@@ -0,0 +1,108 @@
+package pdf
+
+import (
+	"strings"
+)
+
+// PageText is the positioned text of one page in reading order, grouped
+// into blocks, lines, words and characters. All rectangles are in PDF user
+// space.
+type PageText struct {
+	Page   int // 0-indexed
+	Blocks []TextBlock
+}
+
+// TextBlock is a paragraph-like group of lines.
+type TextBlock struct {
+	Rect  Rect
+	Lines []TextLine
+}
+
+// TextLine is a run of words sharing a baseline.
+type TextLine struct {
+	Rect  Rect
+	Words []TextWord
+}
+
+// TextWord is a run of characters without spaces.
+type TextWord struct {
+	Text     string
+	Rect     Rect
+	Font     string  // base font name, empty if unknown
+	FontSize float64 // em size in user space units, 0 if unknown
+	Chars    []TextChar
+}
+
+// TextChar is a single shown glyph.
+type TextChar struct {
+	Text     string
+	Rect     Rect
+	Font     string
+	FontSize float64
+}
+
+// Words returns all words of the page in reading order.
+func (t *PageText) Words() []TextWord {
+	var words []TextWord
+	for _, b := range t.Blocks {
+		for _, l := range b.Lines {
+			words = append(words, l.Words...)
+		}
+	}
+	return words
+}
+
+// String returns the plain text of the page: words separated by spaces,
+// lines by newlines and blocks by blank lines.
+func (t *PageText) String() string {
+	var sb strings.Builder
+	for i, b := range t.Blocks {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		for j, l := range b.Lines {
+			if j > 0 {
+				sb.WriteByte('\n')
+			}
+			for k, w := range l.Words {
+				if k > 0 {
+					sb.WriteByte(' ')
+				}
+				sb.WriteString(w.Text)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// newTextWord builds a word whose character boxes are interpolated evenly
+// across the word box, for sources that only report word geometry.
+func newTextWord(text string, rect Rect, geom PageGeometry) TextWord {
+	w := TextWord{Text: text, Rect: rect}
+	runes := []rune(text)
+	display := geom.UserToDisplay(rect)
+	step := display.Width() / float64(len(runes))
+	for i, r := range runes {
+		box := display
+		box.LLX = display.LLX + float64(i)*step
+		box.URX = box.LLX + step
+		w.Chars = append(w.Chars, TextChar{Text: string(r), Rect: geom.DisplayToUser(box)})
+	}
+	return w
+}
+
+// finishLine computes the rectangles of a line from its words.
+func finishLine(l *TextLine) {
+	l.Rect = Rect{}
+	for _, w := range l.Words {
+		l.Rect = l.Rect.Union(w.Rect)
+	}
+}
+
+// finishBlock computes the rectangle of a block from its lines.
+func finishBlock(b *TextBlock) {
+	b.Rect = Rect{}
+	for _, l := range b.Lines {
+		b.Rect = b.Rect.Union(l.Rect)
+	}
+}
@@ -0,0 +1,34 @@
+package pdf
+
+import "testing"
+
+func TestNewTextWordInterpolatesRotatedPage(t *testing.T) {
+	// On a page rotated by 90 degrees, text running left to right on screen
+	// runs bottom to top in user space.
+	g := defaultPageGeometry()
+	g.Rotate = 90
+
+	rect := g.DisplayToUser(Rect{LLX: 100, LLY: 50, URX: 140, URY: 60})
+	w := newTextWord("ab", rect, g)
+	if len(w.Chars) != 2 {
+		t.Fatalf("got %d chars, want 2", len(w.Chars))
+	}
+	first := g.UserToDisplay(w.Chars[0].Rect)
+	if first != (Rect{LLX: 100, LLY: 50, URX: 120, URY: 60}) {
+		t.Errorf("first char on screen = %+v, want the left half of the word", first)
+	}
+}
+
+func TestPageTextWords(t *testing.T) {
+	text := &PageText{Blocks: []TextBlock{
+		{Lines: []TextLine{{Words: []TextWord{{Text: "a"}, {Text: "b"}}}}},
+		{Lines: []TextLine{{Words: []TextWord{{Text: "c"}}}}},
+	}}
+	words := text.Words()
+	if len(words) != 3 || words[2].Text != "c" {
+		t.Errorf("Words() = %+v, want a b c", words)
+	}
+	if got := text.String(); got != "a b\n\nc" {
+		t.Errorf("String() = %q, want %q", got, "a b\n\nc")
+	}
+}
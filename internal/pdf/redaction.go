@@ -5,7 +5,6 @@ import (
 
 	pdfcolor "github.com/pdfcpu/pdfcpu/pkg/pdfcpu/color"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
-	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
 )
 
 // Redactor provides basic redaction operations.
@@ -17,8 +16,9 @@ func NewRedactor() *Redactor {
 	return &Redactor{}
 }
 
-// ApplyVisualRedaction adds a filled black rectangle annotation to the selected page.
-func (r *Redactor) ApplyVisualRedaction(inputPath, outputPath string, pageNum int, reason string) error {
+// ApplyVisualRedaction adds a filled black rectangle annotation covering
+// rect, in PDF user space, to the selected page.
+func (r *Redactor) ApplyVisualRedaction(inputPath, outputPath string, pageNum int, rect Rect, reason string) error {
 	if inputPath == "" {
 		return errors.New("input path is required")
 	}
@@ -26,8 +26,12 @@ func (r *Redactor) ApplyVisualRedaction(inputPath, outputPath string, pageNum in
 		return errors.New("page number out of range")
 	}
 
+	box, err := annotationRect(inputPath, pageNum, rect)
+	if err != nil {
+		return err
+	}
 	ann := model.NewSquareAnnotation(
-		*types.NewRectangle(150, 430, 460, 660),
+		*box,
 		reason,
 		nextAnnotationID("redact"),
 		"",
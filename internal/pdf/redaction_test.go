@@ -10,16 +10,18 @@ import (
 
 func TestRedactorValidation(t *testing.T) {
 	r := NewRedactor()
+	rect := Rect{LLX: 150, LLY: 430, URX: 460, URY: 660}
 
-	if err := r.ApplyVisualRedaction("", "", 0, "r"); err == nil {
+	if err := r.ApplyVisualRedaction("", "", 0, rect, "r"); err == nil {
 		t.Fatal("expected error for empty input path")
 	}
-	if err := r.ApplyVisualRedaction("in.pdf", "", -1, "r"); err == nil {
+	if err := r.ApplyVisualRedaction("in.pdf", "", -1, rect, "r"); err == nil {
 		t.Fatal("expected error for negative page number")
 	}
 }
 
 func TestRedactorCallsAPI(t *testing.T) {
+	stubLetterGeometry(t)
 	original := addAnnotationsFile
 	defer func() {
 		addAnnotationsFile = original
@@ -48,12 +50,13 @@ func TestRedactorCallsAPI(t *testing.T) {
 	}
 
 	r := NewRedactor()
-	if err := r.ApplyVisualRedaction("in.pdf", "out.pdf", 3, "confidential"); err != nil {
+	if err := r.ApplyVisualRedaction("in.pdf", "out.pdf", 3, Rect{LLX: 150, LLY: 430, URX: 460, URY: 660}, "confidential"); err != nil {
 		t.Fatalf("ApplyVisualRedaction() returned error: %v", err)
 	}
 }
 
 func TestRedactorPropagatesError(t *testing.T) {
+	stubLetterGeometry(t)
 	original := addAnnotationsFile
 	defer func() {
 		addAnnotationsFile = original
@@ -64,7 +67,7 @@ func TestRedactorPropagatesError(t *testing.T) {
 	}
 
 	r := NewRedactor()
-	err := r.ApplyVisualRedaction("in.pdf", "", 0, "x")
+	err := r.ApplyVisualRedaction("in.pdf", "", 0, Rect{LLX: 150, LLY: 430, URX: 460, URY: 660}, "x")
 	if err == nil {
 		t.Fatal("expected error")
 	}
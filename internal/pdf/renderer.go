@@ -1,108 +1,134 @@
 package pdf
 
 import (
-	"bytes"
-	"fmt"
+	"errors"
 	"image"
-	"image/png"
-	"os/exec"
-	"strconv"
+	"sync/atomic"
 )
 
-// Renderer handles PDF page rendering.
+// Built-in rendering backend names reported by Renderer.Backend.
+const (
+	BackendPoppler = "poppler"
+	BackendMutool  = "mutool"
+	BackendNative  = "native"
+)
+
+// Renderer handles PDF page rendering through a RenderBackend.
 type Renderer struct {
-	usePoppler bool
+	backend RenderBackend
 }
 
+// defaultRenderer is used by documents that have no renderer of their own.
+var defaultRenderer atomic.Pointer[Renderer]
+
 // NewRenderer creates a new PDF renderer.
-// It checks for available rendering backends.
+// It picks the first available registered backend: pdftoppm (poppler-utils),
+// then mutool, then the built-in native rasterizer.
 func NewRenderer() *Renderer {
-	r := &Renderer{}
+	b, err := LookupBackend(BackendAuto)
+	if err != nil {
+		return &Renderer{}
+	}
+	return &Renderer{backend: b}
+}
+
+// NewRendererWithBackend creates a renderer using a specific backend.
+func NewRendererWithBackend(b RenderBackend) *Renderer {
+	return &Renderer{backend: b}
+}
+
+// NewRendererByName creates a renderer for a registered backend name.
+// An empty name or "auto" selects automatically.
+func NewRendererByName(name string) (*Renderer, error) {
+	b, err := LookupBackend(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Renderer{backend: b}, nil
+}
+
+// DefaultRenderer returns the renderer shared by all documents.
+func DefaultRenderer() *Renderer {
+	if r := defaultRenderer.Load(); r != nil {
+		return r
+	}
+	defaultRenderer.CompareAndSwap(nil, NewRenderer())
+	return defaultRenderer.Load()
+}
 
-	// Check if pdftoppm (poppler-utils) is available
-	if _, err := exec.LookPath("pdftoppm"); err == nil {
-		r.usePoppler = true
+// SetDefaultRenderer replaces the renderer shared by all documents.
+// Passing nil restores automatic selection.
+func SetDefaultRenderer(r *Renderer) {
+	defaultRenderer.Store(r)
+}
+
+// Backend returns the name of the active rendering backend.
+func (r *Renderer) Backend() string {
+	if r.backend == nil {
+		return ""
 	}
+	return r.backend.Name()
+}
 
-	return r
+// Capabilities reports the features of the active backend.
+func (r *Renderer) Capabilities() Capabilities {
+	if r.backend == nil {
+		return Capabilities{}
+	}
+	return r.backend.Capabilities()
 }
 
 // CanRender returns true if a rendering backend is available.
 func (r *Renderer) CanRender() bool {
-	return r.usePoppler
+	return r.backend != nil && r.backend.Capabilities().Render
 }
 
 // RenderPage renders a PDF page to an image.
 // pageNum is 0-indexed, scale is DPI (72 = 100%, 144 = 200%).
 func (r *Renderer) RenderPage(pdfPath string, pageNum int, dpi int) (image.Image, error) {
-	if r.usePoppler {
-		return r.renderWithPoppler(pdfPath, pageNum, dpi)
-	}
-	return nil, fmt.Errorf("no rendering backend available")
+	return r.renderSourcePage(RenderSource{Path: pdfPath}, pageNum, dpi)
 }
 
-func (r *Renderer) renderWithPoppler(pdfPath string, pageNum int, dpi int) (image.Image, error) {
-	// pdftoppm uses 1-indexed pages
-	pageStr := strconv.Itoa(pageNum + 1)
-	dpiStr := strconv.Itoa(dpi)
-
-	// Run pdftoppm to convert page to PNG
-	cmd := exec.Command("pdftoppm",
-		"-png",
-		"-f", pageStr, // first page
-		"-l", pageStr, // last page (same = single page)
-		"-r", dpiStr, // resolution
-		"-singlefile", // don't add page suffix
-		pdfPath,
-		"-", // output to stdout
-	)
-
-	// pdftoppm with "-" outputs to stdout but still needs a prefix
-	// Use different approach: output to stdout with -png
-	cmd = exec.Command("pdftoppm",
-		"-png",
-		"-f", pageStr,
-		"-l", pageStr,
-		"-r", dpiStr,
-		"-singlefile",
-		pdfPath,
-	)
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("pdftoppm failed: %v: %s", err, stderr.String())
+func (r *Renderer) renderSourcePage(src RenderSource, pageNum int, dpi int) (image.Image, error) {
+	if !r.CanRender() {
+		return nil, errors.New("no rendering backend available")
 	}
+	return r.backend.RenderPage(src, pageNum, dpi)
+}
 
-	// Decode PNG from stdout
-	img, err := png.Decode(&stdout)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode PNG: %v", err)
-	}
+// RenderPages renders pages first..last (inclusive, 0-indexed) at one DPI and
+// calls fn for each page in order. Backends that support batching render the
+// whole range in one pass; others render page by page.
+func (r *Renderer) RenderPages(pdfPath string, first, last int, dpi int, fn PageImageFunc) error {
+	return r.renderSourcePages(RenderSource{Path: pdfPath}, first, last, dpi, fn)
+}
 
-	return img, nil
+func (r *Renderer) renderSourcePages(src RenderSource, first, last int, dpi int, fn PageImageFunc) error {
+	if !r.CanRender() {
+		return errors.New("no rendering backend available")
+	}
+	if first < 0 || last < first {
+		return errors.New("invalid page range")
+	}
+	if batch, ok := r.backend.(BatchRenderBackend); ok {
+		return batch.RenderPages(src, first, last, dpi, fn)
+	}
+	return renderPagesOneByOne(r.backend, src, first, last, dpi, fn)
 }
 
-// GetPageCount returns the number of pages using pdfinfo.
+// GetPageCount returns the number of pages reported by the active backend.
 func (r *Renderer) GetPageCount(pdfPath string) (int, error) {
-	cmd := exec.Command("pdfinfo", pdfPath)
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, err
+	if r.backend == nil || !r.backend.Capabilities().PageCount {
+		return 0, errors.New("page count is not supported by this backend")
 	}
+	return r.backend.PageCount(RenderSource{Path: pdfPath})
+}
 
-	// Parse "Pages: N" from output
-	lines := bytes.Split(output, []byte("\n"))
-	for _, line := range lines {
-		if bytes.HasPrefix(line, []byte("Pages:")) {
-			parts := bytes.Fields(line)
-			if len(parts) >= 2 {
-				return strconv.Atoi(string(parts[1]))
-			}
-		}
+// extractText extracts page text with the active backend, falling back to
+// pdftotext for backends that cannot extract text themselves.
+func (r *Renderer) extractText(src RenderSource, pageNum int) (string, error) {
+	if r.backend != nil && r.backend.Capabilities().Text {
+		return r.backend.ExtractText(src, pageNum)
 	}
-
-	return 0, fmt.Errorf("could not determine page count")
+	return extractPageText(src.Path, pageNum, src.UserPassword, src.OwnerPassword)
 }
@@ -103,3 +103,38 @@ func TestRendererRenderPageInvalidDPI(t *testing.T) {
 		t.Error("RenderPage() returned nil image")
 	}
 }
+
+func TestNativeRendererRenderPage(t *testing.T) {
+	r := NewRendererWithBackend(newNativeBackend())
+	if r.Backend() != BackendNative {
+		t.Fatalf("Backend() = %q, want %q", r.Backend(), BackendNative)
+	}
+
+	testPDF := filepath.Join(t.TempDir(), "test.pdf")
+	if !createTestPDF(testPDF) {
+		t.Skip("Cannot create test PDF")
+	}
+
+	img, err := r.RenderPage(testPDF, 0, 72)
+	if err != nil {
+		t.Fatalf("RenderPage() failed: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 612 || b.Dy() != 792 {
+		t.Errorf("image size = %dx%d, want 612x792", b.Dx(), b.Dy())
+	}
+
+	count, err := r.GetPageCount(testPDF)
+	if err != nil || count != 1 {
+		t.Errorf("GetPageCount() = %d, %v; want 1", count, err)
+	}
+}
+
+func TestRendererWithoutBackend(t *testing.T) {
+	r := NewRendererWithBackend(nil)
+	if r.CanRender() {
+		t.Error("CanRender() should be false without a backend")
+	}
+	if _, err := r.RenderPage("/tmp/test.pdf", 0, 72); err == nil {
+		t.Error("RenderPage() should fail without a backend")
+	}
+}
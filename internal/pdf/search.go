@@ -0,0 +1,160 @@
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// SearchOptions controls how a query is matched.
+type SearchOptions struct {
+	CaseSensitive bool
+	WholeWord     bool
+	Regexp        bool // treat the query as a regular expression
+}
+
+// SearchHit is one match of a query.
+type SearchHit struct {
+	Page  int    // 0-indexed
+	Text  string // the matched text
+	Rects []Rect // boxes in PDF user space, one per word the match touches
+}
+
+// Search finds every match of query in the document, in page order.
+func (d *Document) Search(query string, opts SearchOptions) ([]SearchHit, error) {
+	re, err := compileSearch(query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []SearchHit
+	for page := 0; page < d.PageCount(); page++ {
+		pageHits, err := d.searchPage(page, re)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, pageHits...)
+	}
+	return hits, nil
+}
+
+// SearchPage finds every match of query on one page (0-indexed).
+func (d *Document) SearchPage(pageNum int, query string, opts SearchOptions) ([]SearchHit, error) {
+	re, err := compileSearch(query, opts)
+	if err != nil {
+		return nil, err
+	}
+	return d.searchPage(pageNum, re)
+}
+
+func (d *Document) searchPage(pageNum int, re *regexp.Regexp) ([]SearchHit, error) {
+	words, err := d.Words(pageNum)
+	if err != nil {
+		return nil, err
+	}
+	geom, err := d.PageGeometry(pageNum)
+	if err != nil {
+		return nil, err
+	}
+	return searchWords(pageNum, words, geom, re), nil
+}
+
+// compileSearch turns a query into a regular expression. Plain queries
+// match literally, with any run of whitespace matching any other.
+func compileSearch(query string, opts SearchOptions) (*regexp.Regexp, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("empty search query")
+	}
+
+	pattern := query
+	if !opts.Regexp {
+		fields := strings.Fields(query)
+		for i, f := range fields {
+			fields[i] = regexp.QuoteMeta(f)
+		}
+		pattern = strings.Join(fields, `\s+`)
+	}
+	if opts.WholeWord {
+		pattern = `\b(?:` + pattern + `)\b`
+	}
+	if !opts.CaseSensitive {
+		pattern = `(?i)` + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search pattern: %w", err)
+	}
+	return re, nil
+}
+
+// searchWords matches re against the page's words joined by single spaces
+// and maps each match back to the boxes of the words it covers. Boxes of
+// partially matched words are narrowed to the matched characters.
+func searchWords(pageNum int, words []TextWord, geom PageGeometry, re *regexp.Regexp) []SearchHit {
+	var text strings.Builder
+	starts := make([]int, len(words))
+	for i, w := range words {
+		if i > 0 {
+			text.WriteByte(' ')
+		}
+		starts[i] = text.Len()
+		text.WriteString(w.Text)
+	}
+	joined := text.String()
+
+	var hits []SearchHit
+	for _, m := range re.FindAllStringIndex(joined, -1) {
+		start, end := m[0], m[1]
+		if start == end {
+			continue
+		}
+
+		hit := SearchHit{Page: pageNum, Text: joined[start:end]}
+		for i, w := range words {
+			wordStart, wordEnd := starts[i], starts[i]+len(w.Text)
+			if wordEnd <= start || wordStart >= end {
+				continue
+			}
+			from := max(start, wordStart) - wordStart
+			to := min(end, wordEnd) - wordStart
+			hit.Rects = append(hit.Rects, wordPart(w, from, to, geom))
+		}
+		hits = append(hits, hit)
+	}
+	return hits
+}
+
+// wordPart returns the box of the bytes from..to of a word, from its
+// character boxes when they line up with the text and otherwise by
+// interpolating along the reading direction in display space.
+func wordPart(w TextWord, from, to int, geom PageGeometry) Rect {
+	if from == 0 && to == len(w.Text) {
+		return w.Rect
+	}
+
+	if len(w.Chars) > 0 {
+		var out Rect
+		offset := 0
+		for _, c := range w.Chars {
+			if offset >= from && offset < to {
+				out = out.Union(c.Rect)
+			}
+			offset += len(c.Text)
+		}
+		if offset == len(w.Text) && !out.Empty() {
+			return out
+		}
+	}
+
+	total := float64(utf8.RuneCountInString(w.Text))
+	a := float64(utf8.RuneCountInString(w.Text[:from])) / total
+	b := float64(utf8.RuneCountInString(w.Text[:to])) / total
+
+	display := geom.UserToDisplay(w.Rect)
+	width := display.Width()
+	display.LLX, display.URX = display.LLX+a*width, display.LLX+b*width
+	return geom.DisplayToUser(display)
+}
@@ -0,0 +1,124 @@
+package pdf
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testWords() []TextWord {
+	return []TextWord{
+		{Text: "The", Rect: Rect{LLX: 10, LLY: 700, URX: 40, URY: 712}},
+		{Text: "quick", Rect: Rect{LLX: 50, LLY: 700, URX: 100, URY: 712}},
+		{Text: "brown", Rect: Rect{LLX: 110, LLY: 700, URX: 160, URY: 712}},
+		{Text: "fox,", Rect: Rect{LLX: 10, LLY: 680, URX: 50, URY: 692}},
+		{Text: "the", Rect: Rect{LLX: 60, LLY: 680, URX: 90, URY: 692}},
+		{Text: "end.", Rect: Rect{LLX: 100, LLY: 680, URX: 140, URY: 692}},
+	}
+}
+
+func searchTestWords(t *testing.T, query string, opts SearchOptions) []SearchHit {
+	t.Helper()
+	re, err := compileSearch(query, opts)
+	if err != nil {
+		t.Fatalf("compileSearch(%q) returned error: %v", query, err)
+	}
+	return searchWords(3, testWords(), defaultPageGeometry(), re)
+}
+
+func hitTexts(hits []SearchHit) []string {
+	texts := make([]string, len(hits))
+	for i, h := range hits {
+		texts[i] = h.Text
+	}
+	return texts
+}
+
+func TestSearchOptions(t *testing.T) {
+	tests := []struct {
+		query string
+		opts  SearchOptions
+		want  []string
+	}{
+		{"the", SearchOptions{}, []string{"The", "the"}},
+		{"the", SearchOptions{CaseSensitive: true}, []string{"the"}},
+		{"o", SearchOptions{WholeWord: true}, nil},
+		{"fox", SearchOptions{WholeWord: true}, []string{"fox"}},
+		{"quick   brown", SearchOptions{}, []string{"quick brown"}},
+		{"f.x", SearchOptions{}, nil},
+		{"f.x", SearchOptions{Regexp: true}, []string{"fox"}},
+		{`e\w*\.`, SearchOptions{Regexp: true}, []string{"end."}},
+	}
+	for _, tt := range tests {
+		got := hitTexts(searchTestWords(t, tt.query, tt.opts))
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("search %q %+v = %q, want %q", tt.query, tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestSearchHitRects(t *testing.T) {
+	hits := searchTestWords(t, "brown fox", SearchOptions{})
+	if len(hits) != 1 {
+		t.Fatalf("got %d hits, want 1", len(hits))
+	}
+	hit := hits[0]
+	if hit.Page != 3 {
+		t.Errorf("Page = %d, want 3", hit.Page)
+	}
+	want := []Rect{
+		{LLX: 110, LLY: 700, URX: 160, URY: 712},
+		// "fox" is three of the four characters of "fox,".
+		{LLX: 10, LLY: 680, URX: 40, URY: 692},
+	}
+	if !reflect.DeepEqual(hit.Rects, want) {
+		t.Errorf("Rects = %+v, want %+v", hit.Rects, want)
+	}
+}
+
+func TestCompileSearchErrors(t *testing.T) {
+	if _, err := compileSearch("  ", SearchOptions{}); err == nil {
+		t.Error("compileSearch() expected error for empty query")
+	}
+	if _, err := compileSearch("a(", SearchOptions{Regexp: true}); err == nil || !strings.Contains(err.Error(), "invalid search pattern") {
+		t.Errorf("compileSearch() error = %v, want invalid search pattern", err)
+	}
+	if _, err := compileSearch("a(", SearchOptions{}); err != nil {
+		t.Errorf("compileSearch() literal query returned error: %v", err)
+	}
+}
+
+func TestDocumentSearchUsesPdftotextBBox(t *testing.T) {
+	doc := openContentPDF(t, "[0 0 612 792]")
+
+	origLookPath, origRun := textLookPath, runTextCommand
+	t.Cleanup(func() { textLookPath, runTextCommand = origLookPath, origRun })
+
+	var calls int
+	textLookPath = func(string) (string, error) { return "/usr/bin/pdftotext", nil }
+	runTextCommand = func(command string, args []string) ([]byte, error) {
+		calls++
+		if !reflect.DeepEqual(args[:5], []string{"-f", "1", "-l", "1", "-bbox-layout"}) {
+			t.Errorf("args = %v, want -bbox-layout for page 1", args)
+		}
+		return []byte(bboxLayoutOutput), nil
+	}
+
+	hits, err := doc.Search("r&d", SearchOptions{})
+	if err != nil {
+		t.Fatalf("Search() returned error: %v", err)
+	}
+	if len(hits) != 1 || hits[0].Text != "R&D" || hits[0].Page != 0 {
+		t.Fatalf("Search() = %+v, want one R&D hit on page 0", hits)
+	}
+
+	if _, err := doc.SearchPage(0, "hello", SearchOptions{}); err != nil {
+		t.Fatalf("SearchPage() returned error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("pdftotext ran %d times, want 1 (page text is cached)", calls)
+	}
+}
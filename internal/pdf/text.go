@@ -2,19 +2,22 @@ package pdf
 
 import (
 	"bytes"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"os/exec"
 	"strconv"
 	"strings"
 )
 
-func extractPageText(pdfPath string, pageNum int, userPassword, ownerPassword string) (string, error) {
-	command, args, err := resolveTextExtractCommand(exec.LookPath, pdfPath, pageNum, userPassword, ownerPassword)
-	if err != nil {
-		return "", err
-	}
+// textLookPath finds text extraction tools. It is a variable so tests can
+// pretend tools are installed.
+var textLookPath = exec.LookPath
 
+// runTextCommand runs a text extraction tool and returns its standard
+// output. It is a variable so tests can substitute canned output.
+var runTextCommand = func(command string, args []string) ([]byte, error) {
 	cmd := exec.Command(command, args...)
 
 	var stdout, stderr bytes.Buffer
@@ -24,12 +27,131 @@ func extractPageText(pdfPath string, pageNum int, userPassword, ownerPassword st
 	if err := cmd.Run(); err != nil {
 		details := strings.TrimSpace(stderr.String())
 		if details != "" {
-			return "", fmt.Errorf("pdftotext failed: %v: %s", err, details)
+			return nil, fmt.Errorf("%s failed: %v: %s", command, err, details)
+		}
+		return nil, fmt.Errorf("%s failed: %w", command, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+func extractPageText(pdfPath string, pageNum int, userPassword, ownerPassword string) (string, error) {
+	command, args, err := resolveTextExtractCommand(textLookPath, pdfPath, pageNum, userPassword, ownerPassword)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := runTextCommand(command, args)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// extractPageTextLayout returns the positioned text of a page using
+// pdftotext -bbox-layout.
+func extractPageTextLayout(pdfPath string, pageNum int, geom PageGeometry, userPassword, ownerPassword string) (*PageText, error) {
+	command, args, err := pdftotextCommand(textLookPath, pdfPath, pageNum, "-bbox-layout", userPassword, ownerPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := runTextCommand(command, args)
+	if err != nil {
+		return nil, err
+	}
+	return parseBBoxLayout(out, pageNum, geom)
+}
+
+// parseBBoxLayout reads pdftotext -bbox-layout output. The tool reports
+// boxes in display space relative to the page size it prints, which is
+// scaled to the geometry's display size before mapping back to user space.
+// It reports no character boxes or fonts; characters are interpolated
+// across their words.
+func parseBBoxLayout(data []byte, pageNum int, geom PageGeometry) (*PageText, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+
+	displayWidth, displayHeight := geom.Size()
+	sx, sy := 1.0, 1.0
+	box := func(el xml.StartElement) Rect {
+		return geom.DisplayToUser(Rect{
+			LLX: xmlFloatAttr(el, "xMin") * sx,
+			LLY: xmlFloatAttr(el, "yMin") * sy,
+			URX: xmlFloatAttr(el, "xMax") * sx,
+			URY: xmlFloatAttr(el, "yMax") * sy,
+		})
+	}
+
+	t := &PageText{Page: pageNum}
+	var (
+		block *TextBlock
+		line  *TextLine
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse pdftotext output: %w", err)
+		}
+
+		switch el := tok.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "page":
+				if w := xmlFloatAttr(el, "width"); w > 0 {
+					sx = displayWidth / w
+				}
+				if h := xmlFloatAttr(el, "height"); h > 0 {
+					sy = displayHeight / h
+				}
+			case "block":
+				block = &TextBlock{Rect: box(el)}
+			case "line":
+				line = &TextLine{Rect: box(el)}
+			case "word":
+				var text string
+				if err := dec.DecodeElement(&text, &el); err != nil {
+					return nil, fmt.Errorf("failed to parse pdftotext output: %w", err)
+				}
+				text = strings.TrimSpace(text)
+				if text == "" || line == nil {
+					continue
+				}
+				line.Words = append(line.Words, newTextWord(text, box(el), geom))
+			}
+		case xml.EndElement:
+			switch el.Name.Local {
+			case "line":
+				if line != nil && block != nil && len(line.Words) > 0 {
+					block.Lines = append(block.Lines, *line)
+				}
+				line = nil
+			case "block":
+				if block != nil && len(block.Lines) > 0 {
+					t.Blocks = append(t.Blocks, *block)
+				}
+				block = nil
+			}
 		}
-		return "", fmt.Errorf("pdftotext failed: %w", err)
 	}
+	return t, nil
+}
 
-	return strings.TrimSpace(stdout.String()), nil
+func xmlFloatAttr(el xml.StartElement, name string) float64 {
+	for _, attr := range el.Attr {
+		if attr.Name.Local == name {
+			v, err := strconv.ParseFloat(attr.Value, 64)
+			if err != nil {
+				return 0
+			}
+			return v
+		}
+	}
+	return 0
 }
 
 func resolveTextExtractCommand(
@@ -38,6 +160,19 @@ func resolveTextExtractCommand(
 	pageNum int,
 	userPassword string,
 	ownerPassword string,
+) (string, []string, error) {
+	return pdftotextCommand(lookPath, pdfPath, pageNum, "-layout", userPassword, ownerPassword)
+}
+
+// pdftotextCommand builds a pdftotext invocation for one page in the given
+// output mode, e.g. -layout or -bbox-layout.
+func pdftotextCommand(
+	lookPath func(string) (string, error),
+	pdfPath string,
+	pageNum int,
+	mode string,
+	userPassword string,
+	ownerPassword string,
 ) (string, []string, error) {
 	if _, err := lookPath("pdftotext"); err != nil {
 		return "", nil, errors.New("no text extraction backend available: install pdftotext from poppler-utils")
@@ -47,7 +182,7 @@ func resolveTextExtractCommand(
 	args := []string{
 		"-f", page,
 		"-l", page,
-		"-layout",
+		mode,
 	}
 
 	if userPassword != "" {
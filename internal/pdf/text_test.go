@@ -2,6 +2,8 @@ package pdf
 
 import (
 	"errors"
+	"math"
+	"reflect"
 	"testing"
 
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
@@ -70,3 +72,101 @@ func TestDocumentExtractTextValidation(t *testing.T) {
 		t.Fatal("ExtractText() expected error for out-of-range page number")
 	}
 }
+
+func TestPdftotextCommandBBoxLayout(t *testing.T) {
+	lookPath := func(string) (string, error) { return "/usr/bin/pdftotext", nil }
+
+	command, args, err := pdftotextCommand(lookPath, "/tmp/test.pdf", 0, "-bbox-layout", "", "")
+	if err != nil {
+		t.Fatalf("pdftotextCommand() returned error: %v", err)
+	}
+	want := []string{"-f", "1", "-l", "1", "-bbox-layout", "/tmp/test.pdf", "-"}
+	if command != "pdftotext" || !reflect.DeepEqual(args, want) {
+		t.Fatalf("pdftotextCommand() = %q %v, want pdftotext %v", command, args, want)
+	}
+}
+
+const bboxLayoutOutput = `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head>
+<title></title>
+<meta name="Producer" content="pdftotext"/>
+</head>
+<body>
+<doc>
+  <page width="612.000000" height="792.000000">
+    <flow>
+      <block xMin="72.000000" yMin="80.000000" xMax="160.000000" yMax="108.000000">
+        <line xMin="72.000000" yMin="80.000000" xMax="160.000000" yMax="92.000000">
+          <word xMin="72.000000" yMin="80.000000" xMax="120.000000" yMax="92.000000">Hello</word>
+          <word xMin="124.000000" yMin="80.000000" xMax="160.000000" yMax="92.000000">R&amp;D</word>
+        </line>
+        <line xMin="72.000000" yMin="96.000000" xMax="100.000000" yMax="108.000000">
+          <word xMin="72.000000" yMin="96.000000" xMax="100.000000" yMax="108.000000">team</word>
+          <word xMin="0" yMin="0" xMax="0" yMax="0"> </word>
+        </line>
+      </block>
+    </flow>
+    <flow>
+      <block xMin="72.000000" yMin="200.000000" xMax="96.000000" yMax="212.000000">
+        <line xMin="72.000000" yMin="200.000000" xMax="96.000000" yMax="212.000000">
+          <word xMin="72.000000" yMin="200.000000" xMax="96.000000" yMax="212.000000">Bye</word>
+        </line>
+      </block>
+    </flow>
+  </page>
+</doc>
+</body>
+</html>
+`
+
+func TestParseBBoxLayout(t *testing.T) {
+	text, err := parseBBoxLayout([]byte(bboxLayoutOutput), 2, defaultPageGeometry())
+	if err != nil {
+		t.Fatalf("parseBBoxLayout() returned error: %v", err)
+	}
+	if text.Page != 2 {
+		t.Errorf("Page = %d, want 2", text.Page)
+	}
+	if got, want := text.String(), "Hello R&D\nteam\n\nBye"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	if len(text.Blocks) != 2 || len(text.Blocks[0].Lines) != 2 {
+		t.Fatalf("got %d blocks, want 2 with 2 lines in the first", len(text.Blocks))
+	}
+	if got, want := text.Blocks[0].Rect, (Rect{LLX: 72, LLY: 684, URX: 160, URY: 712}); got != want {
+		t.Errorf("block rect = %+v, want %+v", got, want)
+	}
+
+	hello := text.Blocks[0].Lines[0].Words[0]
+	if got, want := hello.Rect, (Rect{LLX: 72, LLY: 700, URX: 120, URY: 712}); got != want {
+		t.Errorf("word rect = %+v, want %+v", got, want)
+	}
+	if len(hello.Chars) != 5 {
+		t.Fatalf("got %d chars, want 5", len(hello.Chars))
+	}
+	if got, want := hello.Chars[1], (TextChar{Text: "e", Rect: Rect{LLX: 81.6, LLY: 700, URX: 91.2, URY: 712}}); !rectNear(got.Rect, want.Rect) || got.Text != want.Text {
+		t.Errorf("char = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseBBoxLayoutScalesToPageSize(t *testing.T) {
+	// pdftotext ignores /UserUnit, so its page is half the displayed size.
+	g := defaultPageGeometry()
+	g.UserUnit = 2
+
+	text, err := parseBBoxLayout([]byte(bboxLayoutOutput), 0, g)
+	if err != nil {
+		t.Fatalf("parseBBoxLayout() returned error: %v", err)
+	}
+	if got := text.Words()[0].Rect; got != (Rect{LLX: 72, LLY: 700, URX: 120, URY: 712}) {
+		t.Fatalf("word box = %+v, want user space box unchanged", got)
+	}
+}
+
+func rectNear(a, b Rect) bool {
+	const eps = 1e-9
+	return math.Abs(a.LLX-b.LLX) < eps && math.Abs(a.LLY-b.LLY) < eps &&
+		math.Abs(a.URX-b.URX) < eps && math.Abs(a.URY-b.URY) < eps
+}
@@ -0,0 +1,740 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Namespaces of the XMP syntax and of the properties that mirror the Info
+// dictionary.
+const (
+	nsX   = "adobe:ns:meta/"
+	nsRDF = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsXML = "http://www.w3.org/XML/1998/namespace"
+	nsDC  = "http://purl.org/dc/elements/1.1/"
+	nsXMP = "http://ns.adobe.com/xap/1.0/"
+	nsPDF = "http://ns.adobe.com/pdf/1.3/"
+)
+
+// xmpPrefixes are the customary prefixes of well-known XMP namespaces.
+var xmpPrefixes = map[string]string{
+	"dc":        nsDC,
+	"xmp":       nsXMP,
+	"pdf":       nsPDF,
+	"xmpMM":     "http://ns.adobe.com/xap/1.0/mm/",
+	"xmpRights": "http://ns.adobe.com/xap/1.0/rights/",
+	"pdfaid":    "http://www.aiim.org/pdfa/ns/id/",
+}
+
+// XMPKind is the value type of an XMP property.
+type XMPKind int
+
+const (
+	// XMPText is a simple text value.
+	XMPText XMPKind = iota
+	// XMPBag is an unordered array.
+	XMPBag
+	// XMPSeq is an ordered array.
+	XMPSeq
+	// XMPAlt is a language alternative.
+	XMPAlt
+)
+
+// String returns the RDF container name of the kind.
+func (k XMPKind) String() string {
+	switch k {
+	case XMPBag:
+		return "Bag"
+	case XMPSeq:
+		return "Seq"
+	case XMPAlt:
+		return "Alt"
+	}
+	return "Text"
+}
+
+// XMPProperty is a top-level property of an XMP packet.
+type XMPProperty struct {
+	Namespace string
+	Name      string
+	Kind      XMPKind
+	Values    []string
+	// Langs holds the xml:lang of each value of an XMPAlt property.
+	Langs []string
+
+	// raw is the XML of a structured value this package does not model;
+	// it is written back unchanged.
+	raw string
+}
+
+// Value returns the value of a text property, the x-default (or first)
+// entry of a language alternative, or the first item of an array.
+func (p XMPProperty) Value() string {
+	if p.Kind == XMPAlt {
+		for i, lang := range p.Langs {
+			if lang == "x-default" && i < len(p.Values) {
+				return p.Values[i]
+			}
+		}
+	}
+	if len(p.Values) == 0 {
+		return ""
+	}
+	return p.Values[0]
+}
+
+// Structured reports whether the property has a structured value, which is
+// preserved but not exposed in Values.
+func (p XMPProperty) Structured() bool {
+	return p.raw != ""
+}
+
+// XMP is an XMP metadata packet.
+type XMP struct {
+	// namespaces maps the prefixes declared in the packet to namespace URIs.
+	namespaces map[string]string
+	props      []XMPProperty
+}
+
+// NewXMP returns an empty XMP packet.
+func NewXMP() *XMP {
+	return &XMP{namespaces: map[string]string{}}
+}
+
+// ParseXMP parses an XMP packet. Properties may be written as elements or
+// as attributes of rdf:Description, and several descriptions are merged.
+func ParseXMP(packet []byte) (*XMP, error) {
+	p := &xmpParser{
+		dec:    xml.NewDecoder(bytes.NewReader(packet)),
+		packet: packet,
+		xmp:    NewXMP(),
+	}
+	for {
+		tok, err := p.dec.Token()
+		if errors.Is(err, io.EOF) {
+			return p.xmp, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			p.declare(start)
+			if start.Name == (xml.Name{Space: nsRDF, Local: "Description"}) {
+				if err := p.description(start); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+}
+
+// Properties returns the properties of the packet in document order.
+func (x *XMP) Properties() []XMPProperty {
+	return append([]XMPProperty(nil), x.props...)
+}
+
+// Property returns the property name of namespace.
+func (x *XMP) Property(namespace, name string) (XMPProperty, bool) {
+	if i := x.index(namespace, name); i >= 0 {
+		return x.props[i], true
+	}
+	return XMPProperty{}, false
+}
+
+// Set adds a property, replacing any property of the same name.
+func (x *XMP) Set(p XMPProperty) {
+	p.raw = ""
+	if i := x.index(p.Namespace, p.Name); i >= 0 {
+		x.props[i] = p
+		return
+	}
+	x.props = append(x.props, p)
+}
+
+// SetText sets a simple text property.
+func (x *XMP) SetText(namespace, name, value string) {
+	x.Set(XMPProperty{Namespace: namespace, Name: name, Kind: XMPText, Values: []string{value}})
+}
+
+// Remove deletes a property, reporting whether it existed.
+func (x *XMP) Remove(namespace, name string) bool {
+	i := x.index(namespace, name)
+	if i < 0 {
+		return false
+	}
+	x.props = append(x.props[:i], x.props[i+1:]...)
+	return true
+}
+
+// RegisterNamespace declares prefix for a namespace URI. A prefix already
+// bound to another namespace cannot be rebound.
+func (x *XMP) RegisterNamespace(prefix, namespace string) error {
+	if prefix == "" || namespace == "" {
+		return errors.New("namespace prefix and URI must not be empty")
+	}
+	if prefix == "x" || prefix == "rdf" || strings.HasPrefix(strings.ToLower(prefix), "xml") {
+		return fmt.Errorf("namespace prefix %q is reserved", prefix)
+	}
+	if bound, ok := x.namespaces[prefix]; ok && bound != namespace {
+		return fmt.Errorf("namespace prefix %q is already bound to %s", prefix, bound)
+	}
+	x.namespaces[prefix] = namespace
+	return nil
+}
+
+// Namespace returns the namespace URI of prefix, as declared in the packet
+// or registered, or of a well-known prefix such as dc or xmp.
+func (x *XMP) Namespace(prefix string) (string, bool) {
+	if namespace, ok := x.namespaces[prefix]; ok {
+		return namespace, true
+	}
+	namespace, ok := xmpPrefixes[prefix]
+	return namespace, ok
+}
+
+// Prefix returns the prefix of a namespace URI, as declared in the packet
+// or registered, or its well-known prefix.
+func (x *XMP) Prefix(namespace string) (string, bool) {
+	if prefix, ok := prefixOf(x.namespaces, namespace); ok {
+		return prefix, true
+	}
+	if prefix, ok := prefixOf(xmpPrefixes, namespace); ok {
+		if _, taken := x.namespaces[prefix]; !taken {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// prefixOf returns the alphabetically first prefix bound to namespace.
+func prefixOf(namespaces map[string]string, namespace string) (string, bool) {
+	found := ""
+	for prefix, ns := range namespaces {
+		if ns == namespace && (found == "" || prefix < found) {
+			found = prefix
+		}
+	}
+	return found, found != ""
+}
+
+// Marshal serializes the packet with a single rdf:Description and padding
+// for in-place updates.
+func (x *XMP) Marshal() []byte {
+	// Bind a prefix to every namespace used; unknown namespaces get ns1,
+	// ns2 and so on.
+	declared := make(map[string]string, len(x.namespaces))
+	for prefix, ns := range x.namespaces {
+		declared[prefix] = ns
+	}
+	prefixes := map[string]string{}
+	for _, p := range x.props {
+		if _, ok := prefixes[p.Namespace]; ok {
+			continue
+		}
+		prefix, ok := x.Prefix(p.Namespace)
+		for n := 1; !ok; n++ {
+			prefix = fmt.Sprintf("ns%d", n)
+			_, taken := declared[prefix]
+			ok = !taken
+		}
+		declared[prefix] = p.Namespace
+		prefixes[p.Namespace] = prefix
+	}
+	names := make([]string, 0, len(declared))
+	for prefix := range declared {
+		names = append(names, prefix)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	b.WriteString("<?xpacket begin=\"\ufeff\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	b.WriteString("<x:xmpmeta xmlns:x=\"" + nsX + "\">\n")
+	b.WriteString(" <rdf:RDF xmlns:rdf=\"" + nsRDF + "\">\n")
+	b.WriteString("  <rdf:Description rdf:about=\"\"")
+	for _, prefix := range names {
+		fmt.Fprintf(&b, "\n    xmlns:%s=\"%s\"", prefix, xmlEscape(declared[prefix]))
+	}
+	b.WriteString(">\n")
+	for _, p := range x.props {
+		if p.raw != "" {
+			b.WriteString("   " + p.raw + "\n")
+			continue
+		}
+		name := prefixes[p.Namespace] + ":" + p.Name
+		if p.Kind == XMPText {
+			fmt.Fprintf(&b, "   <%s>%s</%s>\n", name, xmlEscape(p.Value()), name)
+			continue
+		}
+		fmt.Fprintf(&b, "   <%s>\n    <rdf:%s>\n", name, p.Kind)
+		for i, value := range p.Values {
+			lang := ""
+			if p.Kind == XMPAlt {
+				lang = "x-default"
+				if i < len(p.Langs) && p.Langs[i] != "" {
+					lang = p.Langs[i]
+				}
+				lang = ` xml:lang="` + xmlEscape(lang) + `"`
+			}
+			fmt.Fprintf(&b, "     <rdf:li%s>%s</rdf:li>\n", lang, xmlEscape(value))
+		}
+		fmt.Fprintf(&b, "    </rdf:%s>\n   </%s>\n", p.Kind, name)
+	}
+	b.WriteString("  </rdf:Description>\n </rdf:RDF>\n</x:xmpmeta>\n")
+	// Padding lets other tools update the packet without rewriting the file.
+	for range 20 {
+		b.WriteString(strings.Repeat(" ", 99) + "\n")
+	}
+	b.WriteString(`<?xpacket end="w"?>`)
+	return b.Bytes()
+}
+
+func (x *XMP) index(namespace, name string) int {
+	for i, p := range x.props {
+		if p.Namespace == namespace && p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetAlt sets the x-default entry of a language alternative, keeping the
+// other languages.
+func (x *XMP) SetAlt(namespace, name, value string) {
+	if p, ok := x.Property(namespace, name); ok && p.Kind == XMPAlt && !p.Structured() {
+		for i, lang := range p.Langs {
+			if lang == "x-default" && i < len(p.Values) {
+				values := append([]string(nil), p.Values...)
+				values[i] = value
+				p.Values = values
+				x.Set(p)
+				return
+			}
+		}
+	}
+	x.Set(XMPProperty{Namespace: namespace, Name: name, Kind: XMPAlt, Values: []string{value}, Langs: []string{"x-default"}})
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// xmpParser reads the properties of the rdf:Description elements of a
+// packet.
+type xmpParser struct {
+	dec    *xml.Decoder
+	packet []byte
+	xmp    *XMP
+}
+
+// declare records the namespace declarations of an element.
+func (p *xmpParser) declare(start xml.StartElement) {
+	for _, attr := range start.Attr {
+		if attr.Name.Space != "xmlns" || attr.Name.Local == "x" || attr.Name.Local == "rdf" {
+			continue
+		}
+		if _, ok := p.xmp.namespaces[attr.Name.Local]; !ok {
+			p.xmp.namespaces[attr.Name.Local] = attr.Value
+		}
+	}
+}
+
+// description reads the properties of an rdf:Description element.
+func (p *xmpParser) description(start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Space {
+		case "", "xmlns", nsRDF, nsXML:
+			continue
+		}
+		p.xmp.SetText(attr.Name.Space, attr.Name.Local, strings.TrimSpace(attr.Value))
+	}
+
+	for {
+		offset := p.dec.InputOffset()
+		tok, err := p.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if err := p.property(t, offset); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// property reads a property element that starts at offset in the packet.
+// Text, arrays of text and language alternatives are modeled; any other
+// value is kept as raw XML.
+func (p *xmpParser) property(start xml.StartElement, offset int64) error {
+	p.declare(start)
+	prop := XMPProperty{Namespace: start.Name.Space, Name: start.Name.Local}
+	structured := !plainXMPAttrs(start, false)
+
+	var text, item strings.Builder
+	container := false
+	lang := ""
+	for depth := 0; ; {
+		tok, err := p.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			p.declare(t)
+			depth++
+			switch {
+			case depth == 1 && !container && t.Name.Space == nsRDF && plainXMPAttrs(t, false):
+				switch t.Name.Local {
+				case "Bag":
+					prop.Kind = XMPBag
+				case "Seq":
+					prop.Kind = XMPSeq
+				case "Alt":
+					prop.Kind = XMPAlt
+				default:
+					structured = true
+				}
+				container = true
+			case depth == 2 && t.Name == (xml.Name{Space: nsRDF, Local: "li"}) && plainXMPAttrs(t, true):
+				item.Reset()
+				lang = ""
+				for _, attr := range t.Attr {
+					if attr.Name == (xml.Name{Space: nsXML, Local: "lang"}) {
+						lang = attr.Value
+					}
+				}
+			default:
+				structured = true
+			}
+		case xml.CharData:
+			switch {
+			case depth == 0:
+				text.Write(t)
+			case depth == 2:
+				item.Write(t)
+			case len(bytes.TrimSpace(t)) > 0:
+				structured = true
+			}
+		case xml.EndElement:
+			switch depth {
+			case 0:
+				switch {
+				case structured || (container && strings.TrimSpace(text.String()) != ""):
+					prop.Kind, prop.Values, prop.Langs = XMPText, nil, nil
+					prop.raw = strings.TrimSpace(string(p.packet[offset:p.dec.InputOffset()]))
+				case !container:
+					prop.Values = []string{strings.TrimSpace(text.String())}
+				}
+				if i := p.xmp.index(prop.Namespace, prop.Name); i >= 0 {
+					p.xmp.props[i] = prop
+				} else {
+					p.xmp.props = append(p.xmp.props, prop)
+				}
+				return nil
+			case 2:
+				prop.Values = append(prop.Values, strings.TrimSpace(item.String()))
+				if prop.Kind == XMPAlt {
+					prop.Langs = append(prop.Langs, lang)
+				}
+			}
+			depth--
+		}
+	}
+}
+
+// plainXMPAttrs reports whether an element has no attributes but namespace
+// declarations and, if allowLang is set, xml:lang.
+func plainXMPAttrs(start xml.StartElement, allowLang bool) bool {
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" {
+			continue
+		}
+		if allowLang && attr.Name == (xml.Name{Space: nsXML, Local: "lang"}) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// XMP returns the document-level XMP metadata, or an empty packet if the
+// document has none.
+func (d *Document) XMP() (*XMP, error) {
+	if d.context() == nil {
+		return nil, errors.New("no document loaded")
+	}
+
+	d.renderMu.Lock()
+	defer d.renderMu.Unlock()
+	catalog, err := d.ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	packet, err := readXMPPacket(d.ctx.XRefTable, catalog)
+	if err != nil {
+		return nil, err
+	}
+	if packet == nil {
+		return NewXMP(), nil
+	}
+	return ParseXMP(packet)
+}
+
+// SetXMP replaces the document-level XMP metadata. Mirrored properties that
+// changed, such as dc:title, are copied to the Info dictionary. The change
+// is kept in memory until the document is saved.
+func (d *Document) SetXMP(x *XMP) error {
+	if d.context() == nil {
+		return errors.New("no document loaded")
+	}
+
+	d.renderMu.Lock()
+	defer d.renderMu.Unlock()
+	catalog, err := d.ctx.Catalog()
+	if err != nil {
+		return err
+	}
+	// A missing or broken packet mirrors nothing, so every field set in x
+	// counts as changed.
+	old, _ := readXMPInfo(d.ctx.XRefTable, catalog)
+	info := readInfoDict(d.ctx)
+	updated := x.info()
+	for _, key := range infoEditableKeys {
+		if *updated.text(key) != *old.text(key) {
+			*info.text(key) = *updated.text(key)
+		}
+	}
+	if err := writeInfo(d.ctx, info); err != nil {
+		return err
+	}
+	if err := writeXMPPacket(d.ctx.XRefTable, catalog, x.Marshal()); err != nil {
+		return err
+	}
+	d.setModified(true)
+	return nil
+}
+
+// syncXMP copies the Info dictionary into the XMP metadata before a save,
+// including the producer and dates the PDF writer is about to set. A
+// document without XMP metadata is left alone, and a packet that cannot be
+// parsed is not touched.
+func syncXMP(ctx *model.Context) error {
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+	packet, err := readXMPPacket(ctx.XRefTable, catalog)
+	if err != nil || packet == nil {
+		return nil
+	}
+	x, err := ParseXMP(packet)
+	if err != nil {
+		return nil
+	}
+
+	info := readInfoDict(ctx)
+	info.Producer = "pdfcpu " + model.VersionStr
+	info.Modified = time.Now()
+	// The writer also resets CreationDate, but the XMP creation date
+	// records when the document was made.
+	info.Created = time.Time{}
+	if _, ok := x.Property(nsXMP, "CreateDate"); !ok {
+		info.Created = info.Modified
+	}
+	x.setInfo(info)
+	return writeXMPPacket(ctx.XRefTable, catalog, x.Marshal())
+}
+
+// clearXMPInfo removes the XMP properties mirroring the editable Info
+// fields that are empty in info. Saving copies only the fields that are
+// set, so a cleared field would otherwise come back from the XMP metadata.
+// A document without XMP metadata, or with a packet that cannot be parsed,
+// is left alone.
+func clearXMPInfo(ctx *model.Context, info Info) error {
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+	packet, err := readXMPPacket(ctx.XRefTable, catalog)
+	if err != nil || packet == nil {
+		return nil
+	}
+	x, err := ParseXMP(packet)
+	if err != nil {
+		return nil
+	}
+
+	removed := false
+	for _, key := range infoEditableKeys {
+		if strings.TrimSpace(*info.text(key)) == "" {
+			namespace, name := xmpInfoProperty(key)
+			removed = x.Remove(namespace, name) || removed
+		}
+	}
+	if !removed {
+		return nil
+	}
+	return writeXMPPacket(ctx.XRefTable, catalog, x.Marshal())
+}
+
+// xmpInfoProperty returns the XMP property mirroring an Info dictionary
+// text key.
+func xmpInfoProperty(key string) (namespace, name string) {
+	switch key {
+	case "Title":
+		return nsDC, "title"
+	case "Author":
+		return nsDC, "creator"
+	case "Subject":
+		return nsDC, "description"
+	case "Keywords":
+		return nsPDF, "Keywords"
+	case "Creator":
+		return nsXMP, "CreatorTool"
+	case "Producer":
+		return nsPDF, "Producer"
+	}
+	return "", ""
+}
+
+// readXMPPacket returns the decoded document-level XMP metadata stream, or
+// nil if the catalog has none.
+func readXMPPacket(xRefTable *model.XRefTable, catalog types.Dict) ([]byte, error) {
+	obj, ok := catalog.Find("Metadata")
+	if !ok || obj == nil {
+		return nil, nil
+	}
+	sd, _, err := xRefTable.DereferenceStreamDict(obj)
+	if err != nil || sd == nil {
+		return nil, err
+	}
+	if err := sd.Decode(); err != nil {
+		return nil, err
+	}
+	return sd.Content, nil
+}
+
+// writeXMPPacket stores packet as the document-level metadata stream. The
+// stream is left unfiltered so that tools scanning the file for XMP can
+// read it.
+func writeXMPPacket(xRefTable *model.XRefTable, catalog types.Dict, packet []byte) error {
+	sd := types.StreamDict{Dict: types.NewDict(), Content: packet}
+	sd.InsertName("Type", "Metadata")
+	sd.InsertName("Subtype", "XML")
+	if err := sd.Encode(); err != nil {
+		return err
+	}
+	ref, err := xRefTable.IndRefForNewObject(sd)
+	if err != nil {
+		return err
+	}
+	catalog.Update("Metadata", *ref)
+	return nil
+}
+
+// readXMPInfo reads the Info fields mirrored in the XMP metadata.
+func readXMPInfo(xRefTable *model.XRefTable, catalog types.Dict) (Info, error) {
+	packet, err := readXMPPacket(xRefTable, catalog)
+	if err != nil || packet == nil {
+		return Info{}, err
+	}
+	return parseXMPInfo(packet)
+}
+
+// parseXMPInfo extracts the Info fields from an XMP packet.
+func parseXMPInfo(packet []byte) (Info, error) {
+	x, err := ParseXMP(packet)
+	if err != nil {
+		return Info{}, err
+	}
+	return x.info(), nil
+}
+
+// info returns the Info fields mirrored in the packet; of language
+// alternatives the x-default entry wins, and creators are joined.
+func (x *XMP) info() Info {
+	value := func(namespace, name string) string {
+		p, _ := x.Property(namespace, name)
+		return p.Value()
+	}
+	creator, _ := x.Property(nsDC, "creator")
+	return Info{
+		Title:    value(nsDC, "title"),
+		Author:   strings.Join(creator.Values, ", "),
+		Subject:  value(nsDC, "description"),
+		Keywords: value(nsPDF, "Keywords"),
+		Creator:  value(nsXMP, "CreatorTool"),
+		Producer: value(nsPDF, "Producer"),
+		Created:  parseXMPDate(value(nsXMP, "CreateDate")),
+		Modified: parseXMPDate(value(nsXMP, "ModifyDate")),
+	}
+}
+
+// setInfo updates the mirrored properties from the non-empty fields of info.
+func (x *XMP) setInfo(info Info) {
+	if info.Title != "" {
+		x.SetAlt(nsDC, "title", info.Title)
+	}
+	if info.Author != "" {
+		// Keep the list of creators the author was joined from.
+		if creator, ok := x.Property(nsDC, "creator"); !ok || strings.Join(creator.Values, ", ") != info.Author {
+			x.Set(XMPProperty{Namespace: nsDC, Name: "creator", Kind: XMPSeq, Values: []string{info.Author}})
+		}
+	}
+	if info.Subject != "" {
+		x.SetAlt(nsDC, "description", info.Subject)
+	}
+	if info.Keywords != "" {
+		x.SetText(nsPDF, "Keywords", info.Keywords)
+	}
+	if info.Creator != "" {
+		x.SetText(nsXMP, "CreatorTool", info.Creator)
+	}
+	if info.Producer != "" {
+		x.SetText(nsPDF, "Producer", info.Producer)
+	}
+	if !info.Created.IsZero() {
+		x.SetText(nsXMP, "CreateDate", formatXMPDate(info.Created))
+	}
+	if !info.Modified.IsZero() {
+		x.SetText(nsXMP, "ModifyDate", formatXMPDate(info.Modified))
+		x.SetText(nsXMP, "MetadataDate", formatXMPDate(info.Modified))
+	}
+}
+
+// parseXMPDate parses an XMP date, which may omit the seconds, the time or
+// the time zone. It returns the zero time if the date is malformed.
+func parseXMPDate(s string) time.Time {
+	for _, layout := range []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04Z07:00",
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02",
+		"2006-01",
+		"2006",
+	} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func formatXMPDate(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05Z07:00")
+}
@@ -0,0 +1,247 @@
+package pdf
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testCustomXMPPacket = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:arc="http://example.com/archive/" arc:Box="B-17">
+   <dc:title><rdf:Alt><rdf:li xml:lang="x-default">Minutes &amp; notes</rdf:li></rdf:Alt></dc:title>
+   <dc:subject><rdf:Bag><rdf:li>board</rdf:li><rdf:li>2024</rdf:li></rdf:Bag></dc:subject>
+   <arc:Retention rdf:parseType="Resource"><arc:Years>10</arc:Years></arc:Retention>
+  </rdf:Description>
+  <rdf:Description rdf:about="" xmlns:arc="http://example.com/archive/">
+   <arc:Reviewers><rdf:Seq><rdf:li>Ann</rdf:li><rdf:li>Bob</rdf:li></rdf:Seq></arc:Reviewers>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+const nsArchive = "http://example.com/archive/"
+
+func TestParseXMP(t *testing.T) {
+	x, err := ParseXMP([]byte(testCustomXMPPacket))
+	if err != nil {
+		t.Fatalf("ParseXMP() returned error: %v", err)
+	}
+
+	props := x.Properties()
+	if len(props) != 5 {
+		t.Fatalf("ParseXMP() read %d properties, want 5: %+v", len(props), props)
+	}
+	if p, ok := x.Property(nsArchive, "Box"); !ok || p.Kind != XMPText || p.Value() != "B-17" {
+		t.Errorf("arc:Box = %+v, %v", p, ok)
+	}
+	if p, _ := x.Property(nsDC, "title"); p.Kind != XMPAlt || p.Value() != "Minutes & notes" {
+		t.Errorf("dc:title = %+v", p)
+	}
+	if p, _ := x.Property(nsDC, "subject"); p.Kind != XMPBag || !reflect.DeepEqual(p.Values, []string{"board", "2024"}) {
+		t.Errorf("dc:subject = %+v", p)
+	}
+	if p, _ := x.Property(nsArchive, "Reviewers"); p.Kind != XMPSeq || !reflect.DeepEqual(p.Values, []string{"Ann", "Bob"}) {
+		t.Errorf("arc:Reviewers = %+v", p)
+	}
+	if p, _ := x.Property(nsArchive, "Retention"); !p.Structured() {
+		t.Errorf("arc:Retention = %+v, want structured", p)
+	}
+	if prefix, ok := x.Prefix(nsArchive); !ok || prefix != "arc" {
+		t.Errorf("Prefix(archive) = %q, %v, want arc", prefix, ok)
+	}
+
+	// Marshal and parse again; structured values survive unchanged.
+	again, err := ParseXMP(x.Marshal())
+	if err != nil {
+		t.Fatalf("ParseXMP(Marshal()) returned error: %v", err)
+	}
+	if !reflect.DeepEqual(again.Properties(), props) {
+		t.Errorf("round trip = %+v, want %+v", again.Properties(), props)
+	}
+}
+
+func TestXMPEdit(t *testing.T) {
+	x := NewXMP()
+	if err := x.RegisterNamespace("arc", nsArchive); err != nil {
+		t.Fatalf("RegisterNamespace() returned error: %v", err)
+	}
+	if err := x.RegisterNamespace("arc", "http://example.com/other/"); err == nil {
+		t.Error("RegisterNamespace() rebound a prefix")
+	}
+	if err := x.RegisterNamespace("rdf", nsArchive); err == nil {
+		t.Error("RegisterNamespace() accepted a reserved prefix")
+	}
+	x.SetText(nsArchive, "Box", "B-1")
+	x.SetText(nsArchive, "Box", "B-2")
+	x.SetText("http://example.com/unbound/", "Flag", "<yes>")
+	x.Set(XMPProperty{Namespace: nsDC, Name: "creator", Kind: XMPSeq, Values: []string{"Ann"}})
+	x.SetText(nsDC, "format", "application/pdf")
+	if !x.Remove(nsDC, "format") || x.Remove(nsDC, "format") {
+		t.Error("Remove() did not report the removal once")
+	}
+
+	packet := string(x.Marshal())
+	for _, want := range []string{
+		`xmlns:arc="http://example.com/archive/"`,
+		`xmlns:ns1="http://example.com/unbound/"`,
+		`xmlns:dc="http://purl.org/dc/elements/1.1/"`,
+		"<arc:Box>B-2</arc:Box>",
+		"<ns1:Flag>&lt;yes&gt;</ns1:Flag>",
+		"<rdf:Seq>",
+		`<?xpacket end="w"?>`,
+	} {
+		if !strings.Contains(packet, want) {
+			t.Errorf("Marshal() lacks %s:\n%s", want, packet)
+		}
+	}
+	if strings.Contains(packet, "dc:format") {
+		t.Errorf("Marshal() kept the removed property:\n%s", packet)
+	}
+}
+
+func TestDocumentXMP(t *testing.T) {
+	path := writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R /Metadata 4 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		fmt.Sprintf("<< /Type /Metadata /Subtype /XML /Length %d >>\nstream\n%s\nendstream", len(testCustomXMPPacket)+1, testCustomXMPPacket),
+	)
+	doc, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+
+	x, err := doc.XMP()
+	if err != nil {
+		t.Fatalf("XMP() returned error: %v", err)
+	}
+	x.SetText(nsArchive, "Box", "C-3")
+	x.SetAlt(nsDC, "title", "Approved minutes")
+	if err := doc.SetXMP(x); err != nil {
+		t.Fatalf("SetXMP() returned error: %v", err)
+	}
+	if !doc.IsModified() {
+		t.Error("SetXMP() did not mark the document modified")
+	}
+	// The changed title is mirrored into the Info dictionary.
+	if info := readInfoDict(doc.ctx); info.Title != "Approved minutes" {
+		t.Errorf("Info Title = %q, want Approved minutes", info.Title)
+	}
+
+	// Info changes reach the XMP metadata on save.
+	info, err := doc.Info()
+	if err != nil {
+		t.Fatalf("Info() returned error: %v", err)
+	}
+	info.Author = "Carol"
+	if err := doc.SetInfo(info); err != nil {
+		t.Fatalf("SetInfo() returned error: %v", err)
+	}
+	out := filepath.Join(t.TempDir(), "xmp.pdf")
+	if err := doc.SaveAs(out); err != nil {
+		t.Fatalf("SaveAs() failed: %v", err)
+	}
+
+	saved, err := Open(out)
+	if err != nil {
+		t.Fatalf("Open(saved) failed: %v", err)
+	}
+	defer saved.Close()
+	got, err := saved.XMP()
+	if err != nil {
+		t.Fatalf("XMP() of saved returned error: %v", err)
+	}
+	for _, want := range []struct {
+		namespace, name, value string
+	}{
+		{nsArchive, "Box", "C-3"},
+		{nsDC, "title", "Approved minutes"},
+		{nsDC, "creator", "Carol"},
+		{nsArchive, "Reviewers", "Ann"},
+	} {
+		if p, _ := got.Property(want.namespace, want.name); p.Value() != want.value {
+			t.Errorf("saved %s = %+v, want %q", want.name, p, want.value)
+		}
+	}
+	if p, _ := got.Property(nsPDF, "Producer"); !strings.HasPrefix(p.Value(), "pdfcpu") {
+		t.Errorf("saved pdf:Producer = %q, want the writer", p.Value())
+	}
+	if p, _ := got.Property(nsXMP, "ModifyDate"); parseXMPDate(p.Value()).IsZero() {
+		t.Errorf("saved xmp:ModifyDate = %q", p.Value())
+	}
+	if p, _ := got.Property(nsArchive, "Retention"); !p.Structured() {
+		t.Errorf("saved arc:Retention = %+v, want structured", p)
+	}
+
+	// A document without metadata has an empty packet.
+	plain, err := Open(writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+	))
+	if err != nil {
+		t.Fatalf("Open(plain) failed: %v", err)
+	}
+	defer plain.Close()
+	if x, err := plain.XMP(); err != nil || len(x.Properties()) != 0 {
+		t.Errorf("XMP() without metadata = %v, %v, want an empty packet", x, err)
+	}
+}
+
+func TestDocumentSetInfoClearsXMP(t *testing.T) {
+	path := writeObjectsPDF(t,
+		"<< /Type /Catalog /Pages 2 0 R /Metadata 4 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		fmt.Sprintf("<< /Type /Metadata /Subtype /XML /Length %d >>\nstream\n%s\nendstream", len(testCustomXMPPacket)+1, testCustomXMPPacket),
+	)
+	doc, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer doc.Close()
+
+	// The title comes from the XMP metadata alone.
+	info, err := doc.Info()
+	if err != nil || info.Title != "Minutes & notes" {
+		t.Fatalf("Info() = %+v, %v", info, err)
+	}
+	info.Title = ""
+	info.Author = "Carol"
+	if err := doc.SetInfo(info); err != nil {
+		t.Fatalf("SetInfo() returned error: %v", err)
+	}
+	out := filepath.Join(t.TempDir(), "cleared.pdf")
+	if err := doc.SaveAs(out); err != nil {
+		t.Fatalf("SaveAs() failed: %v", err)
+	}
+
+	saved, err := Open(out)
+	if err != nil {
+		t.Fatalf("Open(saved) failed: %v", err)
+	}
+	defer saved.Close()
+	got, err := saved.Info()
+	if err != nil {
+		t.Fatalf("Info() of saved returned error: %v", err)
+	}
+	if got.Title != "" || got.Author != "Carol" {
+		t.Errorf("saved Title = %q, Author = %q, want the title cleared", got.Title, got.Author)
+	}
+	x, err := saved.XMP()
+	if err != nil {
+		t.Fatalf("XMP() of saved returned error: %v", err)
+	}
+	if p, ok := x.Property(nsDC, "title"); ok {
+		t.Errorf("saved dc:title = %+v, want it removed", p)
+	}
+	if p, _ := x.Property(nsArchive, "Box"); p.Value() != "B-17" {
+		t.Errorf("saved arc:Box = %+v, want it kept", p)
+	}
+}
@@ -0,0 +1,166 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+// newAttachmentsTab builds the content of the Attachments tab: the list of
+// embedded files with actions to add, remove, save and open them.
+func (s *Sidebar) newAttachmentsTab() fyne.CanvasObject {
+	s.selectedAttachment = -1
+	s.attachmentList = widget.NewList(
+		func() int {
+			return len(s.attachments)
+		},
+		func() fyne.CanvasObject {
+			name := widget.NewLabel("attachment.xml")
+			name.Truncation = fyne.TextTruncateEllipsis
+			detail := widget.NewLabel("")
+			detail.Truncation = fyne.TextTruncateEllipsis
+			detail.SizeName = theme.SizeNameCaptionText
+			return container.NewVBox(name, detail)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < 0 || id >= len(s.attachments) {
+				return
+			}
+			a := s.attachments[id]
+			item := obj.(*fyne.Container)
+			item.Objects[0].(*widget.Label).SetText(a.Name)
+			item.Objects[1].(*widget.Label).SetText(attachmentDetail(a))
+		},
+	)
+	s.attachmentList.OnSelected = func(id widget.ListItemID) {
+		s.selectedAttachment = id
+	}
+	s.attachmentList.OnUnselected = func(widget.ListItemID) {
+		s.selectedAttachment = -1
+	}
+	s.noAttachments = widget.NewLabel("This document has no attachments")
+	s.noAttachments.Wrapping = fyne.TextWrapWord
+
+	tools := widget.NewToolbar(
+		widget.NewToolbarAction(theme.ContentAddIcon(), s.addAttachment),
+		widget.NewToolbarAction(theme.DeleteIcon(), s.removeAttachment),
+		widget.NewToolbarSeparator(),
+		widget.NewToolbarAction(theme.DocumentSaveIcon(), s.saveAttachment),
+		widget.NewToolbarAction(theme.FolderOpenIcon(), s.openAttachment),
+	)
+	return container.NewBorder(tools, nil, nil, nil,
+		container.NewStack(s.attachmentList, container.NewVBox(s.noAttachments)))
+}
+
+// loadAttachments lists the attachments of the current document.
+func (s *Sidebar) loadAttachments() {
+	s.attachments = nil
+	if s.document != nil {
+		// Unreadable attachments just leave the tab empty.
+		s.attachments, _ = s.document.Attachments()
+	}
+	s.selectedAttachment = -1
+	s.attachmentList.UnselectAll()
+	s.attachmentList.Refresh()
+	if len(s.attachments) == 0 {
+		s.attachmentList.Hide()
+		s.noAttachments.Show()
+	} else {
+		s.noAttachments.Hide()
+		s.attachmentList.Show()
+	}
+}
+
+// selectedAttachmentItem returns the attachment the actions apply to.
+func (s *Sidebar) selectedAttachmentItem() (pdf.Attachment, bool) {
+	if s.selectedAttachment < 0 || s.selectedAttachment >= len(s.attachments) {
+		return pdf.Attachment{}, false
+	}
+	return s.attachments[s.selectedAttachment], true
+}
+
+// addAttachment embeds a file chosen by the user.
+func (s *Sidebar) addAttachment() {
+	if s.document == nil || s.ChooseAttachmentFile == nil {
+		return
+	}
+	doc := s.document
+	s.ChooseAttachmentFile(func(path string) {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			err = doc.AddAttachment(pdf.Attachment{Name: filepath.Base(path)}, data)
+		}
+		s.attachmentsChanged(doc, err)
+	})
+}
+
+func (s *Sidebar) removeAttachment() {
+	a, ok := s.selectedAttachmentItem()
+	if !ok || s.document == nil {
+		return
+	}
+	s.attachmentsChanged(s.document, s.document.RemoveAttachment(a.Name))
+}
+
+func (s *Sidebar) saveAttachment() {
+	if a, ok := s.selectedAttachmentItem(); ok && s.OnSaveAttachment != nil {
+		s.OnSaveAttachment(a)
+	}
+}
+
+func (s *Sidebar) openAttachment() {
+	if a, ok := s.selectedAttachmentItem(); ok && s.OnOpenAttachment != nil {
+		s.OnOpenAttachment(a)
+	}
+}
+
+// attachmentsChanged reloads the list after an edit of doc, unless another
+// document has been shown meanwhile.
+func (s *Sidebar) attachmentsChanged(doc *pdf.Document, err error) {
+	if s.document == doc {
+		s.loadAttachments()
+	}
+	if s.OnAttachmentsChanged != nil {
+		s.OnAttachmentsChanged(err)
+	}
+}
+
+// attachmentDetail describes the size, date and description of an
+// attachment for its list entry.
+func attachmentDetail(a pdf.Attachment) string {
+	var parts []string
+	if a.Size >= 0 {
+		parts = append(parts, formatByteSize(a.Size))
+	}
+	if !a.Modified.IsZero() {
+		parts = append(parts, a.Modified.Local().Format("2006-01-02"))
+	}
+	if a.Description != "" {
+		parts = append(parts, a.Description)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatByteSize formats a size such as 1536 as "1.5 KB".
+func formatByteSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value, suffix := float64(size)/unit, "KB"
+	for _, next := range []string{"MB", "GB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
@@ -0,0 +1,26 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+func TestAttachmentDetail(t *testing.T) {
+	modified := time.Date(2024, 5, 6, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		a    pdf.Attachment
+		want string
+	}{
+		{pdf.Attachment{Size: 512}, "512 B"},
+		{pdf.Attachment{Size: 1536, Modified: modified, Description: "Invoice"}, "1.5 KB · 2024-05-06 · Invoice"},
+		{pdf.Attachment{Size: 3 << 20}, "3.0 MB"},
+		{pdf.Attachment{Size: -1, Description: "Unknown size"}, "Unknown size"},
+	}
+	for _, tt := range tests {
+		if got := attachmentDetail(tt.a); got != tt.want {
+			t.Errorf("attachmentDetail(%+v) = %q, want %q", tt.a, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+// The bookmark edits below work on a copy of an outline and address items
+// by their outlineTree node ID. Each returns the new outline and the ID of
+// the edited item in it, or ok false if the edit does not apply.
+
+// outlinePath parses a node ID such as "0.2" into child indexes.
+func outlinePath(id widget.TreeNodeID) ([]int, bool) {
+	if id == "" {
+		return nil, false
+	}
+	parts := strings.Split(id, ".")
+	path := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		path[i] = n
+	}
+	return path, true
+}
+
+// outlineID formats child indexes as a node ID.
+func outlineID(path []int) widget.TreeNodeID {
+	parts := make([]string, len(path))
+	for i, n := range path {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ".")
+}
+
+// cloneOutline copies the item slices of an outline at all levels.
+func cloneOutline(items []pdf.OutlineItem) []pdf.OutlineItem {
+	if items == nil {
+		return nil
+	}
+	out := make([]pdf.OutlineItem, len(items))
+	for i, item := range items {
+		item.Children = cloneOutline(item.Children)
+		out[i] = item
+	}
+	return out
+}
+
+// outlineSiblings returns the list holding the item at path, checking that
+// every index on the way exists.
+func outlineSiblings(items *[]pdf.OutlineItem, path []int) (*[]pdf.OutlineItem, bool) {
+	list := items
+	for i, n := range path {
+		if n >= len(*list) {
+			return nil, false
+		}
+		if i < len(path)-1 {
+			list = &(*list)[n].Children
+		}
+	}
+	return list, true
+}
+
+// insertBookmark adds item after the item with ID after, or at the end of
+// the top level if after is empty.
+func insertBookmark(items []pdf.OutlineItem, after widget.TreeNodeID, item pdf.OutlineItem) ([]pdf.OutlineItem, widget.TreeNodeID, bool) {
+	items = cloneOutline(items)
+	path, ok := outlinePath(after)
+	if !ok {
+		items = append(items, item)
+		return items, outlineID([]int{len(items) - 1}), true
+	}
+	list, ok := outlineSiblings(&items, path)
+	if !ok {
+		return nil, "", false
+	}
+	i := path[len(path)-1] + 1
+	*list = append((*list)[:i], append([]pdf.OutlineItem{item}, (*list)[i:]...)...)
+	path[len(path)-1] = i
+	return items, outlineID(path), true
+}
+
+// removeBookmark deletes an item with its children.
+func removeBookmark(items []pdf.OutlineItem, id widget.TreeNodeID) ([]pdf.OutlineItem, bool) {
+	items = cloneOutline(items)
+	path, ok := outlinePath(id)
+	if !ok {
+		return nil, false
+	}
+	list, ok := outlineSiblings(&items, path)
+	if !ok {
+		return nil, false
+	}
+	i := path[len(path)-1]
+	*list = append((*list)[:i], (*list)[i+1:]...)
+	return items, true
+}
+
+// renameBookmark sets the title of an item.
+func renameBookmark(items []pdf.OutlineItem, id widget.TreeNodeID, title string) ([]pdf.OutlineItem, bool) {
+	items = cloneOutline(items)
+	path, ok := outlinePath(id)
+	if !ok {
+		return nil, false
+	}
+	list, ok := outlineSiblings(&items, path)
+	if !ok {
+		return nil, false
+	}
+	(*list)[path[len(path)-1]].Title = title
+	return items, true
+}
+
+// moveBookmark swaps an item with the sibling delta places away.
+func moveBookmark(items []pdf.OutlineItem, id widget.TreeNodeID, delta int) ([]pdf.OutlineItem, widget.TreeNodeID, bool) {
+	items = cloneOutline(items)
+	path, ok := outlinePath(id)
+	if !ok {
+		return nil, "", false
+	}
+	list, ok := outlineSiblings(&items, path)
+	if !ok {
+		return nil, "", false
+	}
+	i := path[len(path)-1]
+	j := i + delta
+	if j < 0 || j >= len(*list) {
+		return nil, "", false
+	}
+	(*list)[i], (*list)[j] = (*list)[j], (*list)[i]
+	path[len(path)-1] = j
+	return items, outlineID(path), true
+}
+
+// indentBookmark makes an item the last child of its previous sibling,
+// which is opened to show it.
+func indentBookmark(items []pdf.OutlineItem, id widget.TreeNodeID) ([]pdf.OutlineItem, widget.TreeNodeID, bool) {
+	items = cloneOutline(items)
+	path, ok := outlinePath(id)
+	if !ok {
+		return nil, "", false
+	}
+	list, ok := outlineSiblings(&items, path)
+	i := path[len(path)-1]
+	if !ok || i == 0 {
+		return nil, "", false
+	}
+	item := (*list)[i]
+	*list = append((*list)[:i], (*list)[i+1:]...)
+	parent := &(*list)[i-1]
+	parent.Children = append(parent.Children, item)
+	parent.Open = true
+	path[len(path)-1] = i - 1
+	return items, outlineID(append(path, len(parent.Children)-1)), true
+}
+
+// outdentBookmark moves an item out of its parent to just after it.
+func outdentBookmark(items []pdf.OutlineItem, id widget.TreeNodeID) ([]pdf.OutlineItem, widget.TreeNodeID, bool) {
+	items = cloneOutline(items)
+	path, ok := outlinePath(id)
+	if !ok || len(path) < 2 {
+		return nil, "", false
+	}
+	list, ok := outlineSiblings(&items, path)
+	if !ok {
+		return nil, "", false
+	}
+	i := path[len(path)-1]
+	item := (*list)[i]
+	*list = append((*list)[:i], (*list)[i+1:]...)
+
+	parentPath := path[:len(path)-1]
+	outer, _ := outlineSiblings(&items, parentPath)
+	j := parentPath[len(parentPath)-1] + 1
+	*outer = append((*outer)[:j], append([]pdf.OutlineItem{item}, (*outer)[j:]...)...)
+	parentPath[len(parentPath)-1] = j
+	return items, outlineID(parentPath), true
+}
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+// outlineShape renders the titles of an outline, with children in
+// parentheses, e.g. "A(A1 A2) B".
+func outlineShape(items []pdf.OutlineItem) string {
+	s := ""
+	for i, item := range items {
+		if i > 0 {
+			s += " "
+		}
+		s += item.Title
+		if len(item.Children) > 0 {
+			s += "(" + outlineShape(item.Children) + ")"
+		}
+	}
+	return s
+}
+
+func testOutline() []pdf.OutlineItem {
+	return []pdf.OutlineItem{
+		{Title: "A", Children: []pdf.OutlineItem{{Title: "A1"}, {Title: "A2"}}},
+		{Title: "B"},
+		{Title: "C"},
+	}
+}
+
+func TestBookmarkEdits(t *testing.T) {
+	type edit func([]pdf.OutlineItem) ([]pdf.OutlineItem, widget.TreeNodeID, bool)
+	tests := []struct {
+		name   string
+		edit   edit
+		want   string
+		wantID widget.TreeNodeID
+	}{
+		{"insert at end", func(items []pdf.OutlineItem) ([]pdf.OutlineItem, widget.TreeNodeID, bool) {
+			return insertBookmark(items, "", pdf.OutlineItem{Title: "N"})
+		}, "A(A1 A2) B C N", "3"},
+		{"insert after child", func(items []pdf.OutlineItem) ([]pdf.OutlineItem, widget.TreeNodeID, bool) {
+			return insertBookmark(items, "0.0", pdf.OutlineItem{Title: "N"})
+		}, "A(A1 N A2) B C", "0.1"},
+		{"move down", func(items []pdf.OutlineItem) ([]pdf.OutlineItem, widget.TreeNodeID, bool) {
+			return moveBookmark(items, "0", 1)
+		}, "B A(A1 A2) C", "1"},
+		{"move up", func(items []pdf.OutlineItem) ([]pdf.OutlineItem, widget.TreeNodeID, bool) {
+			return moveBookmark(items, "0.1", -1)
+		}, "A(A2 A1) B C", "0.0"},
+		{"indent", func(items []pdf.OutlineItem) ([]pdf.OutlineItem, widget.TreeNodeID, bool) {
+			return indentBookmark(items, "1")
+		}, "A(A1 A2 B) C", "0.2"},
+		{"indent nested", func(items []pdf.OutlineItem) ([]pdf.OutlineItem, widget.TreeNodeID, bool) {
+			return indentBookmark(items, "0.1")
+		}, "A(A1(A2)) B C", "0.0.0"},
+		{"outdent", func(items []pdf.OutlineItem) ([]pdf.OutlineItem, widget.TreeNodeID, bool) {
+			return outdentBookmark(items, "0.0")
+		}, "A(A2) A1 B C", "1"},
+	}
+	for _, tt := range tests {
+		orig := testOutline()
+		items, id, ok := tt.edit(orig)
+		if !ok {
+			t.Errorf("%s: edit did not apply", tt.name)
+			continue
+		}
+		if got := outlineShape(items); got != tt.want || id != tt.wantID {
+			t.Errorf("%s: got %q at %q, want %q at %q", tt.name, got, id, tt.want, tt.wantID)
+		}
+		if got := outlineShape(orig); got != "A(A1 A2) B C" {
+			t.Errorf("%s: edit changed the original outline to %q", tt.name, got)
+		}
+	}
+
+	items, ok := removeBookmark(testOutline(), "0")
+	if !ok || outlineShape(items) != "B C" {
+		t.Errorf("removeBookmark() = %q, %v", outlineShape(items), ok)
+	}
+	items, ok = renameBookmark(testOutline(), "0.1", "Z")
+	if !ok || outlineShape(items) != "A(A1 Z) B C" {
+		t.Errorf("renameBookmark() = %q, %v", outlineShape(items), ok)
+	}
+
+	indented, _, _ := indentBookmark(testOutline(), "2")
+	if !indented[1].Open {
+		t.Error("indentBookmark() did not open the new parent")
+	}
+
+	if _, _, ok := moveBookmark(testOutline(), "2", 1); ok {
+		t.Error("moveBookmark() moved the last item down")
+	}
+	if _, _, ok := indentBookmark(testOutline(), "0"); ok {
+		t.Error("indentBookmark() indented the first item")
+	}
+	if _, _, ok := outdentBookmark(testOutline(), "1"); ok {
+		t.Error("outdentBookmark() outdented a top-level item")
+	}
+	if _, ok := removeBookmark(testOutline(), "5"); ok {
+		t.Error("removeBookmark() removed a missing item")
+	}
+}
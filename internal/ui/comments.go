@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+// newCommentsTab builds the content of the Comments tab: the annotations of
+// the document, with actions to edit, move and delete them. Selecting one
+// shows it in the viewer.
+func (s *Sidebar) newCommentsTab() fyne.CanvasObject {
+	s.selectedComment = -1
+	s.commentList = widget.NewList(
+		func() int {
+			return len(s.comments)
+		},
+		func() fyne.CanvasObject {
+			swatch := canvas.NewRectangle(color.Transparent)
+			swatch.SetMinSize(fyne.NewSize(6, 0))
+			title := widget.NewLabel("Highlight · page 000")
+			title.Truncation = fyne.TextTruncateEllipsis
+			detail := widget.NewLabel("")
+			detail.Truncation = fyne.TextTruncateEllipsis
+			detail.SizeName = theme.SizeNameCaptionText
+			return container.NewBorder(nil, nil, swatch, nil, container.NewVBox(title, detail))
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < 0 || id >= len(s.comments) {
+				return
+			}
+			a := s.comments[id]
+			item := obj.(*fyne.Container)
+			text := item.Objects[0].(*fyne.Container)
+			swatch := item.Objects[1].(*canvas.Rectangle)
+			text.Objects[0].(*widget.Label).SetText(fmt.Sprintf("%s · page %d", a.Type, a.Page+1))
+			text.Objects[1].(*widget.Label).SetText(commentDetail(a))
+			swatch.FillColor = color.Color(color.Transparent)
+			if a.Color != nil {
+				swatch.FillColor = *a.Color
+			}
+			swatch.Refresh()
+		},
+	)
+	s.commentList.OnSelected = func(id widget.ListItemID) {
+		s.selectedComment = id
+		if a, ok := s.selectedCommentItem(); ok && s.viewer != nil {
+			s.viewer.GoToRect(a.Page, a.Rect)
+		}
+	}
+	s.commentList.OnUnselected = func(widget.ListItemID) {
+		s.selectedComment = -1
+	}
+	s.noComments = widget.NewLabel("This document has no comments")
+	s.noComments.Wrapping = fyne.TextWrapWord
+
+	tools := widget.NewToolbar(
+		widget.NewToolbarAction(theme.DocumentCreateIcon(), s.editComment),
+		widget.NewToolbarAction(theme.ViewFullScreenIcon(), s.moveComment),
+		widget.NewToolbarAction(theme.DeleteIcon(), s.removeComment),
+	)
+	return container.NewBorder(tools, nil, nil, nil,
+		container.NewStack(s.commentList, container.NewVBox(s.noComments)))
+}
+
+// loadComments lists the annotations of the current document, leaving out
+// links and form fields.
+func (s *Sidebar) loadComments() {
+	s.comments = nil
+	if s.document != nil {
+		// Unreadable annotations just leave the tab empty.
+		all, _ := s.document.Annotations()
+		for _, a := range all {
+			if isComment(a) {
+				s.comments = append(s.comments, a)
+			}
+		}
+	}
+	s.selectedComment = -1
+	s.commentList.UnselectAll()
+	s.commentList.Refresh()
+	if len(s.comments) == 0 {
+		s.commentList.Hide()
+		s.noComments.Show()
+	} else {
+		s.noComments.Hide()
+		s.commentList.Show()
+	}
+}
+
+// selectedCommentItem returns the annotation the actions apply to.
+func (s *Sidebar) selectedCommentItem() (pdf.Annotation, bool) {
+	if s.selectedComment < 0 || s.selectedComment >= len(s.comments) {
+		return pdf.Annotation{}, false
+	}
+	return s.comments[s.selectedComment], true
+}
+
+func (s *Sidebar) editComment() {
+	if a, ok := s.selectedCommentItem(); ok && s.OnEditAnnotation != nil {
+		s.OnEditAnnotation(a)
+	}
+}
+
+func (s *Sidebar) moveComment() {
+	if a, ok := s.selectedCommentItem(); ok && s.OnMoveAnnotation != nil {
+		s.OnMoveAnnotation(a)
+	}
+}
+
+func (s *Sidebar) removeComment() {
+	if a, ok := s.selectedCommentItem(); ok && s.OnRemoveAnnotation != nil {
+		s.OnRemoveAnnotation(a)
+	}
+}
+
+// isComment reports whether an annotation belongs in the Comments tab.
+func isComment(a pdf.Annotation) bool {
+	switch a.Type {
+	case "Link", "Widget":
+		return false
+	}
+	return true
+}
+
+// commentDetail describes the author, date and text of an annotation for
+// its list entry.
+func commentDetail(a pdf.Annotation) string {
+	var parts []string
+	if a.Author != "" {
+		parts = append(parts, a.Author)
+	}
+	if !a.Modified.IsZero() {
+		parts = append(parts, a.Modified.Local().Format("2006-01-02"))
+	}
+	if text := strings.Join(strings.Fields(a.Contents), " "); text != "" {
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, " · ")
+}
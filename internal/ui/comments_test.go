@@ -0,0 +1,33 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+func TestCommentDetail(t *testing.T) {
+	modified := time.Date(2024, 5, 6, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		a    pdf.Annotation
+		want string
+	}{
+		{pdf.Annotation{}, ""},
+		{pdf.Annotation{Author: "Ann", Modified: modified, Contents: "check\n  this"}, "Ann · 2024-05-06 · check this"},
+		{pdf.Annotation{Contents: "Note"}, "Note"},
+	}
+	for _, tt := range tests {
+		if got := commentDetail(tt.a); got != tt.want {
+			t.Errorf("commentDetail(%+v) = %q, want %q", tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestIsComment(t *testing.T) {
+	for typ, want := range map[string]bool{"Highlight": true, "Text": true, "Ink": true, "Link": false, "Widget": false} {
+		if got := isComment(pdf.Annotation{Type: typ}); got != want {
+			t.Errorf("isComment(%s) = %v, want %v", typ, got, want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package dialogs
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+// folderSearchLimit caps the number of results listed.
+const folderSearchLimit = 500
+
+// FolderSearchDialog searches the full-text index of a folder of PDFs,
+// building or updating the index first.
+type FolderSearchDialog struct {
+	window fyne.Window
+	folder string
+	onOpen func(path string, page int)
+
+	// indexed is the folder whose index is up to date in this session.
+	indexed string
+	failed  int // files of the indexed folder that could not be read
+	hits    []pdf.IndexHit
+}
+
+// NewFolderSearchDialog creates a folder search dialog. onOpen is called
+// with a result's file and 0-indexed page when the user picks it.
+func NewFolderSearchDialog(window fyne.Window, defaultFolder string, onOpen func(path string, page int)) *FolderSearchDialog {
+	return &FolderSearchDialog{
+		window: window,
+		folder: defaultFolder,
+		onOpen: onOpen,
+	}
+}
+
+// Show displays the folder search dialog.
+func (d *FolderSearchDialog) Show() {
+	folderEntry := widget.NewEntry()
+	folderEntry.SetText(d.folder)
+	folderEntry.SetPlaceHolder("Folder of PDF files")
+
+	chooseFolderBtn := widget.NewButton("Choose Folder...", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			folderEntry.SetText(uri.Path())
+		}, d.window)
+	})
+
+	queryEntry := widget.NewEntry()
+	queryEntry.SetPlaceHolder("Words to find")
+
+	status := widget.NewLabel("")
+	status.Wrapping = fyne.TextWrapWord
+
+	results := widget.NewList(
+		func() int { return len(d.hits) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(folderSearchResultText(d.folder, d.hits[id]))
+		},
+	)
+
+	var dlg dialog.Dialog
+	results.OnSelected = func(id widget.ListItemID) {
+		hit := d.hits[id]
+		results.UnselectAll()
+		dlg.Hide()
+		if d.onOpen != nil {
+			d.onOpen(hit.Path, hit.Page)
+		}
+	}
+
+	var searchBtn *widget.Button
+	search := func() {
+		if searchBtn.Disabled() {
+			return
+		}
+		d.folder = strings.TrimSpace(folderEntry.Text)
+		query := strings.TrimSpace(queryEntry.Text)
+		if d.folder == "" {
+			dialog.ShowError(errors.New("select a folder to search"), d.window)
+			return
+		}
+		if query == "" {
+			dialog.ShowError(errors.New("enter words to find"), d.window)
+			return
+		}
+
+		searchBtn.Disable()
+		d.hits = nil
+		results.Refresh()
+		go d.run(d.folder, query, func(text string) {
+			fyne.Do(func() { status.SetText(text) })
+		}, func(hits []pdf.IndexHit, err error) {
+			fyne.Do(func() {
+				searchBtn.Enable()
+				if err != nil {
+					status.SetText("Error: " + err.Error())
+					return
+				}
+				d.hits = hits
+				results.Refresh()
+				status.SetText(folderSearchSummary(len(hits), d.failed))
+			})
+		})
+	}
+	searchBtn = widget.NewButton("Search", search)
+	queryEntry.OnSubmitted = func(string) { search() }
+
+	top := container.NewVBox(
+		widget.NewLabel("Folder:"),
+		container.NewBorder(nil, nil, nil, chooseFolderBtn, folderEntry),
+		widget.NewLabel("Find:"),
+		container.NewBorder(nil, nil, nil, searchBtn, queryEntry),
+		status,
+	)
+	content := container.NewBorder(top, nil, nil, nil, results)
+
+	dlg = dialog.NewCustom("Search in Folder", "Close", content, d.window)
+	dlg.Resize(fyne.NewSize(720, 520))
+	dlg.Show()
+}
+
+// run brings the folder's index up to date, once per folder and dialog,
+// and searches it.
+func (d *FolderSearchDialog) run(folder, query string, progress func(string), done func([]pdf.IndexHit, error)) {
+	ix, err := pdf.OpenIndex(pdf.DefaultIndexDir(folder))
+	if err != nil {
+		done(nil, err)
+		return
+	}
+
+	if d.indexed != folder {
+		progress("Updating index...")
+		stats, err := ix.Update(folder, func(path string) {
+			progress("Indexing " + path)
+		})
+		if err != nil {
+			done(nil, err)
+			return
+		}
+		if err := ix.Save(); err != nil {
+			done(nil, err)
+			return
+		}
+		d.indexed = folder
+		d.failed = len(stats.Failed)
+	}
+
+	hits, err := ix.Search(query, folderSearchLimit)
+	done(hits, err)
+}
+
+// folderSearchResultText formats a result with its path relative to the
+// searched folder.
+func folderSearchResultText(folder string, hit pdf.IndexHit) string {
+	path := hit.Path
+	if rel, err := filepath.Rel(folder, hit.Path); err == nil && !strings.HasPrefix(rel, "..") {
+		path = rel
+	}
+	return fmt.Sprintf("%s, page %d: %s", path, hit.Page+1, hit.Snippet)
+}
+
+func folderSearchSummary(count, failed int) string {
+	var summary string
+	switch {
+	case count == 0:
+		summary = "No matches"
+	case count >= folderSearchLimit:
+		summary = fmt.Sprintf("Showing the first %d matching pages", count)
+	case count == 1:
+		summary = "1 matching page"
+	default:
+		summary = fmt.Sprintf("%d matching pages", count)
+	}
+	if failed > 0 {
+		summary += fmt.Sprintf(" (%d file(s) could not be indexed)", failed)
+	}
+	return summary
+}
@@ -0,0 +1,104 @@
+package dialogs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+// OCRRunFunc recognizes the document in the given languages, reporting the
+// pages done. It is called on a background goroutine.
+type OCRRunFunc func(lang string, progress func(done, total int)) (pdf.OCRResult, error)
+
+// OCRDialog asks for the OCR languages and shows progress while the pages
+// of a document are recognized.
+type OCRDialog struct {
+	window fyne.Window
+	lang   string
+	run    OCRRunFunc
+	onDone func(pdf.OCRResult, error)
+}
+
+// NewOCRDialog creates an OCR dialog. onDone is called on the UI thread
+// when recognition finishes.
+func NewOCRDialog(window fyne.Window, defaultLang string, run OCRRunFunc, onDone func(pdf.OCRResult, error)) *OCRDialog {
+	return &OCRDialog{
+		window: window,
+		lang:   defaultLang,
+		run:    run,
+		onDone: onDone,
+	}
+}
+
+// Show displays the OCR dialog.
+func (d *OCRDialog) Show() {
+	langEntry := widget.NewEntry()
+	langEntry.SetText(d.lang)
+	langEntry.SetPlaceHolder("eng, deu+eng, ...")
+
+	content := container.NewVBox(
+		widget.NewLabel("Recognize text on scanned pages and add a searchable text layer.\nPages that already contain text are left unchanged."),
+		widget.NewLabel("Tesseract languages:"),
+		langEntry,
+	)
+
+	dlg := dialog.NewCustomConfirm("OCR Scanned Pages", "Recognize", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		lang := strings.TrimSpace(langEntry.Text)
+		if lang == "" {
+			dialog.ShowError(errors.New("enter at least one language"), d.window)
+			return
+		}
+		d.lang = lang
+		d.start()
+	}, d.window)
+	dlg.Resize(fyne.NewSize(460, 220))
+	dlg.Show()
+}
+
+// start runs recognition in the background behind a progress dialog.
+func (d *OCRDialog) start() {
+	status := widget.NewLabel("Rendering pages...")
+	bar := widget.NewProgressBar()
+	progress := dialog.NewCustomWithoutButtons("OCR Scanned Pages", container.NewVBox(status, bar), d.window)
+	progress.Resize(fyne.NewSize(420, 140))
+	progress.Show()
+
+	go func() {
+		result, err := d.run(d.lang, func(done, total int) {
+			fyne.Do(func() {
+				if total > 0 {
+					bar.SetValue(float64(done) / float64(total))
+				}
+				status.SetText(fmt.Sprintf("Processed page %d of %d", done, total))
+			})
+		})
+		fyne.Do(func() {
+			progress.Hide()
+			if d.onDone != nil {
+				d.onDone(result, err)
+			}
+		})
+	}()
+}
+
+// OCRSummary describes the outcome of OCR for the status bar and dialogs.
+func OCRSummary(result pdf.OCRResult) string {
+	summary := fmt.Sprintf("Recognized %d word(s) on %d page(s)", result.Words, result.Recognized)
+	if result.Skipped > 0 {
+		summary += fmt.Sprintf(", %d page(s) already had text", result.Skipped)
+	}
+	if len(result.Failed) > 0 {
+		summary += fmt.Sprintf(", %d page(s) could not be rendered", len(result.Failed))
+	}
+	return summary
+}
@@ -0,0 +1,119 @@
+package dialogs
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+// PropertiesDialog shows the metadata and file properties of a document
+// and lets the user edit its descriptive fields.
+type PropertiesDialog struct {
+	window fyne.Window
+	path   string
+	info   pdf.Info
+	onSave func(pdf.Info)
+}
+
+// NewPropertiesDialog creates a properties dialog for the document at path.
+// onSave is called with the edited info when the user applies changes.
+func NewPropertiesDialog(window fyne.Window, path string, info pdf.Info, onSave func(pdf.Info)) *PropertiesDialog {
+	return &PropertiesDialog{
+		window: window,
+		path:   path,
+		info:   info,
+		onSave: onSave,
+	}
+}
+
+// Show displays the properties dialog.
+func (d *PropertiesDialog) Show() {
+	entry := func(value string) *widget.Entry {
+		e := widget.NewEntry()
+		e.SetText(value)
+		return e
+	}
+	titleEntry := entry(d.info.Title)
+	authorEntry := entry(d.info.Author)
+	subjectEntry := entry(d.info.Subject)
+	keywordsEntry := entry(d.info.Keywords)
+	creatorEntry := entry(d.info.Creator)
+
+	label := func(value string) *widget.Label {
+		l := widget.NewLabel(value)
+		l.Truncation = fyne.TextTruncateEllipsis
+		return l
+	}
+	items := []*widget.FormItem{
+		widget.NewFormItem("File", label(filepath.Base(d.path))),
+		widget.NewFormItem("Title", titleEntry),
+		widget.NewFormItem("Author", authorEntry),
+		widget.NewFormItem("Subject", subjectEntry),
+		widget.NewFormItem("Keywords", keywordsEntry),
+		widget.NewFormItem("Creator", creatorEntry),
+		widget.NewFormItem("Producer", label(d.info.Producer)),
+		widget.NewFormItem("Created", label(formatInfoDate(d.info.Created))),
+		widget.NewFormItem("Modified", label(formatInfoDate(d.info.Modified))),
+		widget.NewFormItem("PDF version", label(d.info.Version)),
+		widget.NewFormItem("Pages", label(strconv.Itoa(d.info.PageCount))),
+		widget.NewFormItem("File size", label(formatFileSize(d.info.FileSize))),
+		widget.NewFormItem("Encrypted", label(yesNo(d.info.Encrypted))),
+		widget.NewFormItem("Tagged", label(yesNo(d.info.Tagged))),
+		widget.NewFormItem("Linearized", label(yesNo(d.info.Linearized))),
+	}
+
+	form := dialog.NewForm("Document Properties", "Apply", "Close", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		info := d.info
+		info.Title = titleEntry.Text
+		info.Author = authorEntry.Text
+		info.Subject = subjectEntry.Text
+		info.Keywords = keywordsEntry.Text
+		info.Creator = creatorEntry.Text
+		if info == d.info || d.onSave == nil {
+			return
+		}
+		d.onSave(info)
+	}, d.window)
+	form.Resize(fyne.NewSize(520, 620))
+	form.Show()
+}
+
+// formatFileSize formats a byte count for display, e.g. "1.5 MB (1572864 bytes)".
+func formatFileSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d bytes", size)
+	}
+	value, suffix := float64(size)/unit, "KB"
+	for _, next := range []string{"MB", "GB", "TB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s (%d bytes)", value, suffix, size)
+}
+
+func formatInfoDate(t time.Time) string {
+	if t.IsZero() {
+		return "Unknown"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
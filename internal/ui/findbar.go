@@ -0,0 +1,241 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+// FindBar searches the active document and steps through the matches.
+type FindBar struct {
+	container *fyne.Container
+	window    *MainWindow
+	entry     *widget.Entry
+	matchCase *widget.Check
+	wholeWord *widget.Check
+	regex     *widget.Check
+	status    *widget.Label
+
+	// The results of the last search and what they were computed for.
+	document  *pdf.Document
+	stamp     uint64
+	query     string
+	opts      pdf.SearchOptions
+	hits      []pdf.SearchHit
+	current   int
+	searching bool
+	cancel    context.CancelFunc
+}
+
+// NewFindBar creates a hidden find bar.
+func NewFindBar(window *MainWindow) *FindBar {
+	f := &FindBar{
+		window:  window,
+		status:  widget.NewLabel(""),
+		current: -1,
+	}
+
+	f.entry = widget.NewEntry()
+	f.entry.SetPlaceHolder("Find in document")
+	f.entry.OnSubmitted = func(string) {
+		f.Next()
+	}
+
+	f.matchCase = widget.NewCheck("Match case", nil)
+	f.wholeWord = widget.NewCheck("Whole word", nil)
+	f.regex = widget.NewCheck("Regex", nil)
+
+	prevBtn := widget.NewButton("▲", f.Previous)
+	nextBtn := widget.NewButton("▼", f.Next)
+	closeBtn := widget.NewButton("✕", f.Hide)
+
+	f.container = container.NewBorder(
+		nil,
+		nil,
+		widget.NewLabel("Find:"),
+		container.NewHBox(
+			prevBtn,
+			nextBtn,
+			f.matchCase,
+			f.wholeWord,
+			f.regex,
+			f.status,
+			closeBtn,
+		),
+		f.entry,
+	)
+	f.container.Hide()
+
+	return f
+}
+
+// Container returns the find bar's container.
+func (f *FindBar) Container() *fyne.Container {
+	return f.container
+}
+
+// Visible reports whether the find bar is shown.
+func (f *FindBar) Visible() bool {
+	return f.container.Visible()
+}
+
+// Show displays the find bar and focuses the query field.
+func (f *FindBar) Show() {
+	f.container.Show()
+	f.window.window.Canvas().Focus(f.entry)
+}
+
+// Hide closes the find bar and clears the highlights.
+func (f *FindBar) Hide() {
+	f.reset()
+	f.status.SetText("")
+	f.container.Hide()
+	if f.window.viewer != nil {
+		f.window.viewer.ShowSearchHits(nil, -1)
+	}
+}
+
+// Next moves to the following match, searching first if the query,
+// options or document changed.
+func (f *FindBar) Next() {
+	f.step(1)
+}
+
+// Previous moves to the preceding match, searching first if the query,
+// options or document changed.
+func (f *FindBar) Previous() {
+	f.step(-1)
+}
+
+func (f *FindBar) options() pdf.SearchOptions {
+	return pdf.SearchOptions{
+		CaseSensitive: f.matchCase.Checked,
+		WholeWord:     f.wholeWord.Checked,
+		Regexp:        f.regex.Checked,
+	}
+}
+
+func (f *FindBar) step(delta int) {
+	doc := f.window.document
+	if doc == nil || f.entry.Text == "" {
+		return
+	}
+
+	query, opts := f.entry.Text, f.options()
+	if doc != f.document || doc.Stamp() != f.stamp || query != f.query || opts != f.opts {
+		f.search(doc, query, opts, delta)
+		return
+	}
+	if f.searching {
+		return
+	}
+	if len(f.hits) == 0 {
+		f.status.SetText("No matches")
+		return
+	}
+
+	f.current = (f.current + delta + len(f.hits)) % len(f.hits)
+	f.showCurrent()
+}
+
+// search runs a new search in the background and shows the first match in
+// direction delta from the current page.
+func (f *FindBar) search(doc *pdf.Document, query string, opts pdf.SearchOptions, delta int) {
+	f.reset()
+	f.document, f.stamp, f.query, f.opts = doc, doc.Stamp(), query, opts
+	f.searching = true
+	f.status.SetText("Searching...")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+
+	go func() {
+		var found []pdf.SearchHit
+		pageCount := doc.PageCount()
+		for page := 0; page < pageCount; page++ {
+			if ctx.Err() != nil {
+				return
+			}
+			hits, err := doc.SearchPage(page, query, opts)
+			if err != nil {
+				fyne.Do(func() {
+					if ctx.Err() != nil {
+						return
+					}
+					f.searching = false
+					f.status.SetText("Error: " + err.Error())
+				})
+				return
+			}
+			found = append(found, hits...)
+
+			if page%10 == 9 {
+				progress := fmt.Sprintf("Searching page %d of %d...", page+1, pageCount)
+				fyne.Do(func() {
+					if ctx.Err() == nil {
+						f.status.SetText(progress)
+					}
+				})
+			}
+		}
+
+		fyne.Do(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			f.searching = false
+			f.hits = found
+			if len(found) == 0 {
+				f.status.SetText("No matches")
+				f.window.viewer.ShowSearchHits(nil, -1)
+				return
+			}
+			f.current = firstHitFrom(found, f.window.viewer.CurrentPage(), delta)
+			f.showCurrent()
+		})
+	}()
+}
+
+func (f *FindBar) showCurrent() {
+	f.status.SetText(fmt.Sprintf("%d of %d", f.current+1, len(f.hits)))
+	if f.window.viewer != nil && f.window.document == f.document {
+		f.window.viewer.ShowSearchHits(f.hits, f.current)
+	}
+}
+
+// reset cancels a running search and forgets the results.
+func (f *FindBar) reset() {
+	if f.cancel != nil {
+		f.cancel()
+		f.cancel = nil
+	}
+	f.document = nil
+	f.hits = nil
+	f.current = -1
+	f.searching = false
+}
+
+// firstHitFrom returns the index of the first hit on or after page when
+// delta is positive, or the last hit on or before page otherwise, wrapping
+// around the document.
+func firstHitFrom(hits []pdf.SearchHit, page, delta int) int {
+	if delta >= 0 {
+		for i, hit := range hits {
+			if hit.Page >= page {
+				return i
+			}
+		}
+		return 0
+	}
+	for i := len(hits) - 1; i >= 0; i-- {
+		if hits[i].Page <= page {
+			return i
+		}
+	}
+	return len(hits) - 1
+}
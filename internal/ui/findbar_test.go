@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+func TestFirstHitFrom(t *testing.T) {
+	hits := []pdf.SearchHit{{Page: 1}, {Page: 1}, {Page: 4}, {Page: 7}}
+
+	tests := []struct {
+		page, delta, want int
+	}{
+		{0, 1, 0},
+		{1, 1, 0},
+		{2, 1, 2},
+		{8, 1, 0}, // wraps to the start
+		{5, -1, 2},
+		{1, -1, 1},
+		{0, -1, 3}, // wraps to the end
+	}
+	for _, tt := range tests {
+		if got := firstHitFrom(hits, tt.page, tt.delta); got != tt.want {
+			t.Errorf("firstHitFrom(page %d, delta %d) = %d, want %d", tt.page, tt.delta, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+var (
+	highlightColor        = color.NRGBA{R: 255, G: 220, B: 0, A: 90}
+	currentHighlightColor = color.NRGBA{R: 255, G: 120, B: 0, A: 140}
+)
+
+// highlightRect is a box drawn over a page, in display points at 100% zoom
+// with the origin at the page's top-left corner.
+type highlightRect struct {
+	rect    pdf.Rect
+	current bool
+}
+
+// highlightLayout positions highlight boxes over a page, scaling display
+// points to the size the page is drawn at.
+type highlightLayout struct {
+	pageWidth float32
+	rects     []pdf.Rect // parallel to the container's objects
+}
+
+func (l *highlightLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	return fyne.NewSize(0, 0)
+}
+
+func (l *highlightLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	if l.pageWidth <= 0 {
+		return
+	}
+	scale := size.Width / l.pageWidth
+	for i, o := range objects {
+		r := l.rects[i]
+		o.Move(fyne.NewPos(float32(r.LLX)*scale, float32(r.LLY)*scale))
+		o.Resize(fyne.NewSize(float32(r.Width())*scale, float32(r.Height())*scale))
+	}
+}
+
+// newHighlightLayer creates an empty layer to stack over a page image.
+func newHighlightLayer() *fyne.Container {
+	return container.New(&highlightLayout{})
+}
+
+// setHighlights replaces the boxes drawn by a highlight layer.
+func setHighlights(layer *fyne.Container, pageWidth float32, highlights []highlightRect) {
+	rects := make([]pdf.Rect, len(highlights))
+	for i, h := range highlights {
+		rects[i] = h.rect
+	}
+	setBoxes(layer, pageWidth, rects, func(i int) color.Color {
+		if highlights[i].current {
+			return currentHighlightColor
+		}
+		return highlightColor
+	})
+}
+
+// setBoxes replaces the boxes drawn by a layer, filling box i with fill(i).
+func setBoxes(layer *fyne.Container, pageWidth float32, rects []pdf.Rect, fill func(i int) color.Color) {
+	l := layer.Layout.(*highlightLayout)
+	l.pageWidth = pageWidth
+	l.rects = append(l.rects[:0], rects...)
+
+	objects := make([]fyne.CanvasObject, len(rects))
+	for i := range rects {
+		objects[i] = canvas.NewRectangle(fill(i))
+	}
+	layer.Objects = objects
+	layer.Refresh()
+}
+
+// ShowSearchHits highlights hits over the pages and, if current is a valid
+// index, moves to that hit and scrolls it into view. Passing no hits
+// clears the highlights.
+func (v *Viewer) ShowSearchHits(hits []pdf.SearchHit, current int) {
+	v.highlights = make(map[int][]highlightRect)
+	if v.document != nil {
+		geometries := make(map[int]pdf.PageGeometry)
+		for i, hit := range hits {
+			geom, ok := geometries[hit.Page]
+			if !ok {
+				var err error
+				if geom, err = v.document.PageGeometry(hit.Page); err != nil {
+					continue
+				}
+				geometries[hit.Page] = geom
+			}
+			for _, r := range hit.Rects {
+				v.highlights[hit.Page] = append(v.highlights[hit.Page], highlightRect{
+					rect:    geom.UserToDisplay(r),
+					current: i == current,
+				})
+			}
+		}
+	}
+
+	if current >= 0 && current < len(hits) {
+		hit := hits[current]
+		v.GoToPage(hit.Page)
+		v.refreshHighlights()
+		if len(hit.Rects) > 0 {
+			if geom, ok := v.pageGeometry(hit.Page); ok {
+				v.revealRect(hit.Page, geom.UserToDisplay(hit.Rects[0]))
+			}
+		}
+		return
+	}
+	v.refreshHighlights()
+}
+
+// refreshHighlights redraws the highlights of every page on screen.
+func (v *Viewer) refreshHighlights() {
+	if v.mode.multiPage() {
+		if v.strip.pages == nil {
+			return
+		}
+		for page, slot := range v.strip.slots {
+			setHighlights(slot.layer, v.strip.pages.sizes[page].Width, v.highlights[page])
+		}
+		return
+	}
+	setHighlights(v.highlightLayer, v.pageWidth, v.highlights[v.currentPage])
+}
+
+func (v *Viewer) pageGeometry(page int) (pdf.PageGeometry, bool) {
+	if v.document == nil {
+		return pdf.PageGeometry{}, false
+	}
+	geom, err := v.document.PageGeometry(page)
+	return geom, err == nil
+}
+
+// revealRect scrolls so that a box on page (in display points) is in view,
+// a third of the way down the viewport.
+func (v *Viewer) revealRect(page int, r pdf.Rect) {
+	zoom := float32(v.zoom)
+	left, top := float32(r.LLX), float32(r.LLY)
+	if v.mode.multiPage() {
+		if v.strip.pages == nil {
+			return
+		}
+		pos, _ := v.strip.pages.pageRect(page, v.stripHolder.Size().Width/zoom)
+		left += pos.X
+		top += pos.Y
+	}
+
+	viewport := v.scroll.Size()
+	offset := fyne.NewPos(
+		max(0, left*zoom-viewport.Width/2),
+		max(0, top*zoom-viewport.Height/3),
+	)
+	v.scroll.Refresh()
+	v.scrollTo(offset)
+	v.updateVisiblePages()
+}
@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+// maxNavHistory bounds the positions kept for Back.
+const maxNavHistory = 100
+
+// pageLink is a link annotation with its area in display points.
+type pageLink struct {
+	rect pdf.Rect
+	link pdf.Link
+}
+
+// navPosition is a place in the document: a page and how far below its top
+// edge the viewport starts, in points at 100% zoom.
+type navPosition struct {
+	page int
+	top  float32
+}
+
+// navHistory holds the positions left by jumps, for Back and Forward.
+type navHistory struct {
+	back, forward []navPosition
+}
+
+// push records the position a jump leaves, dropping the forward history.
+func (h *navHistory) push(from navPosition) {
+	h.back = append(h.back, from)
+	if len(h.back) > maxNavHistory {
+		h.back = h.back[len(h.back)-maxNavHistory:]
+	}
+	h.forward = nil
+}
+
+// goBack returns the previous position, remembering current for Forward.
+func (h *navHistory) goBack(current navPosition) (navPosition, bool) {
+	if len(h.back) == 0 {
+		return navPosition{}, false
+	}
+	to := h.back[len(h.back)-1]
+	h.back = h.back[:len(h.back)-1]
+	h.forward = append(h.forward, current)
+	return to, true
+}
+
+// goForward returns the position Back left, remembering current for Back.
+func (h *navHistory) goForward(current navPosition) (navPosition, bool) {
+	if len(h.forward) == 0 {
+		return navPosition{}, false
+	}
+	to := h.forward[len(h.forward)-1]
+	h.forward = h.forward[:len(h.forward)-1]
+	h.back = append(h.back, current)
+	return to, true
+}
+
+// pageLinks returns the links of page, reading them on first use.
+func (v *Viewer) pageLinks(page int) []pageLink {
+	if links, ok := v.links[page]; ok {
+		return links
+	}
+	if v.document == nil {
+		return nil
+	}
+
+	var links []pageLink
+	geom, ok := v.pageGeometry(page)
+	if ok {
+		// A page whose annotations cannot be read just has no links.
+		all, _ := v.document.Links(page)
+		for _, link := range all {
+			links = append(links, pageLink{rect: geom.UserToDisplay(link.Rect), link: link})
+		}
+	}
+	if v.links == nil {
+		v.links = make(map[int][]pageLink)
+	}
+	v.links[page] = links
+	return links
+}
+
+// linkAt returns the link under a point on page in display points. Later
+// annotations are drawn on top, so they win.
+func (v *Viewer) linkAt(page int, x, y float64) (pdf.Link, bool) {
+	links := v.pageLinks(page)
+	for i := len(links) - 1; i >= 0; i-- {
+		r := links[i].rect
+		if x >= r.LLX && x <= r.URX && y >= r.LLY && y <= r.URY {
+			return links[i].link, true
+		}
+	}
+	return pdf.Link{}, false
+}
+
+// followLink jumps to the target of a link in this document. Links to other
+// files and URIs are handed to OnExternalLink.
+func (v *Viewer) followLink(link pdf.Link) {
+	switch link.Kind {
+	case pdf.LinkGoTo:
+		v.GoToDestination(link.Dest)
+	case pdf.LinkNamed:
+		v.runNamedAction(link.Name)
+	default:
+		if v.OnExternalLink != nil {
+			v.OnExternalLink(link)
+		}
+	}
+}
+
+// runNamedAction performs the standard named actions of link annotations.
+func (v *Viewer) runNamedAction(name string) {
+	if v.document == nil {
+		return
+	}
+	switch name {
+	case "NextPage":
+		v.NextPage()
+	case "PrevPage":
+		v.PreviousPage()
+	case "FirstPage":
+		v.GoToDestination(pdf.Destination{Page: 0})
+	case "LastPage":
+		v.GoToDestination(pdf.Destination{Page: v.document.PageCount() - 1})
+	case "GoBack":
+		v.Back()
+	case "GoForward":
+		v.Forward()
+	}
+}
+
+// GoToDestination jumps to a destination, scrolled to its position when it
+// has one, and records the position left for Back.
+func (v *Viewer) GoToDestination(dest pdf.Destination) {
+	if v.document == nil || dest.Page < 0 || dest.Page >= v.document.PageCount() {
+		return
+	}
+
+	page, top := v.CurrentPosition()
+	v.history.push(navPosition{page: page, top: top})
+
+	if geom, ok := v.pageGeometry(dest.Page); ok {
+		if top, ok := geom.DisplayTop(dest); ok {
+			v.GoToPosition(dest.Page, float32(top))
+			return
+		}
+	}
+	v.GoToPosition(dest.Page, 0)
+}
+
+// GoToRect shows a box on page, given in PDF user space, a little below
+// the top of the view, recording the jump like GoToDestination.
+func (v *Viewer) GoToRect(page int, rect pdf.Rect) {
+	if v.document == nil || page < 0 || page >= v.document.PageCount() {
+		return
+	}
+
+	current, top := v.CurrentPosition()
+	v.history.push(navPosition{page: current, top: top})
+
+	var y float64
+	if geom, ok := v.pageGeometry(page); ok {
+		y = max(0, geom.UserToDisplay(rect).LLY-rectMargin)
+	}
+	v.GoToPosition(page, float32(y))
+}
+
+// rectMargin is the space, in points, left above a box shown by GoToRect.
+const rectMargin = 36
+
+// Back returns to the position before the last jump.
+func (v *Viewer) Back() {
+	page, top := v.CurrentPosition()
+	if to, ok := v.history.goBack(navPosition{page: page, top: top}); ok {
+		v.GoToPosition(to.page, to.top)
+	}
+}
+
+// Forward repeats the jump undone by Back.
+func (v *Viewer) Forward() {
+	page, top := v.CurrentPosition()
+	if to, ok := v.history.goForward(navPosition{page: page, top: top}); ok {
+		v.GoToPosition(to.page, to.top)
+	}
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+func TestNavHistory(t *testing.T) {
+	var h navHistory
+	if _, ok := h.goBack(navPosition{page: 0}); ok {
+		t.Fatal("goBack() on an empty history succeeded")
+	}
+
+	h.push(navPosition{page: 0, top: 10})
+	h.push(navPosition{page: 4})
+	// Now at page 7.
+	if to, ok := h.goBack(navPosition{page: 7}); !ok || to != (navPosition{page: 4}) {
+		t.Fatalf("goBack() = %+v, %v; want page 4", to, ok)
+	}
+	if to, ok := h.goBack(navPosition{page: 4}); !ok || to != (navPosition{page: 0, top: 10}) {
+		t.Fatalf("goBack() = %+v, %v; want page 0", to, ok)
+	}
+	if to, ok := h.goForward(navPosition{page: 0, top: 10}); !ok || to != (navPosition{page: 4}) {
+		t.Fatalf("goForward() = %+v, %v; want page 4", to, ok)
+	}
+
+	// A new jump drops the forward history.
+	h.push(navPosition{page: 4})
+	if _, ok := h.goForward(navPosition{page: 9}); ok {
+		t.Error("goForward() succeeded after a new jump")
+	}
+
+	for i := range maxNavHistory + 10 {
+		h.push(navPosition{page: i})
+	}
+	if len(h.back) != maxNavHistory || h.back[0].page != 10 {
+		t.Errorf("history kept %d positions from page %d, want %d from page 10", len(h.back), h.back[0].page, maxNavHistory)
+	}
+}
+
+func TestViewerLinkAt(t *testing.T) {
+	uri := pdf.Link{Kind: pdf.LinkURI, URI: "https://example.com"}
+	named := pdf.Link{Kind: pdf.LinkNamed, Name: "NextPage"}
+	v := &Viewer{links: map[int][]pageLink{
+		0: {
+			{rect: pdf.Rect{LLX: 10, LLY: 10, URX: 100, URY: 30}, link: uri},
+			{rect: pdf.Rect{LLX: 50, LLY: 20, URX: 150, URY: 40}, link: named},
+		},
+	}}
+
+	tests := []struct {
+		x, y float64
+		want pdf.Link
+		ok   bool
+	}{
+		{20, 20, uri, true},
+		{60, 25, named, true}, // overlap: the later link is on top
+		{120, 35, named, true},
+		{5, 5, pdf.Link{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := v.linkAt(0, tt.x, tt.y)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("linkAt(%v, %v) = %+v, %v; want %+v, %v", tt.x, tt.y, got, ok, tt.want, tt.ok)
+		}
+	}
+	if _, ok := v.linkAt(1, 20, 20); ok {
+		t.Error("linkAt() found a link on a page without links")
+	}
+}
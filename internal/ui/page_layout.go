@@ -0,0 +1,199 @@
+package ui
+
+import (
+	"context"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+)
+
+// pageGap is the space between pages in continuous mode, in points at 100%.
+const pageGap = 12
+
+// pageLayout arranges the pages of a document in rows stacked vertically:
+// one page per row, or two facing pages per row for spreads. All positions
+// are in points at 100% zoom; callers scale them by the zoom factor.
+type pageLayout struct {
+	sizes     []fyne.Size
+	positions []fyne.Position // top-left corner of each page
+	rows      []pageRow
+	rowOf     []int   // row index of each page
+	width     float32 // width of the widest row
+	height    float32 // total height including gaps
+}
+
+// pageRow is one horizontal band of the layout.
+type pageRow struct {
+	first, last int // inclusive page range
+	y, height   float32
+}
+
+// newPageLayout lays out pages in columns (1 or 2) per row. With two
+// columns, facing pages meet at a common spine and coverAlone places the
+// first page on its own on the right, as in a printed book.
+func newPageLayout(sizes []fyne.Size, columns int, coverAlone bool, gap float32) *pageLayout {
+	if columns != 2 {
+		columns, coverAlone = 1, false
+	}
+
+	l := &pageLayout{
+		sizes:     sizes,
+		positions: make([]fyne.Position, len(sizes)),
+		rowOf:     make([]int, len(sizes)),
+	}
+
+	column := func(page int) int {
+		if coverAlone {
+			page++
+		}
+		return page % columns
+	}
+
+	columnWidths := make([]float32, columns)
+	for i, size := range sizes {
+		c := column(i)
+		if size.Width > columnWidths[c] {
+			columnWidths[c] = size.Width
+		}
+		if i == 0 || c == 0 {
+			l.rows = append(l.rows, pageRow{first: i})
+		}
+		row := &l.rows[len(l.rows)-1]
+		row.last = i
+		if size.Height > row.height {
+			row.height = size.Height
+		}
+		l.rowOf[i] = len(l.rows) - 1
+	}
+
+	for c, w := range columnWidths {
+		if c > 0 {
+			l.width += gap
+		}
+		l.width += w
+	}
+
+	var y float32
+	for r := range l.rows {
+		row := &l.rows[r]
+		if r > 0 {
+			y += gap
+		}
+		row.y = y
+		for page := row.first; page <= row.last; page++ {
+			size := sizes[page]
+			var x float32
+			switch {
+			case columns == 1:
+				x = (l.width - size.Width) / 2
+			case column(page) == 0:
+				x = columnWidths[0] - size.Width
+			default:
+				x = columnWidths[0] + gap
+			}
+			l.positions[page] = fyne.NewPos(x, y+(row.height-size.Height)/2)
+		}
+		y += row.height
+	}
+	l.height = y
+	return l
+}
+
+// pageCount returns the number of pages in the layout.
+func (l *pageLayout) pageCount() int {
+	return len(l.sizes)
+}
+
+// row returns the row containing page.
+func (l *pageLayout) row(page int) pageRow {
+	return l.rows[l.rowOf[page]]
+}
+
+// pageRect returns the position and size of a page with the layout centred
+// horizontally in a strip of the given width.
+func (l *pageLayout) pageRect(page int, stripWidth float32) (fyne.Position, fyne.Size) {
+	shift := (stripWidth - l.width) / 2
+	if shift < 0 {
+		shift = 0
+	}
+	pos := l.positions[page]
+	return fyne.NewPos(pos.X+shift, pos.Y), l.sizes[page]
+}
+
+// rowAt returns the index of the row at vertical position y. Positions
+// inside a gap belong to the row above it.
+func (l *pageLayout) rowAt(y float32) int {
+	if len(l.rows) == 0 {
+		return -1
+	}
+	i := sort.Search(len(l.rows), func(i int) bool {
+		return l.rows[i].y > y
+	})
+	if i == 0 {
+		return 0
+	}
+	return i - 1
+}
+
+// pageAt returns the first page of the row at vertical position y, or -1
+// for an empty layout.
+func (l *pageLayout) pageAt(y float32) int {
+	r := l.rowAt(y)
+	if r < 0 {
+		return -1
+	}
+	return l.rows[r].first
+}
+
+// visibleRange returns the inclusive range of pages in the rows overlapping
+// top..bottom, or -1, -1 for an empty layout.
+func (l *pageLayout) visibleRange(top, bottom float32) (int, int) {
+	if len(l.rows) == 0 {
+		return -1, -1
+	}
+	if bottom < top {
+		top, bottom = bottom, top
+	}
+	return l.rows[l.rowAt(top)].first, l.rows[l.rowAt(bottom)].last
+}
+
+// pageSlot is a materialized page in continuous mode.
+type pageSlot struct {
+	view      *fyne.Container // image with the highlight, selection, tool and ink layers on top
+	image     *canvas.Image
+	layer     *fyne.Container
+	selection *fyne.Container
+	tool      *fyne.Container
+	ink       *fyne.Container
+	cancel    context.CancelFunc // stops a pending render of the page
+	scale     float64            // zoom factor the page is rendered at
+}
+
+// stripLayout positions the materialized pages of a continuous view. Its
+// minimum size is that of the whole document so the scroll bars reflect
+// every page, even though only a few are backed by canvas objects.
+type stripLayout struct {
+	pages *pageLayout
+	zoom  float32
+	slots map[int]*pageSlot
+}
+
+func (s *stripLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	if s.pages == nil {
+		return fyne.NewSize(0, 0)
+	}
+	return fyne.NewSize(s.pages.width*s.zoom, s.pages.height*s.zoom)
+}
+
+func (s *stripLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	if s.pages == nil {
+		return
+	}
+	stripWidth := size.Width / s.zoom
+	for page, slot := range s.slots {
+		pos, pageSize := s.pages.pageRect(page, stripWidth)
+		slot.view.Move(fyne.NewPos(pos.X*s.zoom, pos.Y*s.zoom))
+		slot.view.Resize(fyne.NewSize(pageSize.Width*s.zoom, pageSize.Height*s.zoom))
+	}
+}
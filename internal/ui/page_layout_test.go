@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"fyne.io/fyne/v2"
+)
+
+func testPageLayout() *pageLayout {
+	return newPageLayout([]fyne.Size{
+		fyne.NewSize(600, 800),
+		fyne.NewSize(800, 600),
+		fyne.NewSize(600, 800),
+	}, 1, false, 10)
+}
+
+func TestPageLayoutOffsets(t *testing.T) {
+	l := testPageLayout()
+
+	want := []float32{0, 810, 1420}
+	for i, w := range want {
+		if got := l.row(i).y; got != w {
+			t.Errorf("row(%d).y = %v, want %v", i, got, w)
+		}
+	}
+	if l.width != 800 || l.height != 2220 {
+		t.Errorf("size = %vx%v, want 800x2220", l.width, l.height)
+	}
+}
+
+func TestPageLayoutPageAt(t *testing.T) {
+	l := testPageLayout()
+
+	tests := []struct {
+		y    float32
+		want int
+	}{
+		{-50, 0},
+		{0, 0},
+		{799, 0},
+		{805, 0}, // gap belongs to the page above
+		{810, 1},
+		{1500, 2},
+		{5000, 2},
+	}
+	for _, tt := range tests {
+		if got := l.pageAt(tt.y); got != tt.want {
+			t.Errorf("pageAt(%v) = %d, want %d", tt.y, got, tt.want)
+		}
+	}
+
+	if got := newPageLayout(nil, 1, false, 10).pageAt(0); got != -1 {
+		t.Errorf("pageAt on empty layout = %d, want -1", got)
+	}
+}
+
+func TestPageLayoutVisibleRange(t *testing.T) {
+	l := testPageLayout()
+
+	tests := []struct {
+		top, bottom         float32
+		wantFirst, wantLast int
+	}{
+		{0, 500, 0, 0},
+		{700, 900, 0, 1},
+		{900, 700, 0, 1},
+		{-800, 3000, 0, 2},
+		{1500, 1600, 2, 2},
+	}
+	for _, tt := range tests {
+		first, last := l.visibleRange(tt.top, tt.bottom)
+		if first != tt.wantFirst || last != tt.wantLast {
+			t.Errorf("visibleRange(%v, %v) = %d, %d, want %d, %d", tt.top, tt.bottom, first, last, tt.wantFirst, tt.wantLast)
+		}
+	}
+}
+
+func TestPageLayoutPageRectCentres(t *testing.T) {
+	l := testPageLayout()
+
+	pos, size := l.pageRect(0, 800)
+	if pos != fyne.NewPos(100, 0) || size != fyne.NewSize(600, 800) {
+		t.Errorf("pageRect(0) = %v %v, want (100,0) 600x800", pos, size)
+	}
+	pos, _ = l.pageRect(1, 500)
+	if pos != fyne.NewPos(0, 810) {
+		t.Errorf("pageRect(1) in narrow strip = %v, want (0,810)", pos)
+	}
+}
+
+func spreadSizes(n int) []fyne.Size {
+	sizes := make([]fyne.Size, n)
+	for i := range sizes {
+		sizes[i] = fyne.NewSize(600, 800)
+	}
+	return sizes
+}
+
+func rowRanges(l *pageLayout) [][2]int {
+	var out [][2]int
+	for _, row := range l.rows {
+		out = append(out, [2]int{row.first, row.last})
+	}
+	return out
+}
+
+func TestPageLayoutSpreadRows(t *testing.T) {
+	tests := []struct {
+		name       string
+		pages      int
+		coverAlone bool
+		want       [][2]int
+	}{
+		{"pairs", 5, false, [][2]int{{0, 1}, {2, 3}, {4, 4}}},
+		{"book", 5, true, [][2]int{{0, 0}, {1, 2}, {3, 4}}},
+		{"book single page", 1, true, [][2]int{{0, 0}}},
+	}
+	for _, tt := range tests {
+		l := newPageLayout(spreadSizes(tt.pages), 2, tt.coverAlone, 10)
+		if got := rowRanges(l); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: rows = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPageLayoutSpreadPositions(t *testing.T) {
+	l := newPageLayout(spreadSizes(4), 2, true, 10)
+
+	if l.width != 1210 || l.height != 2420 {
+		t.Fatalf("size = %vx%v, want 1210x2420", l.width, l.height)
+	}
+
+	// The cover sits on the right of the spine, like a book's front page.
+	if pos, _ := l.pageRect(0, 1210); pos != fyne.NewPos(610, 0) {
+		t.Errorf("cover at %v, want (610,0)", pos)
+	}
+	if pos, _ := l.pageRect(1, 1210); pos != fyne.NewPos(0, 810) {
+		t.Errorf("page 2 at %v, want (0,810)", pos)
+	}
+	if pos, _ := l.pageRect(2, 1210); pos != fyne.NewPos(610, 810) {
+		t.Errorf("page 3 at %v, want (610,810)", pos)
+	}
+
+	first, last := l.visibleRange(900, 1000)
+	if first != 1 || last != 2 {
+		t.Errorf("visibleRange in second spread = %d, %d, want 1, 2", first, last)
+	}
+	if got := l.pageAt(900); got != 1 {
+		t.Errorf("pageAt in second spread = %d, want 1", got)
+	}
+}
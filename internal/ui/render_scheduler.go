@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"container/heap"
+	"context"
+	"image"
+	"sync"
+)
+
+// RenderPriority orders queued render jobs; lower values run first.
+type RenderPriority int
+
+const (
+	// PriorityVisible is used for the page currently shown in a viewer.
+	PriorityVisible RenderPriority = iota
+	// PriorityPrefetch is used for pages likely to be shown next.
+	PriorityPrefetch
+	// PriorityThumbnail is used for sidebar thumbnails.
+	PriorityThumbnail
+)
+
+// defaultRenderWorkers bounds how many pages render at the same time.
+const defaultRenderWorkers = 2
+
+// RenderFunc produces an image for a scheduled job.
+type RenderFunc func(ctx context.Context) (image.Image, error)
+
+// RenderDoneFunc receives the result of a job. It runs on a worker goroutine,
+// so UI updates must be wrapped in fyne.Do.
+type RenderDoneFunc func(img image.Image, err error)
+
+// RenderScheduler runs page renders on a bounded pool of background workers.
+// Jobs are started in priority order and skipped once their context is
+// cancelled, e.g. because the user navigated away from the page.
+type RenderScheduler struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   renderQueue
+	seq     uint64
+	closed  bool
+	workers sync.WaitGroup
+}
+
+type renderJob struct {
+	ctx      context.Context
+	priority RenderPriority
+	seq      uint64
+	render   RenderFunc
+	done     RenderDoneFunc
+}
+
+// NewRenderScheduler starts a scheduler with the given number of workers.
+func NewRenderScheduler(workers int) *RenderScheduler {
+	if workers < 1 {
+		workers = 1
+	}
+
+	s := &RenderScheduler{}
+	s.cond = sync.NewCond(&s.mu)
+	for i := 0; i < workers; i++ {
+		s.workers.Add(1)
+		go s.work()
+	}
+	return s
+}
+
+// Submit queues a render job. done is not called if ctx is cancelled before
+// the job finishes.
+func (s *RenderScheduler) Submit(ctx context.Context, priority RenderPriority, render RenderFunc, done RenderDoneFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.seq++
+	heap.Push(&s.queue, &renderJob{
+		ctx:      ctx,
+		priority: priority,
+		seq:      s.seq,
+		render:   render,
+		done:     done,
+	})
+	s.cond.Signal()
+}
+
+// Pending returns the number of queued jobs that have not started yet.
+func (s *RenderScheduler) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queue.Len()
+}
+
+// Close stops the workers after the jobs that are already running finish.
+// Queued jobs are discarded.
+func (s *RenderScheduler) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.queue = nil
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	s.workers.Wait()
+}
+
+func (s *RenderScheduler) work() {
+	defer s.workers.Done()
+
+	for {
+		s.mu.Lock()
+		for s.queue.Len() == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.mu.Unlock()
+			return
+		}
+		job := heap.Pop(&s.queue).(*renderJob)
+		s.mu.Unlock()
+
+		if job.ctx.Err() != nil {
+			continue
+		}
+		img, err := job.render(job.ctx)
+		if job.ctx.Err() != nil {
+			continue
+		}
+		if job.done != nil {
+			job.done(img, err)
+		}
+	}
+}
+
+// renderQueue is a min-heap of jobs ordered by priority, then submission order.
+type renderQueue []*renderJob
+
+func (q renderQueue) Len() int { return len(q) }
+
+func (q renderQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority < q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q renderQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *renderQueue) Push(x any) { *q = append(*q, x.(*renderJob)) }
+
+func (q *renderQueue) Pop() any {
+	old := *q
+	n := len(old)
+	job := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return job
+}
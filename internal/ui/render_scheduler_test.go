@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"context"
+	"image"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRenderSchedulerPriorityOrder(t *testing.T) {
+	s := NewRenderScheduler(1)
+	defer s.Close()
+
+	// Block the only worker so the remaining jobs queue up.
+	release := make(chan struct{})
+	started := make(chan struct{})
+	s.Submit(context.Background(), PriorityVisible, func(context.Context) (image.Image, error) {
+		close(started)
+		<-release
+		return nil, nil
+	}, nil)
+	<-started
+
+	var (
+		mu    sync.Mutex
+		order []string
+		wg    sync.WaitGroup
+	)
+	submit := func(name string, priority RenderPriority) {
+		wg.Add(1)
+		s.Submit(context.Background(), priority, func(context.Context) (image.Image, error) {
+			return nil, nil
+		}, func(image.Image, error) {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			wg.Done()
+		})
+	}
+	submit("thumb-1", PriorityThumbnail)
+	submit("prefetch", PriorityPrefetch)
+	submit("thumb-2", PriorityThumbnail)
+	submit("visible", PriorityVisible)
+
+	if got := s.Pending(); got != 4 {
+		t.Fatalf("Pending() = %d, want 4", got)
+	}
+	close(release)
+	wg.Wait()
+
+	want := []string{"visible", "prefetch", "thumb-1", "thumb-2"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("execution order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestRenderSchedulerSkipsCancelledJobs(t *testing.T) {
+	s := NewRenderScheduler(1)
+	defer s.Close()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	s.Submit(context.Background(), PriorityVisible, func(context.Context) (image.Image, error) {
+		close(started)
+		<-release
+		return nil, nil
+	}, nil)
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := make(chan struct{}, 1)
+	s.Submit(ctx, PriorityVisible, func(context.Context) (image.Image, error) {
+		ran <- struct{}{}
+		return nil, nil
+	}, func(image.Image, error) {
+		t.Error("done called for cancelled job")
+	})
+	cancel()
+
+	finished := make(chan struct{})
+	s.Submit(context.Background(), PriorityThumbnail, func(context.Context) (image.Image, error) {
+		return nil, nil
+	}, func(image.Image, error) { close(finished) })
+	close(release)
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduler did not process remaining jobs")
+	}
+	select {
+	case <-ran:
+		t.Error("cancelled job was rendered")
+	default:
+	}
+}
+
+func TestRenderSchedulerCloseDiscardsQueue(t *testing.T) {
+	s := NewRenderScheduler(1)
+	s.Close()
+
+	s.Submit(context.Background(), PriorityVisible, func(context.Context) (image.Image, error) {
+		t.Error("job ran after Close")
+		return nil, nil
+	}, nil)
+	if got := s.Pending(); got != 0 {
+		t.Errorf("Pending() after Close = %d, want 0", got)
+	}
+}
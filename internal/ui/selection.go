@@ -0,0 +1,524 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+var selectionColor = color.NRGBA{R: 40, G: 120, B: 255, A: 80}
+
+// multiClickInterval is the longest pause between the clicks of a double
+// or triple click.
+const multiClickInterval = 500 * time.Millisecond
+
+// selectableText is the text of one page flattened into characters in
+// reading order, with boxes in display points, for mapping pointer
+// positions to text. A caret is an index between characters, 0..len(chars).
+type selectableText struct {
+	chars []selectableChar
+	words []charRange
+	lines []charRange
+	geom  pdf.PageGeometry
+}
+
+type selectableChar struct {
+	text              string
+	rect              pdf.Rect // display points, LLX/LLY is the top-left corner
+	word, line, block int
+}
+
+// charRange is the characters from..to-1 and their bounding box.
+type charRange struct {
+	from, to int
+	rect     pdf.Rect
+}
+
+// newSelectableText indexes the text of a page for selection.
+func newSelectableText(text *pdf.PageText, geom pdf.PageGeometry) *selectableText {
+	s := &selectableText{geom: geom}
+	for b, block := range text.Blocks {
+		for _, line := range block.Lines {
+			lineRange := charRange{from: len(s.chars)}
+			for _, word := range line.Words {
+				wordRange := charRange{from: len(s.chars)}
+				chars := word.Chars
+				if len(chars) == 0 {
+					chars = []pdf.TextChar{{Text: word.Text, Rect: word.Rect}}
+				}
+				for _, c := range chars {
+					rect := geom.UserToDisplay(c.Rect)
+					s.chars = append(s.chars, selectableChar{
+						text:  c.Text,
+						rect:  rect,
+						word:  len(s.words),
+						line:  len(s.lines),
+						block: b,
+					})
+					wordRange.rect = wordRange.rect.Union(rect)
+				}
+				wordRange.to = len(s.chars)
+				s.words = append(s.words, wordRange)
+				lineRange.rect = lineRange.rect.Union(wordRange.rect)
+			}
+			lineRange.to = len(s.chars)
+			if lineRange.to > lineRange.from {
+				s.lines = append(s.lines, lineRange)
+			}
+		}
+	}
+	return s
+}
+
+// caretAt returns the caret nearest to a point in display points: before
+// the first character of the closest line whose centre lies right of x.
+func (s *selectableText) caretAt(x, y float64) int {
+	line, ok := s.lineNear(x, y)
+	if !ok {
+		return 0
+	}
+	for i := line.from; i < line.to; i++ {
+		r := s.chars[i].rect
+		if x < (r.LLX+r.URX)/2 {
+			return i
+		}
+	}
+	return line.to
+}
+
+// charAt returns the character under a point in display points, or the
+// nearest one on the closest line.
+func (s *selectableText) charAt(x, y float64) int {
+	line, ok := s.lineNear(x, y)
+	if !ok {
+		return 0
+	}
+	for i := line.from; i < line.to; i++ {
+		if x < s.chars[i].rect.URX {
+			return i
+		}
+	}
+	return line.to - 1
+}
+
+// lineNear returns the line closest to a point, preferring lines that
+// span it vertically.
+func (s *selectableText) lineNear(x, y float64) (charRange, bool) {
+	if len(s.lines) == 0 {
+		return charRange{}, false
+	}
+	best, bestDY, bestDX := 0, math.Inf(1), math.Inf(1)
+	for i, l := range s.lines {
+		dy := outside(y, l.rect.LLY, l.rect.URY)
+		dx := outside(x, l.rect.LLX, l.rect.URX)
+		if dy < bestDY || (dy == bestDY && dx < bestDX) {
+			best, bestDY, bestDX = i, dy, dx
+		}
+	}
+	return s.lines[best], true
+}
+
+// outside returns how far v lies outside lo..hi.
+func outside(v, lo, hi float64) float64 {
+	switch {
+	case v < lo:
+		return lo - v
+	case v > hi:
+		return v - hi
+	default:
+		return 0
+	}
+}
+
+// wordAt returns the characters of the word containing character i.
+func (s *selectableText) wordAt(i int) (int, int) {
+	if i < 0 || i >= len(s.chars) {
+		return 0, 0
+	}
+	w := s.words[s.chars[i].word]
+	return w.from, w.to
+}
+
+// lineAt returns the characters of the line containing character i.
+func (s *selectableText) lineAt(i int) (int, int) {
+	if i < 0 || i >= len(s.chars) {
+		return 0, 0
+	}
+	l := s.lines[s.chars[i].line]
+	return l.from, l.to
+}
+
+// text returns the characters from..to-1 with words separated by spaces,
+// lines by newlines and blocks by blank lines.
+func (s *selectableText) text(from, to int) string {
+	var sb strings.Builder
+	for i := from; i < to; i++ {
+		c := s.chars[i]
+		if i > from {
+			prev := s.chars[i-1]
+			switch {
+			case c.block != prev.block:
+				sb.WriteString("\n\n")
+			case c.line != prev.line:
+				sb.WriteByte('\n')
+			case c.word != prev.word:
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(c.text)
+	}
+	return sb.String()
+}
+
+// rects returns one box per line covering the characters from..to-1.
+func (s *selectableText) rects(from, to int) []pdf.Rect {
+	var out []pdf.Rect
+	for i := from; i < to; i++ {
+		c := s.chars[i]
+		if i == from || c.line != s.chars[i-1].line {
+			out = append(out, c.rect)
+			continue
+		}
+		out[len(out)-1] = out[len(out)-1].Union(c.rect)
+	}
+	return out
+}
+
+// userRects returns the boxes of rects(from, to) in PDF user space.
+func (s *selectableText) userRects(from, to int) []pdf.Rect {
+	rects := s.rects(from, to)
+	for i, r := range rects {
+		rects[i] = s.geom.DisplayToUser(r)
+	}
+	return rects
+}
+
+// contains reports whether a point in display points is over one of the
+// boxes of rects(from, to).
+func (s *selectableText) contains(from, to int, x, y float64) bool {
+	for _, r := range s.rects(from, to) {
+		if x >= r.LLX && x <= r.URX && y >= r.LLY && y <= r.URY {
+			return true
+		}
+	}
+	return false
+}
+
+// textSelection is a run of selected characters on one page.
+type textSelection struct {
+	page     int
+	text     *selectableText
+	anchor   int // caret where the gesture started
+	from, to int
+}
+
+// pageInput is a transparent overlay that turns mouse gestures over a page
+// into text selection: drag to select, double-click for a word and
+// triple-click for a line. Right-clicking the selection opens its menu.
+type pageInput struct {
+	widget.BaseWidget
+	viewer *Viewer
+	page   int // -1 for the viewer's current page
+
+	clicks    int
+	lastClick time.Time
+	lastPos   fyne.Position
+
+	// overLink is set while the pointer is over a link; pressed is the
+	// link the primary button went down on, followed when it comes up.
+	overLink bool
+	pressed  *pdf.Link
+}
+
+func newPageInput(viewer *Viewer, page int) *pageInput {
+	p := &pageInput{viewer: viewer, page: page}
+	p.ExtendBaseWidget(p)
+	return p
+}
+
+func (p *pageInput) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(canvas.NewRectangle(color.Transparent))
+}
+
+// Cursor shows the crosshair while a drawing tool is active, the hand over
+// links and the text cursor elsewhere on pages.
+func (p *pageInput) Cursor() desktop.Cursor {
+	if p.viewer.tool != ToolSelect {
+		return desktop.CrosshairCursor
+	}
+	if p.overLink {
+		return desktop.PointerCursor
+	}
+	return desktop.TextCursor
+}
+
+// MouseIn is required by desktop.Hoverable.
+func (p *pageInput) MouseIn(ev *desktop.MouseEvent) {
+	p.MouseMoved(ev)
+}
+
+// MouseMoved tracks whether the pointer is over a link.
+func (p *pageInput) MouseMoved(ev *desktop.MouseEvent) {
+	_, p.overLink = p.viewer.linkAt(p.pagePoint(ev.Position))
+}
+
+// MouseOut is required by desktop.Hoverable.
+func (p *pageInput) MouseOut() {
+	p.overLink = false
+}
+
+// MouseDown starts a selection, counting repeated clicks at one spot. A
+// press on a link arms it instead; with a drawing tool it starts a drawing.
+func (p *pageInput) MouseDown(ev *desktop.MouseEvent) {
+	p.pressed = nil
+	if ev.Button == desktop.MouseButtonSecondary && p.viewer.tool == ToolSelect {
+		page, x, y := p.pagePoint(ev.Position)
+		p.viewer.showSelectionMenu(p, page, x, y, ev.AbsolutePosition)
+		return
+	}
+	if ev.Button != desktop.MouseButtonPrimary {
+		return
+	}
+	if p.viewer.tool != ToolSelect {
+		p.viewer.startToolDrag(p.pagePoint(ev.Position))
+		return
+	}
+	if link, ok := p.viewer.linkAt(p.pagePoint(ev.Position)); ok {
+		p.pressed = &link
+		return
+	}
+
+	now := time.Now()
+	d := ev.Position.Subtract(p.lastPos)
+	if now.Sub(p.lastClick) < multiClickInterval && d.X*d.X+d.Y*d.Y < 16 {
+		p.clicks = p.clicks%3 + 1
+	} else {
+		p.clicks = 1
+	}
+	p.lastClick, p.lastPos = now, ev.Position
+
+	page, x, y := p.pagePoint(ev.Position)
+	p.viewer.pressText(page, x, y, p.clicks)
+}
+
+// MouseUp follows the link pressed, unless the pointer left it, or
+// finishes a drawing.
+func (p *pageInput) MouseUp(ev *desktop.MouseEvent) {
+	if p.viewer.tool != ToolSelect {
+		p.viewer.finishToolDrag()
+		return
+	}
+	pressed := p.pressed
+	p.pressed = nil
+	if pressed == nil {
+		return
+	}
+	if link, ok := p.viewer.linkAt(p.pagePoint(ev.Position)); ok && link.Rect == pressed.Rect {
+		p.viewer.followLink(*pressed)
+	}
+}
+
+// Dragged extends the selection or the drawing to the pointer.
+func (p *pageInput) Dragged(ev *fyne.DragEvent) {
+	if p.viewer.tool != ToolSelect {
+		p.viewer.dragTool(p.pagePoint(ev.Position))
+		return
+	}
+	if p.pressed != nil {
+		return
+	}
+	page, x, y := p.pagePoint(ev.Position)
+	p.viewer.dragText(page, x, y)
+}
+
+// DragEnd finishes a drawing; fyne may deliver it instead of MouseUp.
+func (p *pageInput) DragEnd() {
+	if p.viewer.tool != ToolSelect {
+		p.viewer.finishToolDrag()
+	}
+}
+
+// pagePoint maps a position on the overlay to display points on its page,
+// undoing the zoom.
+func (p *pageInput) pagePoint(pos fyne.Position) (int, float64, float64) {
+	page := p.page
+	if page < 0 {
+		page = p.viewer.currentPage
+	}
+	width := p.Size().Width
+	if width <= 0 {
+		return page, 0, 0
+	}
+	scale := float64(p.viewer.pageDisplayWidth(page) / width)
+	return page, float64(pos.X) * scale, float64(pos.Y) * scale
+}
+
+// pageDisplayWidth returns the width of a page in points at 100% zoom.
+func (v *Viewer) pageDisplayWidth(page int) float32 {
+	if v.mode.multiPage() && v.strip.pages != nil && page < v.strip.pages.pageCount() {
+		return v.strip.pages.sizes[page].Width
+	}
+	return v.pageWidth
+}
+
+// pageDisplayHeight returns the height of a page in points at 100% zoom.
+func (v *Viewer) pageDisplayHeight(page int) float32 {
+	if v.mode.multiPage() && v.strip.pages != nil && page < v.strip.pages.pageCount() {
+		return v.strip.pages.sizes[page].Height
+	}
+	return v.pageHeight
+}
+
+// pressText starts a selection on page at a point in display points,
+// selecting the word or line around it for double and triple clicks.
+func (v *Viewer) pressText(page int, x, y float64, clicks int) {
+	text := v.selectableText(page)
+	if text == nil {
+		v.ClearSelection()
+		return
+	}
+
+	caret := text.caretAt(x, y)
+	sel := &textSelection{page: page, text: text, anchor: caret, from: caret, to: caret}
+	switch clicks {
+	case 2:
+		sel.from, sel.to = text.wordAt(text.charAt(x, y))
+		sel.anchor = sel.from
+	case 3:
+		sel.from, sel.to = text.lineAt(text.charAt(x, y))
+		sel.anchor = sel.from
+	}
+	v.setSelection(sel)
+}
+
+// dragText extends the selection from its anchor to a point on page.
+func (v *Viewer) dragText(page int, x, y float64) {
+	sel := v.selection
+	if sel == nil || sel.page != page {
+		return
+	}
+	caret := sel.text.caretAt(x, y)
+	sel.from, sel.to = min(sel.anchor, caret), max(sel.anchor, caret)
+	v.setSelection(sel)
+}
+
+// selectableText returns the positioned text of page, or nil if it has
+// none or cannot be read.
+func (v *Viewer) selectableText(page int) *selectableText {
+	if v.document == nil {
+		return nil
+	}
+	geom, ok := v.pageGeometry(page)
+	if !ok {
+		return nil
+	}
+	text, err := v.document.PageText(page)
+	if err != nil {
+		return nil
+	}
+	s := newSelectableText(text, geom)
+	if len(s.chars) == 0 {
+		return nil
+	}
+	return s
+}
+
+// SelectAll selects all text on the current page and returns it.
+func (v *Viewer) SelectAll() string {
+	text := v.selectableText(v.currentPage)
+	if text == nil {
+		v.ClearSelection()
+		return ""
+	}
+	v.setSelection(&textSelection{page: v.currentPage, text: text, to: len(text.chars)})
+	return v.SelectedText()
+}
+
+// ClearSelection removes the text selection.
+func (v *Viewer) ClearSelection() {
+	if v.selection == nil {
+		return
+	}
+	v.setSelection(nil)
+}
+
+// SelectedText returns the selected text, or "" if nothing is selected.
+func (v *Viewer) SelectedText() string {
+	if v.selection == nil {
+		return ""
+	}
+	return v.selection.text.text(v.selection.from, v.selection.to)
+}
+
+// SelectionLines returns one box per selected line in PDF user space, for
+// marking up the selected text.
+func (v *Viewer) SelectionLines() []pdf.Rect {
+	if v.selection == nil {
+		return nil
+	}
+	return v.selection.text.userRects(v.selection.from, v.selection.to)
+}
+
+// showSelectionMenu pops up SelectionMenu at the pointer if a point on page,
+// in display points, is over the selected text.
+func (v *Viewer) showSelectionMenu(obj fyne.CanvasObject, page int, x, y float64, at fyne.Position) {
+	sel := v.selection
+	if v.SelectionMenu == nil || sel == nil || sel.page != page || !sel.text.contains(sel.from, sel.to, x, y) {
+		return
+	}
+	c := fyne.CurrentApp().Driver().CanvasForObject(obj)
+	if c == nil {
+		return
+	}
+	widget.ShowPopUpMenuAtPosition(v.SelectionMenu(), c, at)
+}
+
+// SelectedPage returns the page holding the selection, or -1.
+func (v *Viewer) SelectedPage() int {
+	if v.selection == nil {
+		return -1
+	}
+	return v.selection.page
+}
+
+func (v *Viewer) setSelection(sel *textSelection) {
+	v.selection = sel
+	v.refreshSelection()
+	if v.OnSelectionChanged != nil {
+		v.OnSelectionChanged(v.SelectedPage(), v.SelectedText())
+	}
+}
+
+// refreshSelection redraws the selection boxes of every page on screen.
+func (v *Viewer) refreshSelection() {
+	if v.mode.multiPage() {
+		if v.strip.pages == nil {
+			return
+		}
+		for page, slot := range v.strip.slots {
+			setBoxes(slot.selection, v.strip.pages.sizes[page].Width, v.selectionRects(page), selectionFill)
+		}
+		return
+	}
+	setBoxes(v.selectionLayer, v.pageWidth, v.selectionRects(v.currentPage), selectionFill)
+}
+
+// selectionRects returns the selection boxes on page in display points.
+func (v *Viewer) selectionRects(page int) []pdf.Rect {
+	if v.selection == nil || v.selection.page != page {
+		return nil
+	}
+	return v.selection.text.rects(v.selection.from, v.selection.to)
+}
+
+func selectionFill(int) color.Color {
+	return selectionColor
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+// testSelectableText lays out "ab cd" / "ef" in one block and "gh" in
+// another on a Letter page, each character 10pt wide and 10pt tall.
+func testSelectableText() *selectableText {
+	word := func(text string, x, y float64) pdf.TextWord {
+		w := pdf.TextWord{Text: text}
+		for i, r := range text {
+			c := pdf.TextChar{Text: string(r), Rect: pdf.Rect{
+				LLX: x + float64(i)*10, LLY: y, URX: x + float64(i+1)*10, URY: y + 10,
+			}}
+			w.Chars = append(w.Chars, c)
+			w.Rect = w.Rect.Union(c.Rect)
+		}
+		return w
+	}
+	text := &pdf.PageText{Blocks: []pdf.TextBlock{
+		{Lines: []pdf.TextLine{
+			{Words: []pdf.TextWord{word("ab", 100, 700), word("cd", 130, 700)}},
+			{Words: []pdf.TextWord{word("ef", 100, 686)}},
+		}},
+		{Lines: []pdf.TextLine{
+			{Words: []pdf.TextWord{word("gh", 100, 500)}},
+		}},
+	}}
+	geom := pdf.PageGeometry{CropBox: pdf.Rect{URX: 612, URY: 792}, UserUnit: 1}
+	return newSelectableText(text, geom)
+}
+
+func TestSelectableTextCaretAt(t *testing.T) {
+	s := testSelectableText()
+	// The first line spans y 82..92 in display space, the second 96..106.
+	tests := []struct {
+		x, y float64
+		want int
+	}{
+		{104, 85, 0},  // left half of "a"
+		{106, 85, 1},  // right half of "a"
+		{500, 85, 4},  // right of the first line
+		{0, 0, 0},     // above all text
+		{115, 100, 6}, // right half of "f"
+		{105, 400, 7}, // below everything: the closest line is "gh"
+	}
+	for _, tt := range tests {
+		if got := s.caretAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("caretAt(%v, %v) = %d, want %d", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestSelectableTextWordAndLine(t *testing.T) {
+	s := testSelectableText()
+
+	i := s.charAt(136, 85) // "c"
+	if i != 2 {
+		t.Fatalf("charAt() = %d, want 2", i)
+	}
+	if from, to := s.wordAt(i); from != 2 || to != 4 {
+		t.Errorf("wordAt(%d) = %d..%d, want 2..4", i, from, to)
+	}
+	if from, to := s.lineAt(i); from != 0 || to != 4 {
+		t.Errorf("lineAt(%d) = %d..%d, want 0..4", i, from, to)
+	}
+	if from, to := s.wordAt(99); from != 0 || to != 0 {
+		t.Errorf("wordAt(99) = %d..%d, want empty", from, to)
+	}
+}
+
+func TestSelectableTextText(t *testing.T) {
+	s := testSelectableText()
+	tests := []struct {
+		from, to int
+		want     string
+	}{
+		{0, 8, "ab cd\nef\n\ngh"},
+		{1, 3, "b c"},
+		{3, 5, "d\ne"},
+		{2, 2, ""},
+	}
+	for _, tt := range tests {
+		if got := s.text(tt.from, tt.to); got != tt.want {
+			t.Errorf("text(%d, %d) = %q, want %q", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestSelectableTextRects(t *testing.T) {
+	s := testSelectableText()
+	got := s.rects(1, 5)
+	want := []pdf.Rect{
+		{LLX: 110, LLY: 82, URX: 150, URY: 92},
+		{LLX: 100, LLY: 96, URX: 110, URY: 106},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rects(1, 5) = %+v, want %+v", got, want)
+	}
+}
+
+func TestSelectableTextUserRects(t *testing.T) {
+	s := testSelectableText()
+	got := s.userRects(1, 5)
+	want := []pdf.Rect{
+		{LLX: 110, LLY: 700, URX: 150, URY: 710},
+		{LLX: 100, LLY: 686, URX: 110, URY: 696},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("userRects(1, 5) = %+v, want %+v", got, want)
+	}
+	if !s.contains(1, 5, 120, 85) || !s.contains(1, 5, 105, 100) {
+		t.Error("contains() missed a point over the selection")
+	}
+	if s.contains(1, 5, 105, 85) || s.contains(1, 5, 105, 300) {
+		t.Error("contains() found a point outside the selection")
+	}
+}
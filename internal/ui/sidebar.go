@@ -1,41 +1,103 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"image/color"
-	"sync"
+	"strconv"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/openpdfreader/openpdfreader/internal/pdf"
 )
 
-// Sidebar provides page thumbnails and bookmarks.
+// Sidebar provides page thumbnails, bookmarks, comments and attachments.
 type Sidebar struct {
 	container  *fyne.Container
+	tabs       *container.AppTabs
 	list       *widget.List
 	viewer     *Viewer
 	document   *pdf.Document
 	visible    bool
-	cacheMu    sync.RWMutex
-	cache      map[int]image.Image
 	emptyThumb image.Image
+
+	scheduler *RenderScheduler
+	// pending tracks thumbnail batches that are queued, by first page.
+	pending      map[int]bool
+	renderCtx    context.Context
+	renderCancel context.CancelFunc
+
+	outline     *outlineTree
+	bookmarks   *widget.Tree
+	noBookmarks *widget.Label
+	// selected is the bookmark the edit actions apply to; restoring is set
+	// while it is reselected after an edit, so the viewer does not jump.
+	selected  widget.TreeNodeID
+	restoring bool
+
+	// PromptBookmarkTitle asks for a bookmark title, calling done with the
+	// entered one; without it new bookmarks keep their default title.
+	PromptBookmarkTitle func(title string, done func(string))
+	// OnBookmarksChanged is called after the outline has been edited, with
+	// the error if it could not be changed.
+	OnBookmarksChanged func(err error)
+
+	comments        []pdf.Annotation
+	commentList     *widget.List
+	noComments      *widget.Label
+	selectedComment int
+
+	// OnEditAnnotation, OnMoveAnnotation and OnRemoveAnnotation change the
+	// selected annotation of the Comments tab: its text and colour, its
+	// position, or whether it exists.
+	OnEditAnnotation   func(a pdf.Annotation)
+	OnMoveAnnotation   func(a pdf.Annotation)
+	OnRemoveAnnotation func(a pdf.Annotation)
+
+	attachments        []pdf.Attachment
+	attachmentList     *widget.List
+	noAttachments      *widget.Label
+	selectedAttachment int
+
+	// ChooseAttachmentFile asks for a file to attach, calling done with its
+	// path.
+	ChooseAttachmentFile func(done func(path string))
+	// OnSaveAttachment and OnOpenAttachment save the selected attachment to
+	// a file and open it with the system's default application.
+	OnSaveAttachment func(a pdf.Attachment)
+	OnOpenAttachment func(a pdf.Attachment)
+	// OnAttachmentsChanged is called after an attachment has been added or
+	// removed, with the error if it could not be.
+	OnAttachmentsChanged func(err error)
 }
 
-const thumbnailScale = 0.35
+const (
+	thumbnailScale = 0.35
+	// thumbnailBatchSize is how many thumbnails are rendered per backend pass.
+	thumbnailBatchSize = 12
+)
 
 // NewSidebar creates a new sidebar.
-func NewSidebar(viewer *Viewer) *Sidebar {
+// Thumbnails are rendered in the background on scheduler; a private
+// scheduler is created when nil.
+func NewSidebar(viewer *Viewer, scheduler *RenderScheduler) *Sidebar {
+	if scheduler == nil {
+		scheduler = NewRenderScheduler(defaultRenderWorkers)
+	}
+
 	s := &Sidebar{
 		viewer:     viewer,
 		visible:    true,
-		cache:      make(map[int]image.Image),
 		emptyThumb: buildPlaceholderThumbnail(),
+		scheduler:  scheduler,
+		pending:    make(map[int]bool),
 	}
+	s.renderCtx, s.renderCancel = context.WithCancel(context.Background())
 
 	s.list = widget.NewList(
 		func() int {
@@ -62,26 +124,21 @@ func NewSidebar(viewer *Viewer) *Sidebar {
 			label.SetText(fmt.Sprintf("Page %d", id+1))
 
 			pageNum := int(id)
-			if cached := s.getCachedThumbnail(pageNum); cached != nil {
-				thumb.Image = cached
-				thumb.Refresh()
-				return
-			}
-
 			if s.document == nil {
 				thumb.Image = s.emptyThumb
 				thumb.Refresh()
 				return
 			}
 
-			rendered, err := s.document.RenderPage(pageNum, thumbnailScale)
-			if err != nil || rendered == nil {
-				rendered = s.emptyThumb
+			if cached, ok := s.document.CachedPage(pageNum, thumbnailScale); ok {
+				thumb.Image = cached
+				thumb.Refresh()
+				return
 			}
-			s.setCachedThumbnail(pageNum, rendered)
 
-			thumb.Image = rendered
+			thumb.Image = s.emptyThumb
 			thumb.Refresh()
+			s.requestThumbnailBatch(pageNum)
 		},
 	)
 
@@ -91,16 +148,54 @@ func NewSidebar(viewer *Viewer) *Sidebar {
 		}
 	}
 
-	header := widget.NewLabel("Pages")
-	header.TextStyle = fyne.TextStyle{Bold: true}
+	s.outline = newOutlineTree(nil)
+	s.bookmarks = widget.NewTree(
+		func(id widget.TreeNodeID) []widget.TreeNodeID {
+			return s.outline.children[id]
+		},
+		func(id widget.TreeNodeID) bool {
+			return len(s.outline.children[id]) > 0
+		},
+		func(bool) fyne.CanvasObject {
+			label := widget.NewLabel("Bookmark")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.TreeNodeID, _ bool, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(s.outline.title(id))
+		},
+	)
+	s.bookmarks.OnSelected = func(id widget.TreeNodeID) {
+		s.selected = id
+		if !s.restoring {
+			s.goToBookmark(id)
+		}
+	}
+	s.bookmarks.OnUnselected = func(widget.TreeNodeID) {
+		s.selected = ""
+	}
+	s.noBookmarks = widget.NewLabel("This document has no bookmarks")
+	s.noBookmarks.Wrapping = fyne.TextWrapWord
+
+	bookmarkTools := widget.NewToolbar(
+		widget.NewToolbarAction(theme.ContentAddIcon(), s.addBookmark),
+		widget.NewToolbarAction(theme.DocumentCreateIcon(), s.renameBookmark),
+		widget.NewToolbarAction(theme.DeleteIcon(), s.deleteBookmark),
+		widget.NewToolbarSeparator(),
+		widget.NewToolbarAction(theme.MoveUpIcon(), func() { s.moveBookmark(-1) }),
+		widget.NewToolbarAction(theme.MoveDownIcon(), func() { s.moveBookmark(1) }),
+		widget.NewToolbarAction(theme.NavigateBackIcon(), s.outdentBookmark),
+		widget.NewToolbarAction(theme.NavigateNextIcon(), s.indentBookmark),
+	)
 
-	s.container = container.NewBorder(
-		header,
-		nil,
-		nil,
-		nil,
-		s.list,
+	s.tabs = container.NewAppTabs(
+		container.NewTabItem("Pages", s.list),
+		container.NewTabItem("Bookmarks", container.NewBorder(bookmarkTools, nil, nil, nil,
+			container.NewStack(s.bookmarks, container.NewVBox(s.noBookmarks)))),
+		container.NewTabItem("Comments", s.newCommentsTab()),
+		container.NewTabItem("Attachments", s.newAttachmentsTab()),
 	)
+	s.container = container.NewStack(s.tabs)
 
 	return s
 }
@@ -113,13 +208,142 @@ func (s *Sidebar) Container() *fyne.Container {
 // SetDocument updates the sidebar with a new document.
 func (s *Sidebar) SetDocument(doc *pdf.Document) {
 	s.document = doc
-	s.cacheMu.Lock()
-	s.cache = make(map[int]image.Image)
-	s.cacheMu.Unlock()
+	s.renderCancel()
+	s.renderCtx, s.renderCancel = context.WithCancel(context.Background())
+	s.pending = make(map[int]bool)
 	s.list.Refresh()
 	if doc != nil && doc.PageCount() > 0 {
 		s.list.Select(0)
 	}
+	s.loadOutline()
+	s.loadComments()
+	s.loadAttachments()
+}
+
+// loadOutline shows the outline of the current document in the Bookmarks
+// tab, with the items the document marks as open expanded.
+func (s *Sidebar) loadOutline() {
+	var items []pdf.OutlineItem
+	if s.document != nil {
+		// A broken outline just leaves the tab empty.
+		items, _ = s.document.Outline()
+	}
+	s.outline = newOutlineTree(items)
+	s.selected = ""
+
+	s.bookmarks.UnselectAll()
+	s.bookmarks.CloseAllBranches()
+	s.bookmarks.Refresh()
+	for _, id := range s.outline.open {
+		s.bookmarks.OpenBranch(id)
+	}
+	if len(items) == 0 {
+		s.bookmarks.Hide()
+		s.noBookmarks.Show()
+	} else {
+		s.noBookmarks.Hide()
+		s.bookmarks.Show()
+	}
+}
+
+// goToBookmark shows the target of a bookmark, scrolled to its position
+// when it has one.
+func (s *Sidebar) goToBookmark(id widget.TreeNodeID) {
+	item, ok := s.outline.items[id]
+	if ok && s.viewer != nil {
+		s.viewer.GoToDestination(item.Dest)
+	}
+}
+
+// addBookmark adds a bookmark to the current viewer position after the
+// selected one, or at the end.
+func (s *Sidebar) addBookmark() {
+	if s.document == nil || s.viewer == nil {
+		return
+	}
+	page, top := s.viewer.CurrentPosition()
+	dest := pdf.Destination{Page: page, Fit: "Fit"}
+	if geom, err := s.document.PageGeometry(page); err == nil {
+		dest = geom.DestinationAt(page, float64(top))
+	}
+
+	after := s.selected
+	s.promptTitle(fmt.Sprintf("Page %d", page+1), func(title string) {
+		item := pdf.OutlineItem{Title: title, Dest: dest}
+		if items, id, ok := insertBookmark(s.outline.root, after, item); ok {
+			s.applyOutline(items, id)
+		}
+	})
+}
+
+func (s *Sidebar) renameBookmark() {
+	id := s.selected
+	item, ok := s.outline.items[id]
+	if !ok || s.PromptBookmarkTitle == nil {
+		return
+	}
+	s.PromptBookmarkTitle(item.Title, func(title string) {
+		if items, ok := renameBookmark(s.outline.root, id, title); ok {
+			s.applyOutline(items, id)
+		}
+	})
+}
+
+func (s *Sidebar) deleteBookmark() {
+	if items, ok := removeBookmark(s.outline.root, s.selected); ok {
+		s.applyOutline(items, "")
+	}
+}
+
+func (s *Sidebar) moveBookmark(delta int) {
+	if items, id, ok := moveBookmark(s.outline.root, s.selected, delta); ok {
+		s.applyOutline(items, id)
+	}
+}
+
+func (s *Sidebar) indentBookmark() {
+	if items, id, ok := indentBookmark(s.outline.root, s.selected); ok {
+		s.applyOutline(items, id)
+	}
+}
+
+func (s *Sidebar) outdentBookmark() {
+	if items, id, ok := outdentBookmark(s.outline.root, s.selected); ok {
+		s.applyOutline(items, id)
+	}
+}
+
+// promptTitle asks for a bookmark title if a prompt is set.
+func (s *Sidebar) promptTitle(title string, done func(string)) {
+	if s.PromptBookmarkTitle == nil {
+		done(title)
+		return
+	}
+	s.PromptBookmarkTitle(title, done)
+}
+
+// applyOutline replaces the document outline and shows it with the item
+// at selectID selected.
+func (s *Sidebar) applyOutline(items []pdf.OutlineItem, selectID widget.TreeNodeID) {
+	if s.document == nil {
+		return
+	}
+	err := s.document.SetOutline(items)
+	if err == nil {
+		s.loadOutline()
+		if _, ok := s.outline.items[selectID]; ok {
+			path, _ := outlinePath(selectID)
+			for i := 1; i < len(path); i++ {
+				s.bookmarks.OpenBranch(outlineID(path[:i]))
+			}
+			s.restoring = true
+			s.bookmarks.Select(selectID)
+			s.restoring = false
+		}
+	}
+	if s.OnBookmarksChanged != nil {
+		s.OnBookmarksChanged(err)
+	}
 }
 
 // Toggle shows or hides the sidebar.
@@ -132,16 +356,97 @@ func (s *Sidebar) Toggle() {
 	}
 }
 
-func (s *Sidebar) getCachedThumbnail(pageNum int) image.Image {
-	s.cacheMu.RLock()
-	defer s.cacheMu.RUnlock()
-	return s.cache[pageNum]
+// requestThumbnailBatch queues the block of thumbnails containing pageNum for
+// background rendering and refreshes the list items once they are cached.
+func (s *Sidebar) requestThumbnailBatch(pageNum int) {
+	doc := s.document
+	first, last := thumbnailBatchRange(pageNum, doc.PageCount())
+	if s.pending[first] {
+		return
+	}
+	s.pending[first] = true
+
+	ctx := s.renderCtx
+	s.scheduler.Submit(ctx, PriorityThumbnail,
+		func(ctx context.Context) (image.Image, error) {
+			return nil, s.renderThumbnailBatch(ctx, doc, first, last)
+		},
+		func(image.Image, error) {
+			fyne.Do(func() {
+				if ctx.Err() != nil || s.document != doc {
+					return
+				}
+				delete(s.pending, first)
+				for p := first; p <= last; p++ {
+					s.list.RefreshItem(widget.ListItemID(p))
+				}
+			})
+		},
+	)
+}
+
+// renderThumbnailBatch renders pages first..last in one pass into the
+// document's render cache.
+func (s *Sidebar) renderThumbnailBatch(ctx context.Context, doc *pdf.Document, first, last int) error {
+	err := doc.RenderPages(first, last, thumbnailScale, func(int, image.Image) error {
+		return ctx.Err()
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// thumbnailBatchRange returns the inclusive batch of pages that contains pageNum.
+func thumbnailBatchRange(pageNum, pageCount int) (int, int) {
+	first := pageNum - pageNum%thumbnailBatchSize
+	last := first + thumbnailBatchSize - 1
+	if last >= pageCount {
+		last = pageCount - 1
+	}
+	return first, last
 }
 
-func (s *Sidebar) setCachedThumbnail(pageNum int, img image.Image) {
-	s.cacheMu.Lock()
-	defer s.cacheMu.Unlock()
-	s.cache[pageNum] = img
+// outlineTree indexes outline items by tree node ID for a widget.Tree. The
+// root is ""; other IDs are dot-separated child indexes such as "0.2".
+type outlineTree struct {
+	root     []pdf.OutlineItem
+	items    map[widget.TreeNodeID]pdf.OutlineItem
+	children map[widget.TreeNodeID][]widget.TreeNodeID
+	open     []widget.TreeNodeID // branches to expand initially
+}
+
+func newOutlineTree(items []pdf.OutlineItem) *outlineTree {
+	t := &outlineTree{
+		root:     items,
+		items:    map[widget.TreeNodeID]pdf.OutlineItem{},
+		children: map[widget.TreeNodeID][]widget.TreeNodeID{},
+	}
+	t.add("", items)
+	return t
+}
+
+func (t *outlineTree) add(parent widget.TreeNodeID, items []pdf.OutlineItem) {
+	for i, item := range items {
+		id := strconv.Itoa(i)
+		if parent != "" {
+			id = parent + "." + id
+		}
+		t.items[id] = item
+		t.children[parent] = append(t.children[parent], id)
+		if item.Open && len(item.Children) > 0 {
+			t.open = append(t.open, id)
+		}
+		t.add(id, item.Children)
+	}
+}
+
+// title returns the label of a node.
+func (t *outlineTree) title(id widget.TreeNodeID) string {
+	if title := t.items[id].Title; title != "" {
+		return title
+	}
+	return "(untitled)"
 }
 
 func buildPlaceholderThumbnail() image.Image {
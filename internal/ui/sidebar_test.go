@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+func TestThumbnailBatchRange(t *testing.T) {
+	tests := []struct {
+		page, count int
+		first, last int
+	}{
+		{0, 100, 0, 11},
+		{11, 100, 0, 11},
+		{12, 100, 12, 23},
+		{97, 100, 96, 99},
+		{2, 3, 0, 2},
+	}
+
+	for _, tt := range tests {
+		first, last := thumbnailBatchRange(tt.page, tt.count)
+		if first != tt.first || last != tt.last {
+			t.Errorf("thumbnailBatchRange(%d, %d) = %d..%d, want %d..%d", tt.page, tt.count, first, last, tt.first, tt.last)
+		}
+	}
+}
+
+func TestOutlineTree(t *testing.T) {
+	tree := newOutlineTree([]pdf.OutlineItem{
+		{Title: "One", Open: true, Children: []pdf.OutlineItem{
+			{Title: "One.A", Dest: pdf.Destination{Page: 1}},
+			{Title: "", Open: true},
+		}},
+		{Title: "Two", Children: []pdf.OutlineItem{{Title: "Two.A"}}},
+	})
+
+	wantChildren := map[string][]string{
+		"":  {"0", "1"},
+		"0": {"0.0", "0.1"},
+		"1": {"1.0"},
+	}
+	if !reflect.DeepEqual(tree.children, wantChildren) {
+		t.Errorf("children = %v, want %v", tree.children, wantChildren)
+	}
+	// Only branches are opened; "0.1" is marked open but has no children.
+	if !reflect.DeepEqual(tree.open, []string{"0"}) {
+		t.Errorf("open = %v, want [0]", tree.open)
+	}
+	if got := tree.items["0.0"].Dest.Page; got != 1 {
+		t.Errorf("items[0.0] page = %d, want 1", got)
+	}
+	if got := tree.title("0.1"); got != "(untitled)" {
+		t.Errorf("title(0.1) = %q, want (untitled)", got)
+	}
+}
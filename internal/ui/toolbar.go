@@ -32,13 +32,13 @@ func NewToolbar(window *MainWindow) *Toolbar {
 
 	prevPageBtn := widget.NewButton("◀", func() {
 		if window.viewer != nil {
-			window.viewer.GoToPage(window.viewer.currentPage - 1)
+			window.viewer.PreviousPage()
 		}
 	})
 
 	nextPageBtn := widget.NewButton("▶", func() {
 		if window.viewer != nil {
-			window.viewer.GoToPage(window.viewer.currentPage + 1)
+			window.viewer.NextPage()
 		}
 	})
 
@@ -0,0 +1,396 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+// Tool is what pointer gestures over a page do.
+type Tool int
+
+const (
+	// ToolSelect selects text and follows links.
+	ToolSelect Tool = iota
+	// ToolRect drags out a box, reported through OnRectDrawn.
+	ToolRect
+	// ToolPen draws freehand strokes on one page, kept until TakeInk
+	// collects them.
+	ToolPen
+	// ToolLine drags out a line, reported through OnLineDrawn.
+	ToolLine
+	// ToolPolygon places the vertices of a polygon on one page, one per
+	// click; dragging moves the vertex just placed. TakeInk collects them
+	// as a single stroke.
+	ToolPolygon
+)
+
+var toolColor = color.NRGBA{R: 255, G: 80, B: 40, A: 70}
+
+// minToolRect is the smallest box, in points, a drag must span on both axes
+// to count; anything smaller is taken for a stray click.
+const minToolRect = 2
+
+// defaultPenWidth is the width, in points, of pen strokes unless SetPen
+// chooses another.
+const defaultPenWidth = 2
+
+// minInkStep is how far, in points, the pointer must move before a stroke
+// records another point.
+const minInkStep = 1
+
+// toolDrag is a drawing gesture on one page, in display points.
+type toolDrag struct {
+	page           int
+	startX, startY float64
+	x, y           float64
+}
+
+// rect returns the box spanned by the gesture, LLX/LLY being the top-left
+// corner.
+func (d *toolDrag) rect() pdf.Rect {
+	return pdf.Rect{
+		LLX: math.Min(d.startX, d.x),
+		LLY: math.Min(d.startY, d.y),
+		URX: math.Max(d.startX, d.x),
+		URY: math.Max(d.startY, d.y),
+	}
+}
+
+// inkDrawing is the strokes drawn with the pen on one page, in display
+// points.
+type inkDrawing struct {
+	page    int
+	strokes [][]pdf.Point
+	drawing bool // the last stroke is still being drawn
+}
+
+// SetTool switches what pointer gestures over pages do, abandoning a
+// drawing in progress.
+func (v *Viewer) SetTool(tool Tool) {
+	if tool != ToolSelect {
+		v.ClearSelection()
+	}
+	v.tool = tool
+	v.ink = nil
+	v.setToolDrag(nil)
+}
+
+// Tool returns the active tool.
+func (v *Viewer) Tool() Tool {
+	return v.tool
+}
+
+// startToolDrag begins a drawing gesture at a point on page.
+func (v *Viewer) startToolDrag(page int, x, y float64) {
+	if v.document == nil {
+		return
+	}
+	x, y = v.clampToPage(page, x, y)
+	switch v.tool {
+	case ToolPen:
+		v.startStroke(page, x, y)
+		return
+	case ToolPolygon:
+		v.addVertex(page, x, y)
+		return
+	}
+	v.setToolDrag(&toolDrag{page: page, startX: x, startY: y, x: x, y: y})
+}
+
+// dragTool moves the free corner or end of the gesture, keeping it on the
+// page where the gesture started.
+func (v *Viewer) dragTool(page int, x, y float64) {
+	switch v.tool {
+	case ToolPen:
+		v.extendStroke(page, x, y)
+		return
+	case ToolPolygon:
+		v.moveVertex(page, x, y)
+		return
+	}
+	d := v.toolDrag
+	if d == nil || d.page != page {
+		return
+	}
+	d.x, d.y = v.clampToPage(page, x, y)
+	v.refreshToolDrag()
+}
+
+// finishToolDrag ends the gesture and reports the box or line drawn, in
+// PDF user space, to OnRectDrawn or OnLineDrawn.
+func (v *Viewer) finishToolDrag() {
+	if v.ink != nil {
+		v.ink.drawing = false
+	}
+	d := v.toolDrag
+	if d == nil {
+		return
+	}
+	v.setToolDrag(nil)
+
+	geom, ok := v.pageGeometry(d.page)
+	if !ok {
+		return
+	}
+	if v.tool == ToolLine {
+		if math.Hypot(d.x-d.startX, d.y-d.startY) < minToolRect || v.OnLineDrawn == nil {
+			return
+		}
+		v.OnLineDrawn(d.page,
+			geom.DisplayPointToUser(pdf.Point{X: d.startX, Y: d.startY}),
+			geom.DisplayPointToUser(pdf.Point{X: d.x, Y: d.y}))
+		return
+	}
+	r := d.rect()
+	if r.Width() < minToolRect || r.Height() < minToolRect {
+		return
+	}
+	if v.OnRectDrawn != nil {
+		v.OnRectDrawn(d.page, geom.DisplayToUser(r))
+	}
+}
+
+// clampToPage limits a point in display points to the bounds of page.
+func (v *Viewer) clampToPage(page int, x, y float64) (float64, float64) {
+	width := float64(v.pageDisplayWidth(page))
+	height := float64(v.pageDisplayHeight(page))
+	return math.Max(0, math.Min(x, width)), math.Max(0, math.Min(y, height))
+}
+
+func (v *Viewer) setToolDrag(d *toolDrag) {
+	v.toolDrag = d
+	v.refreshToolDrag()
+}
+
+// refreshToolDrag redraws the box of the gesture and the ink drawing on
+// every page on screen.
+func (v *Viewer) refreshToolDrag() {
+	if v.mode.multiPage() {
+		if v.strip.pages == nil {
+			return
+		}
+		for page, slot := range v.strip.slots {
+			width := v.strip.pages.sizes[page].Width
+			setBoxes(slot.tool, width, v.toolRects(page), toolFill)
+			v.setInk(slot.ink, width, page)
+		}
+		return
+	}
+	setBoxes(v.toolLayer, v.pageWidth, v.toolRects(v.currentPage), toolFill)
+	v.setInk(v.inkLayer, v.pageWidth, v.currentPage)
+}
+
+// toolRects returns the box of the gesture if it is on page.
+func (v *Viewer) toolRects(page int) []pdf.Rect {
+	if v.toolDrag == nil || v.toolDrag.page != page || v.tool == ToolLine {
+		return nil
+	}
+	return []pdf.Rect{v.toolDrag.rect()}
+}
+
+func toolFill(int) color.Color {
+	return toolColor
+}
+
+// SetPen sets the colour and width, in points, of the strokes drawn with
+// ToolPen and of the lines and polygons drawn with ToolLine and
+// ToolPolygon. A nil colour draws in black and a zero width uses the
+// default.
+func (v *Viewer) SetPen(c color.Color, width float64) {
+	v.penColor, v.penWidth = c, width
+	v.refreshToolDrag()
+}
+
+// HasInk reports whether strokes have been drawn with the pen.
+func (v *Viewer) HasInk() bool {
+	return v.ink != nil && len(v.ink.strokes) > 0
+}
+
+// UndoStroke removes the last stroke drawn with the pen, or the last
+// vertex placed with ToolPolygon, reporting whether there was one.
+func (v *Viewer) UndoStroke() bool {
+	if !v.HasInk() {
+		return false
+	}
+	last := len(v.ink.strokes) - 1
+	if stroke := v.ink.strokes[last]; v.tool == ToolPolygon && len(stroke) > 1 {
+		v.ink.strokes[last] = stroke[:len(stroke)-1]
+	} else {
+		v.ink.strokes = v.ink.strokes[:last]
+	}
+	v.ink.drawing = false
+	if len(v.ink.strokes) == 0 {
+		v.ink = nil
+	}
+	v.refreshToolDrag()
+	return true
+}
+
+// TakeInk returns the strokes drawn with the pen, as paths in PDF user
+// space, and the page they are on, and clears them. ok is false if there
+// are none.
+func (v *Viewer) TakeInk() (page int, strokes [][]pdf.Point, ok bool) {
+	ink := v.ink
+	if !v.HasInk() {
+		return 0, nil, false
+	}
+	v.ink = nil
+	v.refreshToolDrag()
+
+	geom, found := v.pageGeometry(ink.page)
+	if !found {
+		return 0, nil, false
+	}
+	strokes = make([][]pdf.Point, len(ink.strokes))
+	for i, stroke := range ink.strokes {
+		strokes[i] = make([]pdf.Point, len(stroke))
+		for j, p := range stroke {
+			strokes[i][j] = geom.DisplayPointToUser(p)
+		}
+	}
+	return ink.page, strokes, true
+}
+
+// startStroke begins a pen stroke at a point on page. The strokes of a
+// drawing all go on the page of its first one.
+func (v *Viewer) startStroke(page int, x, y float64) {
+	if v.ink == nil {
+		v.ink = &inkDrawing{page: page}
+	}
+	if v.ink.page != page {
+		return
+	}
+	v.ink.strokes = append(v.ink.strokes, []pdf.Point{{X: x, Y: y}})
+	v.ink.drawing = true
+	v.refreshToolDrag()
+}
+
+// extendStroke continues the stroke being drawn to a point on page.
+func (v *Viewer) extendStroke(page int, x, y float64) {
+	ink := v.ink
+	if ink == nil || !ink.drawing || ink.page != page {
+		return
+	}
+	x, y = v.clampToPage(page, x, y)
+	stroke := ink.strokes[len(ink.strokes)-1]
+	last := stroke[len(stroke)-1]
+	if math.Hypot(x-last.X, y-last.Y) < minInkStep {
+		return
+	}
+	ink.strokes[len(ink.strokes)-1] = append(stroke, pdf.Point{X: x, Y: y})
+	v.refreshToolDrag()
+}
+
+// addVertex places a vertex of the polygon being drawn at a point on
+// page. All vertices go on the page of the first.
+func (v *Viewer) addVertex(page int, x, y float64) {
+	if v.ink == nil {
+		v.ink = &inkDrawing{page: page, strokes: [][]pdf.Point{nil}}
+	}
+	if v.ink.page != page {
+		return
+	}
+	v.ink.strokes[0] = append(v.ink.strokes[0], pdf.Point{X: x, Y: y})
+	v.ink.drawing = true
+	v.refreshToolDrag()
+}
+
+// moveVertex moves the vertex just placed to a point on page.
+func (v *Viewer) moveVertex(page int, x, y float64) {
+	ink := v.ink
+	if ink == nil || !ink.drawing || ink.page != page {
+		return
+	}
+	x, y = v.clampToPage(page, x, y)
+	vertices := ink.strokes[0]
+	vertices[len(vertices)-1] = pdf.Point{X: x, Y: y}
+	v.refreshToolDrag()
+}
+
+// setInk draws the ink drawing, or the line being dragged out, on layer if
+// it is on page.
+func (v *Viewer) setInk(layer *fyne.Container, pageWidth float32, page int) {
+	var strokes [][]pdf.Point
+	switch d := v.toolDrag; {
+	case v.ink != nil && v.ink.page == page:
+		strokes = v.ink.strokes
+	case v.tool == ToolLine && d != nil && d.page == page:
+		strokes = [][]pdf.Point{{{X: d.startX, Y: d.startY}, {X: d.x, Y: d.y}}}
+	}
+	c := v.penColor
+	if c == nil {
+		c = color.Black
+	}
+	width := v.penWidth
+	if width <= 0 {
+		width = defaultPenWidth
+	}
+	setStrokes(layer, pageWidth, strokes, c, width)
+}
+
+// inkLayout positions pen strokes over a page, scaling display points to
+// the size the page is drawn at. Each segment of a stroke is a line; a
+// stroke of a single point is a dot.
+type inkLayout struct {
+	pageWidth float32
+	width     float64
+	segments  [][2]pdf.Point // parallel to the container's objects
+}
+
+func (l *inkLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	return fyne.NewSize(0, 0)
+}
+
+func (l *inkLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	if l.pageWidth <= 0 {
+		return
+	}
+	scale := size.Width / l.pageWidth
+	width := max(1, float32(l.width)*scale)
+	for i, o := range objects {
+		from, to := l.segments[i][0], l.segments[i][1]
+		switch o := o.(type) {
+		case *canvas.Line:
+			o.Position1 = fyne.NewPos(float32(from.X)*scale, float32(from.Y)*scale)
+			o.Position2 = fyne.NewPos(float32(to.X)*scale, float32(to.Y)*scale)
+			o.StrokeWidth = width
+		case *canvas.Circle:
+			o.Move(fyne.NewPos(float32(from.X)*scale-width/2, float32(from.Y)*scale-width/2))
+			o.Resize(fyne.NewSize(width, width))
+		}
+	}
+}
+
+// newInkLayer creates an empty layer for pen strokes to stack over a page
+// image.
+func newInkLayer() *fyne.Container {
+	return container.New(&inkLayout{})
+}
+
+// setStrokes replaces the strokes drawn by an ink layer.
+func setStrokes(layer *fyne.Container, pageWidth float32, strokes [][]pdf.Point, c color.Color, width float64) {
+	l := layer.Layout.(*inkLayout)
+	l.pageWidth, l.width = pageWidth, width
+	l.segments = l.segments[:0]
+
+	var objects []fyne.CanvasObject
+	for _, stroke := range strokes {
+		if len(stroke) == 1 {
+			l.segments = append(l.segments, [2]pdf.Point{stroke[0], stroke[0]})
+			objects = append(objects, canvas.NewCircle(c))
+			continue
+		}
+		for i := 1; i < len(stroke); i++ {
+			l.segments = append(l.segments, [2]pdf.Point{stroke[i-1], stroke[i]})
+			objects = append(objects, canvas.NewLine(c))
+		}
+	}
+	layer.Objects = objects
+	layer.Refresh()
+}
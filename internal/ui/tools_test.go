@@ -0,0 +1,234 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/test"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
+
+// openRotatedPage opens a one-page document whose CropBox is offset into
+// the MediaBox and which is displayed rotated by 90 degrees, 400x300
+// points on screen.
+func openRotatedPage(t *testing.T) *pdf.Document {
+	t.Helper()
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 400 600] /CropBox [100 200 400 600] /Rotate 90 >>",
+	}
+	out := "%PDF-1.4\n"
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = len(out)
+		out += fmt.Sprintf("%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := len(out)
+	out += fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		out += fmt.Sprintf("%010d 00000 n \n", off)
+	}
+	out += fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	path := filepath.Join(t.TempDir(), "rotated.pdf")
+	if err := os.WriteFile(path, []byte(out), 0644); err != nil {
+		t.Fatalf("failed to write test PDF: %v", err)
+	}
+	doc, err := pdf.Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	t.Cleanup(func() { doc.Close() })
+	return doc
+}
+
+func TestViewerRectTool(t *testing.T) {
+	test.NewTempApp(t)
+	v := &Viewer{
+		document:   openRotatedPage(t),
+		pageWidth:  400,
+		pageHeight: 300,
+		toolLayer:  newHighlightLayer(),
+		inkLayer:   newInkLayer(),
+	}
+	var drawn []pdf.Rect
+	v.OnRectDrawn = func(page int, rect pdf.Rect) {
+		if page != 0 {
+			t.Errorf("OnRectDrawn page = %d, want 0", page)
+		}
+		drawn = append(drawn, rect)
+	}
+	v.SetTool(ToolRect)
+
+	// The page is shown at 200%.
+	input := newPageInput(v, -1)
+	input.Resize(fyne.NewSize(800, 600))
+	drag := func(from, to fyne.Position) {
+		input.MouseDown(&desktop.MouseEvent{
+			PointEvent: fyne.PointEvent{Position: from},
+			Button:     desktop.MouseButtonPrimary,
+		})
+		input.Dragged(&fyne.DragEvent{PointEvent: fyne.PointEvent{Position: to}})
+		input.DragEnd()
+	}
+
+	drag(fyne.NewPos(100, 40), fyne.NewPos(300, 140))
+	// Dragging off the page stops at its edge.
+	drag(fyne.NewPos(780, 580), fyne.NewPos(2000, 2000))
+	// A click draws nothing.
+	drag(fyne.NewPos(100, 100), fyne.NewPos(101, 101))
+
+	want := []pdf.Rect{
+		{LLX: 120, LLY: 250, URX: 170, URY: 350},
+		{LLX: 390, LLY: 590, URX: 400, URY: 600},
+	}
+	if fmt.Sprint(drawn) != fmt.Sprint(want) {
+		t.Errorf("drawn = %v, want %v", drawn, want)
+	}
+
+	v.SetTool(ToolSelect)
+	drag(fyne.NewPos(100, 40), fyne.NewPos(300, 140))
+	if len(drawn) != len(want) {
+		t.Error("the select tool drew a box")
+	}
+}
+
+func TestViewerPenTool(t *testing.T) {
+	test.NewTempApp(t)
+	v := &Viewer{
+		document:   openRotatedPage(t),
+		pageWidth:  400,
+		pageHeight: 300,
+		toolLayer:  newHighlightLayer(),
+		inkLayer:   newInkLayer(),
+	}
+	v.SetTool(ToolPen)
+	v.SetPen(color.NRGBA{R: 255, A: 255}, 3)
+
+	// The page is shown at 200%.
+	input := newPageInput(v, -1)
+	input.Resize(fyne.NewSize(800, 600))
+	stroke := func(points ...fyne.Position) {
+		input.MouseDown(&desktop.MouseEvent{
+			PointEvent: fyne.PointEvent{Position: points[0]},
+			Button:     desktop.MouseButtonPrimary,
+		})
+		for _, p := range points[1:] {
+			input.Dragged(&fyne.DragEvent{PointEvent: fyne.PointEvent{Position: p}})
+		}
+		input.DragEnd()
+	}
+
+	// Moves shorter than a point are dropped and the stroke stops at the
+	// edge of the page.
+	stroke(fyne.NewPos(100, 40), fyne.NewPos(101, 40), fyne.NewPos(300, 140), fyne.NewPos(900, 140))
+	stroke(fyne.NewPos(200, 200))
+	stroke(fyne.NewPos(400, 400), fyne.NewPos(500, 500))
+	if !v.HasInk() {
+		t.Fatal("HasInk() = false after drawing")
+	}
+	// Three line segments and a dot are shown over the page.
+	if n := len(v.inkLayer.Objects); n != 4 {
+		t.Errorf("ink layer has %d objects, want 4", n)
+	}
+
+	if !v.UndoStroke() {
+		t.Fatal("UndoStroke() = false")
+	}
+	page, strokes, ok := v.TakeInk()
+	if !ok || page != 0 {
+		t.Fatalf("TakeInk() = %d, %v, %v", page, strokes, ok)
+	}
+	// Display points map to user space on the rotated, cropped page.
+	want := [][]pdf.Point{
+		{{X: 120, Y: 250}, {X: 170, Y: 350}, {X: 170, Y: 600}},
+		{{X: 200, Y: 300}},
+	}
+	if fmt.Sprint(strokes) != fmt.Sprint(want) {
+		t.Errorf("TakeInk() strokes = %v, want %v", strokes, want)
+	}
+	if v.HasInk() || len(v.inkLayer.Objects) != 0 {
+		t.Error("TakeInk() did not clear the drawing")
+	}
+	if _, _, ok := v.TakeInk(); ok {
+		t.Error("TakeInk() returned a drawing twice")
+	}
+
+	stroke(fyne.NewPos(100, 40), fyne.NewPos(300, 140))
+	v.SetTool(ToolSelect)
+	if v.HasInk() {
+		t.Error("SetTool() kept the drawing")
+	}
+}
+
+func TestViewerLineAndPolygonTools(t *testing.T) {
+	test.NewTempApp(t)
+	v := &Viewer{
+		document:   openRotatedPage(t),
+		pageWidth:  400,
+		pageHeight: 300,
+		toolLayer:  newHighlightLayer(),
+		inkLayer:   newInkLayer(),
+	}
+	var lines [][2]pdf.Point
+	v.OnLineDrawn = func(page int, from, to pdf.Point) {
+		lines = append(lines, [2]pdf.Point{from, to})
+	}
+
+	// The page is shown at 200%.
+	input := newPageInput(v, -1)
+	input.Resize(fyne.NewSize(800, 600))
+	press := func(at fyne.Position, drag ...fyne.Position) {
+		input.MouseDown(&desktop.MouseEvent{
+			PointEvent: fyne.PointEvent{Position: at},
+			Button:     desktop.MouseButtonPrimary,
+		})
+		for _, p := range drag {
+			input.Dragged(&fyne.DragEvent{PointEvent: fyne.PointEvent{Position: p}})
+		}
+		input.MouseUp(&desktop.MouseEvent{PointEvent: fyne.PointEvent{Position: at}})
+	}
+
+	v.SetTool(ToolLine)
+	input.MouseDown(&desktop.MouseEvent{
+		PointEvent: fyne.PointEvent{Position: fyne.NewPos(100, 40)},
+		Button:     desktop.MouseButtonPrimary,
+	})
+	input.Dragged(&fyne.DragEvent{PointEvent: fyne.PointEvent{Position: fyne.NewPos(300, 140)}})
+	// The line is shown while it is dragged out, and not as a box.
+	if len(v.inkLayer.Objects) != 1 || len(v.toolLayer.Objects) != 0 {
+		t.Errorf("line preview has %d lines and %d boxes", len(v.inkLayer.Objects), len(v.toolLayer.Objects))
+	}
+	input.DragEnd()
+	// A click draws nothing.
+	press(fyne.NewPos(100, 100), fyne.NewPos(101, 101))
+	want := [][2]pdf.Point{{{X: 120, Y: 250}, {X: 170, Y: 350}}}
+	if fmt.Sprint(lines) != fmt.Sprint(want) {
+		t.Errorf("lines = %v, want %v", lines, want)
+	}
+
+	v.SetTool(ToolPolygon)
+	press(fyne.NewPos(100, 40))
+	press(fyne.NewPos(300, 40))
+	// Dragging moves the vertex just placed.
+	press(fyne.NewPos(300, 200), fyne.NewPos(320, 220))
+	press(fyne.NewPos(500, 500))
+	if len(v.inkLayer.Objects) != 3 {
+		t.Errorf("polygon preview has %d segments, want 3", len(v.inkLayer.Objects))
+	}
+	if !v.UndoStroke() {
+		t.Fatal("UndoStroke() = false")
+	}
+	page, strokes, ok := v.TakeInk()
+	wantVertices := [][]pdf.Point{{{X: 120, Y: 250}, {X: 120, Y: 350}, {X: 210, Y: 360}}}
+	if !ok || page != 0 || fmt.Sprint(strokes) != fmt.Sprint(wantVertices) {
+		t.Errorf("TakeInk() = %d, %v, %v, want the vertices %v", page, strokes, ok, wantVertices)
+	}
+}
@@ -1,8 +1,11 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"image"
+	"image/color"
+	"math"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -28,6 +31,68 @@ func (f *fixedSizeLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
 	}
 }
 
+// ViewMode selects how the viewer lays out pages.
+type ViewMode int
+
+const (
+	// ViewSinglePage shows one page at a time.
+	ViewSinglePage ViewMode = iota
+	// ViewContinuous stacks all pages in one vertical scroll.
+	ViewContinuous
+	// ViewTwoPage scrolls through facing pages: 1–2, 3–4, ...
+	ViewTwoPage
+	// ViewBook scrolls through facing pages with the cover alone: 1, 2–3, ...
+	ViewBook
+)
+
+// String returns the configuration name of the mode.
+func (m ViewMode) String() string {
+	switch m {
+	case ViewContinuous:
+		return "continuous"
+	case ViewTwoPage:
+		return "two-page"
+	case ViewBook:
+		return "book"
+	default:
+		return "single"
+	}
+}
+
+// ParseViewMode returns the mode for a configuration name, defaulting to
+// single page.
+func ParseViewMode(name string) ViewMode {
+	switch name {
+	case "continuous":
+		return ViewContinuous
+	case "two-page":
+		return ViewTwoPage
+	case "book":
+		return ViewBook
+	default:
+		return ViewSinglePage
+	}
+}
+
+// multiPage reports whether the mode lays out every page in one scrolling
+// strip rather than showing a single page.
+func (m ViewMode) multiPage() bool {
+	return m != ViewSinglePage
+}
+
+// columns returns the pages per row of the mode's layout and whether the
+// first page sits alone.
+func (m ViewMode) columns() (int, bool) {
+	switch m {
+	case ViewTwoPage:
+		return 2, false
+	case ViewBook:
+		return 2, true
+	default:
+		return 1, false
+	}
+}
+
 // Viewer displays PDF pages.
 type Viewer struct {
 	container   *fyne.Container
@@ -42,20 +107,94 @@ type Viewer struct {
 	zoomLabel   *widget.Label
 	prevBtn     *widget.Button
 	nextBtn     *widget.Button
-	cachedImage image.Image
-	cachedPage  int
-	baseWidth   int
-	baseHeight  int
+	// pageWidth and pageHeight are the displayed size of the current page
+	// in points, i.e. its size at 100% zoom.
+	pageWidth  float32
+	pageHeight float32
+
+	// displayedPage is the page whose rendering is shown, or -1 while a
+	// placeholder is displayed, and displayedScale the zoom factor it was
+	// rendered at. Rendered pages live in the document's shared render
+	// cache.
+	displayedPage  int
+	displayedScale float64
+	scheduler      *RenderScheduler
+	renderCancel   context.CancelFunc
+
+	// prefetchPages is how many pages before and after the current one
+	// are rendered speculatively.
+	prefetchPages  int
+	prefetchCancel context.CancelFunc
+
+	// mode is the page layout. In the multi-page modes only the pages near
+	// the viewport have canvas objects; strip positions them.
+	mode        ViewMode
+	strip       *stripLayout
+	stripHolder *fyne.Container
+	scrollingTo bool
+
+	// highlights are the search hit boxes drawn over each page;
+	// highlightLayer draws them in single page mode.
+	highlights     map[int][]highlightRect
+	highlightLayer *fyne.Container
+
+	// selection is the selected text, drawn by selectionLayer in single
+	// page mode. OnSelectionChanged is called whenever it changes;
+	// SelectionMenu supplies the menu shown when it is right-clicked.
+	selection          *textSelection
+	selectionLayer     *fyne.Container
+	OnSelectionChanged func(page int, text string)
+	SelectionMenu      func() *fyne.Menu
+
+	// tool is what gestures over pages do; toolDrag is the box or line
+	// being drawn with it, shown by toolLayer in single page mode.
+	// OnRectDrawn and OnLineDrawn receive boxes drawn with ToolRect and
+	// lines drawn with ToolLine, in PDF user space. ink is the drawing made
+	// with ToolPen or ToolPolygon in penColor and penWidth, shown by
+	// inkLayer.
+	tool        Tool
+	toolDrag    *toolDrag
+	toolLayer   *fyne.Container
+	OnRectDrawn func(page int, rect pdf.Rect)
+	OnLineDrawn func(page int, from, to pdf.Point)
+	ink         *inkDrawing
+	inkLayer    *fyne.Container
+	penColor    color.Color
+	penWidth    float64
+
+	// links caches the link annotations of each page; history records the
+	// positions jumps leave. OnExternalLink is called for links to other
+	// files and URIs.
+	links          map[int][]pageLink
+	history        navHistory
+	OnExternalLink func(link pdf.Link)
 }
 
+// Pages are rendered at the zoom factor rounded up to a multiple of
+// renderScaleStep, so that zooming within a step scales the rendered image
+// instead of re-rendering. maxRenderScale matches the 300 DPI limit of
+// document rendering.
+const (
+	renderScaleStep = 0.5
+	maxRenderScale  = 300.0 / 72
+)
+
 // NewViewer creates a new PDF viewer widget.
-func NewViewer() *Viewer {
+// Pages are rendered in the background on scheduler; a private scheduler is
+// created when nil.
+func NewViewer(scheduler *RenderScheduler) *Viewer {
+	if scheduler == nil {
+		scheduler = NewRenderScheduler(defaultRenderWorkers)
+	}
+
 	v := &Viewer{
 		currentPage: 0,
 		zoom:        1.0,
-		cachedPage:  -1,
 		pageLabel:   widget.NewLabel("No document loaded"),
 		zoomLabel:   widget.NewLabel("100%"),
+
+		displayedPage: -1,
+		scheduler:     scheduler,
 	}
 
 	// Create image
@@ -65,9 +204,19 @@ func NewViewer() *Viewer {
 
 	// Use fixed size layout to control image size
 	v.sizeLayout = &fixedSizeLayout{size: fyne.NewSize(100, 100)}
-	v.imageHolder = container.New(v.sizeLayout, v.pageImage)
+	v.highlightLayer = newHighlightLayer()
+	v.selectionLayer = newHighlightLayer()
+	v.toolLayer = newHighlightLayer()
+	v.inkLayer = newInkLayer()
+	v.imageHolder = container.New(v.sizeLayout, v.pageImage, v.highlightLayer, v.selectionLayer, v.toolLayer, v.inkLayer, newPageInput(v, -1))
+
+	v.strip = &stripLayout{zoom: 1, slots: make(map[int]*pageSlot)}
+	v.stripHolder = container.New(v.strip)
 
 	v.scroll = container.NewScroll(v.imageHolder)
+	v.scroll.OnScrolled = func(fyne.Position) {
+		v.onScrolled()
+	}
 
 	v.container = container.NewBorder(
 		nil,
@@ -82,17 +231,58 @@ func NewViewer() *Viewer {
 	return v
 }
 
+// SetPrefetchPages sets how many pages around the current one are rendered
+// in the background. Zero disables prefetching.
+func (v *Viewer) SetPrefetchPages(n int) {
+	if n < 0 {
+		n = 0
+	}
+	v.prefetchPages = n
+}
+
 // Container returns the viewer's container.
 func (v *Viewer) Container() *fyne.Container {
 	return v.container
 }
 
+// SetViewMode switches the page layout, keeping the current page.
+func (v *Viewer) SetViewMode(mode ViewMode) {
+	if mode == v.mode {
+		return
+	}
+	v.ClearSelection()
+	v.mode = mode
+	v.displayedPage = -1
+	v.clearStrip()
+
+	if mode.multiPage() {
+		v.cancelRender()
+		v.cancelPrefetch()
+		v.scroll.Content = v.stripHolder
+	} else {
+		v.scroll.Content = v.imageHolder
+	}
+	v.scroll.Refresh()
+	v.renderCurrentPage()
+}
+
+// ViewMode returns the current page layout.
+func (v *Viewer) ViewMode() ViewMode {
+	return v.mode
+}
+
 // SetDocument sets the PDF document to display.
 func (v *Viewer) SetDocument(doc *pdf.Document) {
+	v.ClearSelection()
+	v.ink = nil
+	v.setToolDrag(nil)
 	v.document = doc
+	v.highlights = nil
+	v.links = nil
+	v.history = navHistory{}
 	v.currentPage = 0
-	v.cachedPage = -1
-	v.cachedImage = nil
+	v.displayedPage = -1
+	v.clearStrip()
 	v.renderCurrentPage()
 }
 
@@ -108,14 +298,90 @@ func (v *Viewer) GoToPage(page int) {
 	}
 
 	v.currentPage = page
+	if v.mode.multiPage() {
+		v.scrollToPage(page)
+		v.updatePageLabel()
+		return
+	}
+	v.ClearSelection()
+	v.setToolDrag(nil)
 	v.renderCurrentPage()
 }
 
+// NextPage moves to the following page, or to the following spread in the
+// two-page modes.
+func (v *Viewer) NextPage() {
+	if page := v.adjacentPage(1); page >= 0 {
+		v.GoToPage(page)
+	}
+}
+
+// PreviousPage moves to the preceding page, or to the preceding spread in
+// the two-page modes.
+func (v *Viewer) PreviousPage() {
+	if page := v.adjacentPage(-1); page >= 0 {
+		v.GoToPage(page)
+	}
+}
+
+// adjacentPage returns the first page of the row delta rows away from the
+// current one, or -1 if there is none.
+func (v *Viewer) adjacentPage(delta int) int {
+	if v.document == nil {
+		return -1
+	}
+	if v.mode.multiPage() && v.strip.pages != nil && v.currentPage < v.strip.pages.pageCount() {
+		r := v.strip.pages.rowOf[v.currentPage] + delta
+		if r < 0 || r >= len(v.strip.pages.rows) {
+			return -1
+		}
+		return v.strip.pages.rows[r].first
+	}
+
+	page := v.currentPage + delta
+	if page < 0 || page >= v.document.PageCount() {
+		return -1
+	}
+	return page
+}
+
 // CurrentPage returns the currently displayed page (0-indexed).
 func (v *Viewer) CurrentPage() int {
 	return v.currentPage
 }
 
+// CurrentPosition returns the current page and how far below its top edge
+// the top of the viewport is, in points at 100% zoom.
+func (v *Viewer) CurrentPosition() (int, float32) {
+	var top float32
+	if v.mode.multiPage() && v.strip.pages != nil && v.currentPage < v.strip.pages.pageCount() {
+		top = v.scroll.Offset.Y/v.strip.zoom - v.strip.pages.positions[v.currentPage].Y
+	} else if v.zoom > 0 {
+		top = v.scroll.Offset.Y / float32(v.zoom)
+	}
+	return v.currentPage, max(top, 0)
+}
+
+// GoToPosition navigates to page (0-indexed) and scrolls so that the point
+// top points below its top edge is at the top of the viewport.
+func (v *Viewer) GoToPosition(page int, top float32) {
+	if v.document == nil || page < 0 || page >= v.document.PageCount() {
+		return
+	}
+
+	v.GoToPage(page)
+	if v.mode.multiPage() {
+		if v.strip.pages == nil || page >= v.strip.pages.pageCount() {
+			return
+		}
+		y := (v.strip.pages.positions[page].Y + top) * v.strip.zoom
+		v.scrollTo(fyne.NewPos(v.scroll.Offset.X, y))
+		v.updateVisiblePages()
+		return
+	}
+	v.scroll.ScrollToOffset(fyne.NewPos(v.scroll.Offset.X, top*float32(v.zoom)))
+}
+
 // ZoomIn increases the zoom level.
 func (v *Viewer) ZoomIn() {
 	newZoom := v.zoom * 1.25
@@ -142,62 +408,70 @@ func (v *Viewer) ZoomOut() {
 
 // FitToPage fits the page to the viewport.
 func (v *Viewer) FitToPage() {
-	if v.cachedImage == nil {
-		return
-	}
-
 	viewport := v.scroll.Size()
 	if viewport.Width <= 0 || viewport.Height <= 0 {
 		return
 	}
 
-	baseWidth := float32(v.baseWidth) / 2.0
-	baseHeight := float32(v.baseHeight) / 2.0
-	if baseWidth <= 0 || baseHeight <= 0 {
+	width, height := v.fitSize()
+	if width <= 0 || height <= 0 {
 		return
 	}
 
-	widthZoom := float64(viewport.Width / baseWidth)
-	heightZoom := float64(viewport.Height / baseHeight)
+	widthZoom := float64(viewport.Width / width)
+	heightZoom := float64(viewport.Height / height)
 	v.zoom = clampZoom(minFloat(widthZoom, heightZoom))
 	v.applyZoom()
 }
 
 // FitToWidth fits the page width to the viewport.
 func (v *Viewer) FitToWidth() {
-	if v.cachedImage == nil {
-		return
-	}
-
 	viewport := v.scroll.Size()
 	if viewport.Width <= 0 {
 		return
 	}
 
-	baseWidth := float32(v.baseWidth) / 2.0
-	if baseWidth <= 0 {
+	width, _ := v.fitSize()
+	if width <= 0 {
 		return
 	}
 
-	v.zoom = clampZoom(float64(viewport.Width / baseWidth))
+	v.zoom = clampZoom(float64(viewport.Width / width))
 	v.applyZoom()
 }
 
+// fitSize returns the size in points that zoom fitting makes visible: the
+// current page, or in the multi-page modes the widest row and the height of
+// the current row, so that spreads fit as a whole.
+func (v *Viewer) fitSize() (float32, float32) {
+	if v.mode.multiPage() && v.strip.pages != nil && v.currentPage < v.strip.pages.pageCount() {
+		return v.strip.pages.width, v.strip.pages.row(v.currentPage).height
+	}
+	return v.pageWidth, v.pageHeight
+}
+
 func (v *Viewer) applyZoom() {
-	if v.cachedImage == nil {
+	if v.pageWidth <= 0 || v.pageHeight <= 0 {
+		return
+	}
+
+	if v.mode.multiPage() {
+		v.applyStripZoom()
+		v.zoomLabel.SetText(fmt.Sprintf("%.0f%%", v.zoom*100))
+		v.updateRenderScale()
 		return
 	}
 
-	// Calculate scaled size based on cached image and zoom
-	// Base render is at 2.0x, so divide by 2 to get 100% size
-	scaledWidth := float32(v.baseWidth) * float32(v.zoom) / 2.0
-	scaledHeight := float32(v.baseHeight) * float32(v.zoom) / 2.0
+	// Calculate scaled size based on the page size in points and zoom
+	scaledWidth := v.pageWidth * float32(v.zoom)
+	scaledHeight := v.pageHeight * float32(v.zoom)
 
 	// Update the layout size and refresh
 	v.sizeLayout.size = fyne.NewSize(scaledWidth, scaledHeight)
 	v.imageHolder.Refresh()
 
 	v.zoomLabel.SetText(fmt.Sprintf("%.0f%%", v.zoom*100))
+	v.updateRenderScale()
 }
 
 func clampZoom(zoom float64) float64 {
@@ -219,35 +493,458 @@ func minFloat(a, b float64) float64 {
 
 func (v *Viewer) renderCurrentPage() {
 	if v.document == nil {
+		v.cancelRender()
+		v.cancelPrefetch()
 		v.pageLabel.SetText("No document loaded")
 		v.zoomLabel.SetText("--")
 		v.updateButtonStates()
 		return
 	}
 
+	if v.mode.multiPage() {
+		v.renderContinuous()
+		return
+	}
+
 	// Only re-render from PDF if page changed
-	if v.cachedPage != v.currentPage || v.cachedImage == nil {
-		img, err := v.document.RenderPage(v.currentPage, 2.0)
-		if err != nil {
-			v.pageLabel.SetText("Error: " + err.Error())
-			return
+	if v.displayedPage != v.currentPage {
+		v.updatePageSize()
+		v.refreshHighlights()
+		v.refreshSelection()
+		scale := v.renderScale()
+		if img, ok := v.document.CachedPage(v.currentPage, scale); ok {
+			v.showPage(v.currentPage, scale, img)
+		} else {
+			v.requestPage(v.document, v.currentPage)
 		}
-		v.cachedImage = img
-		v.cachedPage = v.currentPage
-		bounds := img.Bounds()
-		v.baseWidth = bounds.Dx()
-		v.baseHeight = bounds.Dy()
-		v.pageImage.Image = img
-		v.pageImage.Refresh()
 	}
+	v.prefetchAround(v.document, v.currentPage)
 
 	v.applyZoom()
 	v.scroll.ScrollToTop()
 
-	v.pageLabel.SetText(fmt.Sprintf("Page %d of %d", v.currentPage+1, v.document.PageCount()))
+	v.updatePageLabel()
+}
+
+// updatePageLabel shows the current page number, or the current spread in
+// the two-page modes, and refreshes the navigation buttons.
+func (v *Viewer) updatePageLabel() {
+	first, last := v.currentPage, v.currentPage
+	if v.mode.multiPage() && v.strip.pages != nil && v.currentPage < v.strip.pages.pageCount() {
+		row := v.strip.pages.row(v.currentPage)
+		first, last = row.first, row.last
+	}
+	v.pageLabel.SetText(pageLabelText(first, last, v.document.PageCount()))
 	v.updateButtonStates()
 }
 
+// pageLabelText formats the 0-indexed page range first..last for display.
+func pageLabelText(first, last, pageCount int) string {
+	if first == last {
+		return fmt.Sprintf("Page %d of %d", first+1, pageCount)
+	}
+	return fmt.Sprintf("Pages %d–%d of %d", first+1, last+1, pageCount)
+}
+
+// requestPage shows a placeholder and renders page in the background,
+// cancelling any render still pending for a previous page.
+func (v *Viewer) requestPage(doc *pdf.Document, page int) {
+	v.displayedPage = -1
+	v.showPlaceholder()
+	v.submitPage(doc, page)
+}
+
+// submitPage renders page in the background at the current render scale
+// and shows it once done, cancelling any render still pending.
+func (v *Viewer) submitPage(doc *pdf.Document, page int) {
+	v.cancelRender()
+	ctx, cancel := context.WithCancel(context.Background())
+	v.renderCancel = cancel
+
+	scale := v.renderScale()
+	v.scheduler.Submit(ctx, PriorityVisible,
+		func(ctx context.Context) (image.Image, error) {
+			return doc.RenderPage(page, scale)
+		},
+		func(img image.Image, err error) {
+			fyne.Do(func() {
+				if ctx.Err() != nil || v.document != doc || v.currentPage != page {
+					return
+				}
+				if err != nil {
+					v.pageLabel.SetText("Error: " + err.Error())
+					return
+				}
+				v.showPage(page, scale, img)
+			})
+		},
+	)
+}
+
+// prefetchAround queues background renders of the pages surrounding page
+// into the render cache, replacing any earlier prefetch.
+func (v *Viewer) prefetchAround(doc *pdf.Document, page int) {
+	v.cancelPrefetch()
+	if v.prefetchPages == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	v.prefetchCancel = cancel
+
+	scale := v.renderScale()
+	for _, p := range prefetchOrder(page, doc.PageCount(), v.prefetchPages) {
+		if _, ok := doc.CachedPage(p, scale); ok {
+			continue
+		}
+		v.scheduler.Submit(ctx, PriorityPrefetch, func(ctx context.Context) (image.Image, error) {
+			return doc.RenderPage(p, scale)
+		}, nil)
+	}
+}
+
+func (v *Viewer) cancelPrefetch() {
+	if v.prefetchCancel != nil {
+		v.prefetchCancel()
+		v.prefetchCancel = nil
+	}
+}
+
+// renderContinuous lays out every page of the document in one strip and
+// scrolls to the current page.
+func (v *Viewer) renderContinuous() {
+	v.cancelRender()
+	v.cancelPrefetch()
+	v.updatePageSize()
+	if v.strip.pages == nil {
+		fallback := fyne.NewSize(v.pageWidth, v.pageHeight)
+		columns, coverAlone := v.mode.columns()
+		v.strip.pages = newPageLayout(documentPageSizes(v.document, fallback), columns, coverAlone, pageGap)
+	}
+
+	v.applyZoom()
+	v.scrollToPage(v.currentPage)
+	v.updatePageLabel()
+}
+
+// documentPageSizes returns the displayed size of every page in points,
+// using fallback for pages whose size cannot be read.
+func documentPageSizes(doc *pdf.Document, fallback fyne.Size) []fyne.Size {
+	sizes := make([]fyne.Size, doc.PageCount())
+	for i := range sizes {
+		width, height, err := doc.GetPageSize(i)
+		if err != nil || width <= 0 || height <= 0 {
+			sizes[i] = fallback
+			continue
+		}
+		sizes[i] = fyne.NewSize(float32(width), float32(height))
+	}
+	return sizes
+}
+
+// applyStripZoom rescales the continuous layout, keeping the same part of
+// the document in view.
+func (v *Viewer) applyStripZoom() {
+	oldZoom := v.strip.zoom
+	v.strip.zoom = float32(v.zoom)
+	v.scroll.Refresh()
+
+	if oldZoom > 0 && oldZoom != v.strip.zoom {
+		ratio := v.strip.zoom / oldZoom
+		offset := v.scroll.Offset
+		v.scrollTo(fyne.NewPos(offset.X*ratio, offset.Y*ratio))
+	}
+	v.updateVisiblePages()
+}
+
+// scrollToPage moves the top of the row containing page to the top of the
+// viewport.
+func (v *Viewer) scrollToPage(page int) {
+	if v.strip.pages == nil || page < 0 || page >= v.strip.pages.pageCount() {
+		return
+	}
+	v.scrollTo(fyne.NewPos(v.scroll.Offset.X, v.strip.pages.row(page).y*v.strip.zoom))
+	v.updateVisiblePages()
+}
+
+// scrollTo sets the scroll offset without letting the resulting scroll
+// event move the current page; the caller has already chosen it.
+func (v *Viewer) scrollTo(offset fyne.Position) {
+	v.scrollingTo = true
+	v.scroll.ScrollToOffset(offset)
+	v.scrollingTo = false
+}
+
+// onScrolled materializes the pages that scrolled into view and makes the
+// row under the centre of the viewport current.
+func (v *Viewer) onScrolled() {
+	if !v.mode.multiPage() || v.document == nil || v.strip.pages == nil || v.scrollingTo {
+		return
+	}
+	v.updateVisiblePages()
+
+	centre := v.scroll.Offset.Y + v.viewportHeight()/2
+	page := v.strip.pages.pageAt(centre / v.strip.zoom)
+	if page >= 0 && v.strip.pages.rowOf[page] != v.strip.pages.rowOf[v.currentPage] {
+		v.currentPage = page
+		v.updatePageSize()
+		v.updatePageLabel()
+	}
+}
+
+// updateVisiblePages creates canvas objects for the pages within one
+// viewport height of the visible area and releases all others.
+func (v *Viewer) updateVisiblePages() {
+	if !v.mode.multiPage() || v.document == nil || v.strip.pages == nil {
+		return
+	}
+
+	zoom := v.strip.zoom
+	top := v.scroll.Offset.Y
+	viewport := v.viewportHeight()
+	first, last := v.strip.pages.visibleRange((top-viewport)/zoom, (top+2*viewport)/zoom)
+	shownFirst, shownLast := v.strip.pages.visibleRange(top/zoom, (top+viewport)/zoom)
+
+	changed := false
+	for page, slot := range v.strip.slots {
+		if page < first || page > last {
+			slot.cancel()
+			delete(v.strip.slots, page)
+			changed = true
+		}
+	}
+	for page := first; page <= last; page++ {
+		if _, ok := v.strip.slots[page]; ok {
+			continue
+		}
+		priority := PriorityPrefetch
+		if page >= shownFirst && page <= shownLast {
+			priority = PriorityVisible
+		}
+		v.strip.slots[page] = v.newPageSlot(v.document, page, priority)
+		changed = true
+	}
+	if !changed {
+		return
+	}
+
+	objects := make([]fyne.CanvasObject, 0, last-first+1)
+	for page := first; page <= last; page++ {
+		objects = append(objects, v.strip.slots[page].view)
+	}
+	v.stripHolder.Objects = objects
+	v.stripHolder.Refresh()
+}
+
+// newPageSlot creates the image for a page in continuous mode, showing the
+// cached rendering or a placeholder until the page renders.
+func (v *Viewer) newPageSlot(doc *pdf.Document, page int, priority RenderPriority) *pageSlot {
+	img := canvas.NewImageFromImage(nil)
+	img.FillMode = canvas.ImageFillContain
+	img.ScaleMode = canvas.ImageScaleSmooth
+
+	width := v.strip.pages.sizes[page].Width
+	layer := newHighlightLayer()
+	setHighlights(layer, width, v.highlights[page])
+	selection := newHighlightLayer()
+	setBoxes(selection, width, v.selectionRects(page), selectionFill)
+	tool := newHighlightLayer()
+	setBoxes(tool, width, v.toolRects(page), toolFill)
+	ink := newInkLayer()
+	v.setInk(ink, width, page)
+
+	slot := &pageSlot{
+		view:      container.NewStack(img, layer, selection, tool, ink, newPageInput(v, page)),
+		image:     img,
+		layer:     layer,
+		selection: selection,
+		tool:      tool,
+		ink:       ink,
+		cancel:    func() {},
+	}
+
+	size := v.strip.pages.sizes[page]
+	img.Image = placeholderImage(int(size.Width/8), int(size.Height/8))
+	v.renderSlot(doc, page, slot, priority)
+	return slot
+}
+
+// renderSlot shows page in slot at the current render scale, from the
+// render cache or by rendering it in the background. The slot keeps its
+// image until the new one arrives.
+func (v *Viewer) renderSlot(doc *pdf.Document, page int, slot *pageSlot, priority RenderPriority) {
+	slot.cancel()
+	slot.cancel = func() {}
+	scale := v.renderScale()
+	slot.scale = scale
+	if cached, ok := doc.CachedPage(page, scale); ok {
+		slot.image.Image = cached
+		slot.image.Refresh()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	slot.cancel = cancel
+	v.scheduler.Submit(ctx, priority,
+		func(ctx context.Context) (image.Image, error) {
+			return doc.RenderPage(page, scale)
+		},
+		func(rendered image.Image, err error) {
+			fyne.Do(func() {
+				if ctx.Err() != nil || err != nil || v.document != doc || v.strip.slots[page] != slot {
+					return
+				}
+				slot.image.Image = rendered
+				slot.image.Refresh()
+			})
+		},
+	)
+}
+
+// clearStrip releases all materialized pages and forgets the layout.
+func (v *Viewer) clearStrip() {
+	for page, slot := range v.strip.slots {
+		slot.cancel()
+		delete(v.strip.slots, page)
+	}
+	v.strip.pages = nil
+	v.stripHolder.Objects = nil
+	v.stripHolder.Refresh()
+}
+
+// viewportHeight returns the visible height of the scroll area, assuming a
+// typical window before the viewer has been laid out.
+func (v *Viewer) viewportHeight() float32 {
+	if h := v.scroll.Size().Height; h > 0 {
+		return h
+	}
+	return 800
+}
+
+// renderScale is the zoom factor pages are rendered at: the zoom, but no
+// less than 100% so small zooms stay sharp, times the pixel density of the
+// screen, rounded up to a render scale step.
+func (v *Viewer) renderScale() float64 {
+	return bucketRenderScale(max(1, v.zoom) * v.deviceScale())
+}
+
+// bucketRenderScale rounds scale up to a multiple of renderScaleStep, at
+// most maxRenderScale.
+func bucketRenderScale(scale float64) float64 {
+	return min(math.Ceil(scale/renderScaleStep)*renderScaleStep, maxRenderScale)
+}
+
+// deviceScale returns the number of screen pixels per canvas unit of the
+// window showing the viewer, or 1 before it is shown.
+func (v *Viewer) deviceScale() float64 {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return 1
+	}
+	if c := app.Driver().CanvasForObject(v.scroll); c != nil && c.Scale() > 0 {
+		return float64(c.Scale())
+	}
+	return 1
+}
+
+// updateRenderScale re-renders the shown pages when zooming has changed the
+// scale they are rendered at. The old images stay up, scaled, until the new
+// ones arrive.
+func (v *Viewer) updateRenderScale() {
+	if v.document == nil {
+		return
+	}
+	scale := v.renderScale()
+	if v.mode.multiPage() {
+		for page, slot := range v.strip.slots {
+			if slot.scale != scale {
+				v.renderSlot(v.document, page, slot, PriorityVisible)
+			}
+		}
+		return
+	}
+	if v.displayedPage != v.currentPage || v.displayedScale == scale {
+		return
+	}
+	if img, ok := v.document.CachedPage(v.currentPage, scale); ok {
+		v.showPage(v.currentPage, scale, img)
+	} else {
+		v.submitPage(v.document, v.currentPage)
+	}
+	v.prefetchAround(v.document, v.currentPage)
+}
+
+// prefetchOrder lists the pages within n of page, nearest first and the
+// following page before the preceding one at each distance.
+func prefetchOrder(page, pageCount, n int) []int {
+	pages := make([]int, 0, 2*n)
+	for d := 1; d <= n; d++ {
+		if next := page + d; next < pageCount {
+			pages = append(pages, next)
+		}
+		if prev := page - d; prev >= 0 {
+			pages = append(pages, prev)
+		}
+	}
+	return pages
+}
+
+func (v *Viewer) cancelRender() {
+	if v.renderCancel != nil {
+		v.renderCancel()
+		v.renderCancel = nil
+	}
+}
+
+// showPage swaps a page rendered at scale into the image.
+func (v *Viewer) showPage(page int, scale float64, img image.Image) {
+	v.displayedPage = page
+	v.displayedScale = scale
+	v.pageImage.Image = img
+	v.pageImage.Refresh()
+	v.applyZoom()
+}
+
+// updatePageSize reads the displayed size of the current page, keeping the
+// previous size (or Letter) if it cannot be determined.
+func (v *Viewer) updatePageSize() {
+	width, height, err := v.document.GetPageSize(v.currentPage)
+	if err == nil && width > 0 && height > 0 {
+		v.pageWidth = float32(width)
+		v.pageHeight = float32(height)
+		return
+	}
+	if v.pageWidth <= 0 || v.pageHeight <= 0 {
+		v.pageWidth, v.pageHeight = 612, 792
+	}
+}
+
+// showPlaceholder displays a blank page with the current page's proportions
+// while the real one renders.
+func (v *Viewer) showPlaceholder() {
+	v.pageImage.Image = placeholderImage(int(v.pageWidth/8), int(v.pageHeight/8))
+	v.pageImage.Refresh()
+}
+
+// placeholderImage returns a flat light page of the given size.
+func placeholderImage(width, height int) image.Image {
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	bg := color.RGBA{245, 245, 245, 255}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, bg)
+		}
+	}
+	return img
+}
+
 func (v *Viewer) updateButtonStates() {
 	if v.prevBtn == nil || v.nextBtn == nil {
 		return
@@ -259,13 +956,13 @@ func (v *Viewer) updateButtonStates() {
 		return
 	}
 
-	if v.currentPage <= 0 {
+	if v.adjacentPage(-1) < 0 {
 		v.prevBtn.Disable()
 	} else {
 		v.prevBtn.Enable()
 	}
 
-	if v.currentPage >= v.document.PageCount()-1 {
+	if v.adjacentPage(1) < 0 {
 		v.nextBtn.Disable()
 	} else {
 		v.nextBtn.Enable()
@@ -274,11 +971,11 @@ func (v *Viewer) updateButtonStates() {
 
 func (v *Viewer) createPageControls() *fyne.Container {
 	v.prevBtn = widget.NewButton("<", func() {
-		v.GoToPage(v.currentPage - 1)
+		v.PreviousPage()
 	})
 
 	v.nextBtn = widget.NewButton(">", func() {
-		v.GoToPage(v.currentPage + 1)
+		v.NextPage()
 	})
 
 	zoomOutBtn := widget.NewButton("-", func() {
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+)
+
+func TestPrefetchOrder(t *testing.T) {
+	tests := []struct {
+		page, count, n int
+		want           []int
+	}{
+		{5, 20, 2, []int{6, 4, 7, 3}},
+		{0, 20, 2, []int{1, 2}},
+		{19, 20, 2, []int{18, 17}},
+		{1, 3, 3, []int{2, 0}},
+		{4, 10, 0, []int{}},
+	}
+
+	for _, tt := range tests {
+		got := prefetchOrder(tt.page, tt.count, tt.n)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("prefetchOrder(%d, %d, %d) = %v, want %v", tt.page, tt.count, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestParseViewMode(t *testing.T) {
+	for _, mode := range []ViewMode{ViewSinglePage, ViewContinuous, ViewTwoPage, ViewBook} {
+		if got := ParseViewMode(mode.String()); got != mode {
+			t.Errorf("ParseViewMode(%q) = %v, want %v", mode.String(), got, mode)
+		}
+	}
+	if got := ParseViewMode("bogus"); got != ViewSinglePage {
+		t.Errorf("ParseViewMode(bogus) = %v, want single page", got)
+	}
+}
+
+func TestPageLabelText(t *testing.T) {
+	if got := pageLabelText(2, 2, 120); got != "Page 3 of 120" {
+		t.Errorf("single page label = %q", got)
+	}
+	if got := pageLabelText(3, 4, 120); got != "Pages 4–5 of 120" {
+		t.Errorf("spread label = %q", got)
+	}
+}
+
+func TestViewerRenderScaleFollowsZoom(t *testing.T) {
+	test.NewTempApp(t)
+	v := NewViewer(nil)
+
+	tests := []struct {
+		zoom, want float64
+	}{
+		// Zooming out keeps rendering at 100%.
+		{0.25, 1},
+		{1, 1},
+		// Zooms round up to the next half step.
+		{1.1, 1.5},
+		{1.5, 1.5},
+		{3.05, 3.5},
+		// 500% is capped at 300 DPI.
+		{5, 300.0 / 72},
+	}
+	for _, tt := range tests {
+		v.zoom = tt.zoom
+		if got := v.renderScale(); got != tt.want {
+			t.Errorf("renderScale() at zoom %v = %v, want %v", tt.zoom, got, tt.want)
+		}
+	}
+}
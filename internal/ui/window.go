@@ -4,7 +4,9 @@ package ui
 import (
 	"errors"
 	"fmt"
+	"image/color"
 	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -13,6 +15,7 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
@@ -28,12 +31,18 @@ type MainWindow struct {
 	tabs         *container.AppTabs
 	viewer       *Viewer
 	toolbar      *Toolbar
+	findBar      *FindBar
 	sidebar      *Sidebar
 	statusBar    *widget.Label
 	document     *pdf.Document
 	selectedText string
 	selectedPage int
 	openTabs     []*DocumentTab
+	scheduler    *RenderScheduler
+	renderCache  *pdf.RenderCache
+	// finishDrawing saves the drawing being made with the pen or polygon
+	// tool; Enter calls it.
+	finishDrawing func()
 }
 
 // DocumentTab represents one open PDF tab.
@@ -58,6 +67,8 @@ func NewMainWindow(fyneApp fyne.App, cfg *config.Config) *MainWindow {
 		config:       cfg,
 		statusBar:    widget.NewLabel("Ready"),
 		selectedPage: -1,
+		scheduler:    NewRenderScheduler(defaultRenderWorkers),
+		renderCache:  pdf.NewRenderCache(int64(cfg.RenderCacheMB) << 20),
 	}
 
 	mw.setupUI()
@@ -82,6 +93,7 @@ func (mw *MainWindow) ShowAndRun() {
 func (mw *MainWindow) setupUI() {
 	// Create toolbar
 	mw.toolbar = NewToolbar(mw)
+	mw.findBar = NewFindBar(mw)
 
 	mw.tabs = container.NewAppTabs()
 	mw.tabs.SetTabLocation(container.TabLocationTop)
@@ -95,12 +107,13 @@ func (mw *MainWindow) setupUI() {
 	}
 
 	// Main layout
+	top := container.NewVBox(mw.toolbar.Container(), mw.findBar.Container())
 	content := container.NewBorder(
-		mw.toolbar.Container(), // top
-		mw.statusBar,           // bottom
-		nil,                    // left
-		nil,                    // right
-		mw.tabs,                // center
+		top,          // top
+		mw.statusBar, // bottom
+		nil,          // left
+		nil,          // right
+		mw.tabs,      // center
 	)
 
 	mw.window.SetContent(content)
@@ -113,6 +126,7 @@ func (mw *MainWindow) setupMenus() {
 		fyne.NewMenuItem("Save", mw.onSave),
 		fyne.NewMenuItem("Save As...", mw.onSaveAs),
 		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Properties...", mw.onProperties),
 		fyne.NewMenuItem("Print...", mw.onPrint),
 		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("Exit", func() { mw.window.Close() }),
@@ -124,6 +138,8 @@ func (mw *MainWindow) setupMenus() {
 		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("Copy", mw.onCopy),
 		fyne.NewMenuItem("Select All", mw.onSelectAll),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Find...", mw.onFind),
 	)
 
 	viewMenu := fyne.NewMenu("View",
@@ -132,6 +148,14 @@ func (mw *MainWindow) setupMenus() {
 		fyne.NewMenuItem("Fit to Page", mw.onFitToPage),
 		fyne.NewMenuItem("Fit to Width", mw.onFitToWidth),
 		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Back", mw.onBack),
+		fyne.NewMenuItem("Forward", mw.onForward),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Single Page", func() { mw.setViewMode(ViewSinglePage) }),
+		fyne.NewMenuItem("Continuous Scroll", func() { mw.setViewMode(ViewContinuous) }),
+		fyne.NewMenuItem("Two-Page Spread", func() { mw.setViewMode(ViewTwoPage) }),
+		fyne.NewMenuItem("Book (Cover Alone)", func() { mw.setViewMode(ViewBook) }),
+		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("Toggle Thumbnails", mw.onToggleThumbnails),
 		fyne.NewMenuItem("Fullscreen", mw.onFullscreen),
 		fyne.NewMenuItemSeparator(),
@@ -150,12 +174,16 @@ func (mw *MainWindow) setupMenus() {
 		fyne.NewMenuItem("Export Pages to Images...", mw.onExportToImages),
 		fyne.NewMenuItem("Export PDF to Text...", mw.onExportToText),
 		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Search in Folder...", mw.onSearchInFolder),
+		fyne.NewMenuItem("OCR Scanned Pages...", mw.onOCR),
+		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("List Form Fields", mw.onListFormFields),
 		fyne.NewMenuItem("Fill Form Fields...", mw.onFillFormFields),
 		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("Add Highlight...", mw.onAddHighlightAnnotation),
 		fyne.NewMenuItem("Add Text Annotation...", mw.onAddTextAnnotation),
 		fyne.NewMenuItem("Add Shape Annotation...", mw.onAddShapeAnnotation),
+		fyne.NewMenuItem("Draw Freehand...", mw.onDrawInk),
 		fyne.NewMenuItem("Add Signature...", mw.onAddSignature),
 		fyne.NewMenuItem("Apply Redaction...", mw.onAddRedaction),
 		fyne.NewMenuItemSeparator(),
@@ -164,6 +192,8 @@ func (mw *MainWindow) setupMenus() {
 	)
 
 	helpMenu := fyne.NewMenu("Help",
+		fyne.NewMenuItem("Render Cache Statistics", mw.onRenderCacheStats),
+		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("About", mw.onAbout),
 	)
 
@@ -178,6 +208,18 @@ func (mw *MainWindow) setupShortcuts() {
 	canvas.AddShortcut(&fyne.ShortcutCopy{}, func(_ fyne.Shortcut) {
 		mw.onCopy()
 	})
+	canvas.AddShortcut(&fyne.ShortcutSelectAll{}, func(_ fyne.Shortcut) {
+		mw.onSelectAll()
+	})
+	canvas.AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyF, Modifier: fyne.KeyModifierShortcutDefault}, func(_ fyne.Shortcut) {
+		mw.onFind()
+	})
+	canvas.AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyLeft, Modifier: fyne.KeyModifierAlt}, func(_ fyne.Shortcut) {
+		mw.onBack()
+	})
+	canvas.AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyRight, Modifier: fyne.KeyModifierAlt}, func(_ fyne.Shortcut) {
+		mw.onForward()
+	})
 
 	// Custom shortcuts using desktop package
 	mw.window.Canvas().SetOnTypedKey(func(ev *fyne.KeyEvent) {
@@ -185,11 +227,11 @@ func (mw *MainWindow) setupShortcuts() {
 		switch ev.Name {
 		case fyne.KeyPageUp:
 			if mw.viewer != nil {
-				mw.viewer.GoToPage(mw.viewer.currentPage - 1)
+				mw.viewer.PreviousPage()
 			}
 		case fyne.KeyPageDown:
 			if mw.viewer != nil {
-				mw.viewer.GoToPage(mw.viewer.currentPage + 1)
+				mw.viewer.NextPage()
 			}
 		case fyne.KeyHome:
 			if mw.viewer != nil {
@@ -201,8 +243,21 @@ func (mw *MainWindow) setupShortcuts() {
 			}
 		case fyne.KeyF11:
 			mw.onFullscreen()
+		case fyne.KeyReturn, fyne.KeyEnter:
+			if mw.finishDrawing != nil {
+				mw.finishDrawing()
+			}
+		case fyne.KeyBackspace:
+			if mw.finishDrawing != nil && mw.viewer != nil {
+				mw.viewer.UndoStroke()
+			}
 		case fyne.KeyEscape:
-			if mw.window.FullScreen() {
+			if mw.cancelTool() {
+				return
+			}
+			if mw.findBar.Visible() {
+				mw.findBar.Hide()
+			} else if mw.window.FullScreen() {
 				mw.window.SetFullScreen(false)
 			}
 		case fyne.KeyPlus, fyne.KeyEqual:
@@ -242,6 +297,34 @@ func (mw *MainWindow) openPasswordProtectedFile(path string) {
 	})
 }
 
+// openFileAtPage shows page (0-indexed) of a PDF, switching to its tab if
+// it is already open.
+func (mw *MainWindow) openFileAtPage(path string, page int) {
+	mw.openFileAtDestination(path, pdf.Destination{Page: page})
+}
+
+// openFileAtDestination shows a destination in a PDF, switching to its tab
+// if it is already open. A destination without a page leaves the view
+// where it is.
+func (mw *MainWindow) openFileAtDestination(path string, dest pdf.Destination) {
+	for _, tab := range mw.openTabs {
+		if filepath.Clean(tab.path) == filepath.Clean(path) {
+			mw.tabs.Select(tab.item)
+			mw.activateTab(tab)
+			tab.viewer.GoToDestination(dest)
+			return
+		}
+	}
+
+	if err := mw.OpenFile(path); err != nil {
+		dialog.ShowError(err, mw.window)
+		return
+	}
+	if tab := mw.currentTab(); tab != nil && filepath.Clean(tab.path) == filepath.Clean(path) {
+		tab.viewer.GoToDestination(dest)
+	}
+}
+
 func (mw *MainWindow) setDocument(doc *pdf.Document, path string) {
 	tab := mw.newDocumentTab(doc, path)
 	mw.openTabs = append(mw.openTabs, tab)
@@ -255,10 +338,25 @@ func (mw *MainWindow) setDocument(doc *pdf.Document, path string) {
 }
 
 func (mw *MainWindow) newDocumentTab(doc *pdf.Document, path string) *DocumentTab {
-	viewer := NewViewer()
+	doc.SetRenderCache(mw.renderCache)
+
+	viewer := NewViewer(mw.scheduler)
+	viewer.SetPrefetchPages(mw.config.PrefetchPages)
+	viewer.SetViewMode(ParseViewMode(mw.config.ViewMode))
 	viewer.SetDocument(doc)
 
-	sidebar := NewSidebar(viewer)
+	viewer.OnExternalLink = mw.onExternalLink
+
+	sidebar := NewSidebar(viewer, mw.scheduler)
+	sidebar.PromptBookmarkTitle = mw.promptBookmarkTitle
+	sidebar.OnBookmarksChanged = mw.onBookmarksChanged
+	sidebar.ChooseAttachmentFile = mw.chooseAttachmentFile
+	sidebar.OnSaveAttachment = mw.onSaveAttachment
+	sidebar.OnOpenAttachment = mw.onOpenAttachment
+	sidebar.OnAttachmentsChanged = mw.onAttachmentsChanged
+	sidebar.OnEditAnnotation = mw.onEditAnnotation
+	sidebar.OnMoveAnnotation = mw.onMoveAnnotation
+	sidebar.OnRemoveAnnotation = mw.onRemoveAnnotation
 	sidebar.SetDocument(doc)
 
 	split := container.NewHSplit(
@@ -270,7 +368,7 @@ func (mw *MainWindow) newDocumentTab(doc *pdf.Document, path string) *DocumentTa
 	title := tabTitleForPath(path)
 	item := container.NewTabItem(title, split)
 
-	return &DocumentTab{
+	tab := &DocumentTab{
 		item:         item,
 		path:         path,
 		document:     doc,
@@ -280,6 +378,167 @@ func (mw *MainWindow) newDocumentTab(doc *pdf.Document, path string) *DocumentTa
 		selectedPage: -1,
 		undo:         newUndoManager(20),
 	}
+	viewer.OnSelectionChanged = func(page int, text string) {
+		tab.selectedText = text
+		tab.selectedPage = page
+		if mw.currentTab() == tab {
+			mw.selectedText = text
+			mw.selectedPage = page
+		}
+	}
+	viewer.SelectionMenu = mw.selectionMenu
+	return tab
+}
+
+// onExternalLink opens the file of a GoToR link in a tab, or a URI in the
+// browser once the user confirms it.
+func (mw *MainWindow) onExternalLink(link pdf.Link) {
+	switch link.Kind {
+	case pdf.LinkGoToR:
+		mw.openFileAtDestination(link.File, link.Dest)
+	case pdf.LinkURI:
+		target, err := url.Parse(link.URI)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("invalid link %q: %w", link.URI, err), mw.window)
+			return
+		}
+		dialog.ShowConfirm("Open Link", "Open this link in your browser?\n\n"+link.URI, func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := fyne.CurrentApp().OpenURL(target); err != nil {
+				dialog.ShowError(err, mw.window)
+			}
+		}, mw.window)
+	}
+}
+
+func (mw *MainWindow) onBack() {
+	if mw.viewer != nil {
+		mw.viewer.Back()
+	}
+}
+
+func (mw *MainWindow) onForward() {
+	if mw.viewer != nil {
+		mw.viewer.Forward()
+	}
+}
+
+// promptBookmarkTitle asks for the title of a new or renamed bookmark.
+func (mw *MainWindow) promptBookmarkTitle(title string, done func(string)) {
+	titleEntry := widget.NewEntry()
+	titleEntry.SetText(title)
+
+	form := dialog.NewForm(
+		"Bookmark",
+		"OK",
+		"Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Title", titleEntry)},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			title := strings.TrimSpace(titleEntry.Text)
+			if title == "" {
+				dialog.ShowError(errors.New("enter a bookmark title"), mw.window)
+				return
+			}
+			done(title)
+		},
+		mw.window,
+	)
+	form.Resize(fyne.NewSize(400, 160))
+	form.Show()
+	mw.window.Canvas().Focus(titleEntry)
+}
+
+func (mw *MainWindow) onBookmarksChanged(err error) {
+	if err != nil {
+		dialog.ShowError(err, mw.window)
+		return
+	}
+	mw.statusBar.SetText("Bookmarks changed; save the document to keep them")
+}
+
+// chooseAttachmentFile asks for a file to embed in the document.
+func (mw *MainWindow) chooseAttachmentFile(done func(path string)) {
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil || reader == nil {
+			return
+		}
+		reader.Close()
+		done(reader.URI().Path())
+	}, mw.window)
+}
+
+// onSaveAttachment writes an attachment to a file chosen by the user.
+func (mw *MainWindow) onSaveAttachment(a pdf.Attachment) {
+	if mw.document == nil {
+		return
+	}
+	data, err := mw.document.AttachmentData(a.Name)
+	if err != nil {
+		dialog.ShowError(err, mw.window)
+		return
+	}
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil || writer == nil {
+			return
+		}
+		_, err = writer.Write(data)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		mw.statusBar.SetText("Saved attachment: " + writer.URI().Path())
+	}, mw.window)
+	save.SetFileName(a.BaseName())
+	save.Show()
+}
+
+// onOpenAttachment copies an attachment to a temporary file and opens it
+// with the default application once the user confirms it.
+func (mw *MainWindow) onOpenAttachment(a pdf.Attachment) {
+	if mw.document == nil {
+		return
+	}
+	doc := mw.document
+	name := a.BaseName()
+	dialog.ShowConfirm("Open Attachment", "Open "+name+" with the default application?\n\nEmbedded files can contain harmful content.", func(ok bool) {
+		if !ok {
+			return
+		}
+		data, err := doc.AttachmentData(a.Name)
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		dir, err := os.MkdirTemp("", "openpdfreader-attachment-")
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		if err := fyne.CurrentApp().OpenURL(&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}); err != nil {
+			dialog.ShowError(err, mw.window)
+		}
+	}, mw.window)
+}
+
+func (mw *MainWindow) onAttachmentsChanged(err error) {
+	if err != nil {
+		dialog.ShowError(err, mw.window)
+		return
+	}
+	mw.statusBar.SetText("Attachments changed; save the document to keep them")
 }
 
 func (mw *MainWindow) findTabByItem(item *container.TabItem) *DocumentTab {
@@ -307,15 +566,6 @@ func (mw *MainWindow) activateTab(tab *DocumentTab) {
 	mw.window.SetTitle("OpenPDF Reader - " + tab.path)
 }
 
-func (mw *MainWindow) syncSelectionToCurrentTab() {
-	tab := mw.currentTab()
-	if tab == nil {
-		return
-	}
-	tab.selectedText = mw.selectedText
-	tab.selectedPage = mw.selectedPage
-}
-
 func (mw *MainWindow) updateCurrentTabPath(path string) {
 	tab := mw.currentTab()
 	if tab == nil {
@@ -398,6 +648,57 @@ func (mw *MainWindow) reloadCurrentDocumentAtPage(page int) error {
 	return nil
 }
 
+// unsavedChangesMessage explains what is lost when a document with changes
+// kept only in memory is reloaded from its file.
+const unsavedChangesMessage = "This document has unsaved changes to its properties, outline or attachments."
+
+// saveChangesFirst runs next, which rewrites the document file and reloads
+// it, once the changes kept only in memory are saved or the user chooses to
+// discard them; reloading would otherwise drop them without asking.
+func (mw *MainWindow) saveChangesFirst(doc *pdf.Document, next func()) {
+	if doc == nil || !doc.IsModified() {
+		next()
+		return
+	}
+
+	dlg := dialog.NewCustomWithoutButtons("Unsaved Changes",
+		widget.NewLabel(unsavedChangesMessage+"\nSave them first, or discard them?"), mw.window)
+	saveBtn := widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), func() {
+		dlg.Hide()
+		if err := doc.Save(); err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		next()
+	})
+	saveBtn.Importance = widget.HighImportance
+	dlg.SetButtons([]fyne.CanvasObject{
+		widget.NewButton("Cancel", dlg.Hide),
+		widget.NewButton("Discard", func() {
+			dlg.Hide()
+			next()
+		}),
+		saveBtn,
+	})
+	dlg.Show()
+}
+
+// discardChangesFirst runs next, which restores an earlier copy of the
+// document file, once the user agrees to discard the changes kept only in
+// memory. Saving them first would not help, as the restored copy predates
+// them.
+func (mw *MainWindow) discardChangesFirst(doc *pdf.Document, title string, next func()) {
+	if doc == nil || !doc.IsModified() {
+		next()
+		return
+	}
+	dialog.ShowConfirm(title, unsavedChangesMessage+"\n"+title+" anyway and discard them?", func(ok bool) {
+		if ok {
+			next()
+		}
+	}, mw.window)
+}
+
 func copyFile(srcPath, dstPath string) (err error) {
 	src, err := os.Open(srcPath)
 	if err != nil {
@@ -471,6 +772,26 @@ func (mw *MainWindow) onSaveAs() {
 	}, mw.window)
 }
 
+func (mw *MainWindow) onProperties() {
+	if mw.document == nil {
+		dialog.ShowInformation("No Document", "Open a PDF file first", mw.window)
+		return
+	}
+	doc := mw.document
+	info, err := doc.Info()
+	if err != nil {
+		dialog.ShowError(err, mw.window)
+		return
+	}
+	dialogs.NewPropertiesDialog(mw.window, doc.Path(), info, func(info pdf.Info) {
+		if err := doc.SetInfo(info); err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		mw.statusBar.SetText("Properties changed; save the document to keep them")
+	}).Show()
+}
+
 func (mw *MainWindow) onPrint() {
 	if mw.document == nil {
 		dialog.ShowInformation("No Document", "Open a PDF file first", mw.window)
@@ -502,7 +823,11 @@ func (mw *MainWindow) onUndo() {
 		dialog.ShowInformation("Undo", "Nothing to undo", mw.window)
 		return
 	}
+	mw.discardChangesFirst(mw.document, "Undo", func() { mw.undo(manager) })
+}
 
+// undo restores the file from the undo stack of manager and reloads it.
+func (mw *MainWindow) undo(manager *undoManager) {
 	page := mw.viewer.CurrentPage()
 	currentSnapshot, err := mw.createSnapshot(mw.document.Path())
 	if err != nil {
@@ -544,7 +869,11 @@ func (mw *MainWindow) onRedo() {
 		dialog.ShowInformation("Redo", "Nothing to redo", mw.window)
 		return
 	}
+	mw.discardChangesFirst(mw.document, "Redo", func() { mw.redo(manager) })
+}
 
+// redo restores the file from the redo stack of manager and reloads it.
+func (mw *MainWindow) redo(manager *undoManager) {
 	page := mw.viewer.CurrentPage()
 	currentSnapshot, err := mw.createSnapshot(mw.document.Path())
 	if err != nil {
@@ -580,18 +909,16 @@ func (mw *MainWindow) onCopy() {
 		return
 	}
 
-	currentPage := mw.viewer.CurrentPage()
-	selectedText := strings.TrimSpace(mw.selectedText)
-	if mw.selectedPage != currentPage || selectedText == "" {
-		text, err := mw.document.ExtractText(currentPage)
+	// Without a selection, copy the whole current page.
+	page, selectedText := mw.selectedPage, mw.selectedText
+	if strings.TrimSpace(selectedText) == "" {
+		page = mw.viewer.CurrentPage()
+		text, err := mw.document.ExtractText(page)
 		if err != nil {
 			dialog.ShowError(err, mw.window)
 			return
 		}
 		selectedText = strings.TrimSpace(text)
-		mw.selectedText = selectedText
-		mw.selectedPage = currentPage
-		mw.syncSelectionToCurrentTab()
 	}
 
 	if selectedText == "" {
@@ -600,7 +927,7 @@ func (mw *MainWindow) onCopy() {
 	}
 
 	mw.window.Clipboard().SetContent(selectedText)
-	mw.statusBar.SetText(fmt.Sprintf("Copied text from page %d", currentPage+1))
+	mw.statusBar.SetText(fmt.Sprintf("Copied text from page %d", page+1))
 }
 
 func (mw *MainWindow) onSelectAll() {
@@ -609,26 +936,19 @@ func (mw *MainWindow) onSelectAll() {
 		return
 	}
 
-	currentPage := mw.viewer.CurrentPage()
-	text, err := mw.document.ExtractText(currentPage)
-	if err != nil {
-		dialog.ShowError(err, mw.window)
+	if mw.viewer.SelectAll() == "" {
+		dialog.ShowInformation("No Text", "No selectable text found on this page", mw.window)
 		return
 	}
+	mw.statusBar.SetText(fmt.Sprintf("Selected all text on page %d", mw.viewer.CurrentPage()+1))
+}
 
-	selectedText := strings.TrimSpace(text)
-	if selectedText == "" {
-		mw.selectedText = ""
-		mw.selectedPage = -1
-		mw.syncSelectionToCurrentTab()
-		dialog.ShowInformation("No Text", "No selectable text found on this page", mw.window)
+func (mw *MainWindow) onFind() {
+	if mw.document == nil {
+		dialog.ShowInformation("No Document", "Open a PDF file first", mw.window)
 		return
 	}
-
-	mw.selectedText = selectedText
-	mw.selectedPage = currentPage
-	mw.syncSelectionToCurrentTab()
-	mw.statusBar.SetText(fmt.Sprintf("Selected all text on page %d", currentPage+1))
+	mw.findBar.Show()
 }
 
 func (mw *MainWindow) onZoomIn() {
@@ -676,6 +996,31 @@ func (mw *MainWindow) setTheme(themeName string) {
 	_ = mw.config.Save()
 }
 
+// viewModeTitle returns the menu name of a view mode.
+func viewModeTitle(mode ViewMode) string {
+	switch mode {
+	case ViewContinuous:
+		return "Continuous Scroll"
+	case ViewTwoPage:
+		return "Two-Page Spread"
+	case ViewBook:
+		return "Book"
+	default:
+		return "Single Page"
+	}
+}
+
+// setViewMode switches the page layout of every open document and
+// remembers it for new ones.
+func (mw *MainWindow) setViewMode(mode ViewMode) {
+	for _, tab := range mw.openTabs {
+		tab.viewer.SetViewMode(mode)
+	}
+	mw.config.ViewMode = mode.String()
+	mw.statusBar.SetText("View mode set to " + viewModeTitle(mode))
+	_ = mw.config.Save()
+}
+
 func (mw *MainWindow) onMergePDFs() {
 	dlg := dialogs.NewMergeDialog(mw.window, func(files []string, output string) {
 		// Optionally open the merged file
@@ -706,7 +1051,10 @@ func (mw *MainWindow) onRotatePages() {
 		dialog.ShowInformation("No Active View", "Select a document tab first", mw.window)
 		return
 	}
+	mw.saveChangesFirst(mw.document, mw.showRotatePages)
+}
 
+func (mw *MainWindow) showRotatePages() {
 	pagesEntry := widget.NewEntry()
 	pagesEntry.SetText(fmt.Sprintf("%d", mw.viewer.CurrentPage()+1))
 	pagesEntry.SetPlaceHolder("e.g. 1,3,5-7 or all")
@@ -897,6 +1245,91 @@ func (mw *MainWindow) onExportToText() {
 	}, mw.window)
 }
 
+func (mw *MainWindow) onSearchInFolder() {
+	folder := ""
+	if mw.document != nil {
+		folder = filepath.Dir(mw.document.Path())
+	}
+	dialogs.NewFolderSearchDialog(mw.window, folder, mw.openFileAtPage).Show()
+}
+
+func (mw *MainWindow) onOCR() {
+	tab := mw.currentTab()
+	if tab == nil || tab.document == nil {
+		dialog.ShowInformation("No Document", "Open a PDF file first", mw.window)
+		return
+	}
+	engine := pdf.NewTesseractEngine()
+	if !engine.Available() {
+		dialog.ShowInformation("OCR Unavailable", "Install tesseract to recognize text on scanned pages", mw.window)
+		return
+	}
+
+	mw.saveChangesFirst(tab.document, func() { mw.showOCR(tab, engine) })
+}
+
+// showOCR recognizes the text of the document of tab and adds it to the file.
+func (mw *MainWindow) showOCR(tab *DocumentTab, engine pdf.OCREngine) {
+	doc := tab.document
+	page := tab.viewer.CurrentPage()
+	var outputPath string
+
+	run := func(lang string, progress func(done, total int)) (pdf.OCRResult, error) {
+		tmp, err := os.CreateTemp("", "openpdfreader-ocr-*.pdf")
+		if err != nil {
+			return pdf.OCRResult{}, err
+		}
+		outputPath = tmp.Name()
+		if err := tmp.Close(); err != nil {
+			return pdf.OCRResult{}, err
+		}
+		userPassword, ownerPassword := doc.Passwords()
+		return pdf.NewOCRProcessor(engine).Process(doc.Path(), outputPath, pdf.OCROptions{
+			Language:      lang,
+			UserPassword:  userPassword,
+			OwnerPassword: ownerPassword,
+			Progress:      progress,
+		})
+	}
+
+	dialogs.NewOCRDialog(mw.window, pdf.DefaultOCRLanguage, run, func(result pdf.OCRResult, err error) {
+		if outputPath != "" {
+			defer os.Remove(outputPath)
+		}
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		summary := dialogs.OCRSummary(result)
+		if result.Words == 0 {
+			dialog.ShowInformation("OCR Complete", summary, mw.window)
+			return
+		}
+
+		snapshotPath, err := mw.createSnapshot(doc.Path())
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		if err := copyFile(outputPath, doc.Path()); err != nil {
+			_ = os.Remove(snapshotPath)
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		if err := doc.Reload(); err != nil {
+			_ = os.Remove(snapshotPath)
+			dialog.ShowError(err, mw.window)
+			return
+		}
+
+		tab.undo.pushUndo(snapshotPath)
+		tab.viewer.SetDocument(doc)
+		tab.sidebar.SetDocument(doc)
+		tab.viewer.GoToPage(page)
+		mw.statusBar.SetText(summary)
+	}).Show()
+}
+
 func (mw *MainWindow) onListFormFields() {
 	if mw.document == nil {
 		dialog.ShowInformation("No Document", "Open a PDF file first", mw.window)
@@ -952,7 +1385,10 @@ func (mw *MainWindow) onFillFormFields() {
 		dialog.ShowInformation("No Document", "Open a PDF file first", mw.window)
 		return
 	}
+	mw.saveChangesFirst(mw.document, mw.showFillFormFields)
+}
 
+func (mw *MainWindow) showFillFormFields() {
 	fields, err := pdf.NewFormManager().ListFields(mw.document.Path())
 	if err != nil {
 		dialog.ShowError(err, mw.window)
@@ -1018,21 +1454,353 @@ func (mw *MainWindow) onFillFormFields() {
 }
 
 func (mw *MainWindow) onAddHighlightAnnotation() {
-	mw.promptAnnotationContents("Add Highlight", "Highlight content", "Highlight", func(contents string) error {
-		return pdf.NewAnnotator().AddHighlight(mw.document.Path(), "", mw.viewer.CurrentPage(), contents)
+	mw.drawAnnotationRect("highlight", func(page int, rect pdf.Rect) {
+		mw.promptAnnotationContents("Add Highlight", "Highlight content", "Highlight", page, func(contents string) error {
+			return pdf.NewAnnotator().AddHighlight(mw.document.Path(), "", page, rect, contents)
+		})
 	})
 }
 
 func (mw *MainWindow) onAddTextAnnotation() {
-	mw.promptAnnotationContents("Add Text Annotation", "Text note", "Note", func(contents string) error {
-		return pdf.NewAnnotator().AddText(mw.document.Path(), "", mw.viewer.CurrentPage(), contents)
+	mw.drawAnnotationRect("note", func(page int, rect pdf.Rect) {
+		mw.promptAnnotationContents("Add Text Annotation", "Text note", "Note", page, func(contents string) error {
+			return pdf.NewAnnotator().AddText(mw.document.Path(), "", page, rect, contents)
+		})
 	})
 }
 
+// shapeKinds are the shapes Add Shape Annotation offers.
+var shapeKinds = []string{"Rectangle", "Ellipse", "Line", "Arrow", "Double Arrow", "Polygon", "Polyline"}
+
+// onAddShapeAnnotation asks for the kind and style of a shape, then lets
+// the user draw it on a page.
 func (mw *MainWindow) onAddShapeAnnotation() {
-	mw.promptAnnotationContents("Add Shape Annotation", "Shape label", "Shape", func(contents string) error {
-		return pdf.NewAnnotator().AddShape(mw.document.Path(), "", mw.viewer.CurrentPage(), contents)
-	})
+	if mw.document == nil {
+		dialog.ShowInformation("No Document", "Open a PDF file first", mw.window)
+		return
+	}
+	if mw.viewer == nil {
+		dialog.ShowInformation("No Active View", "Select a document tab first", mw.window)
+		return
+	}
+
+	kind := widget.NewSelect(shapeKinds, nil)
+	kind.SetSelectedIndex(0)
+	outline := widget.NewSelect(colorOptions("Black"), nil)
+	outline.SetSelected("Red")
+	fill := widget.NewSelect(colorOptions("None"), nil)
+	fill.SetSelectedIndex(0)
+	border := widget.NewSelect([]string{"Solid", "Dashed", "Dotted"}, nil)
+	border.SetSelectedIndex(0)
+	width, widthField := labelledSlider(1, 12, 1, "%.0f pt")
+	opacity, opacityField := labelledSlider(10, 100, 100, "%.0f%%")
+	opacity.Step = 5
+	note := widget.NewMultiLineEntry()
+	note.SetPlaceHolder("Optional comment")
+
+	form := dialog.NewForm(
+		"Add Shape Annotation",
+		"Draw",
+		"Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Shape", kind),
+			widget.NewFormItem("Outline", outline),
+			widget.NewFormItem("Fill", fill),
+			widget.NewFormItem("Border", border),
+			widget.NewFormItem("Width", widthField),
+			widget.NewFormItem("Opacity", opacityField),
+			widget.NewFormItem("Note", note),
+		},
+		func(ok bool) {
+			if !ok || mw.viewer == nil {
+				return
+			}
+			style := pdf.ShapeStyle{
+				Color:   color.NRGBA{A: 255},
+				Width:   width.Value,
+				Opacity: opacity.Value / 100,
+			}
+			if i := outline.SelectedIndex(); i > 0 {
+				style.Color = markupColors[i-1].color
+			}
+			if i := fill.SelectedIndex(); i > 0 {
+				style.Interior = markupColors[i-1].color
+			}
+			switch border.Selected {
+			case "Dashed":
+				style.Dash = []float64{3 * style.Width, 2 * style.Width}
+			case "Dotted":
+				style.Dash = []float64{style.Width, style.Width}
+			}
+			mw.drawShape(kind.Selected, style, strings.TrimSpace(note.Text))
+		},
+		mw.window,
+	)
+	form.Resize(fyne.NewSize(460, 420))
+	form.Show()
+}
+
+// drawShape lets the user draw a shape of kind on a page and adds it as
+// an annotation.
+func (mw *MainWindow) drawShape(kind string, style pdf.ShapeStyle, contents string) {
+	annotator := pdf.NewAnnotator()
+	apply := func(page int, add func(path string) error) {
+		mw.applyAnnotation(page, fmt.Sprintf("%s added on page %d", kind, page+1), func() error {
+			return add(mw.document.Path())
+		})
+	}
+	name := strings.ToLower(kind)
+	mw.viewer.SetPen(style.Color, style.Width)
+
+	switch kind {
+	case "Rectangle", "Ellipse":
+		mw.drawAnnotationRect(name, func(page int, rect pdf.Rect) {
+			apply(page, func(path string) error {
+				if kind == "Ellipse" {
+					return annotator.AddCircle(path, "", page, rect, style, contents)
+				}
+				return annotator.AddSquare(path, "", page, rect, style, contents)
+			})
+		})
+	case "Line", "Arrow", "Double Arrow":
+		if kind != "Line" {
+			style.LineEnd = pdf.LineEndingOpenArrow
+		}
+		if kind == "Double Arrow" {
+			style.LineStart = pdf.LineEndingOpenArrow
+		}
+		viewer := mw.viewer
+		mw.finishDrawing = nil
+		viewer.OnLineDrawn = func(page int, from, to pdf.Point) {
+			viewer.OnLineDrawn = nil
+			viewer.SetTool(ToolSelect)
+			apply(page, func(path string) error {
+				return annotator.AddLine(path, "", page, from, to, style, contents)
+			})
+		}
+		viewer.SetTool(ToolLine)
+		mw.statusBar.SetText(fmt.Sprintf("Drag on the page to draw the %s; press Escape to cancel", name))
+	case "Polygon", "Polyline":
+		mw.startDrawing(ToolPolygon, fmt.Sprintf("Click the corners of the %s; press Enter to finish, Backspace to remove a corner, Escape to cancel", name),
+			func(page int, strokes [][]pdf.Point) {
+				apply(page, func(path string) error {
+					if kind == "Polygon" {
+						return annotator.AddPolygon(path, "", page, strokes[0], style, contents)
+					}
+					return annotator.AddPolyLine(path, "", page, strokes[0], style, contents)
+				})
+			})
+	}
+}
+
+// onDrawInk asks for the colour, width and opacity of the pen, then lets
+// the user draw on a page until Enter saves the drawing as an ink
+// annotation.
+func (mw *MainWindow) onDrawInk() {
+	if mw.document == nil {
+		dialog.ShowInformation("No Document", "Open a PDF file first", mw.window)
+		return
+	}
+	if mw.viewer == nil {
+		dialog.ShowInformation("No Active View", "Select a document tab first", mw.window)
+		return
+	}
+
+	colorSelect := widget.NewSelect(colorOptions("Black"), nil)
+	colorSelect.SetSelectedIndex(0)
+	width, widthField := labelledSlider(1, 12, 2, "%.0f pt")
+	opacity, opacityField := labelledSlider(10, 100, 100, "%.0f%%")
+	opacity.Step = 5
+
+	form := dialog.NewForm(
+		"Draw Freehand",
+		"Draw",
+		"Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Colour", colorSelect),
+			widget.NewFormItem("Width", widthField),
+			widget.NewFormItem("Opacity", opacityField),
+		},
+		func(ok bool) {
+			if !ok || mw.viewer == nil {
+				return
+			}
+			style := pdf.InkStyle{Width: width.Value, Opacity: opacity.Value / 100}
+			if i := colorSelect.SelectedIndex(); i > 0 {
+				style.Color = markupColors[i-1].color
+			}
+			mw.viewer.SetPen(style.Color, style.Width)
+			mw.startDrawing(ToolPen, "Draw on the page; press Enter to save the drawing, Backspace to undo a stroke, Escape to cancel",
+				func(page int, strokes [][]pdf.Point) {
+					mw.applyAnnotation(page, fmt.Sprintf("Drawing added on page %d", page+1), func() error {
+						return pdf.NewAnnotator().AddInk(mw.document.Path(), "", page, strokes, style, "")
+					})
+				})
+		},
+		mw.window,
+	)
+	form.Resize(fyne.NewSize(420, 240))
+	form.Show()
+}
+
+// startDrawing switches the viewer to the pen or polygon tool and calls
+// done with what is drawn, in PDF user space, once Enter finishes the
+// drawing.
+func (mw *MainWindow) startDrawing(tool Tool, status string, done func(page int, strokes [][]pdf.Point)) {
+	viewer := mw.viewer
+	mw.finishDrawing = func() {
+		mw.finishDrawing = nil
+		page, strokes, ok := viewer.TakeInk()
+		viewer.SetTool(ToolSelect)
+		if !ok {
+			mw.statusBar.SetText("Nothing was drawn")
+			return
+		}
+		done(page, strokes)
+	}
+	viewer.SetTool(tool)
+	mw.statusBar.SetText(status)
+}
+
+// colorOptions lists first, which stands for no or the default colour,
+// and then the names of markupColors.
+func colorOptions(first string) []string {
+	names := []string{first}
+	for _, c := range markupColors {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// labelledSlider returns a slider and a form field showing it with its
+// value, formatted with format.
+func labelledSlider(from, to, value float64, format string) (*widget.Slider, fyne.CanvasObject) {
+	label := widget.NewLabel("")
+	slider := widget.NewSlider(from, to)
+	slider.OnChanged = func(value float64) {
+		label.SetText(fmt.Sprintf(format, value))
+	}
+	slider.SetValue(value)
+	label.SetText(fmt.Sprintf(format, value))
+	return slider, container.NewBorder(nil, nil, nil, label, slider)
+}
+
+// selectionMenu is the menu of right-clicked text: copying it and marking
+// it up.
+func (mw *MainWindow) selectionMenu() *fyne.Menu {
+	markup := func(kind pdf.MarkupKind) func() {
+		return func() { mw.onMarkupSelection(kind) }
+	}
+	return fyne.NewMenu("",
+		fyne.NewMenuItem("Copy", mw.onCopy),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Highlight", markup(pdf.MarkupHighlight)),
+		fyne.NewMenuItem("Underline", markup(pdf.MarkupUnderline)),
+		fyne.NewMenuItem("Strikeout", markup(pdf.MarkupStrikeOut)),
+		fyne.NewMenuItem("Squiggly Underline", markup(pdf.MarkupSquiggly)),
+	)
+}
+
+// markupColors are the colours offered for text markup, after the default
+// colour of the kind.
+var markupColors = []struct {
+	name  string
+	color color.NRGBA
+}{
+	{"Yellow", color.NRGBA{R: 255, G: 230, B: 0, A: 255}},
+	{"Green", color.NRGBA{R: 0, G: 170, B: 0, A: 255}},
+	{"Blue", color.NRGBA{R: 0, G: 102, B: 255, A: 255}},
+	{"Red", color.NRGBA{R: 230, G: 0, B: 0, A: 255}},
+	{"Pink", color.NRGBA{R: 255, G: 105, B: 180, A: 255}},
+	{"Orange", color.NRGBA{R: 255, G: 140, B: 0, A: 255}},
+}
+
+// onMarkupSelection asks for the colour, opacity and note of a markup of
+// the selected text, then adds it.
+func (mw *MainWindow) onMarkupSelection(kind pdf.MarkupKind) {
+	if mw.document == nil || mw.viewer == nil {
+		dialog.ShowInformation("No Document", "Open a PDF file first", mw.window)
+		return
+	}
+	page, lines := mw.viewer.SelectedPage(), mw.viewer.SelectionLines()
+	if page < 0 || len(lines) == 0 {
+		dialog.ShowInformation("No Selection", "Select text on the page first", mw.window)
+		return
+	}
+
+	colorSelect := widget.NewSelect(colorOptions("Default"), nil)
+	colorSelect.SetSelectedIndex(0)
+
+	opacity, opacityField := labelledSlider(10, 100, 100, "%.0f%%")
+	opacity.Step = 5
+
+	note := widget.NewMultiLineEntry()
+	note.SetPlaceHolder("Optional comment")
+
+	title := "Add " + kind.String()
+	form := dialog.NewForm(
+		title,
+		"Apply",
+		"Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Colour", colorSelect),
+			widget.NewFormItem("Opacity", opacityField),
+			widget.NewFormItem("Note", note),
+		},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			style := pdf.MarkupStyle{Opacity: opacity.Value / 100}
+			if i := colorSelect.SelectedIndex(); i > 0 {
+				style.Color = markupColors[i-1].color
+			}
+			contents := strings.TrimSpace(note.Text)
+			mw.applyAnnotation(page, fmt.Sprintf("%s added on page %d", title, page+1), func() error {
+				return pdf.NewAnnotator().AddTextMarkup(mw.document.Path(), "", page, kind, lines, style, contents)
+			})
+		},
+		mw.window,
+	)
+	form.Resize(fyne.NewSize(460, 260))
+	form.Show()
+}
+
+// drawAnnotationRect switches the viewer to the rectangle tool and calls
+// done with the box the user drags on a page, in PDF user space. Escape
+// cancels.
+func (mw *MainWindow) drawAnnotationRect(what string, done func(page int, rect pdf.Rect)) {
+	if mw.document == nil {
+		dialog.ShowInformation("No Document", "Open a PDF file first", mw.window)
+		return
+	}
+	if mw.viewer == nil {
+		dialog.ShowInformation("No Active View", "Select a document tab first", mw.window)
+		return
+	}
+
+	viewer := mw.viewer
+	mw.finishDrawing = nil
+	viewer.OnRectDrawn = func(page int, rect pdf.Rect) {
+		viewer.OnRectDrawn = nil
+		viewer.SetTool(ToolSelect)
+		done(page, rect)
+	}
+	viewer.SetTool(ToolRect)
+	mw.statusBar.SetText(fmt.Sprintf("Drag a box on the page to place the %s; press Escape to cancel", what))
+}
+
+// cancelTool returns the viewer to text selection, abandoning a drawing.
+func (mw *MainWindow) cancelTool() bool {
+	if mw.viewer == nil || mw.viewer.Tool() == ToolSelect {
+		return false
+	}
+	mw.viewer.OnRectDrawn = nil
+	mw.viewer.OnLineDrawn = nil
+	mw.finishDrawing = nil
+	mw.viewer.SetTool(ToolSelect)
+	mw.statusBar.SetText("Cancelled")
+	return true
 }
 
 func (mw *MainWindow) onAddSignature() {
@@ -1044,7 +1812,10 @@ func (mw *MainWindow) onAddSignature() {
 		dialog.ShowInformation("No Active View", "Select a document tab first", mw.window)
 		return
 	}
+	mw.saveChangesFirst(mw.document, mw.showSignaturePad)
+}
 
+func (mw *MainWindow) showSignaturePad() {
 	dialogs.ShowSignaturePadDialog(mw.window, func(signaturePNG []byte) error {
 		page := mw.viewer.CurrentPage()
 		snapshotPath, err := mw.prepareUndoSnapshot()
@@ -1074,25 +1845,10 @@ func (mw *MainWindow) onAddSignature() {
 }
 
 func (mw *MainWindow) onAddRedaction() {
-	mw.promptAnnotationContents("Apply Redaction", "Reason", "Redacted", func(contents string) error {
-		if mw.document == nil || mw.viewer == nil {
-			return errors.New("open a PDF file first")
-		}
-
-		page := mw.viewer.CurrentPage()
-		redactor := pdf.NewRedactor()
-		if err := redactor.ApplyVisualRedaction(mw.document.Path(), "", page, contents); err != nil {
-			return err
-		}
-		if err := mw.document.Reload(); err != nil {
-			return err
-		}
-
-		mw.viewer.SetDocument(mw.document)
-		mw.sidebar.SetDocument(mw.document)
-		mw.viewer.GoToPage(page)
-		mw.statusBar.SetText(fmt.Sprintf("Redaction applied on page %d", page+1))
-		return nil
+	mw.drawAnnotationRect("redaction", func(page int, rect pdf.Rect) {
+		mw.promptAnnotationContents("Apply Redaction", "Reason", "Redacted", page, func(contents string) error {
+			return pdf.NewRedactor().ApplyVisualRedaction(mw.document.Path(), "", page, rect, contents)
+		})
 	})
 }
 
@@ -1100,6 +1856,7 @@ func (mw *MainWindow) promptAnnotationContents(
 	title string,
 	fieldLabel string,
 	defaultValue string,
+	page int,
 	apply func(contents string) error,
 ) {
 	if mw.document == nil {
@@ -1126,37 +1883,125 @@ func (mw *MainWindow) promptAnnotationContents(
 			if contents == "" {
 				contents = defaultValue
 			}
+			mw.applyAnnotation(page, fmt.Sprintf("%s added on page %d", title, page+1), func() error {
+				return apply(contents)
+			})
+		},
+		mw.window,
+	)
+	form.Resize(fyne.NewSize(460, 220))
+	form.Show()
+}
 
-			page := mw.viewer.CurrentPage()
-			snapshotPath, err := mw.prepareUndoSnapshot()
-			if err != nil {
-				dialog.ShowError(err, mw.window)
-				return
-			}
+// applyAnnotation runs apply, which changes the annotations of page in the
+// document file, keeping an undo snapshot, then shows the page and status.
+func (mw *MainWindow) applyAnnotation(page int, status string, apply func() error) {
+	mw.saveChangesFirst(mw.document, func() { mw.writeAnnotation(page, status, apply) })
+}
 
-			if err := apply(contents); err != nil {
-				_ = os.Remove(snapshotPath)
-				dialog.ShowError(err, mw.window)
+func (mw *MainWindow) writeAnnotation(page int, status string, apply func() error) {
+	snapshotPath, err := mw.prepareUndoSnapshot()
+	if err != nil {
+		dialog.ShowError(err, mw.window)
+		return
+	}
+
+	if err := apply(); err != nil {
+		_ = os.Remove(snapshotPath)
+		dialog.ShowError(err, mw.window)
+		return
+	}
+	if err := mw.document.Reload(); err != nil {
+		_ = os.Remove(snapshotPath)
+		dialog.ShowError(err, mw.window)
+		return
+	}
+
+	if undo := mw.currentUndoManager(); undo != nil {
+		undo.pushUndo(snapshotPath)
+	}
+	mw.viewer.SetDocument(mw.document)
+	mw.sidebar.SetDocument(mw.document)
+	mw.viewer.GoToPage(page)
+	mw.statusBar.SetText(status)
+}
+
+// onEditAnnotation asks for a new text and colour of an annotation.
+func (mw *MainWindow) onEditAnnotation(a pdf.Annotation) {
+	if mw.document == nil {
+		dialog.ShowInformation("No Document", "Open a PDF file first", mw.window)
+		return
+	}
+
+	entry := widget.NewMultiLineEntry()
+	entry.SetText(a.Contents)
+	colorNames := []string{"Unchanged"}
+	for _, c := range markupColors {
+		colorNames = append(colorNames, c.name)
+	}
+	colorSelect := widget.NewSelect(colorNames, nil)
+	colorSelect.SetSelectedIndex(0)
+
+	form := dialog.NewForm(
+		"Edit "+a.Type,
+		"Apply",
+		"Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Text", entry),
+			widget.NewFormItem("Colour", colorSelect),
+		},
+		func(ok bool) {
+			if !ok {
 				return
 			}
-			if err := mw.document.Reload(); err != nil {
-				_ = os.Remove(snapshotPath)
-				dialog.ShowError(err, mw.window)
-				return
+			contents := strings.TrimSpace(entry.Text)
+			edit := pdf.AnnotationEdit{Contents: &contents}
+			if i := colorSelect.SelectedIndex(); i > 0 {
+				edit.Color = markupColors[i-1].color
 			}
+			mw.applyAnnotation(a.Page, fmt.Sprintf("%s updated on page %d", a.Type, a.Page+1), func() error {
+				return pdf.NewAnnotatorWithPasswords(mw.document.Passwords()).UpdateAnnotation(mw.document.Path(), "", a.ID, edit)
+			})
+		},
+		mw.window,
+	)
+	form.Resize(fyne.NewSize(460, 260))
+	form.Show()
+}
 
-			if undo := mw.currentUndoManager(); undo != nil {
-				undo.pushUndo(snapshotPath)
+// onMoveAnnotation lets the user drag the new box of an annotation on its
+// page.
+func (mw *MainWindow) onMoveAnnotation(a pdf.Annotation) {
+	mw.drawAnnotationRect(strings.ToLower(a.Type), func(page int, rect pdf.Rect) {
+		if page != a.Page {
+			dialog.ShowError(fmt.Errorf("draw the new box on page %d", a.Page+1), mw.window)
+			return
+		}
+		mw.applyAnnotation(page, fmt.Sprintf("%s moved on page %d", a.Type, page+1), func() error {
+			return pdf.NewAnnotatorWithPasswords(mw.document.Passwords()).UpdateAnnotation(mw.document.Path(), "", a.ID, pdf.AnnotationEdit{Rect: &rect})
+		})
+	})
+}
+
+// onRemoveAnnotation deletes an annotation once the user confirms.
+func (mw *MainWindow) onRemoveAnnotation(a pdf.Annotation) {
+	if mw.document == nil {
+		dialog.ShowInformation("No Document", "Open a PDF file first", mw.window)
+		return
+	}
+	dialog.ShowConfirm("Delete Comment",
+		fmt.Sprintf("Delete this %s on page %d?", a.Type, a.Page+1),
+		func(ok bool) {
+			if !ok {
+				return
 			}
-			mw.viewer.SetDocument(mw.document)
-			mw.sidebar.SetDocument(mw.document)
-			mw.viewer.GoToPage(page)
-			mw.statusBar.SetText(fmt.Sprintf("%s added on page %d", title, page+1))
+			mw.applyAnnotation(a.Page, fmt.Sprintf("%s deleted from page %d", a.Type, a.Page+1), func() error {
+				_, err := pdf.NewAnnotatorWithPasswords(mw.document.Passwords()).RemoveAnnotations(mw.document.Path(), "", []string{a.ID})
+				return err
+			})
 		},
 		mw.window,
 	)
-	form.Resize(fyne.NewSize(460, 220))
-	form.Show()
 }
 
 func parseFieldAssignments(input string) (map[string]string, error) {
@@ -1343,3 +2188,20 @@ func (mw *MainWindow) onAbout() {
 		"OpenPDF Reader v0.1.0\n\nAn open-source PDF viewer and editor.\n\nLicensed under Apache 2.0",
 		mw.window)
 }
+
+func (mw *MainWindow) onRenderCacheStats() {
+	dialog.ShowInformation("Render Cache Statistics", formatRenderCacheStats(mw.renderCache.Stats()), mw.window)
+}
+
+func formatRenderCacheStats(stats pdf.RenderCacheStats) string {
+	const mb = 1 << 20
+	return fmt.Sprintf(
+		"Cached pages: %d\nMemory: %.1f MB of %.0f MB\nHits: %d\nMisses: %d\nEvictions: %d",
+		stats.Entries,
+		float64(stats.Bytes)/mb,
+		float64(stats.Budget)/mb,
+		stats.Hits,
+		stats.Misses,
+		stats.Evictions,
+	)
+}
@@ -1,6 +1,10 @@
 package ui
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/openpdfreader/openpdfreader/internal/pdf"
+)
 
 func TestParseFieldAssignments(t *testing.T) {
 	values, err := parseFieldAssignments("name=Alice\nactive=true\n# comment\ncity=NYC")
@@ -116,3 +120,11 @@ func TestNormalizeThemeName(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatRenderCacheStats(t *testing.T) {
+	got := formatRenderCacheStats(pdf.RenderCacheStats{Entries: 3, Bytes: 3 << 20, Budget: 256 << 20, Hits: 5, Misses: 2, Evictions: 1})
+	want := "Cached pages: 3\nMemory: 3.0 MB of 256 MB\nHits: 5\nMisses: 2\nEvictions: 1"
+	if got != want {
+		t.Fatalf("formatRenderCacheStats() = %q, want %q", got, want)
+	}
+}